@@ -53,6 +53,9 @@ type Config struct {
 		CORSAllowedOrigins      string `envconfig:"CORS_ALLOWED_ORIGINS" default:"*"`
 		RateLimitRPS            int    `envconfig:"RATE_LIMIT_RPS" default:"100"`
 		CredentialEncryptionKey string `envconfig:"CREDENTIAL_ENCRYPTION_KEY"`
+		// TrustedProxies are comma separated IPs or CIDRs of the load balancers in front of the server,
+		// whose X-Forwarded-For entries are believed; loopback, where grpc-gateway runs, always is
+		TrustedProxies string `envconfig:"TRUSTED_PROXIES"`
 	}
 
 	// Logging Configuration
@@ -75,6 +78,38 @@ type Config struct {
 		Username string `envconfig:"SMTP_USER" default:""`
 		Password string `envconfig:"SMTP_PASSWORD" default:""`
 		From     string `envconfig:"EMAILS_FROM_EMAIL" default:""`
+		// SSL is implicit TLS (port 465), TLS is STARTTLS (port 587); with neither mail is sent in the clear
+		SSL         bool          `envconfig:"SMTP_SSL" default:"false"`
+		TLS         bool          `envconfig:"SMTP_TLS" default:"true"`
+		Timeout     time.Duration `envconfig:"SMTP_TIMEOUT" default:"10s"`
+		PoolSize    int           `envconfig:"SMTP_POOL_SIZE" default:"2"`
+		IdleTimeout time.Duration `envconfig:"SMTP_IDLE_TIMEOUT" default:"30s"`
+	}
+
+	// Outbox worker delivering queued email and WhatsApp messages
+	Outbox struct {
+		Workers      int           `envconfig:"OUTBOX_WORKERS" default:"4"`
+		BatchSize    int           `envconfig:"OUTBOX_BATCH_SIZE" default:"50"`
+		PollInterval time.Duration `envconfig:"OUTBOX_POLL_INTERVAL" default:"2s"`
+		Lease        time.Duration `envconfig:"OUTBOX_LEASE" default:"5m"`
+		MaxAttempts  int           `envconfig:"OUTBOX_MAX_ATTEMPTS" default:"8"`
+		BaseBackoff  time.Duration `envconfig:"OUTBOX_BASE_BACKOFF" default:"30s"`
+		MaxBackoff   time.Duration `envconfig:"OUTBOX_MAX_BACKOFF" default:"6h"`
+	}
+
+	// Campaign scheduler turning due campaign runs into outbox messages
+	Campaign struct {
+		PollInterval time.Duration `envconfig:"CAMPAIGN_POLL_INTERVAL" default:"30s"`
+		BatchSize    int           `envconfig:"CAMPAIGN_BATCH_SIZE" default:"10"`
+		// MaxAudience caps the messages of one run; larger audiences are cut off
+		MaxAudience int `envconfig:"CAMPAIGN_MAX_AUDIENCE" default:"5000"`
+	}
+
+	// Bounce and complaint reports; the webhook is disabled without a secret, the mailbox without a path
+	Bounce struct {
+		WebhookSecret string        `envconfig:"BOUNCE_WEBHOOK_SECRET"`
+		MailboxPath   string        `envconfig:"BOUNCE_MAILBOX_PATH"`
+		PollInterval  time.Duration `envconfig:"BOUNCE_POLL_INTERVAL" default:"1m"`
 	}
 
 	// Superuser Configuration
@@ -95,6 +130,30 @@ type Config struct {
 		URL     string `envconfig:"WAHA_URL" default:"http://localhost:3000"`
 		APIKey  string `envconfig:"WAHA_API_KEY"`
 		Session string `envconfig:"WAHA_SESSION" default:"default"`
+		// RatePerMinute caps the messages each session sends, across instances, to keep numbers from being banned; 0 turns it off
+		RatePerMinute int `envconfig:"WAHA_RATE_PER_MINUTE" default:"20"`
+		// WebhookHMACKey is the hmac key of the webhook configured in WAHA; the webhook is disabled without it
+		WebhookHMACKey string `envconfig:"WAHA_WEBHOOK_HMAC_KEY"`
+		// WebhookURL is the public address of /v1/webhooks/waha, set on the sessions salons start
+		WebhookURL string `envconfig:"WAHA_WEBHOOK_URL"`
+	}
+
+	// SMS gateway Configuration; SMS is disabled without a gateway URL
+	SMS struct {
+		GatewayURL string `envconfig:"SMS_GATEWAY_URL"`
+		APIKey     string `envconfig:"SMS_API_KEY"`
+		Sender     string `envconfig:"SMS_SENDER"`
+	}
+
+	// Notification routing: channels tried in order for messages sent to a phone number
+	Notify struct {
+		PhoneRoute []string `envconfig:"NOTIFY_PHONE_ROUTE" default:"whatsapp,sms"`
+		// OTPRoute overrides PhoneRoute for one-time codes, which never go to the in-app inbox
+		OTPRoute []string `envconfig:"NOTIFY_OTP_ROUTE"`
+		// UnsubscribeSecret signs the unsubscribe links of email people can opt out of; no links are added without it
+		UnsubscribeSecret string `envconfig:"NOTIFY_UNSUBSCRIBE_SECRET"`
+		// UnsubscribeURL is the public address of POST /v1/unsubscribe, BASE_URL/v1/unsubscribe when empty
+		UnsubscribeURL string `envconfig:"NOTIFY_UNSUBSCRIBE_URL"`
 	}
 
 	// DOKU (Jokul Checkout) Configuration
@@ -103,6 +162,11 @@ type Config struct {
 		ClientID  string `envconfig:"DOKU_CLIENT_ID"`
 		SecretKey string `envconfig:"DOKU_SECRET_KEY"`
 	}
+
+	// GeoIP (MaxMind country database) Configuration
+	GeoIP struct {
+		DatabasePath string `envconfig:"GEOIP_DB_PATH"`
+	}
 }
 
 // Load loads configuration from environment variables and .env file
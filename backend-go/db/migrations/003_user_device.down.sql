@@ -0,0 +1,6 @@
+DELETE FROM email_template WHERE name IN ('new_device_login', 'new_device_login_phone');
+
+DROP TABLE public.user_device;
+
+ALTER TABLE public."user" DROP COLUMN sessions_revoked_at;
+ALTER TABLE public."user" DROP COLUMN last_login_at;
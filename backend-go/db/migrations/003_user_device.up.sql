@@ -0,0 +1,30 @@
+ALTER TABLE public."user" ADD COLUMN last_login_at timestamptz NULL;
+ALTER TABLE public."user" ADD COLUMN sessions_revoked_at timestamptz NULL;
+
+CREATE TABLE public.user_device (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    fingerprint varchar(64) NOT NULL,
+    user_agent varchar DEFAULT '' NOT NULL,
+    ip_prefix varchar(64) DEFAULT '' NOT NULL,
+    country varchar(2) DEFAULT '' NOT NULL,
+    first_seen_at timestamptz DEFAULT now() NOT NULL,
+    last_seen_at timestamptz DEFAULT now() NOT NULL,
+    CONSTRAINT user_device_pkey PRIMARY KEY (id),
+    CONSTRAINT user_device_user_id_fkey FOREIGN KEY (user_id) REFERENCES public."user"(id) ON DELETE CASCADE,
+    CONSTRAINT uq_user_device_fingerprint UNIQUE (user_id, fingerprint)
+);
+
+INSERT INTO email_template (name, subject, body)
+VALUES
+(
+  'new_device_login',
+  'New Sign-in to Your Account',
+  '<p>Hello,</p><p>Your account was just signed in from a new device.</p><p>Device: {{.device}}<br>IP address: {{.ip}}<br>Country: {{.country}}<br>Time: {{.time}}</p><p>If this was you, you can ignore this email. Otherwise <a href="{{.link}}">secure your account</a> to sign out all sessions.</p>'
+),
+(
+  'new_device_login_phone',
+  'New Sign-in to Your Account',
+  'New sign-in to your account from {{.device}} ({{.ip}}, {{.country}}) at {{.time}}. Not you? Secure your account: {{.link}}'
+)
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,4 @@
+DELETE FROM email_template WHERE name IN ('organization_invitation', 'organization_invitation_phone');
+
+DROP TABLE public.organization_member;
+DROP TABLE public.organization;
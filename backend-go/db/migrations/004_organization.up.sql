@@ -0,0 +1,37 @@
+CREATE TABLE public.organization (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    name varchar(255) NOT NULL,
+    created_by uuid NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    updated_at timestamptz DEFAULT now() NOT NULL,
+    CONSTRAINT organization_pkey PRIMARY KEY (id),
+    CONSTRAINT organization_created_by_fkey FOREIGN KEY (created_by) REFERENCES public."user"(id) ON DELETE SET NULL
+);
+
+-- Roles here are scoped to one organization, unlike the global user_role assignments
+CREATE TABLE public.organization_member (
+    organization_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    role varchar(50) NOT NULL,
+    joined_at timestamptz DEFAULT now() NOT NULL,
+    CONSTRAINT organization_member_pkey PRIMARY KEY (organization_id, user_id),
+    CONSTRAINT organization_member_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organization(id) ON DELETE CASCADE,
+    CONSTRAINT organization_member_user_id_fkey FOREIGN KEY (user_id) REFERENCES public."user"(id) ON DELETE CASCADE,
+    CONSTRAINT organization_member_role_check CHECK (role IN ('salon_owner', 'salon_employee'))
+);
+
+CREATE INDEX idx_organization_member_user ON public.organization_member (user_id);
+
+INSERT INTO email_template (name, subject, body)
+VALUES
+(
+  'organization_invitation',
+  'You Have Been Invited to Join a Salon',
+  '<p>Hello,</p><p>{{.inviter}} invited you to join <strong>{{.organization}}</strong> as {{.role}}.</p><p><a href="{{.link}}">Accept Invitation</a></p><p>This invitation expires in 7 days.</p>'
+),
+(
+  'organization_invitation_phone',
+  'You Have Been Invited to Join a Salon',
+  '{{.inviter}} invited you to join {{.organization}} as {{.role}}. Accept the invitation: {{.link}}'
+)
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,16 @@
+DROP POLICY organization_member_delete ON public.organization_member;
+DROP POLICY organization_member_update ON public.organization_member;
+DROP POLICY organization_member_insert ON public.organization_member;
+DROP POLICY organization_member_select ON public.organization_member;
+ALTER TABLE public.organization_member NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.organization_member DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY organization_delete ON public.organization;
+DROP POLICY organization_update ON public.organization;
+DROP POLICY organization_insert ON public.organization;
+DROP POLICY organization_select ON public.organization;
+ALTER TABLE public.organization NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.organization DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION public.app_current_user_id();
+DROP FUNCTION public.app_current_org_id();
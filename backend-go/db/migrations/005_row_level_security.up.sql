@@ -0,0 +1,61 @@
+-- Row level security for tenant-scoped tables.
+-- The application publishes the request tenant through the app.current_org_id and
+-- app.current_user_id settings; see internal/infrastructure/database/tenant.go.
+-- Policies do not apply to superusers or roles with BYPASSRLS, so the application
+-- must connect as a regular role. FORCE makes them apply to the table owner too.
+
+CREATE OR REPLACE FUNCTION public.app_current_org_id() RETURNS uuid
+LANGUAGE sql STABLE AS $$
+    SELECT NULLIF(current_setting('app.current_org_id', true), '')::uuid
+$$;
+
+CREATE OR REPLACE FUNCTION public.app_current_user_id() RETURNS uuid
+LANGUAGE sql STABLE AS $$
+    SELECT NULLIF(current_setting('app.current_user_id', true), '')::uuid
+$$;
+
+ALTER TABLE public.organization ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.organization FORCE ROW LEVEL SECURITY;
+
+-- Visible when selected via X-Org-Id, or when the current user created or belongs to it
+CREATE POLICY organization_select ON public.organization FOR SELECT
+    USING (
+        id = public.app_current_org_id()
+        OR created_by = public.app_current_user_id()
+        OR EXISTS (
+            SELECT 1 FROM public.organization_member m
+            WHERE m.organization_id = organization.id
+              AND m.user_id = public.app_current_user_id()
+        )
+    );
+CREATE POLICY organization_insert ON public.organization FOR INSERT
+    WITH CHECK (created_by = public.app_current_user_id());
+CREATE POLICY organization_update ON public.organization FOR UPDATE
+    USING (id = public.app_current_org_id())
+    WITH CHECK (id = public.app_current_org_id());
+CREATE POLICY organization_delete ON public.organization FOR DELETE
+    USING (id = public.app_current_org_id());
+
+ALTER TABLE public.organization_member ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.organization_member FORCE ROW LEVEL SECURITY;
+
+-- Members of the selected organization, plus the current user's own memberships
+CREATE POLICY organization_member_select ON public.organization_member FOR SELECT
+    USING (
+        organization_id = public.app_current_org_id()
+        OR user_id = public.app_current_user_id()
+    );
+CREATE POLICY organization_member_insert ON public.organization_member FOR INSERT
+    WITH CHECK (
+        organization_id = public.app_current_org_id()
+        OR EXISTS (
+            SELECT 1 FROM public.organization o
+            WHERE o.id = organization_member.organization_id
+              AND o.created_by = public.app_current_user_id()
+        )
+    );
+CREATE POLICY organization_member_update ON public.organization_member FOR UPDATE
+    USING (organization_id = public.app_current_org_id())
+    WITH CHECK (organization_id = public.app_current_org_id());
+CREATE POLICY organization_member_delete ON public.organization_member FOR DELETE
+    USING (organization_id = public.app_current_org_id());
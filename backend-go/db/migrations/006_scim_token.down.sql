@@ -0,0 +1 @@
+DROP TABLE public.organization_scim_token;
@@ -0,0 +1,14 @@
+-- Bearer tokens used by an organization's identity provider for SCIM provisioning.
+-- Looked up by hash before the tenant is known, in the system scope; row level security is added in 024.
+CREATE TABLE public.organization_scim_token (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    organization_id uuid NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    description varchar(255) DEFAULT '' NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    last_used_at timestamptz NULL,
+    revoked_at timestamptz NULL,
+    CONSTRAINT organization_scim_token_pkey PRIMARY KEY (id),
+    CONSTRAINT organization_scim_token_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organization(id) ON DELETE CASCADE,
+    CONSTRAINT uq_organization_scim_token_hash UNIQUE (token_hash)
+);
@@ -0,0 +1,3 @@
+DELETE FROM email_template WHERE name IN ('welcome_user', 'welcome_user_phone');
+
+DROP TABLE public.user_import_job;
@@ -0,0 +1,33 @@
+-- Bulk user imports run in the background; admins poll the job for progress and the per-row report
+CREATE TABLE public.user_import_job (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    created_by uuid NULL,
+    status varchar(20) DEFAULT 'pending' NOT NULL,
+    dry_run boolean DEFAULT false NOT NULL,
+    total_rows int DEFAULT 0 NOT NULL,
+    processed_rows int DEFAULT 0 NOT NULL,
+    created_count int DEFAULT 0 NOT NULL,
+    skipped_count int DEFAULT 0 NOT NULL,
+    error_count int DEFAULT 0 NOT NULL,
+    report jsonb DEFAULT '[]'::jsonb NOT NULL,
+    error text DEFAULT '' NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    finished_at timestamptz NULL,
+    CONSTRAINT user_import_job_pkey PRIMARY KEY (id),
+    CONSTRAINT user_import_job_created_by_fkey FOREIGN KEY (created_by) REFERENCES public."user"(id) ON DELETE SET NULL,
+    CONSTRAINT user_import_job_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+INSERT INTO email_template (name, subject, body)
+VALUES
+(
+  'welcome_user',
+  'Welcome! Your Account Is Ready',
+  '<p>Hello {{.name}},</p><p>An account has been created for you.</p><p><a href="{{.link}}">Set your password</a> to sign in.</p><p>This link expires in 7 days.</p>'
+),
+(
+  'welcome_user_phone',
+  'Welcome! Your Account Is Ready',
+  'Hello {{.name}}, an account has been created for you. Sign in with your phone number: {{.link}}'
+)
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,7 @@
+DROP FUNCTION public.user_sort_key(public."user", text);
+
+DROP INDEX public.ix_oauth_account_user_provider;
+DROP INDEX public.ix_user_created_at;
+DROP INDEX public.ix_user_phone_trgm;
+DROP INDEX public.ix_user_full_name_trgm;
+DROP INDEX public.ix_user_email_trgm;
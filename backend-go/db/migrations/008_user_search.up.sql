@@ -0,0 +1,21 @@
+-- Substring search over email, name and phone for the admin user list
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX ix_user_email_trgm ON public."user" USING gin (email gin_trgm_ops);
+CREATE INDEX ix_user_full_name_trgm ON public."user" USING gin (full_name gin_trgm_ops);
+CREATE INDEX ix_user_phone_trgm ON public."user" USING gin (phone_number gin_trgm_ops);
+
+CREATE INDEX ix_user_created_at ON public."user" (created_at DESC, id DESC);
+CREATE INDEX ix_oauth_account_user_provider ON public.oauth_account (user_id, provider);
+
+-- Value the admin user list is ordered by; also the cursor position for keyset pagination.
+-- Timestamps are rendered as fixed width UTC text so they order the same as the time they encode.
+CREATE FUNCTION public.user_sort_key(u public."user", sort_by text) RETURNS text
+LANGUAGE sql STABLE AS $$
+    SELECT CASE sort_by
+        WHEN 'email' THEN lower(u.email)
+        WHEN 'full_name' THEN lower(coalesce(u.full_name, ''))
+        WHEN 'last_login_at' THEN to_char(coalesce(u.last_login_at, 'epoch'::timestamptz) AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US')
+        ELSE to_char(coalesce(u.created_at, 'epoch'::timestamptz) AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US')
+    END
+$$;
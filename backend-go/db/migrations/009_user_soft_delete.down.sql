@@ -0,0 +1,12 @@
+ALTER TABLE public.subscription DROP CONSTRAINT subscription_user_id_fkey;
+ALTER TABLE public.subscription ADD CONSTRAINT subscription_user_id_fkey FOREIGN KEY (user_id) REFERENCES public."user"(id) ON DELETE CASCADE;
+ALTER TABLE public.payment DROP CONSTRAINT payment_user_id_fkey;
+ALTER TABLE public.payment ADD CONSTRAINT payment_user_id_fkey FOREIGN KEY (user_id) REFERENCES public."user"(id) ON DELETE CASCADE;
+
+DROP INDEX public.ix_user_deleted_at;
+DROP INDEX public.ix_user_phone;
+DROP INDEX public.ix_user_email;
+CREATE UNIQUE INDEX ix_user_email ON public."user" USING btree (email) WHERE (is_active = true);
+CREATE UNIQUE INDEX ix_user_phone ON public."user" USING btree (phone_number) WHERE (is_active = true AND phone_number IS NOT NULL);
+
+ALTER TABLE public."user" DROP COLUMN deleted_at;
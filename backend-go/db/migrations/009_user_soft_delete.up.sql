@@ -0,0 +1,15 @@
+-- Users are soft deleted so their payments and subscriptions are kept
+ALTER TABLE public."user" ADD COLUMN deleted_at timestamptz NULL;
+
+-- A deleted user's email and phone number may be registered again
+DROP INDEX public.ix_user_email;
+DROP INDEX public.ix_user_phone;
+CREATE UNIQUE INDEX ix_user_email ON public."user" USING btree (email) WHERE (is_active = true AND deleted_at IS NULL);
+CREATE UNIQUE INDEX ix_user_phone ON public."user" USING btree (phone_number) WHERE (is_active = true AND deleted_at IS NULL AND phone_number IS NOT NULL);
+CREATE INDEX ix_user_deleted_at ON public."user" (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Financial history must never disappear with a user row
+ALTER TABLE public.payment DROP CONSTRAINT payment_user_id_fkey;
+ALTER TABLE public.payment ADD CONSTRAINT payment_user_id_fkey FOREIGN KEY (user_id) REFERENCES public."user"(id) ON DELETE RESTRICT;
+ALTER TABLE public.subscription DROP CONSTRAINT subscription_user_id_fkey;
+ALTER TABLE public.subscription ADD CONSTRAINT subscription_user_id_fkey FOREIGN KEY (user_id) REFERENCES public."user"(id) ON DELETE RESTRICT;
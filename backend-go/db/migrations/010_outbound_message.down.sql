@@ -0,0 +1 @@
+DROP TABLE public.outbound_message;
@@ -0,0 +1,25 @@
+-- Transactional outbox: messages are written with the data they belong to and delivered by a worker
+CREATE TABLE public.outbound_message (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    channel varchar(20) NOT NULL,
+    recipient varchar(255) NOT NULL,
+    subject varchar(255) DEFAULT '' NOT NULL,
+    body text NOT NULL,
+    user_id uuid NULL,
+    status varchar(20) DEFAULT 'pending' NOT NULL,
+    attempts int DEFAULT 0 NOT NULL,
+    max_attempts int DEFAULT 8 NOT NULL,
+    next_attempt_at timestamptz DEFAULT now() NOT NULL,
+    locked_until timestamptz NULL,
+    last_error text DEFAULT '' NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    sent_at timestamptz NULL,
+    CONSTRAINT outbound_message_pkey PRIMARY KEY (id),
+    CONSTRAINT outbound_message_user_id_fkey FOREIGN KEY (user_id) REFERENCES public."user"(id) ON DELETE SET NULL,
+    CONSTRAINT outbound_message_channel_check CHECK (channel IN ('email', 'whatsapp')),
+    CONSTRAINT outbound_message_status_check CHECK (status IN ('pending', 'sending', 'sent', 'dead'))
+);
+
+CREATE INDEX ix_outbound_message_due ON public.outbound_message (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX ix_outbound_message_lease ON public.outbound_message (locked_until) WHERE status = 'sending';
+CREATE INDEX ix_outbound_message_status ON public.outbound_message (status, created_at DESC);
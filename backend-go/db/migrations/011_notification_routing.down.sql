@@ -0,0 +1,8 @@
+ALTER TABLE public."user" DROP CONSTRAINT user_preferred_channel_check;
+ALTER TABLE public."user" DROP COLUMN preferred_channel;
+
+DELETE FROM public.outbound_message WHERE channel = 'sms';
+ALTER TABLE public.outbound_message DROP CONSTRAINT outbound_message_channel_check;
+ALTER TABLE public.outbound_message ADD CONSTRAINT outbound_message_channel_check CHECK (channel IN ('email', 'whatsapp'));
+
+ALTER TABLE public.outbound_message DROP COLUMN fallback_channels;
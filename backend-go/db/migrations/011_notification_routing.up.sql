@@ -0,0 +1,9 @@
+-- Channels after the first are tried in order when the first cannot deliver
+ALTER TABLE public.outbound_message ADD COLUMN fallback_channels text[] DEFAULT '{}' NOT NULL;
+
+ALTER TABLE public.outbound_message DROP CONSTRAINT outbound_message_channel_check;
+ALTER TABLE public.outbound_message ADD CONSTRAINT outbound_message_channel_check CHECK (channel IN ('email', 'whatsapp', 'sms'));
+
+-- Channel tried first for messages sent to the user's phone
+ALTER TABLE public."user" ADD COLUMN preferred_channel varchar(20) NULL;
+ALTER TABLE public."user" ADD CONSTRAINT user_preferred_channel_check CHECK (preferred_channel IN ('whatsapp', 'sms'));
@@ -0,0 +1,10 @@
+-- Only the active version of each template survives the rollback
+DELETE FROM email_template WHERE is_active = FALSE;
+
+ALTER TABLE email_template
+    DROP CONSTRAINT IF EXISTS email_template_created_by_fkey,
+    DROP CONSTRAINT IF EXISTS email_template_name_version_key,
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS version;
+
+ALTER TABLE email_template ADD CONSTRAINT email_template_name_key UNIQUE (name);
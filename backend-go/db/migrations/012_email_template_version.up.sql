@@ -0,0 +1,8 @@
+-- Keep every edit of a template as a numbered version; one version per name is active
+ALTER TABLE email_template DROP CONSTRAINT IF EXISTS email_template_name_key;
+
+ALTER TABLE email_template
+    ADD COLUMN version integer DEFAULT 1 NOT NULL,
+    ADD COLUMN created_by uuid NULL,
+    ADD CONSTRAINT email_template_name_version_key UNIQUE (name, version),
+    ADD CONSTRAINT email_template_created_by_fkey FOREIGN KEY (created_by) REFERENCES public."user"(id) ON DELETE SET NULL;
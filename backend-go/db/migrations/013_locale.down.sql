@@ -0,0 +1,11 @@
+DELETE FROM email_template WHERE locale <> 'en';
+
+DROP INDEX IF EXISTS idx_email_template_name_locale;
+CREATE UNIQUE INDEX idx_email_template_name ON public.email_template USING btree (name) WHERE (is_active = true);
+
+ALTER TABLE email_template
+    DROP CONSTRAINT IF EXISTS email_template_name_locale_version_key,
+    ADD CONSTRAINT email_template_name_version_key UNIQUE (name, version),
+    DROP COLUMN IF EXISTS locale;
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,70 @@
+-- BCP 47 language tag such as id-ID; NULL follows the language of each request
+ALTER TABLE "user" ADD COLUMN locale varchar(35) NULL;
+
+-- Templates get one variant per locale, each with its own versions and active version
+ALTER TABLE email_template ADD COLUMN locale varchar(35) DEFAULT 'en' NOT NULL;
+
+ALTER TABLE email_template
+    DROP CONSTRAINT email_template_name_version_key,
+    ADD CONSTRAINT email_template_name_locale_version_key UNIQUE (name, locale, version);
+
+DROP INDEX IF EXISTS idx_email_template_name;
+CREATE UNIQUE INDEX idx_email_template_name_locale ON public.email_template USING btree (name, locale) WHERE (is_active = true);
+
+INSERT INTO email_template (name, locale, subject, body)
+VALUES
+(
+  'verification_email',
+  'id',
+  'Verifikasi Alamat Email Anda',
+  '<p>Halo,</p><p>Kode verifikasi Anda adalah: <strong>{{.code}}</strong></p>'
+),
+(
+  'password_reset',
+  'id',
+  'Atur Ulang Kata Sandi Anda',
+  '<p>Halo,</p><p>Klik tautan berikut untuk mengatur ulang kata sandi Anda: <a href="{{.link}}">Atur Ulang Kata Sandi</a></p>'
+),
+(
+  'verification_phone',
+  'id',
+  'Verifikasi Nomor Telepon Anda',
+  'Kode verifikasi Anda adalah {{.code}}'
+),
+(
+  'new_device_login',
+  'id',
+  'Login Baru ke Akun Anda',
+  '<p>Halo,</p><p>Akun Anda baru saja digunakan untuk masuk dari perangkat baru.</p><p>Perangkat: {{.device}}<br>Alamat IP: {{.ip}}<br>Negara: {{.country}}<br>Waktu: {{.time}}</p><p>Jika ini Anda, abaikan email ini. Jika bukan, <a href="{{.link}}">amankan akun Anda</a> untuk mengakhiri semua sesi.</p>'
+),
+(
+  'new_device_login_phone',
+  'id',
+  'Login Baru ke Akun Anda',
+  'Login baru ke akun Anda dari {{.device}} ({{.ip}}, {{.country}}) pada {{.time}}. Bukan Anda? Amankan akun Anda: {{.link}}'
+),
+(
+  'organization_invitation',
+  'id',
+  'Anda Diundang Bergabung dengan Salon',
+  '<p>Halo,</p><p>{{.inviter}} mengundang Anda bergabung dengan <strong>{{.organization}}</strong> sebagai {{.role}}.</p><p><a href="{{.link}}">Terima Undangan</a></p><p>Undangan ini berlaku selama 7 hari.</p>'
+),
+(
+  'organization_invitation_phone',
+  'id',
+  'Anda Diundang Bergabung dengan Salon',
+  '{{.inviter}} mengundang Anda bergabung dengan {{.organization}} sebagai {{.role}}. Terima undangan: {{.link}}'
+),
+(
+  'welcome_user',
+  'id',
+  'Selamat Datang! Akun Anda Sudah Siap',
+  '<p>Halo {{.name}},</p><p>Sebuah akun telah dibuat untuk Anda.</p><p><a href="{{.link}}">Atur kata sandi Anda</a> untuk masuk.</p><p>Tautan ini berlaku selama 7 hari.</p>'
+),
+(
+  'welcome_user_phone',
+  'id',
+  'Selamat Datang! Akun Anda Sudah Siap',
+  'Halo {{.name}}, sebuah akun telah dibuat untuk Anda. Masuk dengan nomor telepon Anda: {{.link}}'
+)
+ON CONFLICT (name, locale, version) DO NOTHING;
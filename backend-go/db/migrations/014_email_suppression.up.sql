@@ -0,0 +1,14 @@
+-- Addresses that hard bounced or complained are not mailed again until an admin lifts them
+CREATE TABLE public.email_suppression (
+    email varchar(255) NOT NULL,
+    reason varchar(20) NOT NULL,
+    detail text DEFAULT '' NOT NULL,
+    source varchar(20) DEFAULT '' NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    updated_at timestamptz DEFAULT now() NOT NULL,
+    CONSTRAINT email_suppression_pkey PRIMARY KEY (email),
+    CONSTRAINT email_suppression_email_lower_check CHECK (email = lower(email)),
+    CONSTRAINT email_suppression_reason_check CHECK (reason IN ('bounce', 'complaint'))
+);
+
+CREATE INDEX ix_email_suppression_created ON public.email_suppression (created_at DESC);
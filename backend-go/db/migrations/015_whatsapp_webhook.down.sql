@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS public.whatsapp_session;
+
+DROP INDEX IF EXISTS ix_outbound_message_external_id;
+
+ALTER TABLE public.outbound_message
+    DROP COLUMN IF EXISTS read_at,
+    DROP COLUMN IF EXISTS delivered_at,
+    DROP COLUMN IF EXISTS external_id;
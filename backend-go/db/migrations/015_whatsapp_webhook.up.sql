@@ -0,0 +1,16 @@
+-- WhatsApp acks are matched to outbox messages by the id WAHA assigned when sending
+ALTER TABLE public.outbound_message
+    ADD COLUMN external_id varchar(255) NULL,
+    ADD COLUMN delivered_at timestamptz NULL,
+    ADD COLUMN read_at timestamptz NULL;
+
+CREATE INDEX ix_outbound_message_external_id ON public.outbound_message (external_id) WHERE external_id IS NOT NULL;
+
+-- Last reported state of each WAHA session, kept up to date by its webhook events
+CREATE TABLE public.whatsapp_session (
+    name varchar(100) NOT NULL,
+    status varchar(30) NOT NULL,
+    status_changed_at timestamptz DEFAULT now() NOT NULL,
+    last_event_at timestamptz DEFAULT now() NOT NULL,
+    CONSTRAINT whatsapp_session_pkey PRIMARY KEY (name)
+);
@@ -0,0 +1,5 @@
+DELETE FROM email_template WHERE name IN ('wedding_invitation_phone', 'booking_confirmation_phone');
+
+ALTER TABLE public.outbound_message DROP COLUMN IF EXISTS whatsapp_content;
+
+ALTER TABLE email_template DROP COLUMN IF EXISTS whatsapp_content;
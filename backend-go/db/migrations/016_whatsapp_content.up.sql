@@ -0,0 +1,37 @@
+-- Media, map pins and buttons of WhatsApp templates; the body stays the text SMS falls back to
+ALTER TABLE email_template ADD COLUMN whatsapp_content jsonb NULL;
+
+-- The rendered content travels with the queued message to the WhatsApp channel
+ALTER TABLE public.outbound_message ADD COLUMN whatsapp_content jsonb NULL;
+
+INSERT INTO email_template (name, locale, subject, body, whatsapp_content)
+VALUES
+(
+  'wedding_invitation_phone',
+  'en',
+  'Wedding Invitation',
+  'Dear {{.name}}, {{.couple}} joyfully invite you to their wedding on {{.date}} at {{.venue}}. Will you attend? Details: {{.link}}',
+  '{"media": {"kind": "image", "url": "{{.image_url}}"}, "location": {"latitude": "{{.latitude}}", "longitude": "{{.longitude}}", "title": "{{.venue}}"}, "buttons": [{"type": "reply", "text": "Yes, I will attend"}, {"type": "reply", "text": "Sorry, I can''t"}, {"type": "url", "text": "View invitation", "url": "{{.link}}"}]}'
+),
+(
+  'wedding_invitation_phone',
+  'id',
+  'Undangan Pernikahan',
+  'Yth. {{.name}}, {{.couple}} dengan bahagia mengundang Anda ke pernikahan mereka pada {{.date}} di {{.venue}}. Apakah Anda akan hadir? Detail: {{.link}}',
+  '{"media": {"kind": "image", "url": "{{.image_url}}"}, "location": {"latitude": "{{.latitude}}", "longitude": "{{.longitude}}", "title": "{{.venue}}"}, "buttons": [{"type": "reply", "text": "Ya, saya hadir"}, {"type": "reply", "text": "Maaf, tidak bisa"}, {"type": "url", "text": "Lihat undangan", "url": "{{.link}}"}]}'
+),
+(
+  'booking_confirmation_phone',
+  'en',
+  'Booking Confirmed',
+  'Hi {{.name}}, your {{.service}} at {{.organization}} is booked for {{.time}}. Manage your booking: {{.link}}',
+  '{"location": {"latitude": "{{.latitude}}", "longitude": "{{.longitude}}", "title": "{{.organization}}"}, "buttons": [{"type": "reply", "text": "Confirm"}, {"type": "url", "text": "Manage booking", "url": "{{.link}}"}]}'
+),
+(
+  'booking_confirmation_phone',
+  'id',
+  'Booking Dikonfirmasi',
+  'Halo {{.name}}, {{.service}} Anda di {{.organization}} sudah dipesan untuk {{.time}}. Kelola booking Anda: {{.link}}',
+  '{"location": {"latitude": "{{.latitude}}", "longitude": "{{.longitude}}", "title": "{{.organization}}"}, "buttons": [{"type": "reply", "text": "Konfirmasi"}, {"type": "url", "text": "Kelola booking", "url": "{{.link}}"}]}'
+)
+ON CONFLICT (name, locale, version) DO NOTHING;
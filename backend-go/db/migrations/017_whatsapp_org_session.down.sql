@@ -0,0 +1,12 @@
+ALTER TABLE public.outbound_message
+    DROP CONSTRAINT IF EXISTS outbound_message_organization_id_fkey,
+    DROP COLUMN IF EXISTS sender,
+    DROP COLUMN IF EXISTS organization_id;
+
+DELETE FROM public.whatsapp_session WHERE organization_id IS NOT NULL;
+
+ALTER TABLE public.whatsapp_session
+    DROP CONSTRAINT IF EXISTS whatsapp_session_organization_id_fkey,
+    DROP CONSTRAINT IF EXISTS whatsapp_session_organization_id_key,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS organization_id;
@@ -0,0 +1,14 @@
+-- Salons send from their own WhatsApp number through a WAHA session of their own; the platform
+-- session named by WAHA_SESSION has no organization. Webhook events, which update it, carry no
+-- tenant and run in the system scope of row level security, which is added in 024.
+ALTER TABLE public.whatsapp_session
+    ADD COLUMN organization_id uuid NULL,
+    ADD COLUMN created_at timestamptz DEFAULT now() NOT NULL,
+    ADD CONSTRAINT whatsapp_session_organization_id_key UNIQUE (organization_id),
+    ADD CONSTRAINT whatsapp_session_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organization(id) ON DELETE CASCADE;
+
+-- The organization a message is sent on behalf of, and the WAHA session it went out from
+ALTER TABLE public.outbound_message
+    ADD COLUMN organization_id uuid NULL,
+    ADD COLUMN sender varchar(100) NULL,
+    ADD CONSTRAINT outbound_message_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organization(id) ON DELETE SET NULL;
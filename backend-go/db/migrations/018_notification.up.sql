@@ -0,0 +1,16 @@
+-- In-app notifications: a user's inbox, also streamed to the sessions they have open
+CREATE TABLE public.notification (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    category varchar(50) NOT NULL,
+    title varchar(255) NOT NULL,
+    body text DEFAULT '' NOT NULL,
+    data jsonb DEFAULT '{}'::jsonb NOT NULL,
+    read_at timestamptz NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    CONSTRAINT notification_pkey PRIMARY KEY (id),
+    CONSTRAINT notification_user_id_fkey FOREIGN KEY (user_id) REFERENCES public."user"(id) ON DELETE CASCADE
+);
+
+CREATE INDEX ix_notification_user_created ON public.notification (user_id, created_at DESC, id DESC);
+CREATE INDEX ix_notification_user_unread ON public.notification (user_id) WHERE read_at IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE public.outbound_message DROP COLUMN IF EXISTS category;
+
+DROP TABLE IF EXISTS public.notification_preference;
@@ -0,0 +1,21 @@
+-- Opt-outs per category and channel; a category and channel without a row is sent.
+-- People who are not users, like wedding guests, are identified by their email address or phone number.
+CREATE TABLE public.notification_preference (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NULL,
+    recipient varchar(255) DEFAULT '' NOT NULL,
+    category varchar(50) NOT NULL,
+    channel varchar(20) NOT NULL,
+    enabled boolean NOT NULL,
+    updated_at timestamptz DEFAULT now() NOT NULL,
+    CONSTRAINT notification_preference_pkey PRIMARY KEY (id),
+    CONSTRAINT notification_preference_user_id_fkey FOREIGN KEY (user_id) REFERENCES public."user"(id) ON DELETE CASCADE,
+    CONSTRAINT notification_preference_subject_check CHECK ((user_id IS NULL) <> (recipient = '')),
+    CONSTRAINT notification_preference_channel_check CHECK (channel IN ('email', 'whatsapp', 'sms', 'in_app'))
+);
+
+CREATE UNIQUE INDEX ux_notification_preference_user ON public.notification_preference (user_id, category, channel) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX ux_notification_preference_recipient ON public.notification_preference (recipient, category, channel) WHERE user_id IS NULL;
+
+-- Transactional messages, such as one-time codes and password resets, are sent regardless of preferences
+ALTER TABLE public.outbound_message ADD COLUMN category varchar(50) DEFAULT 'transactional' NOT NULL;
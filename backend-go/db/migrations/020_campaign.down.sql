@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public.whatsapp_send_slot;
+
+ALTER TABLE public.outbound_message DROP COLUMN IF EXISTS campaign_id;
+
+DROP TABLE IF EXISTS public.campaign;
@@ -0,0 +1,49 @@
+-- Reminders and promotions sent to an audience at a given time, or on a cron schedule.
+-- The scheduler turns each run into outbox messages. It runs without a tenant, in the system scope
+-- of the row level security added in 024.
+CREATE TABLE public.campaign (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    organization_id uuid NULL,
+    created_by uuid NULL,
+    name varchar(255) NOT NULL,
+    channel varchar(20) NOT NULL,
+    template_name varchar(100) NOT NULL,
+    category varchar(50) NOT NULL,
+    audience jsonb DEFAULT '{}'::jsonb NOT NULL,
+    fields jsonb DEFAULT '{}'::jsonb NOT NULL,
+    send_at timestamptz NULL,
+    cron_expression varchar(100) DEFAULT '' NOT NULL,
+    timezone varchar(64) DEFAULT 'UTC' NOT NULL,
+    rate_per_minute int DEFAULT 0 NOT NULL,
+    status varchar(20) DEFAULT 'scheduled' NOT NULL,
+    next_run_at timestamptz NULL,
+    last_run_at timestamptz NULL,
+    runs int DEFAULT 0 NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    updated_at timestamptz DEFAULT now() NOT NULL,
+    CONSTRAINT campaign_pkey PRIMARY KEY (id),
+    CONSTRAINT campaign_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES public.organization(id) ON DELETE CASCADE,
+    CONSTRAINT campaign_created_by_fkey FOREIGN KEY (created_by) REFERENCES public."user"(id) ON DELETE SET NULL,
+    CONSTRAINT campaign_channel_check CHECK (channel IN ('email', 'whatsapp', 'sms')),
+    CONSTRAINT campaign_category_check CHECK (category IN ('reminder', 'marketing')),
+    CONSTRAINT campaign_schedule_check CHECK (send_at IS NOT NULL OR cron_expression <> ''),
+    CONSTRAINT campaign_status_check CHECK (status IN ('scheduled', 'completed', 'paused', 'cancelled'))
+);
+
+CREATE INDEX ix_campaign_due ON public.campaign (next_run_at) WHERE status = 'scheduled';
+CREATE INDEX ix_campaign_organization ON public.campaign (organization_id, created_at DESC);
+CREATE INDEX ix_campaign_created_by ON public.campaign (created_by, created_at DESC);
+
+-- Messages of a campaign are held while it is paused and counted for its delivery stats
+ALTER TABLE public.outbound_message
+    ADD COLUMN campaign_id uuid NULL,
+    ADD CONSTRAINT outbound_message_campaign_id_fkey FOREIGN KEY (campaign_id) REFERENCES public.campaign(id) ON DELETE SET NULL;
+
+CREATE INDEX ix_outbound_message_campaign ON public.outbound_message (campaign_id, status) WHERE campaign_id IS NOT NULL;
+
+-- When each WAHA session may send next, shared by every instance so WhatsApp sees a steady pace from a number
+CREATE TABLE public.whatsapp_send_slot (
+    session varchar(100) NOT NULL,
+    next_send_at timestamptz NOT NULL,
+    CONSTRAINT whatsapp_send_slot_pkey PRIMARY KEY (session)
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS public.ix_subscription_stripe_customer;
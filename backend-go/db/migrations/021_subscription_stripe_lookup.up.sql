@@ -0,0 +1,2 @@
+-- Stripe webhooks find the subscription of an event by its customer
+CREATE INDEX ix_subscription_stripe_customer ON public.subscription USING btree (stripe_customer_id);
@@ -0,0 +1,18 @@
+-- Every webhook delivery from a payment provider, kept so retries are processed once and failures can be replayed
+CREATE TABLE public.webhook_event (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    provider varchar(32) NOT NULL,
+    event_id varchar(255) NOT NULL,
+    event_type varchar(255) DEFAULT '' NOT NULL,
+    payload bytea NOT NULL,
+    status varchar(16) DEFAULT 'received' NOT NULL,
+    attempts int4 DEFAULT 0 NOT NULL,
+    last_error text DEFAULT '' NOT NULL,
+    processed_at timestamptz NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    updated_at timestamptz DEFAULT now() NOT NULL,
+    CONSTRAINT webhook_event_pkey PRIMARY KEY (id),
+    CONSTRAINT webhook_event_status_check CHECK (status IN ('received', 'processed', 'failed'))
+);
+CREATE UNIQUE INDEX uix_webhook_event_provider_event ON public.webhook_event USING btree (provider, event_id);
+CREATE INDEX ix_webhook_event_status ON public.webhook_event USING btree (status, created_at);
@@ -0,0 +1,8 @@
+-- Enum values cannot be dropped, so the type is recreated without refunded
+UPDATE public.payment SET status = 'paid' WHERE status = 'refunded';
+ALTER TABLE public.payment ALTER COLUMN status DROP DEFAULT;
+ALTER TYPE public.payment_status RENAME TO payment_status_old;
+CREATE TYPE public.payment_status AS ENUM ('pending', 'paid', 'failed');
+ALTER TABLE public.payment ALTER COLUMN status TYPE public.payment_status USING status::text::public.payment_status;
+ALTER TABLE public.payment ALTER COLUMN status SET DEFAULT 'pending'::public.payment_status;
+DROP TYPE public.payment_status_old;
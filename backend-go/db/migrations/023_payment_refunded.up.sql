@@ -0,0 +1,2 @@
+-- Payments refunded through their provider
+ALTER TYPE public.payment_status ADD VALUE IF NOT EXISTS 'refunded';
@@ -0,0 +1,17 @@
+DROP POLICY outbound_message_tenant ON public.outbound_message;
+ALTER TABLE public.outbound_message NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.outbound_message DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY campaign_tenant ON public.campaign;
+ALTER TABLE public.campaign NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.campaign DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY whatsapp_session_tenant ON public.whatsapp_session;
+ALTER TABLE public.whatsapp_session NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.whatsapp_session DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY organization_scim_token_tenant ON public.organization_scim_token;
+ALTER TABLE public.organization_scim_token NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.organization_scim_token DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION public.app_is_system();
@@ -0,0 +1,53 @@
+-- Row level security for the tenant tables added after 005.
+-- Work that belongs to no tenant, such as the outbox worker, the campaign scheduler, provider
+-- webhooks and the SCIM token lookup, runs in the system scope: the application sets app.system
+-- to on for it (util.WithSystemScope), and the policies below let it through. Nothing a request
+-- sends can set it; the setting is only written by internal/infrastructure/database/tenant.go.
+
+CREATE OR REPLACE FUNCTION public.app_is_system() RETURNS boolean
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE(current_setting('app.system', true), '') = 'on'
+$$;
+
+ALTER TABLE public.organization_scim_token ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.organization_scim_token FORCE ROW LEVEL SECURITY;
+CREATE POLICY organization_scim_token_tenant ON public.organization_scim_token
+    USING (organization_id = public.app_current_org_id() OR public.app_is_system())
+    WITH CHECK (organization_id = public.app_current_org_id() OR public.app_is_system());
+
+-- The platform session has no organization, so only the system scope sees it
+ALTER TABLE public.whatsapp_session ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.whatsapp_session FORCE ROW LEVEL SECURITY;
+CREATE POLICY whatsapp_session_tenant ON public.whatsapp_session
+    USING (organization_id = public.app_current_org_id() OR public.app_is_system())
+    WITH CHECK (organization_id = public.app_current_org_id() OR public.app_is_system());
+
+-- Campaigns of a salon, or of the current user when they belong to none
+ALTER TABLE public.campaign ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.campaign FORCE ROW LEVEL SECURITY;
+CREATE POLICY campaign_tenant ON public.campaign
+    USING (
+        organization_id = public.app_current_org_id()
+        OR (organization_id IS NULL AND created_by = public.app_current_user_id())
+        OR public.app_is_system()
+    )
+    WITH CHECK (
+        organization_id = public.app_current_org_id()
+        OR (organization_id IS NULL AND created_by = public.app_current_user_id())
+        OR public.app_is_system()
+    );
+
+-- Messages sent on behalf of a salon; platform messages, such as sign-in codes, have no organization
+ALTER TABLE public.outbound_message ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.outbound_message FORCE ROW LEVEL SECURITY;
+CREATE POLICY outbound_message_tenant ON public.outbound_message
+    USING (
+        organization_id IS NULL
+        OR organization_id = public.app_current_org_id()
+        OR public.app_is_system()
+    )
+    WITH CHECK (
+        organization_id IS NULL
+        OR organization_id = public.app_current_org_id()
+        OR public.app_is_system()
+    );
@@ -0,0 +1,5 @@
+-- The organization whose identity provider created the account through SCIM. Only that organization
+-- may change the account's email, name or active state; other members keep control of their own.
+ALTER TABLE "user"
+    ADD COLUMN provisioned_by_org uuid NULL,
+    ADD CONSTRAINT user_provisioned_by_org_fkey FOREIGN KEY (provisioned_by_org) REFERENCES public.organization(id) ON DELETE SET NULL;
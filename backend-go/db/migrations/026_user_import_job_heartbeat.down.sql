@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS ix_user_import_job_unfinished;
+ALTER TABLE public.user_import_job DROP COLUMN IF EXISTS heartbeat_at;
@@ -0,0 +1,5 @@
+-- Running imports touch heartbeat_at as they report progress. A job whose heartbeat stopped was
+-- left behind by an instance that went away, and is marked failed when the next one starts.
+ALTER TABLE public.user_import_job ADD COLUMN heartbeat_at timestamptz DEFAULT now() NOT NULL;
+
+CREATE INDEX ix_user_import_job_unfinished ON public.user_import_job (heartbeat_at) WHERE status IN ('pending', 'running');
@@ -0,0 +1,13 @@
+CREATE FUNCTION public.user_sort_key(u public."user", sort_by text) RETURNS text
+LANGUAGE sql STABLE AS $$
+    SELECT CASE sort_by
+        WHEN 'email' THEN lower(u.email)
+        WHEN 'full_name' THEN lower(coalesce(u.full_name, ''))
+        WHEN 'last_login_at' THEN to_char(coalesce(u.last_login_at, 'epoch'::timestamptz) AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US')
+        ELSE to_char(coalesce(u.created_at, 'epoch'::timestamptz) AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US')
+    END
+$$;
+
+DROP INDEX IF EXISTS ix_user_last_login_at_sort;
+DROP INDEX IF EXISTS ix_user_full_name_sort;
+DROP INDEX IF EXISTS ix_user_email_sort;
@@ -0,0 +1,9 @@
+-- Keyset pagination of the admin user list compares (sort key, id) against the cursor; each order
+-- has an index on exactly that pair, scanned backwards for descending lists. created_at is served
+-- by ix_user_created_at from 008.
+CREATE INDEX ix_user_email_sort ON public."user" (lower(email), id);
+CREATE INDEX ix_user_full_name_sort ON public."user" (lower(coalesce(full_name, '')), id);
+CREATE INDEX ix_user_last_login_at_sort ON public."user" (coalesce(last_login_at, 'epoch'::timestamptz), id);
+
+-- Replaced by a query per order
+DROP FUNCTION IF EXISTS public.user_sort_key(public."user", text);
@@ -0,0 +1 @@
+-- The cleared content of sent messages cannot be restored
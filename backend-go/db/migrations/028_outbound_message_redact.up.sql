@@ -0,0 +1,2 @@
+-- Sent messages no longer keep their content, which can hold one-time codes and sign-in links
+UPDATE public.outbound_message SET body = '', whatsapp_content = NULL WHERE sent_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DELETE FROM public.outbound_message WHERE channel = 'in_app';
+ALTER TABLE public.outbound_message DROP CONSTRAINT outbound_message_channel_check;
+ALTER TABLE public.outbound_message ADD CONSTRAINT outbound_message_channel_check CHECK (channel IN ('email', 'whatsapp', 'sms'));
@@ -0,0 +1,3 @@
+-- Messages routed to the in-app inbox record in_app as the channel that delivered them
+ALTER TABLE public.outbound_message DROP CONSTRAINT outbound_message_channel_check;
+ALTER TABLE public.outbound_message ADD CONSTRAINT outbound_message_channel_check CHECK (channel IN ('email', 'whatsapp', 'sms', 'in_app'));
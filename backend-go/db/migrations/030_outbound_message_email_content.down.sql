@@ -0,0 +1 @@
+ALTER TABLE public.outbound_message DROP COLUMN IF EXISTS email_content;
@@ -0,0 +1,3 @@
+-- Cc, Bcc, Reply-To, the plain-text body, extra headers and attachments travel with the queued
+-- message to the email channel, and are cleared with the body once it is sent
+ALTER TABLE public.outbound_message ADD COLUMN email_content jsonb NULL;
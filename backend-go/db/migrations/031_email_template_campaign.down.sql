@@ -0,0 +1 @@
+ALTER TABLE public.email_template DROP COLUMN IF EXISTS campaign;
@@ -0,0 +1,3 @@
+-- Only templates written for campaigns can be sent to a campaign's audience; one-time codes,
+-- password resets and other account messages stay out of reach of campaign senders
+ALTER TABLE public.email_template ADD COLUMN campaign boolean NOT NULL DEFAULT false;
@@ -0,0 +1 @@
+-- Provisioning no longer grants a global role, so none is restored
@@ -0,0 +1,5 @@
+-- Provisioned accounts were given the global salon_employee role; their organization role is their membership
+DELETE FROM public.user_role ur
+USING public.role r, public."user" u
+WHERE r.id = ur.role_id AND r.name = 'salon_employee'
+  AND u.id = ur.user_id AND u.provisioned_by_org IS NOT NULL;
@@ -0,0 +1,92 @@
+-- name: CreateCampaign :one
+INSERT INTO campaign (
+    organization_id, created_by, name, channel, template_name, category, audience, fields,
+    send_at, cron_expression, timezone, rate_per_minute, next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING *;
+
+-- name: GetCampaign :one
+SELECT * FROM campaign
+WHERE id = $1;
+
+-- name: GetCampaignForUpdate :one
+SELECT * FROM campaign
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListCampaigns :many
+-- Campaigns of the organization, or those the user created without one when there is no organization
+SELECT * FROM campaign
+WHERE CASE WHEN sqlc.narg('organization_id')::uuid IS NULL
+           THEN organization_id IS NULL AND created_by = sqlc.arg('created_by')
+           ELSE organization_id = sqlc.narg('organization_id')
+      END
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: CountCampaigns :one
+SELECT COUNT(*)::int FROM campaign
+WHERE CASE WHEN sqlc.narg('organization_id')::uuid IS NULL
+           THEN organization_id IS NULL AND created_by = sqlc.arg('created_by')
+           ELSE organization_id = sqlc.narg('organization_id')
+      END;
+
+-- name: ListDueCampaigns :many
+SELECT id, organization_id FROM campaign
+WHERE status = 'scheduled' AND next_run_at <= now()
+ORDER BY next_run_at
+LIMIT $1;
+
+-- name: LockDueCampaign :one
+-- Skips a campaign another scheduler is running; once that run is done the campaign is no longer due
+SELECT * FROM campaign
+WHERE id = $1 AND status = 'scheduled' AND next_run_at <= now()
+FOR UPDATE SKIP LOCKED;
+
+-- name: CompleteCampaignRun :one
+-- next_run_at is NULL, and status completed, once the schedule has no further runs
+UPDATE campaign
+SET next_run_at = sqlc.narg('next_run_at'),
+    status = sqlc.arg('status'),
+    last_run_at = now(),
+    runs = runs + 1,
+    updated_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: SetCampaignStatus :one
+UPDATE campaign
+SET status = sqlc.arg('status'),
+    next_run_at = sqlc.narg('next_run_at'),
+    updated_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: GetCampaignStats :one
+SELECT COUNT(*)::int AS total,
+       COUNT(*) FILTER (WHERE status IN ('pending', 'sending'))::int AS queued,
+       COUNT(*) FILTER (WHERE status = 'sent')::int AS sent,
+       COUNT(*) FILTER (WHERE status = 'dead')::int AS failed,
+       COUNT(delivered_at)::int AS delivered,
+       COUNT(read_at)::int AS read
+FROM outbound_message
+WHERE campaign_id = $1;
+
+-- name: CancelCampaignMessages :execrows
+-- Messages already being delivered are let through
+UPDATE outbound_message
+SET status = 'dead',
+    last_error = 'campaign cancelled'
+WHERE campaign_id = $1 AND status = 'pending';
+
+-- name: RespaceCampaignMessages :exec
+-- Spreads the queued messages of a resumed campaign from now on again, so they do not all go out at once
+UPDATE outbound_message m
+SET next_attempt_at = now() + make_interval(secs => (q.n - 1) * sqlc.arg('spacing_seconds')::float8)
+FROM (
+    SELECT o.id, row_number() OVER (ORDER BY o.next_attempt_at, o.id) AS n
+    FROM outbound_message o
+    WHERE o.campaign_id = sqlc.arg('campaign_id') AND o.status = 'pending'
+) q
+WHERE m.id = q.id;
@@ -0,0 +1,31 @@
+-- name: UpsertEmailSuppression :one
+-- A complaint is kept over a later bounce of the same address, it is the stronger signal
+INSERT INTO email_suppression (
+    email, reason, detail, source
+) VALUES (
+    lower(sqlc.arg('email')), sqlc.arg('reason'), sqlc.arg('detail'), sqlc.arg('source')
+)
+ON CONFLICT (email) DO UPDATE
+SET reason = CASE WHEN email_suppression.reason = 'complaint' THEN email_suppression.reason ELSE EXCLUDED.reason END,
+    detail = EXCLUDED.detail,
+    source = EXCLUDED.source,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetEmailSuppression :one
+SELECT * FROM email_suppression
+WHERE email = lower(sqlc.arg('email'));
+
+-- name: ListEmailSuppressions :many
+SELECT * FROM email_suppression
+WHERE (sqlc.narg('search')::text IS NULL OR email ILIKE '%' || sqlc.narg('search') || '%')
+ORDER BY created_at DESC, email
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: CountEmailSuppressions :one
+SELECT COUNT(*)::int FROM email_suppression
+WHERE (sqlc.narg('search')::text IS NULL OR email ILIKE '%' || sqlc.narg('search') || '%');
+
+-- name: DeleteEmailSuppression :execrows
+DELETE FROM email_suppression
+WHERE email = lower(sqlc.arg('email'));
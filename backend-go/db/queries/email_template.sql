@@ -1,6 +1,65 @@
--- name: GetEmailTemplateByName :one
+-- name: GetActiveEmailTemplate :one
+-- Picks the active variant for the first locale in the fallback chain that has one
 SELECT *
 FROM email_template
-WHERE name = $1
+WHERE name = sqlc.arg('name')
+  AND locale = ANY(sqlc.arg('locales')::text[])
   AND is_active = TRUE
+ORDER BY array_position(sqlc.arg('locales')::text[], locale::text)
 LIMIT 1;
+
+-- name: ListActiveEmailTemplates :many
+SELECT t.*,
+       (SELECT max(v.version) FROM email_template v WHERE v.name = t.name AND v.locale = t.locale)::int AS latest_version
+FROM email_template t
+WHERE t.is_active = TRUE
+ORDER BY t.name, t.locale;
+
+-- name: ListEmailTemplateVersions :many
+SELECT *
+FROM email_template
+WHERE name = $1
+  AND locale = $2
+ORDER BY version DESC;
+
+-- name: GetEmailTemplateVersion :one
+SELECT *
+FROM email_template
+WHERE name = $1
+  AND locale = $2
+  AND version = $3;
+
+-- name: CreateEmailTemplateVersion :one
+-- New versions start inactive; two concurrent saves of the same name
+-- collide on the (name, locale, version) constraint instead of overwriting each other.
+INSERT INTO email_template (name, locale, version, subject, body, whatsapp_content, campaign, is_active, created_by)
+SELECT sqlc.arg('name'),
+       sqlc.arg('locale'),
+       coalesce(max(version), 0) + 1,
+       sqlc.arg('subject'),
+       sqlc.arg('body'),
+       sqlc.narg('whatsapp_content'),
+       sqlc.arg('campaign'),
+       FALSE,
+       sqlc.narg('created_by')
+FROM email_template
+WHERE name = sqlc.arg('name')
+  AND locale = sqlc.arg('locale')
+RETURNING *;
+
+-- name: DeactivateEmailTemplate :exec
+UPDATE email_template
+SET is_active = FALSE,
+    updated_at = now()
+WHERE name = $1
+  AND locale = $2
+  AND is_active = TRUE;
+
+-- name: ActivateEmailTemplateVersion :one
+UPDATE email_template
+SET is_active = TRUE,
+    updated_at = now()
+WHERE name = $1
+  AND locale = $2
+  AND version = $3
+RETURNING *;
@@ -0,0 +1,27 @@
+-- name: CreateNotification :one
+INSERT INTO notification (
+    user_id, category, title, body, data
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListNotifications :many
+SELECT * FROM notification
+WHERE user_id = sqlc.arg('user_id')
+  AND (NOT sqlc.arg('unread_only')::bool OR read_at IS NULL)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*)::int FROM notification
+WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: MarkNotificationsRead :execrows
+UPDATE notification
+SET read_at = now()
+WHERE user_id = sqlc.arg('user_id') AND read_at IS NULL AND id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notification
+SET read_at = now()
+WHERE user_id = $1 AND read_at IS NULL;
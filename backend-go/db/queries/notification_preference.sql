@@ -0,0 +1,29 @@
+-- name: ListUserNotificationPreferences :many
+SELECT * FROM notification_preference
+WHERE user_id = $1
+ORDER BY category, channel;
+
+-- name: ListRecipientNotificationPreferences :many
+SELECT * FROM notification_preference
+WHERE user_id IS NULL AND recipient = lower(sqlc.arg('recipient'))
+ORDER BY category, channel;
+
+-- name: UpsertUserNotificationPreference :exec
+INSERT INTO notification_preference (
+    user_id, category, channel, enabled
+) VALUES (
+    sqlc.arg('user_id'), sqlc.arg('category'), sqlc.arg('channel'), sqlc.arg('enabled')
+)
+ON CONFLICT (user_id, category, channel) WHERE user_id IS NOT NULL DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    updated_at = now();
+
+-- name: UpsertRecipientNotificationPreference :exec
+INSERT INTO notification_preference (
+    recipient, category, channel, enabled
+) VALUES (
+    lower(sqlc.arg('recipient')), sqlc.arg('category'), sqlc.arg('channel'), sqlc.arg('enabled')
+)
+ON CONFLICT (recipient, category, channel) WHERE user_id IS NULL DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    updated_at = now();
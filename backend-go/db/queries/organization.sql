@@ -0,0 +1,51 @@
+-- name: CreateOrganization :one
+INSERT INTO organization (
+    name, created_by
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetOrganizationByID :one
+SELECT * FROM organization
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListOrganizationsByUser :many
+SELECT o.* FROM organization o
+JOIN organization_member m ON m.organization_id = o.id
+WHERE m.user_id = $1
+ORDER BY o.created_at;
+
+-- name: AddOrganizationMember :one
+INSERT INTO organization_member (
+    organization_id, user_id, role
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (organization_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING *;
+
+-- name: GetOrganizationMember :one
+SELECT * FROM organization_member
+WHERE organization_id = $1 AND user_id = $2
+LIMIT 1;
+
+-- name: ListOrganizationMembers :many
+SELECT m.organization_id, m.user_id, m.role, m.joined_at, u.email, u.full_name, u.phone_number
+FROM organization_member m
+JOIN "user" u ON u.id = m.user_id
+WHERE m.organization_id = $1 AND u.deleted_at IS NULL
+ORDER BY m.joined_at;
+
+-- name: LockOrganizationMembersByRole :many
+-- Deleted users cannot act for the organization, so they are not returned. The rows are locked in
+-- one order, so concurrent callers wait for each other instead of deadlocking.
+SELECT m.user_id FROM organization_member m
+JOIN "user" u ON u.id = m.user_id
+WHERE m.organization_id = $1 AND m.role = $2 AND u.deleted_at IS NULL
+ORDER BY m.user_id
+FOR UPDATE OF m;
+
+-- name: RemoveOrganizationMember :exec
+DELETE FROM organization_member
+WHERE organization_id = $1 AND user_id = $2;
@@ -0,0 +1,118 @@
+-- name: CreateOutboundMessage :one
+-- next_attempt_at delays the first attempt, such as to throttle a campaign; NULL sends right away
+INSERT INTO outbound_message (
+    channel, fallback_channels, recipient, subject, body, user_id, max_attempts, whatsapp_content, organization_id, category,
+    campaign_id, next_attempt_at, email_content
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE($12::timestamptz, now()), $13
+) RETURNING *;
+
+-- name: ClaimOutboundMessages :many
+-- Leases due messages to one worker. Messages whose lease ran out, because a worker
+-- died mid delivery, are picked up again. Messages of paused campaigns are held.
+UPDATE outbound_message
+SET status = 'sending',
+    attempts = attempts + 1,
+    locked_until = now() + make_interval(secs => sqlc.arg('lease_seconds')::int)
+WHERE id IN (
+    SELECT id FROM outbound_message o
+    WHERE ((o.status = 'pending' AND o.next_attempt_at <= now())
+        OR (o.status = 'sending' AND o.locked_until < now()))
+      AND NOT EXISTS (
+          SELECT 1 FROM campaign c
+          WHERE c.id = o.campaign_id AND c.status = 'paused'
+      )
+    ORDER BY o.next_attempt_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkOutboundMessageSent :exec
+-- channel records the route entry that delivered the message, external_id the id the provider gave it
+-- and sender the account it went out from, such as a WAHA session. The content is cleared, it can
+-- hold one-time codes and links that sign the recipient in.
+UPDATE outbound_message
+SET status = 'sent',
+    body = '',
+    whatsapp_content = NULL,
+    email_content = NULL,
+    channel = $2,
+    external_id = $3,
+    sender = $4,
+    sent_at = now(),
+    locked_until = NULL,
+    last_error = ''
+WHERE id = $1;
+
+-- name: RescheduleOutboundMessage :exec
+UPDATE outbound_message
+SET status = 'pending',
+    next_attempt_at = $2,
+    locked_until = NULL,
+    last_error = $3
+WHERE id = $1;
+
+-- name: DeferOutboundMessage :exec
+-- Puts a message back for a sender that has to wait, such as a rate limited WAHA session,
+-- without counting the attempt
+UPDATE outbound_message
+SET status = 'pending',
+    attempts = GREATEST(attempts - 1, 0),
+    next_attempt_at = $2,
+    locked_until = NULL
+WHERE id = $1;
+
+-- name: MarkOutboundMessageDead :exec
+UPDATE outbound_message
+SET status = 'dead',
+    locked_until = NULL,
+    last_error = $2
+WHERE id = $1;
+
+-- name: RequeueOutboundMessage :one
+-- Gives a dead message a fresh set of attempts. Messages the provider failed after they were sent
+-- have no content left to send again.
+UPDATE outbound_message
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = now(),
+    last_error = ''
+WHERE id = $1 AND status = 'dead' AND sent_at IS NULL
+RETURNING *;
+
+-- name: GetOutboundMessage :one
+SELECT * FROM outbound_message
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetOutboundMessageByExternalID :one
+SELECT * FROM outbound_message
+WHERE channel = sqlc.arg('channel') AND external_id = sqlc.arg('external_id')
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: RecordOutboundMessageAck :execrows
+-- Acks can arrive out of order or more than once; a read receipt implies delivery
+UPDATE outbound_message
+SET delivered_at = coalesce(delivered_at, now()),
+    read_at = CASE WHEN sqlc.arg('read')::bool THEN coalesce(read_at, now()) ELSE read_at END
+WHERE channel = sqlc.arg('channel') AND external_id = sqlc.arg('external_id');
+
+-- name: FailSentOutboundMessage :execrows
+-- The provider accepted the message but could not deliver it; dead messages can be retried by an admin
+UPDATE outbound_message
+SET status = 'dead',
+    last_error = sqlc.arg('last_error')
+WHERE channel = sqlc.arg('channel') AND external_id = sqlc.arg('external_id') AND status = 'sent';
+
+-- name: ListOutboundMessages :many
+SELECT * FROM outbound_message
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: CountOutboundMessagesByStatus :many
+SELECT status, COUNT(*)::int AS count FROM outbound_message
+GROUP BY status;
@@ -10,8 +10,11 @@ SELECT * FROM payment WHERE transaction_id = $1 LIMIT 1;
 
 -- name: UpdatePaymentStatus :one
 UPDATE payment
-SET status = $2, amount = COALESCE($3, amount), currency = COALESCE($4, currency), extra_metadata = COALESCE($5, extra_metadata)
-WHERE transaction_id = $1
+SET status = sqlc.arg('status'),
+    amount = COALESCE(sqlc.narg('amount'), amount),
+    currency = COALESCE(sqlc.narg('currency'), currency),
+    extra_metadata = COALESCE(sqlc.narg('extra_metadata'), extra_metadata)
+WHERE transaction_id = sqlc.arg('transaction_id')
 RETURNING *;
 
 -- name: GetPaymentByID :one
@@ -0,0 +1,16 @@
+-- name: CreateScimToken :one
+INSERT INTO organization_scim_token (
+    organization_id, token_hash, description
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetScimTokenByHash :one
+SELECT * FROM organization_scim_token
+WHERE token_hash = $1 AND revoked_at IS NULL
+LIMIT 1;
+
+-- name: TouchScimToken :exec
+UPDATE organization_scim_token
+SET last_used_at = now()
+WHERE id = $1;
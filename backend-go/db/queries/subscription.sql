@@ -1,4 +1,7 @@
 -- name: UpsertSubscription :one
+-- A user has one subscription row. An event about another subscription than the stored one, such
+-- as a late cancellation of the subscription the user replaced, only takes the row over once the
+-- stored subscription has ended; otherwise nothing is returned.
 INSERT INTO subscription (user_id, stripe_customer_id, stripe_subscription_id, status, current_period_end)
 VALUES ($1, $2, $3, $4, $5)
 ON CONFLICT (user_id)
@@ -7,6 +10,9 @@ DO UPDATE SET stripe_customer_id = EXCLUDED.stripe_customer_id,
               status = EXCLUDED.status,
               current_period_end = EXCLUDED.current_period_end,
               updated_at = now()
+WHERE subscription.stripe_subscription_id IS NULL
+   OR subscription.stripe_subscription_id = EXCLUDED.stripe_subscription_id
+   OR subscription.status IN ('canceled', 'expired')
 RETURNING *;
 
 -- name: GetSubscriptionByUser :one
@@ -14,3 +20,6 @@ SELECT * FROM subscription WHERE user_id = $1 LIMIT 1;
 
 -- name: ListAllSubscriptions :many
 SELECT * FROM subscription;
+
+-- name: GetSubscriptionByStripeCustomer :one
+SELECT * FROM subscription WHERE stripe_customer_id = $1 LIMIT 1;
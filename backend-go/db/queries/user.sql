@@ -1,20 +1,20 @@
 -- name: GetUserByID :one
 SELECT * FROM "user"
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetUserByEmail :one
 SELECT * FROM "user"
-WHERE email = $1 LIMIT 1;
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetUserByPhone :one
 SELECT * FROM "user"
-WHERE phone_number = $1 LIMIT 1;
+WHERE phone_number = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: CreateUser :one
 INSERT INTO "user" (
-    email, phone_number, full_name, hashed_password, is_active, is_email_verified
+    email, phone_number, full_name, hashed_password, is_active, is_email_verified, locale, provisioned_by_org
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: UpdateUserProfile :one
@@ -87,10 +87,303 @@ ON CONFLICT (user_id, role_id) DO NOTHING;
 DELETE FROM user_role
 WHERE user_id = $1;
 
--- name: ListUsers :many
-SELECT * FROM "user"
-ORDER BY created_at DESC
-LIMIT $1 OFFSET $2;
+-- The admin user list has one query per order so each is served by the matching index of 027.
+-- Filters are skipped when NULL. Rows after the cursor (after_key, after_id) are returned, with id
+-- breaking ties. The cursor of the text orders is the raw value, which the query lowers.
+
+-- name: ListUsersByCreatedAtAsc :many
+SELECT * FROM "user" u
+WHERE (u.deleted_at IS NOT NULL) = sqlc.arg('deleted')::bool
+  AND (sqlc.narg('search')::text IS NULL
+       OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.full_name ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.phone_number ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::text IS NULL OR EXISTS (
+       SELECT 1 FROM user_role ur
+       INNER JOIN role r ON r.id = ur.role_id
+       WHERE ur.user_id = u.id AND r.name = sqlc.narg('role')))
+  AND (sqlc.narg('is_active')::bool IS NULL OR u.is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('is_email_verified')::bool IS NULL OR u.is_email_verified = sqlc.narg('is_email_verified'))
+  AND (sqlc.narg('is_phone_verified')::bool IS NULL OR u.is_phone_verified = sqlc.narg('is_phone_verified'))
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR u.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('oauth_provider')::text IS NULL OR EXISTS (
+       SELECT 1 FROM oauth_account oa
+       WHERE oa.user_id = u.id AND oa.provider = sqlc.narg('oauth_provider')))
+  AND (sqlc.narg('after_id')::uuid IS NULL
+       OR (u.created_at, u.id) > (sqlc.narg('after_key')::timestamptz, sqlc.narg('after_id')::uuid))
+ORDER BY u.created_at ASC, u.id ASC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: ListUsersByCreatedAtDesc :many
+SELECT * FROM "user" u
+WHERE (u.deleted_at IS NOT NULL) = sqlc.arg('deleted')::bool
+  AND (sqlc.narg('search')::text IS NULL
+       OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.full_name ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.phone_number ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::text IS NULL OR EXISTS (
+       SELECT 1 FROM user_role ur
+       INNER JOIN role r ON r.id = ur.role_id
+       WHERE ur.user_id = u.id AND r.name = sqlc.narg('role')))
+  AND (sqlc.narg('is_active')::bool IS NULL OR u.is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('is_email_verified')::bool IS NULL OR u.is_email_verified = sqlc.narg('is_email_verified'))
+  AND (sqlc.narg('is_phone_verified')::bool IS NULL OR u.is_phone_verified = sqlc.narg('is_phone_verified'))
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR u.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('oauth_provider')::text IS NULL OR EXISTS (
+       SELECT 1 FROM oauth_account oa
+       WHERE oa.user_id = u.id AND oa.provider = sqlc.narg('oauth_provider')))
+  AND (sqlc.narg('after_id')::uuid IS NULL
+       OR (u.created_at, u.id) < (sqlc.narg('after_key')::timestamptz, sqlc.narg('after_id')::uuid))
+ORDER BY u.created_at DESC, u.id DESC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: ListUsersByEmailAsc :many
+SELECT * FROM "user" u
+WHERE (u.deleted_at IS NOT NULL) = sqlc.arg('deleted')::bool
+  AND (sqlc.narg('search')::text IS NULL
+       OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.full_name ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.phone_number ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::text IS NULL OR EXISTS (
+       SELECT 1 FROM user_role ur
+       INNER JOIN role r ON r.id = ur.role_id
+       WHERE ur.user_id = u.id AND r.name = sqlc.narg('role')))
+  AND (sqlc.narg('is_active')::bool IS NULL OR u.is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('is_email_verified')::bool IS NULL OR u.is_email_verified = sqlc.narg('is_email_verified'))
+  AND (sqlc.narg('is_phone_verified')::bool IS NULL OR u.is_phone_verified = sqlc.narg('is_phone_verified'))
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR u.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('oauth_provider')::text IS NULL OR EXISTS (
+       SELECT 1 FROM oauth_account oa
+       WHERE oa.user_id = u.id AND oa.provider = sqlc.narg('oauth_provider')))
+  AND (sqlc.narg('after_id')::uuid IS NULL
+       OR (lower(u.email), u.id) > (lower(sqlc.narg('after_key')::text), sqlc.narg('after_id')::uuid))
+ORDER BY lower(u.email) ASC, u.id ASC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: ListUsersByEmailDesc :many
+SELECT * FROM "user" u
+WHERE (u.deleted_at IS NOT NULL) = sqlc.arg('deleted')::bool
+  AND (sqlc.narg('search')::text IS NULL
+       OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.full_name ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.phone_number ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::text IS NULL OR EXISTS (
+       SELECT 1 FROM user_role ur
+       INNER JOIN role r ON r.id = ur.role_id
+       WHERE ur.user_id = u.id AND r.name = sqlc.narg('role')))
+  AND (sqlc.narg('is_active')::bool IS NULL OR u.is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('is_email_verified')::bool IS NULL OR u.is_email_verified = sqlc.narg('is_email_verified'))
+  AND (sqlc.narg('is_phone_verified')::bool IS NULL OR u.is_phone_verified = sqlc.narg('is_phone_verified'))
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR u.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('oauth_provider')::text IS NULL OR EXISTS (
+       SELECT 1 FROM oauth_account oa
+       WHERE oa.user_id = u.id AND oa.provider = sqlc.narg('oauth_provider')))
+  AND (sqlc.narg('after_id')::uuid IS NULL
+       OR (lower(u.email), u.id) < (lower(sqlc.narg('after_key')::text), sqlc.narg('after_id')::uuid))
+ORDER BY lower(u.email) DESC, u.id DESC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: ListUsersByFullNameAsc :many
+SELECT * FROM "user" u
+WHERE (u.deleted_at IS NOT NULL) = sqlc.arg('deleted')::bool
+  AND (sqlc.narg('search')::text IS NULL
+       OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.full_name ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.phone_number ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::text IS NULL OR EXISTS (
+       SELECT 1 FROM user_role ur
+       INNER JOIN role r ON r.id = ur.role_id
+       WHERE ur.user_id = u.id AND r.name = sqlc.narg('role')))
+  AND (sqlc.narg('is_active')::bool IS NULL OR u.is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('is_email_verified')::bool IS NULL OR u.is_email_verified = sqlc.narg('is_email_verified'))
+  AND (sqlc.narg('is_phone_verified')::bool IS NULL OR u.is_phone_verified = sqlc.narg('is_phone_verified'))
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR u.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('oauth_provider')::text IS NULL OR EXISTS (
+       SELECT 1 FROM oauth_account oa
+       WHERE oa.user_id = u.id AND oa.provider = sqlc.narg('oauth_provider')))
+  AND (sqlc.narg('after_id')::uuid IS NULL
+       OR (lower(coalesce(u.full_name, '')), u.id) > (lower(sqlc.narg('after_key')::text), sqlc.narg('after_id')::uuid))
+ORDER BY lower(coalesce(u.full_name, '')) ASC, u.id ASC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: ListUsersByFullNameDesc :many
+SELECT * FROM "user" u
+WHERE (u.deleted_at IS NOT NULL) = sqlc.arg('deleted')::bool
+  AND (sqlc.narg('search')::text IS NULL
+       OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.full_name ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.phone_number ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::text IS NULL OR EXISTS (
+       SELECT 1 FROM user_role ur
+       INNER JOIN role r ON r.id = ur.role_id
+       WHERE ur.user_id = u.id AND r.name = sqlc.narg('role')))
+  AND (sqlc.narg('is_active')::bool IS NULL OR u.is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('is_email_verified')::bool IS NULL OR u.is_email_verified = sqlc.narg('is_email_verified'))
+  AND (sqlc.narg('is_phone_verified')::bool IS NULL OR u.is_phone_verified = sqlc.narg('is_phone_verified'))
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR u.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('oauth_provider')::text IS NULL OR EXISTS (
+       SELECT 1 FROM oauth_account oa
+       WHERE oa.user_id = u.id AND oa.provider = sqlc.narg('oauth_provider')))
+  AND (sqlc.narg('after_id')::uuid IS NULL
+       OR (lower(coalesce(u.full_name, '')), u.id) < (lower(sqlc.narg('after_key')::text), sqlc.narg('after_id')::uuid))
+ORDER BY lower(coalesce(u.full_name, '')) DESC, u.id DESC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: ListUsersByLastLoginAtAsc :many
+SELECT * FROM "user" u
+WHERE (u.deleted_at IS NOT NULL) = sqlc.arg('deleted')::bool
+  AND (sqlc.narg('search')::text IS NULL
+       OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.full_name ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.phone_number ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::text IS NULL OR EXISTS (
+       SELECT 1 FROM user_role ur
+       INNER JOIN role r ON r.id = ur.role_id
+       WHERE ur.user_id = u.id AND r.name = sqlc.narg('role')))
+  AND (sqlc.narg('is_active')::bool IS NULL OR u.is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('is_email_verified')::bool IS NULL OR u.is_email_verified = sqlc.narg('is_email_verified'))
+  AND (sqlc.narg('is_phone_verified')::bool IS NULL OR u.is_phone_verified = sqlc.narg('is_phone_verified'))
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR u.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('oauth_provider')::text IS NULL OR EXISTS (
+       SELECT 1 FROM oauth_account oa
+       WHERE oa.user_id = u.id AND oa.provider = sqlc.narg('oauth_provider')))
+  AND (sqlc.narg('after_id')::uuid IS NULL
+       OR (coalesce(u.last_login_at, 'epoch'::timestamptz), u.id) > (sqlc.narg('after_key')::timestamptz, sqlc.narg('after_id')::uuid))
+ORDER BY coalesce(u.last_login_at, 'epoch'::timestamptz) ASC, u.id ASC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: ListUsersByLastLoginAtDesc :many
+SELECT * FROM "user" u
+WHERE (u.deleted_at IS NOT NULL) = sqlc.arg('deleted')::bool
+  AND (sqlc.narg('search')::text IS NULL
+       OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.full_name ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.phone_number ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::text IS NULL OR EXISTS (
+       SELECT 1 FROM user_role ur
+       INNER JOIN role r ON r.id = ur.role_id
+       WHERE ur.user_id = u.id AND r.name = sqlc.narg('role')))
+  AND (sqlc.narg('is_active')::bool IS NULL OR u.is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('is_email_verified')::bool IS NULL OR u.is_email_verified = sqlc.narg('is_email_verified'))
+  AND (sqlc.narg('is_phone_verified')::bool IS NULL OR u.is_phone_verified = sqlc.narg('is_phone_verified'))
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR u.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('oauth_provider')::text IS NULL OR EXISTS (
+       SELECT 1 FROM oauth_account oa
+       WHERE oa.user_id = u.id AND oa.provider = sqlc.narg('oauth_provider')))
+  AND (sqlc.narg('after_id')::uuid IS NULL
+       OR (coalesce(u.last_login_at, 'epoch'::timestamptz), u.id) < (sqlc.narg('after_key')::timestamptz, sqlc.narg('after_id')::uuid))
+ORDER BY coalesce(u.last_login_at, 'epoch'::timestamptz) DESC, u.id DESC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
 
 -- name: CountUsers :one
-SELECT COUNT(*)::int FROM "user";
+SELECT COUNT(*)::int FROM "user" u
+WHERE (u.deleted_at IS NOT NULL) = sqlc.arg('deleted')::bool
+  AND (sqlc.narg('search')::text IS NULL
+       OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.full_name ILIKE '%' || sqlc.narg('search') || '%'
+       OR u.phone_number ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::text IS NULL OR EXISTS (
+       SELECT 1 FROM user_role ur
+       INNER JOIN role r ON r.id = ur.role_id
+       WHERE ur.user_id = u.id AND r.name = sqlc.narg('role')))
+  AND (sqlc.narg('is_active')::bool IS NULL OR u.is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('is_email_verified')::bool IS NULL OR u.is_email_verified = sqlc.narg('is_email_verified'))
+  AND (sqlc.narg('is_phone_verified')::bool IS NULL OR u.is_phone_verified = sqlc.narg('is_phone_verified'))
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR u.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('oauth_provider')::text IS NULL OR EXISTS (
+       SELECT 1 FROM oauth_account oa
+       WHERE oa.user_id = u.id AND oa.provider = sqlc.narg('oauth_provider')));
+
+-- The members of an organization as its identity provider lists them, in the order they joined.
+-- The filter is skipped when filter_attr is NULL; otherwise the lowered email, id or full_name it
+-- names is compared with the lowered filter_value by filter_op (eq, ne, co, sw or ew). A missing
+-- full name matches no comparison.
+
+-- name: ListDirectoryUsers :many
+SELECT sqlc.embed(u), m.role, m.joined_at
+FROM organization_member m
+JOIN "user" u ON u.id = m.user_id
+CROSS JOIN LATERAL (SELECT lower(CASE sqlc.narg('filter_attr')::text
+    WHEN 'email' THEN u.email
+    WHEN 'id' THEN u.id::text
+    WHEN 'full_name' THEN u.full_name
+END) AS value) a
+WHERE m.organization_id = sqlc.arg('organization_id') AND u.deleted_at IS NULL
+  AND (sqlc.narg('filter_attr')::text IS NULL OR CASE sqlc.arg('filter_op')::text
+       WHEN 'eq' THEN a.value = lower(sqlc.arg('filter_value')::text)
+       WHEN 'ne' THEN a.value <> lower(sqlc.arg('filter_value')::text)
+       WHEN 'co' THEN strpos(a.value, lower(sqlc.arg('filter_value')::text)) > 0
+       WHEN 'sw' THEN starts_with(a.value, lower(sqlc.arg('filter_value')::text))
+       WHEN 'ew' THEN right(a.value, length(sqlc.arg('filter_value')::text)) = lower(sqlc.arg('filter_value')::text)
+       ELSE false
+  END)
+ORDER BY m.joined_at, u.id
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: CountDirectoryUsers :one
+SELECT COUNT(*)::int
+FROM organization_member m
+JOIN "user" u ON u.id = m.user_id
+CROSS JOIN LATERAL (SELECT lower(CASE sqlc.narg('filter_attr')::text
+    WHEN 'email' THEN u.email
+    WHEN 'id' THEN u.id::text
+    WHEN 'full_name' THEN u.full_name
+END) AS value) a
+WHERE m.organization_id = sqlc.arg('organization_id') AND u.deleted_at IS NULL
+  AND (sqlc.narg('filter_attr')::text IS NULL OR CASE sqlc.arg('filter_op')::text
+       WHEN 'eq' THEN a.value = lower(sqlc.arg('filter_value')::text)
+       WHEN 'ne' THEN a.value <> lower(sqlc.arg('filter_value')::text)
+       WHEN 'co' THEN strpos(a.value, lower(sqlc.arg('filter_value')::text)) > 0
+       WHEN 'sw' THEN starts_with(a.value, lower(sqlc.arg('filter_value')::text))
+       WHEN 'ew' THEN right(a.value, length(sqlc.arg('filter_value')::text)) = lower(sqlc.arg('filter_value')::text)
+       ELSE false
+  END);
+
+-- name: UpdateUserLastLogin :exec
+UPDATE "user"
+SET last_login_at = $2
+WHERE id = $1;
+
+-- name: SetUserPreferredChannel :one
+UPDATE "user"
+SET preferred_channel = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetUserLocale :one
+UPDATE "user"
+SET locale = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: RevokeUserSessions :exec
+UPDATE "user"
+SET sessions_revoked_at = now(),
+    updated_at = now()
+WHERE id = $1;
+
+-- name: SoftDeleteUser :execrows
+-- Also signs the user out everywhere
+UPDATE "user"
+SET deleted_at = now(),
+    sessions_revoked_at = now(),
+    updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreUser :one
+UPDATE "user"
+SET deleted_at = NULL,
+    updated_at = now()
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
@@ -0,0 +1,24 @@
+-- name: GetUserDeviceByFingerprint :one
+SELECT * FROM user_device
+WHERE user_id = $1 AND fingerprint = $2
+LIMIT 1;
+
+-- name: CreateUserDevice :one
+INSERT INTO user_device (
+    user_id, fingerprint, user_agent, ip_prefix, country
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: TouchUserDevice :exec
+UPDATE user_device
+SET last_seen_at = now()
+WHERE id = $1;
+
+-- name: CountUserDevices :one
+SELECT COUNT(*)::int FROM user_device
+WHERE user_id = $1;
+
+-- name: DeleteUserDevice :exec
+DELETE FROM user_device
+WHERE id = $1 AND user_id = $2;
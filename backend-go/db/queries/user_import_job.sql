@@ -0,0 +1,41 @@
+-- name: CreateUserImportJob :one
+INSERT INTO user_import_job (
+    created_by, status, dry_run, total_rows
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetUserImportJob :one
+SELECT * FROM user_import_job
+WHERE id = $1
+LIMIT 1;
+
+-- name: UpdateUserImportJobProgress :exec
+UPDATE user_import_job
+SET status = $2,
+    processed_rows = $3,
+    created_count = $4,
+    skipped_count = $5,
+    error_count = $6,
+    heartbeat_at = now()
+WHERE id = $1;
+
+-- name: FinishUserImportJob :exec
+UPDATE user_import_job
+SET status = $2,
+    processed_rows = $3,
+    created_count = $4,
+    skipped_count = $5,
+    error_count = $6,
+    report = $7,
+    error = $8,
+    finished_at = now()
+WHERE id = $1;
+
+-- name: FailStaleUserImportJobs :execrows
+UPDATE user_import_job
+SET status = 'failed',
+    error = $2,
+    finished_at = now()
+WHERE status IN ('pending', 'running')
+  AND heartbeat_at < $1;
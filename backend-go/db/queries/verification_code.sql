@@ -45,3 +45,14 @@ UPDATE verification_code
 SET used_at = now()
 WHERE id = $1
 RETURNING *;
+
+-- name: GetPendingAddPhoneVerification :one
+-- Newest live code confirming phone as the new number of some user
+SELECT * FROM verification_code
+WHERE verification_type = 'phone'
+  AND used_at IS NULL
+  AND expires_at > now()
+  AND extra_metadata->>'purpose' = 'add_phone'
+  AND extra_metadata->>'new_phone' = sqlc.arg('phone')::text
+ORDER BY created_at DESC
+LIMIT 1;
@@ -0,0 +1,34 @@
+-- name: RecordWebhookEvent :one
+-- A redelivery returns the event already recorded, with its processing status
+INSERT INTO webhook_event (provider, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (provider, event_id) DO UPDATE SET updated_at = now()
+RETURNING *;
+
+-- name: GetWebhookEvent :one
+SELECT * FROM webhook_event WHERE id = $1;
+
+-- name: GetWebhookEventForUpdate :one
+SELECT * FROM webhook_event WHERE id = $1 FOR UPDATE;
+
+-- name: MarkWebhookEventProcessed :exec
+UPDATE webhook_event
+SET status = 'processed', attempts = attempts + 1, last_error = '', processed_at = now(), updated_at = now()
+WHERE id = $1;
+
+-- name: MarkWebhookEventFailed :exec
+UPDATE webhook_event
+SET status = 'failed', attempts = attempts + 1, last_error = $2, updated_at = now()
+WHERE id = $1;
+
+-- name: ListWebhookEvents :many
+SELECT * FROM webhook_event
+WHERE (sqlc.narg('provider')::text IS NULL OR provider = sqlc.narg('provider'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: CountWebhookEvents :one
+SELECT COUNT(*) FROM webhook_event
+WHERE (sqlc.narg('provider')::text IS NULL OR provider = sqlc.narg('provider'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'));
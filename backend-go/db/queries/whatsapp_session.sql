@@ -0,0 +1,56 @@
+-- name: UpsertWhatsAppSessionStatus :one
+-- previous_status is empty for a session seen for the first time
+WITH previous AS (
+    SELECT status FROM whatsapp_session WHERE name = $1
+)
+INSERT INTO whatsapp_session (
+    name, status
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (name) DO UPDATE
+SET status = EXCLUDED.status,
+    status_changed_at = CASE WHEN whatsapp_session.status = EXCLUDED.status THEN whatsapp_session.status_changed_at ELSE now() END,
+    last_event_at = now()
+RETURNING *, coalesce((SELECT status FROM previous), '')::text AS previous_status;
+
+-- name: TouchWhatsAppSession :exec
+UPDATE whatsapp_session
+SET last_event_at = now()
+WHERE name = $1;
+
+-- name: ListWhatsAppSessions :many
+SELECT * FROM whatsapp_session
+ORDER BY name;
+
+-- name: CreateOrganizationWhatsAppSession :one
+-- Starting a session again keeps its row and last reported status
+INSERT INTO whatsapp_session (
+    name, status, organization_id
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (name) DO UPDATE
+SET organization_id = EXCLUDED.organization_id
+RETURNING *;
+
+-- name: GetWhatsAppSessionByOrganization :one
+SELECT * FROM whatsapp_session
+WHERE organization_id = $1;
+
+-- name: TakeWhatsAppSendSlot :one
+-- Takes the session's send slot when it is open and moves the next one spacing later.
+-- No row comes back while the slot is still closed.
+INSERT INTO whatsapp_send_slot (
+    session, next_send_at
+) VALUES (
+    sqlc.arg('session'), now() + make_interval(secs => sqlc.arg('spacing_seconds')::float8)
+)
+ON CONFLICT (session) DO UPDATE
+SET next_send_at = EXCLUDED.next_send_at
+WHERE whatsapp_send_slot.next_send_at <= now()
+RETURNING next_send_at;
+
+-- name: GetWhatsAppSendSlot :one
+SELECT next_send_at FROM whatsapp_send_slot
+WHERE session = $1;
@@ -12,6 +12,7 @@ import (
 
 	"github.com/williamchand/fullstack-fastapi/backend-go/config"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
 )
 
 type App struct {
@@ -60,6 +61,14 @@ func (a *App) Run(ctx context.Context) error {
 
 	g.Go(func() error { return a.runGRPC(ctx) })
 	g.Go(func() error { return a.runHTTP(ctx) })
+	// Background workers serve every salon, so they run in the system scope of row level security
+	systemCtx := util.WithSystemScope(ctx)
+	g.Go(func() error { return a.services.OutboxWorker.Run(systemCtx) })
+	g.Go(func() error { return a.services.CampaignService.Run(systemCtx) })
+	g.Go(func() error { return a.services.UserService.RunImports(ctx) })
+	if a.services.BounceWorker != nil {
+		g.Go(func() error { return a.services.BounceWorker.Run(ctx) })
+	}
 	g.Go(func() error { return a.handleShutdown(ctx, cancel) })
 
 	return g.Wait()
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEventStreamAuthOnlyOnTheStream(t *testing.T) {
+	var auth, query string
+	handler := eventStreamAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, query = r.Header.Get("Authorization"), r.URL.RawQuery
+	}))
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		accept   string
+		wantAuth string
+	}{
+		{"stream", http.MethodGet, notificationStreamPath + "?access_token=t", eventStreamMIME, "Bearer t"},
+		{"stream without event-stream", http.MethodGet, notificationStreamPath + "?access_token=t", "application/json", ""},
+		{"other route", http.MethodGet, "/v1/users/me?access_token=t", eventStreamMIME, ""},
+		{"other method", http.MethodPost, notificationStreamPath + "?access_token=t", eventStreamMIME, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			r.Header.Set("Accept", tt.accept)
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+			if auth != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", auth, tt.wantAuth)
+			}
+			if tt.wantAuth != "" && query != "" {
+				t.Errorf("access_token left in the query: %q", query)
+			}
+		})
+	}
+}
@@ -16,6 +16,6 @@ func initMiddleware(cfg *config.Config, repo *Repositories) (*Middleware, error)
 		return nil, err
 	}
 	return &Middleware{
-		Auth: auth.NewAuthMiddleware(jwtService, repo.UserRepo),
+		Auth: auth.NewAuthMiddleware(jwtService, repo.UserRepo, repo.OrganizationRepo),
 	}, nil
 }
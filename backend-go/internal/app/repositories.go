@@ -9,13 +9,24 @@ import (
 )
 
 type Repositories struct {
-	TransactionManager repositories.TransactionManager
-	UserRepo           repositories.UserRepository
-	OAuthRepo          repositories.OAuthRepository
-	EmailTemplateRepo  repositories.EmailTemplateRepository
-	VerificationRepo   repositories.VerificationCodeRepository
-	SubscriptionRepo   repositories.SubscriptionRepository
-	PaymentRepo        repositories.PaymentRepository
+	TransactionManager  repositories.TransactionManager
+	UserRepo            repositories.UserRepository
+	OAuthRepo           repositories.OAuthRepository
+	EmailTemplateRepo   repositories.EmailTemplateRepository
+	VerificationRepo    repositories.VerificationCodeRepository
+	SubscriptionRepo    repositories.SubscriptionRepository
+	PaymentRepo         repositories.PaymentRepository
+	UserDeviceRepo      repositories.UserDeviceRepository
+	OrganizationRepo    repositories.OrganizationRepository
+	ScimTokenRepo       repositories.ScimTokenRepository
+	UserImportJobRepo   repositories.UserImportJobRepository
+	OutboxRepo          repositories.OutboxRepository
+	SuppressionRepo     repositories.EmailSuppressionRepository
+	WhatsAppSessionRepo repositories.WhatsAppSessionRepository
+	NotificationRepo    repositories.NotificationRepository
+	PreferenceRepo      repositories.NotificationPreferenceRepository
+	CampaignRepo        repositories.CampaignRepository
+	WebhookEventRepo    repositories.WebhookEventRepository
 }
 
 func initRepositories(ctx context.Context, dbURL string) (*Repositories, repositories.ConnectionPool, error) {
@@ -26,12 +37,23 @@ func initRepositories(ctx context.Context, dbURL string) (*Repositories, reposit
 	queries := dbgen.New(dbPool)
 
 	return &Repositories{
-		TransactionManager: database.NewTransactionManager(dbPool),
-		UserRepo:           database.NewUserRepository(queries, dbPool),
-		OAuthRepo:          database.NewOAuthRepository(queries, dbPool),
-		EmailTemplateRepo:  database.NewEmailTemplateRepository(queries, dbPool),
-		VerificationRepo:   database.NewVerificationCodeRepository(queries, dbPool),
-		SubscriptionRepo:   database.NewSubscriptionRepository(queries, dbPool),
-		PaymentRepo:        database.NewPaymentRepository(queries, dbPool),
+		TransactionManager:  database.NewTransactionManager(dbPool),
+		UserRepo:            database.NewUserRepository(queries, dbPool),
+		OAuthRepo:           database.NewOAuthRepository(queries, dbPool),
+		EmailTemplateRepo:   database.NewEmailTemplateRepository(queries, dbPool),
+		VerificationRepo:    database.NewVerificationCodeRepository(queries, dbPool),
+		SubscriptionRepo:    database.NewSubscriptionRepository(queries, dbPool),
+		PaymentRepo:         database.NewPaymentRepository(queries, dbPool),
+		UserDeviceRepo:      database.NewUserDeviceRepository(queries, dbPool),
+		OrganizationRepo:    database.NewOrganizationRepository(queries, dbPool),
+		ScimTokenRepo:       database.NewScimTokenRepository(queries, dbPool),
+		UserImportJobRepo:   database.NewUserImportJobRepository(queries, dbPool),
+		OutboxRepo:          database.NewOutboxRepository(queries, dbPool),
+		SuppressionRepo:     database.NewEmailSuppressionRepository(queries, dbPool),
+		WhatsAppSessionRepo: database.NewWhatsAppSessionRepository(queries, dbPool),
+		NotificationRepo:    database.NewNotificationRepository(queries, dbPool),
+		PreferenceRepo:      database.NewNotificationPreferenceRepository(queries, dbPool),
+		CampaignRepo:        database.NewCampaignRepository(queries, dbPool),
+		WebhookEventRepo:    database.NewWebhookEventRepository(queries, dbPool),
 	}, dbPool, err
 }
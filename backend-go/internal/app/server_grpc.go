@@ -6,6 +6,8 @@ import (
 	"net"
 
 	genprotov1 "github.com/williamchand/fullstack-fastapi/backend-go/gen/proto/v1"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/auth"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/i18n"
 	"google.golang.org/grpc"
 )
 
@@ -15,15 +17,28 @@ func (a *App) runGRPC(ctx context.Context) error {
 		return err
 	}
 
+	trustedProxies, err := auth.ParseTrustedProxies(a.cfg.Security.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
 	server := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
+			auth.GRPCClientInfoInterceptor(trustedProxies),
+			i18n.GRPCErrorInterceptor,
 			a.middleware.Auth.GRPCAuthInterceptor,
+			i18n.GRPCUserLocaleInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			a.middleware.Auth.GRPCStreamAuthInterceptor,
 		),
 	)
 
 	genprotov1.RegisterUserServiceServer(server, a.serviceServer.userServer)
 	genprotov1.RegisterOAuthServiceServer(server, a.serviceServer.oauthServer)
 	genprotov1.RegisterBillingServiceServer(server, a.serviceServer.billingServer)
+	genprotov1.RegisterOrganizationServiceServer(server, a.serviceServer.orgServer)
+	genprotov1.RegisterNotificationServiceServer(server, a.serviceServer.notifServer)
 
 	go func() {
 		<-ctx.Done()
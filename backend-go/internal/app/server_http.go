@@ -6,9 +6,15 @@ import (
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"strings"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	genprotov1 "github.com/williamchand/fullstack-fastapi/backend-go/gen/proto/v1"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/delivery/admin"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/delivery/scim"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/delivery/webhook"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/auth"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/cors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -19,6 +25,9 @@ import (
 func (a *App) runHTTP(ctx context.Context) error {
 	mux := runtime.NewServeMux(
 		runtime.WithErrorHandler(gatewayErrorHandler),
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		// Server-streaming RPCs reach browsers as server-sent events
+		runtime.WithMarshalerOption(eventStreamMIME, newEventStreamMarshaler()),
 	)
 
 	// Register handlers for gRPC services
@@ -52,7 +61,27 @@ func (a *App) runHTTP(ctx context.Context) error {
 		return err
 	}
 
-	handler := a.middleware.Auth.HTTPMiddleware(mux)
+	err = genprotov1.RegisterOrganizationServiceHandlerFromEndpoint(
+		ctx,
+		mux,
+		fmt.Sprintf(":%s", a.cfg.GRPCPort),
+		[]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = genprotov1.RegisterNotificationServiceHandlerFromEndpoint(
+		ctx,
+		mux,
+		fmt.Sprintf(":%s", a.cfg.GRPCPort),
+		[]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+	)
+	if err != nil {
+		return err
+	}
+
+	handler := eventStreamAuth(a.middleware.Auth.HTTPMiddleware(mux))
 
 	// Root mux to serve OpenAPI specs without auth and gRPC-Gateway with auth
 	rootMux := http.NewServeMux()
@@ -62,6 +91,30 @@ func (a *App) runHTTP(ctx context.Context) error {
 	fs := http.FileServer(http.Dir(openapiDir))
 	rootMux.Handle("/v1/openapi/", http.StripPrefix("/v1/openapi/", fs))
 
+	// SCIM provisioning authenticates with per-organization tokens instead of user JWTs
+	rootMux.Handle(scim.BasePath+"/", scim.NewHandler(a.services.ScimService))
+
+	// Bulk import and export stream files, which gRPC-Gateway cannot do
+	rootMux.Handle(admin.UsersPath+"/", a.middleware.Auth.HTTPMiddleware(admin.NewUserHandler(a.services.UserService)))
+
+	// Bounce reports authenticate with a shared secret; without one the endpoint stays unmounted
+	if a.cfg.Bounce.WebhookSecret != "" {
+		rootMux.Handle(webhook.BouncePath, webhook.NewBounceHandler(a.services.Suppression, a.cfg.Bounce.WebhookSecret))
+	}
+
+	// WAHA signs its events with an hmac key; without one the endpoint stays unmounted
+	if a.cfg.WAHA.WebhookHMACKey != "" {
+		rootMux.Handle(webhook.WahaPath, webhook.NewWahaHandler(a.services.WhatsAppService, a.cfg.WAHA.WebhookHMACKey))
+	}
+
+	// Payment providers sign the raw body of their webhooks; without a secret an endpoint stays unmounted
+	if a.cfg.Stripe.WebhookSecret != "" {
+		rootMux.Handle(webhook.StripePath, webhook.NewBillingHandler(a.services.BillingService, entities.PaymentProviderStripe))
+	}
+	if a.cfg.Doku.SecretKey != "" {
+		rootMux.Handle(webhook.DokuPath, webhook.NewBillingHandler(a.services.BillingService, entities.PaymentProviderDoku))
+	}
+
 	// All other routes go through auth + grpc-gateway
 	rootMux.Handle("/", handler)
 
@@ -83,6 +136,14 @@ func (a *App) runHTTP(ctx context.Context) error {
 	return srv.ListenAndServe()
 }
 
+// incomingHeaderMatcher forwards the organization selector to gRPC in addition to the default headers.
+func incomingHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, auth.OrgIDHeader) {
+		return strings.ToLower(key), true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
 // gatewayErrorHandler ensures gRPC-Gateway sends JSON errors with a consistent shape.
 // It includes at least a "message" field so the frontend can display backend-provided messages.
 func gatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
@@ -9,15 +9,21 @@ type ServiceServer struct {
 	userServer    genprotov1.UserServiceServer
 	oauthServer   genprotov1.OAuthServiceServer
 	billingServer genprotov1.BillingServiceServer
+	orgServer     genprotov1.OrganizationServiceServer
+	notifServer   genprotov1.NotificationServiceServer
 }
 
 func initServiceServer(appServices *AppServices) *ServiceServer {
 	userServer := grpc.NewUserServer(appServices.UserService)
 	oauthServer := grpc.NewOAuthServer(appServices.OauthService)
 	billServer := grpc.NewBillingServer(appServices.BillingService)
+	orgServer := grpc.NewOrganizationServer(appServices.OrgService, appServices.WhatsAppService)
+	notifServer := grpc.NewNotificationServer(appServices.NotifService, appServices.TemplateService, appServices.Suppression, appServices.WhatsAppService, appServices.InboxService, appServices.Preferences, appServices.CampaignService)
 	return &ServiceServer{
 		userServer:    userServer,
 		oauthServer:   oauthServer,
 		billingServer: billServer,
+		orgServer:     orgServer,
+		notifServer:   notifServer,
 	}
 }
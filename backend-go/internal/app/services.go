@@ -2,18 +2,38 @@ package app
 
 import (
 	"github.com/williamchand/fullstack-fastapi/backend-go/config"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/bounce"
 	dokunfra "github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/doku"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/geoip"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/jwt"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/notify"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/payment"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/sms"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/smtp"
 	stripeinfra "github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/stripe"
 	wahainfra "github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/waha"
 )
 
 type AppServices struct {
-	UserService    *services.UserService
-	OauthService   *services.OAuthService
-	BillingService *services.BillingService
+	UserService     *services.UserService
+	OauthService    *services.OAuthService
+	BillingService  *services.BillingService
+	OrgService      *services.OrganizationService
+	ScimService     *services.ScimService
+	NotifService    *services.NotificationService
+	TemplateService *services.EmailTemplateService
+	Suppression     *services.EmailSuppressionService
+	WhatsAppService *services.WhatsAppService
+	InboxService    *services.InboxService
+	Preferences     *services.NotificationPreferenceService
+	// CampaignService also runs due campaigns in the background
+	CampaignService *services.CampaignService
+	OutboxWorker    *services.OutboxWorker
+	// BounceWorker is nil unless a bounce mailbox is configured
+	BounceWorker *services.BounceMailboxWorker
 }
 
 func initServices(cfg *config.Config, repo *Repositories) (*AppServices, error) {
@@ -21,13 +41,84 @@ func initServices(cfg *config.Config, repo *Repositories) (*AppServices, error)
 	if err != nil {
 		return nil, err
 	}
-	smtpSender := smtp.NewSMTPSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
-	wahaClient := wahainfra.New(cfg.WAHA.URL, cfg.WAHA.APIKey, cfg.WAHA.Session)
-	stripeClient := stripeinfra.New(cfg.Stripe.SecretKey)
-	dokuClient := dokunfra.New(cfg.Doku.BaseURL, cfg.Doku.ClientID, cfg.Doku.SecretKey)
+	smtpSender := smtp.NewSMTPSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From, smtp.Options{
+		Security:    smtp.ParseSecurity(cfg.SMTP.SSL, cfg.SMTP.TLS),
+		Timeout:     cfg.SMTP.Timeout,
+		PoolSize:    cfg.SMTP.PoolSize,
+		IdleTimeout: cfg.SMTP.IdleTimeout,
+	})
+	wahaClient := wahainfra.New(cfg.WAHA.URL, cfg.WAHA.APIKey, cfg.WAHA.Session, wahainfra.Webhook{
+		URL:     cfg.WAHA.WebhookURL,
+		HMACKey: cfg.WAHA.WebhookHMACKey,
+	})
+
+	// Payments go through the gateway of each provider that is configured
+	gateways := map[entities.PaymentProvider]repositories.PaymentGateway{}
+	if cfg.Stripe.SecretKey != "" {
+		gateways[entities.PaymentProviderStripe] = payment.NewStripeGateway(stripeinfra.New(cfg.Stripe.SecretKey), cfg.Stripe.PriceID, cfg.Stripe.WebhookSecret)
+	}
+	if cfg.Doku.SecretKey != "" {
+		gateways[entities.PaymentProviderDoku] = payment.NewDokuGateway(dokunfra.New(cfg.Doku.BaseURL, cfg.Doku.ClientID, cfg.Doku.SecretKey))
+	}
+
+	channels := map[entities.MessageChannel]repositories.NotificationChannel{
+		entities.MessageChannelEmail:    notify.NewEmailChannel(smtpSender),
+		entities.MessageChannelWhatsApp: notify.NewWhatsAppChannel(wahaClient, repo.WhatsAppSessionRepo, cfg.WAHA.RatePerMinute),
+	}
+	if cfg.SMS.GatewayURL != "" {
+		channels[entities.MessageChannelSMS] = notify.NewSMSChannel(sms.New(cfg.SMS.GatewayURL, cfg.SMS.APIKey, cfg.SMS.Sender))
+	}
+	inboxService := services.NewInboxService(repo.NotificationRepo, repo.PreferenceRepo)
+	channels[entities.MessageChannelInApp] = services.NewInAppChannel(inboxService)
+	notifier, err := services.NewNotifier(cfg, repo.EmailTemplateRepo, repo.SuppressionRepo, repo.PreferenceRepo, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	// GeoIP lookups are optional; without a database devices are fingerprinted without country
+	var geoResolver repositories.GeoIPResolver
+	if cfg.GeoIP.DatabasePath != "" {
+		reader, err := geoip.Open(cfg.GeoIP.DatabasePath)
+		if err != nil {
+			return nil, err
+		}
+		geoResolver = reader
+	}
+	loginAlerts := services.NewLoginAlertService(cfg, repo.UserRepo, repo.UserDeviceRepo, repo.VerificationRepo, notifier, repo.TransactionManager, repo.OutboxRepo, geoResolver)
+
+	suppression := services.NewEmailSuppressionService(repo.SuppressionRepo)
+	var bounceWorker *services.BounceMailboxWorker
+	if cfg.Bounce.MailboxPath != "" {
+		bounceWorker = services.NewBounceMailboxWorker(cfg.Bounce.PollInterval, bounce.NewMailbox(cfg.Bounce.MailboxPath), suppression)
+	}
+
+	orgService := services.NewOrganizationService(cfg, repo.OrganizationRepo, repo.VerificationRepo, notifier, repo.TransactionManager, repo.OutboxRepo, repo.ScimTokenRepo)
+	userService := services.NewUserService(cfg, repo.UserRepo, repo.OAuthRepo, repo.TransactionManager, jwtService, notifier, repo.VerificationRepo, repo.OutboxRepo, loginAlerts, repo.UserImportJobRepo)
+
+	// Domain events, such as inbound WhatsApp messages and payment updates, are published here for the modules that act on them
+	eventBus := services.NewEventBus()
+	services.Subscribe(eventBus, userService.VerifyPhoneByReply)
+
+	services.Subscribe(eventBus, inboxService.NotifyPaymentStatus)
+	services.Subscribe(eventBus, inboxService.NotifySubscriptionExpired)
+	services.Subscribe(eventBus, inboxService.NotifyTrialEnding)
+	// Nothing publishes RSVPs until guests have a service of their own
+	services.Subscribe(eventBus, inboxService.NotifyRSVP)
+
 	return &AppServices{
-		UserService:    services.NewUserService(cfg, repo.UserRepo, repo.OAuthRepo, repo.TransactionManager, jwtService, repo.EmailTemplateRepo, repo.VerificationRepo, smtpSender, wahaClient),
-		OauthService:   services.NewOAuthService(cfg.GetOauthConfig(), repo.OAuthRepo, repo.UserRepo, repo.TransactionManager, jwtService),
-		BillingService: services.NewBillingService(cfg, repo.SubscriptionRepo, repo.PaymentRepo, stripeClient, dokuClient),
+		UserService:     userService,
+		OauthService:    services.NewOAuthService(cfg.GetOauthConfig(), repo.OAuthRepo, repo.UserRepo, repo.TransactionManager, jwtService, loginAlerts),
+		BillingService:  services.NewBillingService(repo.SubscriptionRepo, repo.PaymentRepo, repo.WebhookEventRepo, repo.TransactionManager, gateways, eventBus),
+		OrgService:      orgService,
+		ScimService:     services.NewScimService(repo.UserRepo, repo.OrganizationRepo, repo.ScimTokenRepo, repo.TransactionManager, orgService),
+		NotifService:    services.NewNotificationService(repo.OutboxRepo),
+		TemplateService: services.NewEmailTemplateService(repo.EmailTemplateRepo, repo.OutboxRepo, repo.TransactionManager, notifier),
+		Suppression:     suppression,
+		WhatsAppService: services.NewWhatsAppService(repo.OutboxRepo, repo.WhatsAppSessionRepo, wahaClient, eventBus),
+		InboxService:    inboxService,
+		Preferences:     services.NewNotificationPreferenceService(cfg, repo.PreferenceRepo, repo.TransactionManager),
+		CampaignService: services.NewCampaignService(cfg, repo.CampaignRepo, repo.EmailTemplateRepo, repo.OrganizationRepo, repo.UserRepo, repo.OutboxRepo, repo.TransactionManager, notifier),
+		OutboxWorker:    services.NewOutboxWorker(cfg, repo.OutboxRepo, notifier),
+		BounceWorker:    bounceWorker,
 	}, nil
 }
@@ -0,0 +1,343 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/spreadsheet"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+// UsersPath is where the bulk user endpoints are mounted on the HTTP mux.
+// File uploads and CSV downloads do not fit gRPC-Gateway, so these are plain HTTP handlers.
+const UsersPath = "/v1/admin/users"
+
+const (
+	maxUploadBytes = 20 << 20
+	xlsxMIMEType   = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// UserHandler serves bulk import and export of users for superusers.
+// It expects the user to be put in the request context by the auth middleware.
+type UserHandler struct {
+	userService *services.UserService
+	mux         *http.ServeMux
+}
+
+func NewUserHandler(userService *services.UserService) http.Handler {
+	h := &UserHandler{userService: userService, mux: http.NewServeMux()}
+
+	h.mux.HandleFunc("POST "+UsersPath+"/import", h.importUsers)
+	h.mux.HandleFunc("GET "+UsersPath+"/import/{id}", h.getImportJob)
+	h.mux.HandleFunc("GET "+UsersPath+"/export", h.exportUsers)
+
+	return h
+}
+
+func (h *UserHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := util.UserFromContext(r.Context())
+	if user == nil || !util.HasRole(user, string(entities.RoleSuperuser)) {
+		writeError(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// importUsers accepts the file either as the "file" field of a multipart form
+// or as the raw request body with a text/csv or XLSX content type.
+func (h *UserHandler) importUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := entities.UserImportOptions{
+		DryRun:      parseBool(q.Get("dry_run")),
+		Region:      strings.ToUpper(q.Get("region")),
+		SendWelcome: parseBool(q.Get("send_welcome")),
+	}
+
+	body, format, err := uploadedFile(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	reader, err := spreadsheet.NewReader(body, format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer reader.Close()
+
+	rows, err := readImportRows(reader)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		writeError(w, http.StatusBadRequest, "file has no rows")
+		return
+	}
+
+	user := util.UserFromContext(r.Context())
+	job, err := h.userService.ImportUsers(r.Context(), user.ID, rows, opts)
+	if err != nil {
+		log.Println(err)
+		writeError(w, http.StatusInternalServerError, "failed to import users")
+		return
+	}
+	statusCode := http.StatusOK
+	if job.Status == entities.UserImportStatusPending || job.Status == entities.UserImportStatusRunning {
+		statusCode = http.StatusAccepted
+	}
+	writeJSON(w, statusCode, importJobToResponse(job))
+}
+
+func (h *UserHandler) getImportJob(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+	job, err := h.userService.GetImportJob(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrImportJobNotFound) {
+			writeError(w, http.StatusNotFound, "import job not found")
+			return
+		}
+		log.Println(err)
+		writeError(w, http.StatusInternalServerError, "failed to get import job")
+		return
+	}
+	writeJSON(w, http.StatusOK, importJobToResponse(job))
+}
+
+// exportUsers takes the same filter and sort query parameters as GET /v1/users
+func (h *UserHandler) exportUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := userFilterFromQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filename := fmt.Sprintf("users-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	// Rows are streamed, so a failure part way through can only be logged
+	if err := h.userService.ExportUsers(r.Context(), w, filter); err != nil {
+		log.Println(fmt.Errorf("failed to export users: %w", err))
+	}
+}
+
+// uploadedFile returns the upload as a stream together with its spreadsheet format
+func uploadedFile(w http.ResponseWriter, r *http.Request) (io.Reader, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid multipart body: %w", err)
+		}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil, "", errors.New("file is required")
+			}
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid multipart body: %w", err)
+			}
+			if part.FormName() == "file" {
+				return part, spreadsheet.FormatFromFilename(part.FileName()), nil
+			}
+		}
+	case xlsxMIMEType:
+		return r.Body, spreadsheet.FormatXLSX, nil
+	case "text/csv", "text/plain", "":
+		return r.Body, spreadsheet.FormatCSV, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported content type %q", mediaType)
+	}
+}
+
+// readImportRows maps records to users using the header row.
+// Columns are matched by name so files exported by ExportUsers can be imported again.
+func readImportRows(reader spreadsheet.Reader) ([]entities.UserImportRow, error) {
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "phone" {
+			name = "phone_number"
+		}
+		columns[name] = i
+	}
+	_, hasEmail := columns["email"]
+	_, hasPhone := columns["phone_number"]
+	if !hasEmail && !hasPhone {
+		return nil, errors.New("header must contain an email or phone_number column")
+	}
+
+	cell := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return services.RestoreFormula(strings.TrimSpace(record[i]))
+	}
+
+	var rows []entities.UserImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read line %d: %w", line, err)
+		}
+		if isBlank(record) {
+			continue
+		}
+		if len(rows) == services.MaxImportRows {
+			return nil, fmt.Errorf("file has more than %d rows", services.MaxImportRows)
+		}
+		row := entities.UserImportRow{
+			Line:     line,
+			Email:    cell(record, "email"),
+			Phone:    cell(record, "phone_number"),
+			FullName: cell(record, "full_name"),
+		}
+		if roles := cell(record, "roles"); roles != "" {
+			row.Roles = strings.Split(roles, services.ImportRoleSeparator)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func userFilterFromQuery(q url.Values) (entities.UserFilter, error) {
+	filter := entities.UserFilter{
+		Search:        strings.TrimSpace(q.Get("search")),
+		Role:          q.Get("role"),
+		OAuthProvider: q.Get("oauth_provider"),
+		SortBy:        entities.UserSortField(q.Get("sort_by")),
+	}
+	switch filter.SortBy {
+	case "", entities.UserSortCreatedAt, entities.UserSortEmail, entities.UserSortFullName, entities.UserSortLastLoginAt:
+	default:
+		return filter, errors.New("sort_by must be created_at, email, full_name or last_login_at")
+	}
+	switch strings.ToLower(q.Get("sort_order")) {
+	case "", "desc":
+		filter.SortDesc = true
+	case "asc":
+	default:
+		return filter, errors.New("sort_order must be asc or desc")
+	}
+
+	bools := map[string]**bool{
+		"is_active":         &filter.IsActive,
+		"is_email_verified": &filter.IsEmailVerified,
+		"is_phone_verified": &filter.IsPhoneVerified,
+	}
+	if v := q.Get("deleted"); v != "" {
+		deleted, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("invalid deleted")
+		}
+		filter.Deleted = deleted
+	}
+	for name, dst := range bools {
+		if v := q.Get(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", name)
+			}
+			*dst = &b
+		}
+	}
+	times := map[string]**time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+	}
+	for name, dst := range times {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, expected RFC 3339", name)
+			}
+			*dst = &t
+		}
+	}
+	return filter, nil
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseBool(s string) bool {
+	b, _ := strconv.ParseBool(s)
+	return b
+}
+
+type importJobResponse struct {
+	ID            string                         `json:"id"`
+	Status        string                         `json:"status"`
+	DryRun        bool                           `json:"dry_run"`
+	TotalRows     int                            `json:"total_rows"`
+	ProcessedRows int                            `json:"processed_rows"`
+	CreatedCount  int                            `json:"created_count"`
+	SkippedCount  int                            `json:"skipped_count"`
+	ErrorCount    int                            `json:"error_count"`
+	Report        []entities.UserImportRowResult `json:"report,omitempty"`
+	Error         string                         `json:"error,omitempty"`
+	CreatedAt     time.Time                      `json:"created_at"`
+	FinishedAt    *time.Time                     `json:"finished_at,omitempty"`
+}
+
+func importJobToResponse(job *entities.UserImportJob) importJobResponse {
+	return importJobResponse{
+		ID:            job.ID.String(),
+		Status:        string(job.Status),
+		DryRun:        job.DryRun,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		CreatedCount:  job.CreatedCount,
+		SkippedCount:  job.SkippedCount,
+		ErrorCount:    job.ErrorCount,
+		Report:        job.Report,
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt,
+		FinishedAt:    job.FinishedAt,
+	}
+}
+
+// writeError uses the same body shape as the auth middleware so the frontend can show the message
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
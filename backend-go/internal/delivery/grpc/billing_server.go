@@ -2,15 +2,20 @@ package grpc
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"time"
 
+	"github.com/google/uuid"
 	salonappv1 "github.com/williamchand/fullstack-fastapi/backend-go/gen/proto/v1"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/i18n"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type billingServer struct {
@@ -24,11 +29,15 @@ func NewBillingServer(svc *services.BillingService) salonappv1.BillingServiceSer
 
 func (s *billingServer) CreateCheckoutSession(ctx context.Context, req *salonappv1.CreateCheckoutSessionRequest) (*salonappv1.CreateCheckoutSessionResponse, error) {
 	user := util.UserFromContext(ctx)
-	url, id, err := s.svc.CreateCheckoutSession(ctx, user.ID, req.SuccessUrl, req.CancelUrl)
+	c, err := s.svc.CreateCheckout(ctx, entities.PaymentProviderStripe, entities.CheckoutRequest{
+		UserID:     user.ID,
+		SuccessURL: req.SuccessUrl,
+		CancelURL:  req.CancelUrl,
+	})
 	if err != nil {
-		return nil, err
+		return nil, paymentError(err, "failed to create checkout session")
 	}
-	return &salonappv1.CreateCheckoutSessionResponse{Url: url, SessionId: id}, nil
+	return &salonappv1.CreateCheckoutSessionResponse{Url: c.URL, SessionId: c.TransactionID}, nil
 }
 
 func (s *billingServer) GetSubscriptionStatus(ctx context.Context, _ *emptypb.Empty) (*salonappv1.GetSubscriptionStatusResponse, error) {
@@ -37,61 +46,87 @@ func (s *billingServer) GetSubscriptionStatus(ctx context.Context, _ *emptypb.Em
 	if err != nil {
 		return nil, err
 	}
-	return &salonappv1.GetSubscriptionStatusResponse{Status: string(sub.Status), StripeSubscriptionId: str(sub.StripeSubscriptionID)}, nil
+	resp := &salonappv1.GetSubscriptionStatusResponse{Status: string(sub.Status), StripeSubscriptionId: str(sub.StripeSubscriptionID)}
+	if sub.CurrentPeriodEnd != nil {
+		resp.CurrentPeriodEnd = timestamppb.New(*sub.CurrentPeriodEnd)
+	}
+	return resp, nil
 }
 
-func (s *billingServer) HandleWebhook(ctx context.Context, req *salonappv1.HandleWebhookRequest) (*emptypb.Empty, error) {
-	switch req.Provider {
-	case salonappv1.Provider_PROVIDER_STRIPE:
-		if err := s.svc.HandleWebhook(ctx, req.Payload, req.Signature); err != nil {
-			return nil, err
-		}
-		return &emptypb.Empty{}, nil
-	case salonappv1.Provider_PROVIDER_DOKU:
-		// Parse payload JSON for DOKU notification
-		var invoice, session, currency, amount string
-		var statusStr entities.PaymentStatus
-		// Lightweight decode: try known fields
-		type dokuPayload struct {
-			Response struct {
-				Order struct {
-					InvoiceNumber string `json:"invoice_number"`
-					SessionID     string `json:"session_id"`
-					Currency      string `json:"currency"`
-				} `json:"order"`
-			} `json:"response"`
-			Order struct {
-				InvoiceNumber string `json:"invoice_number"`
-			} `json:"order"`
-			Status    string `json:"status"`
-			Currency  string `json:"currency"`
-			Amount    string `json:"amount"`
-			SessionID string `json:"session_id"`
+func (s *billingServer) ListWebhookEvents(ctx context.Context, req *salonappv1.ListWebhookEventsRequest) (*salonappv1.ListWebhookEventsResponse, error) {
+	var provider entities.PaymentProvider
+	if req.Provider != salonappv1.Provider_PROVIDER_UNSPECIFIED {
+		var ok bool
+		if provider, ok = paymentProviderFromProto(req.Provider); !ok {
+			return nil, status.Error(codes.InvalidArgument, "unsupported provider")
 		}
-		var dp dokuPayload
-		if err := json.Unmarshal(req.Payload, &dp); err == nil {
-			invoice = dp.Response.Order.InvoiceNumber
-			if invoice == "" {
-				invoice = dp.Order.InvoiceNumber
-			}
-			session = dp.Response.Order.SessionID
-			if session == "" {
-				session = dp.SessionID
-			}
-			currency = dp.Response.Order.Currency
-			if currency == "" {
-				currency = dp.Currency
-			}
-			amount = dp.Amount
-			statusStr = entities.PaymentStatus(dp.Status)
+	}
+	events, total, err := s.svc.ListWebhookEvents(ctx, provider, entities.WebhookEventStatus(req.Status), req.Skip, req.Limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidWebhookEventStatus) {
+			return nil, i18n.Error(codes.InvalidArgument, err)
 		}
-		if err := s.svc.HandleDokuNotification(ctx, invoice, session, currency, amount, statusStr); err != nil {
-			return nil, err
+		return nil, status.Error(codes.Internal, "failed to list webhook events")
+	}
+	resp := &salonappv1.ListWebhookEventsResponse{Events: make([]*salonappv1.WebhookEvent, len(events)), Total: int32(total)}
+	for i, e := range events {
+		resp.Events[i] = webhookEventToProto(e)
+	}
+	return resp, nil
+}
+
+func (s *billingServer) ReplayWebhookEvent(ctx context.Context, req *salonappv1.ReplayWebhookEventRequest) (*salonappv1.WebhookEvent, error) {
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	e, err := s.svc.ReplayWebhookEvent(ctx, id)
+	if err != nil {
+		if errors.Is(err, services.ErrWebhookEventNotFound) {
+			return nil, i18n.Error(codes.NotFound, services.ErrWebhookEventNotFound)
 		}
-		return &emptypb.Empty{}, nil
-	default:
-		return nil, status.Error(codes.InvalidArgument, "unsupported provider")
+		return nil, status.Error(codes.Internal, "failed to replay webhook event")
+	}
+	return webhookEventToProto(e), nil
+}
+
+func paymentProviderFromProto(p salonappv1.Provider) (entities.PaymentProvider, bool) {
+	switch p {
+	case salonappv1.Provider_PROVIDER_STRIPE:
+		return entities.PaymentProviderStripe, true
+	case salonappv1.Provider_PROVIDER_DOKU:
+		return entities.PaymentProviderDoku, true
+	}
+	return "", false
+}
+
+func paymentProviderToProto(p entities.PaymentProvider) salonappv1.Provider {
+	switch p {
+	case entities.PaymentProviderStripe:
+		return salonappv1.Provider_PROVIDER_STRIPE
+	case entities.PaymentProviderDoku:
+		return salonappv1.Provider_PROVIDER_DOKU
+	}
+	return salonappv1.Provider_PROVIDER_UNSPECIFIED
+}
+
+func webhookEventToProto(e *entities.WebhookEvent) *salonappv1.WebhookEvent {
+	out := &salonappv1.WebhookEvent{
+		Id:        e.ID.String(),
+		Provider:  paymentProviderToProto(e.Provider),
+		EventId:   e.EventID,
+		EventType: e.EventType,
+		Payload:   string(e.Payload),
+		Status:    string(e.Status),
+		Attempts:  int32(e.Attempts),
+		LastError: e.LastError,
+		CreatedAt: timestamppb.New(e.CreatedAt),
+		UpdatedAt: timestamppb.New(e.UpdatedAt),
 	}
+	if e.ProcessedAt != nil {
+		out.ProcessedAt = timestamppb.New(*e.ProcessedAt)
+	}
+	return out
 }
 
 // CreateDokuPayment initiates a Jokul Checkout payment and returns payment URL
@@ -103,11 +138,16 @@ func (s *billingServer) CreateDokuPayment(ctx context.Context, req *salonappv1.C
 	if amount <= 0 || invoice == "" {
 		return nil, status.Error(codes.InvalidArgument, "invalid amount or invoice number")
 	}
-	url, txid, err := s.svc.CreateDokuPayment(ctx, user.ID, amount, invoice, due)
+	c, err := s.svc.CreateCheckout(ctx, entities.PaymentProviderDoku, entities.CheckoutRequest{
+		UserID:    user.ID,
+		Amount:    amount,
+		Reference: invoice,
+		ExpiresIn: time.Duration(due) * time.Minute,
+	})
 	if err != nil {
-		return nil, err
+		return nil, paymentError(err, "failed to create doku payment")
 	}
-	return &salonappv1.CreateDokuPaymentResponse{PaymentUrl: url, TransactionId: txid}, nil
+	return &salonappv1.CreateDokuPaymentResponse{PaymentUrl: c.URL, TransactionId: c.TransactionID}, nil
 }
 
 // statusError wraps a simple string into an error; reuse existing error handling
@@ -116,22 +156,57 @@ func (s *billingServer) RefreshPaymentStatus(ctx context.Context, req *salonappv
 	if req.TransactionId == "" || req.Provider == salonappv1.Provider_PROVIDER_UNSPECIFIED {
 		return nil, status.Error(codes.InvalidArgument, "transaction_id and provider are required")
 	}
-	var provider entities.PaymentProvider
-	switch req.Provider {
-	case salonappv1.Provider_PROVIDER_STRIPE:
-		provider = entities.PaymentProviderStripe
-	case salonappv1.Provider_PROVIDER_DOKU:
-		provider = entities.PaymentProviderDoku
-	default:
+	provider, ok := paymentProviderFromProto(req.Provider)
+	if !ok {
 		return nil, status.Error(codes.InvalidArgument, "unsupported provider")
 	}
 	statusStr, err := s.svc.RefreshPaymentStatus(ctx, user.ID, req.TransactionId, provider)
 	if err != nil {
-		return nil, err
+		return nil, paymentError(err, "failed to refresh payment status")
 	}
 	return &salonappv1.PaymentStatusResponse{TransactionId: req.TransactionId, Provider: req.Provider, Status: statusStr}, nil
 }
 
+func (s *billingServer) CancelPayment(ctx context.Context, req *salonappv1.CancelPaymentRequest) (*salonappv1.PaymentStatusResponse, error) {
+	user := util.UserFromContext(ctx)
+	provider, ok := paymentProviderFromProto(req.Provider)
+	if req.TransactionId == "" || !ok {
+		return nil, status.Error(codes.InvalidArgument, "transaction_id and provider are required")
+	}
+	p, err := s.svc.CancelPayment(ctx, user.ID, req.TransactionId, provider)
+	if err != nil {
+		return nil, paymentError(err, "failed to cancel payment")
+	}
+	return &salonappv1.PaymentStatusResponse{TransactionId: p.TransactionID, Provider: req.Provider, Status: string(p.Status)}, nil
+}
+
+func (s *billingServer) RefundPayment(ctx context.Context, req *salonappv1.RefundPaymentRequest) (*salonappv1.PaymentStatusResponse, error) {
+	provider, ok := paymentProviderFromProto(req.Provider)
+	if req.TransactionId == "" || !ok {
+		return nil, status.Error(codes.InvalidArgument, "transaction_id and provider are required")
+	}
+	p, err := s.svc.RefundPayment(ctx, req.TransactionId, provider)
+	if err != nil {
+		return nil, paymentError(err, "failed to refund payment")
+	}
+	return &salonappv1.PaymentStatusResponse{TransactionId: p.TransactionID, Provider: req.Provider, Status: string(p.Status)}, nil
+}
+
+func paymentError(err error, internal string) error {
+	switch {
+	case errors.Is(err, services.ErrPaymentNotFound):
+		return i18n.Error(codes.NotFound, services.ErrPaymentNotFound)
+	case errors.Is(err, services.ErrPaymentStatus):
+		return i18n.Error(codes.FailedPrecondition, err)
+	case errors.Is(err, services.ErrPaymentProviderUnavailable):
+		return i18n.Error(codes.FailedPrecondition, err)
+	case errors.Is(err, repositories.ErrPaymentOperationUnsupported):
+		return i18n.Error(codes.Unimplemented, err)
+	default:
+		return status.Error(codes.Internal, internal)
+	}
+}
+
 func (s *billingServer) CheckDailySubscriptions(ctx context.Context, _ *emptypb.Empty) (*salonappv1.CheckDailySubscriptionsResponse, error) {
 	updated, expired, err := s.svc.CheckDailySubscriptions(ctx)
 	if err != nil {
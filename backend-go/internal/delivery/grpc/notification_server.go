@@ -0,0 +1,780 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	salonappv1 "github.com/williamchand/fullstack-fastapi/backend-go/gen/proto/v1"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/i18n"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type notificationServer struct {
+	salonappv1.UnimplementedNotificationServiceServer
+	notifService       *services.NotificationService
+	templateService    *services.EmailTemplateService
+	suppressionService *services.EmailSuppressionService
+	whatsAppService    *services.WhatsAppService
+	inboxService       *services.InboxService
+	preferenceService  *services.NotificationPreferenceService
+	campaignService    *services.CampaignService
+}
+
+func NewNotificationServer(
+	notifService *services.NotificationService,
+	templateService *services.EmailTemplateService,
+	suppressionService *services.EmailSuppressionService,
+	whatsAppService *services.WhatsAppService,
+	inboxService *services.InboxService,
+	preferenceService *services.NotificationPreferenceService,
+	campaignService *services.CampaignService,
+) salonappv1.NotificationServiceServer {
+	return &notificationServer{
+		notifService:       notifService,
+		templateService:    templateService,
+		suppressionService: suppressionService,
+		whatsAppService:    whatsAppService,
+		inboxService:       inboxService,
+		preferenceService:  preferenceService,
+		campaignService:    campaignService,
+	}
+}
+
+func (s *notificationServer) ListOutboundMessages(ctx context.Context, req *salonappv1.ListOutboundMessagesRequest) (*salonappv1.ListOutboundMessagesResponse, error) {
+	var userID *uuid.UUID
+	if req.UserId != "" {
+		id, err := uuid.Parse(req.UserId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+		}
+		userID = &id
+	}
+	msgs, counts, err := s.notifService.ListOutboundMessages(ctx, entities.OutboundMessageStatus(req.Status), userID, req.Skip, req.Limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMessageStatus) {
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidMessageStatus)
+		}
+		return nil, status.Error(codes.Internal, "failed to list outbound messages")
+	}
+	resp := &salonappv1.ListOutboundMessagesResponse{
+		Messages:     make([]*salonappv1.OutboundMessage, len(msgs)),
+		StatusCounts: make(map[string]int32, len(counts)),
+	}
+	for i, m := range msgs {
+		resp.Messages[i] = outboundMessageToProto(m)
+	}
+	for st, n := range counts {
+		resp.StatusCounts[string(st)] = int32(n)
+	}
+	return resp, nil
+}
+
+func (s *notificationServer) GetOutboundMessage(ctx context.Context, req *salonappv1.GetOutboundMessageRequest) (*salonappv1.OutboundMessage, error) {
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	msg, err := s.notifService.GetOutboundMessage(ctx, id)
+	if err != nil {
+		if errors.Is(err, services.ErrOutboundMessageNotFound) {
+			return nil, i18n.Error(codes.NotFound, services.ErrOutboundMessageNotFound)
+		}
+		return nil, status.Error(codes.Internal, "failed to get outbound message")
+	}
+	return outboundMessageToProto(msg), nil
+}
+
+func (s *notificationServer) RetryOutboundMessage(ctx context.Context, req *salonappv1.RetryOutboundMessageRequest) (*salonappv1.OutboundMessage, error) {
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	msg, err := s.notifService.RetryOutboundMessage(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOutboundMessageNotFound):
+			return nil, i18n.Error(codes.NotFound, services.ErrOutboundMessageNotFound)
+		case errors.Is(err, services.ErrMessageNotDead):
+			return nil, i18n.Error(codes.FailedPrecondition, services.ErrMessageNotDead)
+		case errors.Is(err, services.ErrMessageContentCleared):
+			return nil, i18n.Error(codes.FailedPrecondition, services.ErrMessageContentCleared)
+		default:
+			return nil, status.Error(codes.Internal, "failed to retry outbound message")
+		}
+	}
+	return outboundMessageToProto(msg), nil
+}
+
+func (s *notificationServer) RefreshOutboundMessageStatus(ctx context.Context, req *salonappv1.RefreshOutboundMessageStatusRequest) (*salonappv1.OutboundMessage, error) {
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	msg, err := s.whatsAppService.RefreshMessageStatus(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOutboundMessageNotFound):
+			return nil, i18n.Error(codes.NotFound, services.ErrOutboundMessageNotFound)
+		case errors.Is(err, services.ErrMessageNotOnWhatsApp):
+			return nil, i18n.Error(codes.FailedPrecondition, services.ErrMessageNotOnWhatsApp)
+		default:
+			return nil, status.Error(codes.Unavailable, "failed to get message status from whatsapp")
+		}
+	}
+	return outboundMessageToProto(msg), nil
+}
+
+func (s *notificationServer) ListEmailTemplates(ctx context.Context, _ *emptypb.Empty) (*salonappv1.ListEmailTemplatesResponse, error) {
+	tpls, err := s.templateService.ListTemplates(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list email templates")
+	}
+	return emailTemplatesToProto(tpls), nil
+}
+
+func (s *notificationServer) ListEmailTemplateVersions(ctx context.Context, req *salonappv1.ListEmailTemplateVersionsRequest) (*salonappv1.ListEmailTemplatesResponse, error) {
+	tpls, err := s.templateService.ListVersions(ctx, entities.EmailTemplateEnum(req.Name), req.Locale)
+	if err != nil {
+		return nil, emailTemplateError(err, "failed to list email template versions")
+	}
+	return emailTemplatesToProto(tpls), nil
+}
+
+func (s *notificationServer) CreateEmailTemplate(ctx context.Context, req *salonappv1.CreateEmailTemplateRequest) (*salonappv1.EmailTemplate, error) {
+	user := util.UserFromContext(ctx)
+	tpl, err := s.templateService.CreateTemplate(ctx, user.ID, entities.EmailTemplateEnum(req.Name), req.Locale, req.Subject, req.Body, whatsAppContentFromProto(req.Whatsapp), req.Campaign)
+	if err != nil {
+		return nil, emailTemplateError(err, "failed to create email template")
+	}
+	return emailTemplateToProto(tpl), nil
+}
+
+func (s *notificationServer) UpdateEmailTemplate(ctx context.Context, req *salonappv1.UpdateEmailTemplateRequest) (*salonappv1.EmailTemplate, error) {
+	user := util.UserFromContext(ctx)
+	tpl, err := s.templateService.UpdateTemplate(ctx, user.ID, entities.EmailTemplateEnum(req.Name), req.Locale, req.Subject, req.Body, whatsAppContentFromProto(req.Whatsapp), req.Campaign, req.Activate)
+	if err != nil {
+		return nil, emailTemplateError(err, "failed to update email template")
+	}
+	return emailTemplateToProto(tpl), nil
+}
+
+func (s *notificationServer) ActivateEmailTemplateVersion(ctx context.Context, req *salonappv1.ActivateEmailTemplateVersionRequest) (*salonappv1.EmailTemplate, error) {
+	if req.Version <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "version is required")
+	}
+	tpl, err := s.templateService.ActivateVersion(ctx, entities.EmailTemplateEnum(req.Name), req.Locale, int(req.Version))
+	if err != nil {
+		return nil, emailTemplateError(err, "failed to activate email template version")
+	}
+	return emailTemplateToProto(tpl), nil
+}
+
+func (s *notificationServer) PreviewEmailTemplate(ctx context.Context, req *salonappv1.PreviewEmailTemplateRequest) (*salonappv1.PreviewEmailTemplateResponse, error) {
+	var draft *entities.EmailTemplate
+	if req.Subject != nil || req.Body != nil {
+		draft = &entities.EmailTemplate{Subject: fromPtr(req.Subject), Body: fromPtr(req.Body), WhatsApp: whatsAppContentFromProto(req.Whatsapp)}
+	}
+	msg, err := s.templateService.Preview(ctx, entities.EmailTemplateEnum(req.Name), req.Locale, int(req.Version), draft, req.Data)
+	if err != nil {
+		return nil, emailTemplateError(err, "failed to preview email template")
+	}
+	return &salonappv1.PreviewEmailTemplateResponse{
+		Channel:  string(msg.Channel),
+		Subject:  msg.Subject,
+		Body:     msg.Body,
+		Whatsapp: whatsAppContentToProto(msg.WhatsApp),
+	}, nil
+}
+
+func (s *notificationServer) SendTestEmailTemplate(ctx context.Context, req *salonappv1.SendTestEmailTemplateRequest) (*salonappv1.OutboundMessage, error) {
+	name := entities.EmailTemplateEnum(req.Name)
+	recipient := req.Recipient
+	if recipient == "" {
+		user := util.UserFromContext(ctx)
+		if name.IsPhone() {
+			recipient = fromPtr(user.PhoneNumber)
+		} else {
+			recipient = user.Email
+		}
+	}
+	msg, err := s.templateService.SendTestMessage(ctx, name, req.Locale, int(req.Version), recipient, req.Data)
+	if err != nil {
+		return nil, emailTemplateError(err, "failed to send test message")
+	}
+	return outboundMessageToProto(msg), nil
+}
+
+func (s *notificationServer) ListWhatsAppSessions(ctx context.Context, _ *emptypb.Empty) (*salonappv1.ListWhatsAppSessionsResponse, error) {
+	sessions, err := s.whatsAppService.ListSessions(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list whatsapp sessions")
+	}
+	resp := &salonappv1.ListWhatsAppSessionsResponse{Sessions: make([]*salonappv1.WhatsAppSession, len(sessions))}
+	for i, sess := range sessions {
+		resp.Sessions[i] = &salonappv1.WhatsAppSession{
+			Name:            sess.Name,
+			Status:          sess.Status,
+			StatusChangedAt: timestamppb.New(sess.StatusChangedAt),
+			LastEventAt:     timestamppb.New(sess.LastEventAt),
+		}
+		if sess.OrganizationID != nil {
+			resp.Sessions[i].OrganizationId = sess.OrganizationID.String()
+		}
+	}
+	return resp, nil
+}
+
+func (s *notificationServer) ListEmailSuppressions(ctx context.Context, req *salonappv1.ListEmailSuppressionsRequest) (*salonappv1.ListEmailSuppressionsResponse, error) {
+	suppressions, total, err := s.suppressionService.List(ctx, req.Search, req.Skip, req.Limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list email suppressions")
+	}
+	resp := &salonappv1.ListEmailSuppressionsResponse{
+		Suppressions: make([]*salonappv1.EmailSuppression, len(suppressions)),
+		Total:        int32(total),
+	}
+	for i, e := range suppressions {
+		resp.Suppressions[i] = &salonappv1.EmailSuppression{
+			Email:     e.Email,
+			Reason:    string(e.Reason),
+			Detail:    e.Detail,
+			Source:    e.Source,
+			CreatedAt: timestamppb.New(e.CreatedAt),
+			UpdatedAt: timestamppb.New(e.UpdatedAt),
+		}
+	}
+	return resp, nil
+}
+
+func (s *notificationServer) DeleteEmailSuppression(ctx context.Context, req *salonappv1.DeleteEmailSuppressionRequest) (*emptypb.Empty, error) {
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+	if err := s.suppressionService.Lift(ctx, req.Email); err != nil {
+		if errors.Is(err, services.ErrSuppressionNotFound) {
+			return nil, i18n.Error(codes.NotFound, services.ErrSuppressionNotFound)
+		}
+		return nil, status.Error(codes.Internal, "failed to lift email suppression")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func emailTemplateError(err error, internal string) error {
+	switch {
+	case errors.Is(err, services.ErrTemplateNotFound):
+		return i18n.Error(codes.NotFound, services.ErrTemplateNotFound)
+	case errors.Is(err, services.ErrTemplateExists):
+		return i18n.Error(codes.AlreadyExists, services.ErrTemplateExists)
+	case errors.Is(err, services.ErrInvalidTemplate):
+		return i18n.Error(codes.InvalidArgument, err)
+	case errors.Is(err, services.ErrInvalidTemplateName):
+		return i18n.Error(codes.InvalidArgument, services.ErrInvalidTemplateName)
+	case errors.Is(err, services.ErrInvalidLocale):
+		return i18n.Error(codes.InvalidArgument, services.ErrInvalidLocale)
+	case errors.Is(err, services.ErrRecipientRequired):
+		return i18n.Error(codes.InvalidArgument, services.ErrRecipientRequired)
+	case errors.Is(err, repositories.ErrTemplateVersionConflict):
+		return i18n.Error(codes.Aborted, repositories.ErrTemplateVersionConflict)
+	default:
+		return status.Error(codes.Internal, internal)
+	}
+}
+
+func (s *notificationServer) ListNotifications(ctx context.Context, req *salonappv1.ListNotificationsRequest) (*salonappv1.ListNotificationsResponse, error) {
+	user := util.UserFromContext(ctx)
+	list, unread, err := s.inboxService.List(ctx, user.ID, req.UnreadOnly, req.Skip, req.Limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list notifications")
+	}
+	resp := &salonappv1.ListNotificationsResponse{
+		Notifications: make([]*salonappv1.Notification, len(list)),
+		UnreadCount:   int32(unread),
+	}
+	for i, n := range list {
+		resp.Notifications[i] = notificationToProto(n)
+	}
+	return resp, nil
+}
+
+func (s *notificationServer) MarkNotificationsRead(ctx context.Context, req *salonappv1.MarkNotificationsReadRequest) (*salonappv1.MarkNotificationsReadResponse, error) {
+	user := util.UserFromContext(ctx)
+	ids := make([]uuid.UUID, 0, len(req.Ids))
+	for _, raw := range req.Ids {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid notification id")
+		}
+		ids = append(ids, id)
+	}
+	marked, err := s.inboxService.MarkRead(ctx, user.ID, ids, req.All)
+	if err != nil {
+		if errors.Is(err, services.ErrNoNotificationsSelected) {
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrNoNotificationsSelected)
+		}
+		return nil, status.Error(codes.Internal, "failed to mark notifications read")
+	}
+	unread, err := s.inboxService.UnreadCount(ctx, user.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to count unread notifications")
+	}
+	return &salonappv1.MarkNotificationsReadResponse{Marked: int32(marked), UnreadCount: int32(unread)}, nil
+}
+
+func (s *notificationServer) GetUnreadCount(ctx context.Context, _ *emptypb.Empty) (*salonappv1.GetUnreadCountResponse, error) {
+	user := util.UserFromContext(ctx)
+	unread, err := s.inboxService.UnreadCount(ctx, user.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to count unread notifications")
+	}
+	return &salonappv1.GetUnreadCountResponse{UnreadCount: int32(unread)}, nil
+}
+
+// notificationStreamHeartbeat keeps proxies from closing an idle stream, and corrects unread
+// counts changed through another instance, which this one does not hear about
+const notificationStreamHeartbeat = 25 * time.Second
+
+func (s *notificationServer) StreamNotifications(_ *emptypb.Empty, stream salonappv1.NotificationService_StreamNotificationsServer) error {
+	ctx := stream.Context()
+	user := util.UserFromContext(ctx)
+
+	// Subscribe before the first count so nothing created in between is missed
+	updates, cancel := s.inboxService.Subscribe(user.ID)
+	defer cancel()
+
+	send := func(n *entities.Notification) error {
+		unread, err := s.inboxService.UnreadCount(ctx, user.ID)
+		if err != nil {
+			return status.Error(codes.Internal, "failed to count unread notifications")
+		}
+		event := &salonappv1.NotificationEvent{UnreadCount: int32(unread)}
+		if n != nil {
+			event.Notification = notificationToProto(n)
+		}
+		return stream.Send(event)
+	}
+	if err := send(nil); err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(notificationStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-updates:
+			err = send(n)
+		case <-heartbeat.C:
+			err = send(nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *notificationServer) GetNotificationPreferences(ctx context.Context, _ *emptypb.Empty) (*salonappv1.NotificationPreferences, error) {
+	user := util.UserFromContext(ctx)
+	prefs, err := s.preferenceService.Preferences(ctx, user.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get notification preferences")
+	}
+	return notificationPreferencesToProto(prefs), nil
+}
+
+func (s *notificationServer) UpdateNotificationPreferences(ctx context.Context, req *salonappv1.UpdateNotificationPreferencesRequest) (*salonappv1.NotificationPreferences, error) {
+	user := util.UserFromContext(ctx)
+	prefs := make([]*entities.NotificationPreference, len(req.Preferences))
+	for i, p := range req.Preferences {
+		prefs[i] = &entities.NotificationPreference{
+			Category: entities.NotificationCategory(p.Category),
+			Channel:  entities.MessageChannel(p.Channel),
+			Enabled:  p.Enabled,
+		}
+	}
+	updated, err := s.preferenceService.UpdatePreferences(ctx, user.ID, prefs)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPreference) {
+			return nil, i18n.Error(codes.InvalidArgument, err)
+		}
+		return nil, status.Error(codes.Internal, "failed to update notification preferences")
+	}
+	return notificationPreferencesToProto(updated), nil
+}
+
+func (s *notificationServer) Unsubscribe(ctx context.Context, req *salonappv1.UnsubscribeRequest) (*salonappv1.NotificationPreference, error) {
+	pref, err := s.preferenceService.Unsubscribe(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidUnsubscribeToken) {
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidUnsubscribeToken)
+		}
+		return nil, status.Error(codes.Internal, "failed to unsubscribe")
+	}
+	return notificationPreferenceToProto(pref), nil
+}
+
+func (s *notificationServer) CreateCampaign(ctx context.Context, req *salonappv1.CreateCampaignRequest) (*salonappv1.Campaign, error) {
+	user := util.UserFromContext(ctx)
+	c := &entities.Campaign{
+		Name:           req.Name,
+		Channel:        entities.MessageChannel(req.Channel),
+		TemplateName:   entities.EmailTemplateEnum(req.TemplateName),
+		Category:       entities.NotificationCategory(req.Category),
+		Audience:       campaignAudienceFromProto(req.Audience),
+		Fields:         req.Fields,
+		CronExpression: req.CronExpression,
+		Timezone:       req.Timezone,
+		RatePerMinute:  int(req.RatePerMinute),
+	}
+	if req.SendAt != nil {
+		sendAt := req.SendAt.AsTime()
+		c.SendAt = &sendAt
+	}
+	created, err := s.campaignService.CreateCampaign(ctx, user, campaignOrganizationID(ctx), c)
+	if err != nil {
+		return nil, campaignError(err, "failed to create campaign")
+	}
+	return campaignToProto(created, nil), nil
+}
+
+func (s *notificationServer) ListCampaigns(ctx context.Context, req *salonappv1.ListCampaignsRequest) (*salonappv1.ListCampaignsResponse, error) {
+	user := util.UserFromContext(ctx)
+	campaigns, total, err := s.campaignService.ListCampaigns(ctx, user.ID, campaignOrganizationID(ctx), req.Skip, req.Limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list campaigns")
+	}
+	resp := &salonappv1.ListCampaignsResponse{Campaigns: make([]*salonappv1.Campaign, len(campaigns)), Total: int32(total)}
+	for i, c := range campaigns {
+		resp.Campaigns[i] = campaignToProto(c, nil)
+	}
+	return resp, nil
+}
+
+func (s *notificationServer) GetCampaign(ctx context.Context, req *salonappv1.GetCampaignRequest) (*salonappv1.Campaign, error) {
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	user := util.UserFromContext(ctx)
+	c, stats, err := s.campaignService.GetCampaign(ctx, user.ID, campaignOrganizationID(ctx), id)
+	if err != nil {
+		return nil, campaignError(err, "failed to get campaign")
+	}
+	return campaignToProto(c, stats), nil
+}
+
+func (s *notificationServer) PauseCampaign(ctx context.Context, req *salonappv1.CampaignActionRequest) (*salonappv1.Campaign, error) {
+	return s.changeCampaign(ctx, req.Id, s.campaignService.PauseCampaign, "failed to pause campaign")
+}
+
+func (s *notificationServer) ResumeCampaign(ctx context.Context, req *salonappv1.CampaignActionRequest) (*salonappv1.Campaign, error) {
+	return s.changeCampaign(ctx, req.Id, s.campaignService.ResumeCampaign, "failed to resume campaign")
+}
+
+func (s *notificationServer) CancelCampaign(ctx context.Context, req *salonappv1.CampaignActionRequest) (*salonappv1.Campaign, error) {
+	return s.changeCampaign(ctx, req.Id, s.campaignService.CancelCampaign, "failed to cancel campaign")
+}
+
+func (s *notificationServer) changeCampaign(
+	ctx context.Context,
+	rawID string,
+	change func(context.Context, uuid.UUID, *uuid.UUID, uuid.UUID) (*entities.Campaign, error),
+	failure string,
+) (*salonappv1.Campaign, error) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	user := util.UserFromContext(ctx)
+	c, err := change(ctx, user.ID, campaignOrganizationID(ctx), id)
+	if err != nil {
+		return nil, campaignError(err, failure)
+	}
+	return campaignToProto(c, nil), nil
+}
+
+// campaignOrganizationID is the salon selected by X-Org-Id, nil when the caller acts for themselves
+func campaignOrganizationID(ctx context.Context) *uuid.UUID {
+	if member := util.OrganizationMemberFromContext(ctx); member != nil {
+		return &member.OrganizationID
+	}
+	return nil
+}
+
+func campaignError(err error, failure string) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidCampaign), errors.Is(err, services.ErrInvalidCampaignSchedule):
+		return i18n.Error(codes.InvalidArgument, err)
+	case errors.Is(err, services.ErrTemplateNotFound):
+		return i18n.Error(codes.InvalidArgument, services.ErrTemplateNotFound)
+	case errors.Is(err, services.ErrCampaignAudienceDenied), errors.Is(err, services.ErrCampaignRecipientDenied):
+		return i18n.Error(codes.PermissionDenied, err)
+	case errors.Is(err, services.ErrCampaignTemplateDenied):
+		return i18n.Error(codes.InvalidArgument, services.ErrCampaignTemplateDenied)
+	case errors.Is(err, services.ErrCampaignNotFound):
+		return i18n.Error(codes.NotFound, services.ErrCampaignNotFound)
+	case errors.Is(err, services.ErrCampaignStatus):
+		return i18n.Error(codes.FailedPrecondition, err)
+	}
+	return status.Error(codes.Internal, failure)
+}
+
+func campaignAudienceFromProto(a *salonappv1.CampaignAudience) entities.CampaignAudience {
+	var out entities.CampaignAudience
+	if a == nil {
+		return out
+	}
+	for _, role := range a.MemberRoles {
+		out.MemberRoles = append(out.MemberRoles, entities.OrganizationRole(role))
+	}
+	if q := a.Users; q != nil {
+		out.Users = &entities.CampaignUserQuery{
+			Search:          q.Search,
+			Role:            q.Role,
+			IsEmailVerified: q.IsEmailVerified,
+			IsPhoneVerified: q.IsPhoneVerified,
+		}
+	}
+	out.Recipients = a.Recipients
+	out.Region = a.Region
+	return out
+}
+
+func campaignToProto(c *entities.Campaign, stats *entities.CampaignStats) *salonappv1.Campaign {
+	out := &salonappv1.Campaign{
+		Id:             c.ID.String(),
+		Name:           c.Name,
+		Channel:        string(c.Channel),
+		TemplateName:   string(c.TemplateName),
+		Category:       string(c.Category),
+		Audience:       &salonappv1.CampaignAudience{Recipients: c.Audience.Recipients, Region: c.Audience.Region},
+		Fields:         c.Fields,
+		CronExpression: c.CronExpression,
+		Timezone:       c.Timezone,
+		RatePerMinute:  int32(c.RatePerMinute),
+		Status:         string(c.Status),
+		Runs:           int32(c.Runs),
+		CreatedAt:      timestamppb.New(c.CreatedAt),
+	}
+	if c.OrganizationID != nil {
+		out.OrganizationId = c.OrganizationID.String()
+	}
+	for _, role := range c.Audience.MemberRoles {
+		out.Audience.MemberRoles = append(out.Audience.MemberRoles, string(role))
+	}
+	if q := c.Audience.Users; q != nil {
+		out.Audience.Users = &salonappv1.CampaignUserQuery{
+			Search:          q.Search,
+			Role:            q.Role,
+			IsEmailVerified: q.IsEmailVerified,
+			IsPhoneVerified: q.IsPhoneVerified,
+		}
+	}
+	if c.SendAt != nil {
+		out.SendAt = timestamppb.New(*c.SendAt)
+	}
+	if c.NextRunAt != nil {
+		out.NextRunAt = timestamppb.New(*c.NextRunAt)
+	}
+	if c.LastRunAt != nil {
+		out.LastRunAt = timestamppb.New(*c.LastRunAt)
+	}
+	if stats != nil {
+		out.Stats = &salonappv1.CampaignStats{
+			Total:     int32(stats.Total),
+			Queued:    int32(stats.Queued),
+			Sent:      int32(stats.Sent),
+			Failed:    int32(stats.Failed),
+			Delivered: int32(stats.Delivered),
+			Read:      int32(stats.Read),
+		}
+	}
+	return out
+}
+
+func notificationPreferencesToProto(prefs []*entities.NotificationPreference) *salonappv1.NotificationPreferences {
+	resp := &salonappv1.NotificationPreferences{Preferences: make([]*salonappv1.NotificationPreference, len(prefs))}
+	for i, p := range prefs {
+		resp.Preferences[i] = notificationPreferenceToProto(p)
+	}
+	return resp
+}
+
+func notificationPreferenceToProto(p *entities.NotificationPreference) *salonappv1.NotificationPreference {
+	out := &salonappv1.NotificationPreference{
+		Category: string(p.Category),
+		Channel:  string(p.Channel),
+		Enabled:  p.Enabled,
+	}
+	if !p.UpdatedAt.IsZero() {
+		out.UpdatedAt = timestamppb.New(p.UpdatedAt)
+	}
+	return out
+}
+
+func notificationToProto(n *entities.Notification) *salonappv1.Notification {
+	out := &salonappv1.Notification{
+		Id:        n.ID.String(),
+		Category:  string(n.Category),
+		Title:     n.Title,
+		Body:      n.Body,
+		Data:      make(map[string]string, len(n.Data)),
+		CreatedAt: timestamppb.New(n.CreatedAt),
+	}
+	for k, v := range n.Data {
+		out.Data[k] = fmt.Sprint(v)
+	}
+	if n.ReadAt != nil {
+		out.ReadAt = timestamppb.New(*n.ReadAt)
+	}
+	return out
+}
+
+func emailTemplatesToProto(tpls []*entities.EmailTemplate) *salonappv1.ListEmailTemplatesResponse {
+	resp := &salonappv1.ListEmailTemplatesResponse{Templates: make([]*salonappv1.EmailTemplate, len(tpls))}
+	for i, t := range tpls {
+		resp.Templates[i] = emailTemplateToProto(t)
+	}
+	return resp
+}
+
+func emailTemplateToProto(t *entities.EmailTemplate) *salonappv1.EmailTemplate {
+	tpl := &salonappv1.EmailTemplate{
+		Name:          string(t.Name),
+		Locale:        t.Locale,
+		Version:       int32(t.Version),
+		Subject:       t.Subject,
+		Body:          t.Body,
+		IsActive:      t.IsActive,
+		CreatedAt:     timestamppb.New(t.CreatedAt),
+		UpdatedAt:     timestamppb.New(t.UpdatedAt),
+		LatestVersion: int32(t.LatestVersion),
+		Whatsapp:      whatsAppContentToProto(t.WhatsApp),
+		Campaign:      t.Campaign,
+	}
+	if t.CreatedBy != nil {
+		tpl.CreatedBy = t.CreatedBy.String()
+	}
+	return tpl
+}
+
+// outboundMessageToProto leaves out the body, it can hold one-time codes and reset links
+func outboundMessageToProto(m *entities.OutboundMessage) *salonappv1.OutboundMessage {
+	msg := &salonappv1.OutboundMessage{
+		Id:            m.ID.String(),
+		Channel:       string(m.Channel),
+		Recipient:     m.Recipient,
+		Subject:       m.Subject,
+		Status:        string(m.Status),
+		Attempts:      int32(m.Attempts),
+		MaxAttempts:   int32(m.MaxAttempts),
+		NextAttemptAt: timestamppb.New(m.NextAttemptAt),
+		LastError:     m.LastError,
+		CreatedAt:     timestamppb.New(m.CreatedAt),
+	}
+	for _, ch := range m.FallbackChannels {
+		msg.FallbackChannels = append(msg.FallbackChannels, string(ch))
+	}
+	if m.UserID != nil {
+		msg.UserId = m.UserID.String()
+	}
+	if m.SentAt != nil {
+		msg.SentAt = timestamppb.New(*m.SentAt)
+	}
+	if m.DeliveredAt != nil {
+		msg.DeliveredAt = timestamppb.New(*m.DeliveredAt)
+	}
+	if m.ReadAt != nil {
+		msg.ReadAt = timestamppb.New(*m.ReadAt)
+	}
+	return msg
+}
+
+func whatsAppContentToProto(c *entities.WhatsAppContent) *salonappv1.WhatsAppContent {
+	if c == nil {
+		return nil
+	}
+	out := &salonappv1.WhatsAppContent{Footer: c.Footer}
+	if c.Media != nil {
+		out.Media = &salonappv1.WhatsAppMedia{
+			Kind:     string(c.Media.Kind),
+			Url:      c.Media.URL,
+			Mimetype: c.Media.MimeType,
+			Filename: c.Media.Filename,
+		}
+	}
+	if c.Location != nil {
+		out.Location = &salonappv1.WhatsAppLocation{
+			Latitude:  c.Location.Latitude,
+			Longitude: c.Location.Longitude,
+			Title:     c.Location.Title,
+		}
+	}
+	for _, b := range c.Buttons {
+		out.Buttons = append(out.Buttons, &salonappv1.WhatsAppButton{Type: string(b.Type), Text: b.Text, Url: b.URL, Phone: b.Phone})
+	}
+	if c.List != nil {
+		out.List = &salonappv1.WhatsAppList{Button: c.List.Button}
+		for _, section := range c.List.Sections {
+			ps := &salonappv1.WhatsAppListSection{Title: section.Title}
+			for _, row := range section.Rows {
+				ps.Rows = append(ps.Rows, &salonappv1.WhatsAppListRow{Id: row.ID, Title: row.Title, Description: row.Description})
+			}
+			out.List.Sections = append(out.List.Sections, ps)
+		}
+	}
+	return out
+}
+
+// whatsAppContentFromProto maps an absent or empty message to nil, a template without content
+func whatsAppContentFromProto(c *salonappv1.WhatsAppContent) *entities.WhatsAppContent {
+	if c == nil {
+		return nil
+	}
+	out := &entities.WhatsAppContent{Footer: c.Footer}
+	if m := c.Media; m != nil {
+		out.Media = &entities.WhatsAppMedia{
+			Kind:     entities.WhatsAppMediaKind(m.Kind),
+			URL:      m.Url,
+			MimeType: m.Mimetype,
+			Filename: m.Filename,
+		}
+	}
+	if l := c.Location; l != nil {
+		out.Location = &entities.WhatsAppLocation{Latitude: l.Latitude, Longitude: l.Longitude, Title: l.Title}
+	}
+	for _, b := range c.Buttons {
+		out.Buttons = append(out.Buttons, entities.WhatsAppButton{
+			Type:  entities.WhatsAppButtonType(b.Type),
+			Text:  b.Text,
+			URL:   b.Url,
+			Phone: b.Phone,
+		})
+	}
+	if l := c.List; l != nil {
+		out.List = &entities.WhatsAppList{Button: l.Button}
+		for _, section := range l.Sections {
+			es := entities.WhatsAppListSection{Title: section.Title}
+			for _, row := range section.Rows {
+				es.Rows = append(es.Rows, entities.WhatsAppListRow{ID: row.Id, Title: row.Title, Description: row.Description})
+			}
+			out.List.Sections = append(out.List.Sections, es)
+		}
+	}
+	if out.Media == nil && out.Location == nil && len(out.Buttons) == 0 && out.List == nil && out.Footer == "" {
+		return nil
+	}
+	return out
+}
@@ -7,6 +7,7 @@ import (
 	salonappv1 "github.com/williamchand/fullstack-fastapi/backend-go/gen/proto/v1"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/i18n"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -40,10 +41,10 @@ func (s *oAuthServer) HandleOAuthCallback(ctx context.Context, req *salonappv1.H
 	oauthLoginResult, err := s.oauth.HandleCallback(ctx, req.Provider, req.Code)
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidOAuthCode) {
-			return nil, status.Error(codes.InvalidArgument, "invalid authorization code")
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidOAuthCode)
 		}
 		if errors.Is(err, services.ErrOAuthUnauthorized) {
-			return nil, status.Error(codes.Unauthenticated, "authentication failed")
+			return nil, i18n.Error(codes.Unauthenticated, services.ErrOAuthUnauthorized)
 		}
 		return nil, status.Error(codes.Internal, "failed to handle OAuth callback")
 	}
@@ -70,6 +71,9 @@ func (s *oAuthServer) userToProto(u *entities.User) *salonappv1.User {
 		CreatedAt:       timestamppb.New(u.CreatedAt),
 		UpdatedAt:       timestamppb.New(u.UpdatedAt),
 	}
+	if u.LastLoginAt != nil {
+		p.LastLoginAt = timestamppb.New(*u.LastLoginAt)
+	}
 
 	return p
 }
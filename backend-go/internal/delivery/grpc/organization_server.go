@@ -0,0 +1,217 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	salonappv1 "github.com/williamchand/fullstack-fastapi/backend-go/gen/proto/v1"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/i18n"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type organizationServer struct {
+	salonappv1.UnimplementedOrganizationServiceServer
+	orgService      *services.OrganizationService
+	whatsAppService *services.WhatsAppService
+}
+
+func NewOrganizationServer(orgService *services.OrganizationService, whatsAppService *services.WhatsAppService) salonappv1.OrganizationServiceServer {
+	return &organizationServer{
+		orgService:      orgService,
+		whatsAppService: whatsAppService,
+	}
+}
+
+func (s *organizationServer) CreateOrganization(ctx context.Context, req *salonappv1.CreateOrganizationRequest) (*salonappv1.CreateOrganizationResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	user := util.UserFromContext(ctx)
+	org, err := s.orgService.CreateOrganization(ctx, user.ID, name)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create organization")
+	}
+	return &salonappv1.CreateOrganizationResponse{Organization: organizationToProto(org)}, nil
+}
+
+func (s *organizationServer) ListOrganizations(ctx context.Context, _ *emptypb.Empty) (*salonappv1.ListOrganizationsResponse, error) {
+	user := util.UserFromContext(ctx)
+	orgs, err := s.orgService.ListOrganizations(ctx, user.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list organizations")
+	}
+	protoOrgs := make([]*salonappv1.Organization, len(orgs))
+	for i, o := range orgs {
+		protoOrgs[i] = organizationToProto(o)
+	}
+	return &salonappv1.ListOrganizationsResponse{Organizations: protoOrgs}, nil
+}
+
+func (s *organizationServer) InviteMember(ctx context.Context, req *salonappv1.InviteMemberRequest) (*salonappv1.InviteMemberResponse, error) {
+	if req.Email == "" && req.PhoneNumber == "" {
+		return nil, status.Error(codes.InvalidArgument, "email or phone_number is required")
+	}
+	if req.Email == "" && req.Region == "" {
+		return nil, status.Error(codes.InvalidArgument, "region is required")
+	}
+	member := util.OrganizationMemberFromContext(ctx)
+	user := util.UserFromContext(ctx)
+	err := s.orgService.InviteMember(ctx, user, member.OrganizationID, req.Email, req.PhoneNumber, req.Region, entities.OrganizationRole(req.Role))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRole):
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidRole)
+		case errors.Is(err, services.ErrInvalidState):
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidPhoneNumber)
+		case errors.Is(err, services.ErrOrganizationNotFound):
+			return nil, i18n.Error(codes.NotFound, services.ErrOrganizationNotFound)
+		default:
+			return nil, status.Error(codes.Internal, "failed to invite member")
+		}
+	}
+	return &salonappv1.InviteMemberResponse{Success: true, Message: "invitation sent"}, nil
+}
+
+func (s *organizationServer) AcceptInvitation(ctx context.Context, req *salonappv1.AcceptInvitationRequest) (*salonappv1.AcceptInvitationResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+	user := util.UserFromContext(ctx)
+	org, err := s.orgService.AcceptInvitation(ctx, user, req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidOrExpiredCode):
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidToken)
+		case errors.Is(err, services.ErrInvitationMismatch):
+			return nil, i18n.Error(codes.PermissionDenied, services.ErrInvitationMismatch)
+		case errors.Is(err, services.ErrOrganizationNotFound):
+			return nil, i18n.Error(codes.NotFound, services.ErrOrganizationNotFound)
+		default:
+			return nil, status.Error(codes.Internal, "failed to accept invitation")
+		}
+	}
+	return &salonappv1.AcceptInvitationResponse{Organization: organizationToProto(org)}, nil
+}
+
+func (s *organizationServer) ListMembers(ctx context.Context, _ *emptypb.Empty) (*salonappv1.ListMembersResponse, error) {
+	member := util.OrganizationMemberFromContext(ctx)
+	members, err := s.orgService.ListMembers(ctx, member.OrganizationID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list members")
+	}
+	protoMembers := make([]*salonappv1.OrganizationMember, len(members))
+	for i, m := range members {
+		protoMembers[i] = &salonappv1.OrganizationMember{
+			UserId:      m.UserID.String(),
+			Email:       m.Email,
+			FullName:    fromPtr(m.FullName),
+			PhoneNumber: fromPtr(m.PhoneNumber),
+			Role:        string(m.Role),
+			JoinedAt:    timestamppb.New(m.JoinedAt),
+		}
+	}
+	return &salonappv1.ListMembersResponse{Members: protoMembers}, nil
+}
+
+func (s *organizationServer) RemoveMember(ctx context.Context, req *salonappv1.RemoveMemberRequest) (*emptypb.Empty, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+	}
+	member := util.OrganizationMemberFromContext(ctx)
+	if err := s.orgService.RemoveMember(ctx, member.OrganizationID, userID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrMemberNotFound):
+			return nil, i18n.Error(codes.NotFound, services.ErrMemberNotFound)
+		case errors.Is(err, services.ErrLastOwner):
+			return nil, i18n.Error(codes.FailedPrecondition, services.ErrLastOwner)
+		default:
+			return nil, status.Error(codes.Internal, "failed to remove member")
+		}
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *organizationServer) CreateScimToken(ctx context.Context, req *salonappv1.CreateScimTokenRequest) (*salonappv1.CreateScimTokenResponse, error) {
+	member := util.OrganizationMemberFromContext(ctx)
+	token, err := s.orgService.CreateScimToken(ctx, member.OrganizationID, req.Description)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create scim token")
+	}
+	return &salonappv1.CreateScimTokenResponse{Token: token}, nil
+}
+
+func (s *organizationServer) StartWhatsAppSession(ctx context.Context, _ *emptypb.Empty) (*salonappv1.OrganizationWhatsAppSession, error) {
+	member := util.OrganizationMemberFromContext(ctx)
+	session, err := s.whatsAppService.StartOrganizationSession(ctx, member.OrganizationID)
+	if err != nil {
+		return nil, whatsAppSessionError(err, "failed to start whatsapp session")
+	}
+	return whatsAppSessionToProto(session), nil
+}
+
+func (s *organizationServer) GetWhatsAppSession(ctx context.Context, _ *emptypb.Empty) (*salonappv1.OrganizationWhatsAppSession, error) {
+	member := util.OrganizationMemberFromContext(ctx)
+	session, err := s.whatsAppService.OrganizationSession(ctx, member.OrganizationID)
+	if err != nil {
+		return nil, whatsAppSessionError(err, "failed to get whatsapp session")
+	}
+	return whatsAppSessionToProto(session), nil
+}
+
+func (s *organizationServer) GetWhatsAppQRCode(ctx context.Context, _ *emptypb.Empty) (*salonappv1.WhatsAppQRCode, error) {
+	member := util.OrganizationMemberFromContext(ctx)
+	png, err := s.whatsAppService.OrganizationQRCode(ctx, member.OrganizationID)
+	if err != nil {
+		return nil, whatsAppSessionError(err, "failed to get whatsapp qr code")
+	}
+	return &salonappv1.WhatsAppQRCode{Image: png, MimeType: "image/png"}, nil
+}
+
+func (s *organizationServer) StopWhatsAppSession(ctx context.Context, req *salonappv1.StopWhatsAppSessionRequest) (*salonappv1.OrganizationWhatsAppSession, error) {
+	member := util.OrganizationMemberFromContext(ctx)
+	if err := s.whatsAppService.StopOrganizationSession(ctx, member.OrganizationID, req.Logout); err != nil {
+		return nil, whatsAppSessionError(err, "failed to stop whatsapp session")
+	}
+	return s.GetWhatsAppSession(ctx, nil)
+}
+
+func whatsAppSessionError(err error, internal string) error {
+	switch {
+	case errors.Is(err, services.ErrWhatsAppSessionNotFound):
+		return i18n.Error(codes.NotFound, services.ErrWhatsAppSessionNotFound)
+	case errors.Is(err, services.ErrWhatsAppNotPairing):
+		return i18n.Error(codes.FailedPrecondition, services.ErrWhatsAppNotPairing)
+	case errors.Is(err, services.ErrWhatsAppUnavailable):
+		return i18n.Error(codes.Unavailable, services.ErrWhatsAppUnavailable)
+	default:
+		return status.Error(codes.Internal, internal)
+	}
+}
+
+func whatsAppSessionToProto(s *entities.WhatsAppSession) *salonappv1.OrganizationWhatsAppSession {
+	return &salonappv1.OrganizationWhatsAppSession{
+		Name:            s.Name,
+		Status:          s.Status,
+		StatusChangedAt: timestamppb.New(s.StatusChangedAt),
+		LastEventAt:     timestamppb.New(s.LastEventAt),
+	}
+}
+
+func organizationToProto(o *entities.Organization) *salonappv1.Organization {
+	return &salonappv1.Organization{
+		Id:        o.ID.String(),
+		Name:      o.Name,
+		CreatedAt: timestamppb.New(o.CreatedAt),
+	}
+}
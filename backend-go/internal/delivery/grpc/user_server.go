@@ -3,10 +3,12 @@ package grpc
 import (
 	"context"
 	"errors"
+	"strings"
 
 	salonappv1 "github.com/williamchand/fullstack-fastapi/backend-go/gen/proto/v1"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/i18n"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
 
 	"google.golang.org/grpc/codes"
@@ -31,7 +33,7 @@ func (s *userServer) GetUser(ctx context.Context, req *emptypb.Empty) (*salonapp
 	user, err := s.userService.GetUserByID(ctx, user.ID.String())
 	if err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
 		}
 		return nil, status.Error(codes.Internal, "failed to get user")
 	}
@@ -48,9 +50,42 @@ func (s *userServer) ListUsers(ctx context.Context, req *salonappv1.ListUsersReq
 		return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
 	}
 
-	users, total, err := s.userService.ListUsers(ctx, req.Offset, req.Limit)
+	filter := entities.UserFilter{
+		Search:          strings.TrimSpace(req.Search),
+		Role:            req.Role,
+		IsActive:        req.IsActive,
+		IsEmailVerified: req.IsEmailVerified,
+		IsPhoneVerified: req.IsPhoneVerified,
+		OAuthProvider:   req.OauthProvider,
+		Deleted:         req.Deleted,
+		SortBy:          entities.UserSortField(req.SortBy),
+	}
+	switch strings.ToLower(req.SortOrder) {
+	case "", "desc":
+		filter.SortDesc = true
+	case "asc":
+	default:
+		return nil, status.Error(codes.InvalidArgument, "sort_order must be asc or desc")
+	}
+	if req.CreatedAfter != nil {
+		t := req.CreatedAfter.AsTime()
+		filter.CreatedAfter = &t
+	}
+	if req.CreatedBefore != nil {
+		t := req.CreatedBefore.AsTime()
+		filter.CreatedBefore = &t
+	}
+
+	users, total, nextPageToken, err := s.userService.ListUsers(ctx, filter, req.Offset, req.Limit, req.PageToken, req.IncludeTotal)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to list users")
+		switch {
+		case errors.Is(err, services.ErrInvalidSortField):
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidSortField)
+		case errors.Is(err, services.ErrInvalidPageToken):
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidPageToken)
+		default:
+			return nil, status.Error(codes.Internal, "failed to list users")
+		}
 	}
 
 	protoUsers := make([]*salonappv1.User, len(users))
@@ -59,8 +94,9 @@ func (s *userServer) ListUsers(ctx context.Context, req *salonappv1.ListUsersReq
 	}
 
 	return &salonappv1.ListUsersResponse{
-		Users: protoUsers,
-		Total: int32(total),
+		Users:         protoUsers,
+		Total:         int32(total),
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
@@ -85,7 +121,7 @@ func (s *userServer) CreateUser(ctx context.Context, req *salonappv1.CreateUserR
 	userEntity, err := s.userService.CreateUser(ctx, req.Email, req.Password, req.FullName, roles, isActive)
 	if err != nil {
 		if errors.Is(err, services.ErrUserExists) {
-			return nil, status.Error(codes.AlreadyExists, "user already exist")
+			return nil, i18n.Error(codes.AlreadyExists, services.ErrUserExists)
 		}
 		return nil, status.Error(codes.Internal, "failed to get user")
 	}
@@ -103,7 +139,7 @@ func (s *userServer) RefreshToken(ctx context.Context, req *salonappv1.RefreshTo
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidRefreshToken):
-			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+			return nil, i18n.Error(codes.Unauthenticated, services.ErrInvalidRefreshToken)
 		default:
 			return nil, status.Error(codes.Internal, "failed to refresh token")
 		}
@@ -120,13 +156,31 @@ func (s *userServer) UpdateUser(ctx context.Context, req *salonappv1.UpdateUserR
 	user, err := s.userService.UpdateProfile(ctx, user.ID.String(), req.FullName, req.Password, req.PreviousPassword)
 	if err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
 		}
 		if errors.Is(err, services.ErrInvalidPreviousPassword) {
-			return nil, status.Error(codes.InvalidArgument, "invalid previous password")
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidPreviousPassword)
 		}
 		return nil, status.Error(codes.Internal, "failed to get user")
 	}
+	if req.PreferredChannel != nil {
+		user, err = s.userService.SetPreferredChannel(ctx, user.ID.String(), entities.MessageChannel(*req.PreferredChannel))
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidChannel) {
+				return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidChannel)
+			}
+			return nil, status.Error(codes.Internal, "failed to update preferred channel")
+		}
+	}
+	if req.Locale != nil {
+		user, err = s.userService.SetLocale(ctx, user.ID.String(), *req.Locale)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidLocale) {
+				return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidLocale)
+			}
+			return nil, status.Error(codes.Internal, "failed to update locale")
+		}
+	}
 
 	return &salonappv1.UpdateUserResponse{
 		User: s.userToProto(user),
@@ -138,10 +192,10 @@ func (s *userServer) AdminUpdateUser(ctx context.Context, req *salonappv1.AdminU
 	user, err := s.userService.AdminUpdateUser(ctx, admin.ID.String(), req.UserId, req.FullName, req.Password, req.Roles, req.IsActive)
 	if err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
 		}
 		if errors.Is(err, services.ErrUnauthorized) {
-			return nil, status.Error(codes.PermissionDenied, "unauthorized")
+			return nil, i18n.Error(codes.PermissionDenied, services.ErrUnauthorized)
 		}
 		return nil, status.Error(codes.Internal, "failed to update user")
 	}
@@ -150,6 +204,41 @@ func (s *userServer) AdminUpdateUser(ctx context.Context, req *salonappv1.AdminU
 	}, nil
 }
 
+func (s *userServer) AdminDeleteUser(ctx context.Context, req *salonappv1.AdminDeleteUserRequest) (*salonappv1.AdminDeleteUserResponse, error) {
+	admin := util.UserFromContext(ctx)
+	if err := s.userService.AdminDeleteUser(ctx, admin.ID.String(), req.UserId); err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
+		case errors.Is(err, services.ErrUnauthorized):
+			return nil, i18n.Error(codes.PermissionDenied, services.ErrUnauthorized)
+		case errors.Is(err, services.ErrCannotDeleteSelf):
+			return nil, i18n.Error(codes.FailedPrecondition, services.ErrCannotDeleteSelf)
+		default:
+			return nil, status.Error(codes.Internal, "failed to delete user")
+		}
+	}
+	return &salonappv1.AdminDeleteUserResponse{Success: true, Message: "user deleted"}, nil
+}
+
+func (s *userServer) AdminRestoreUser(ctx context.Context, req *salonappv1.AdminRestoreUserRequest) (*salonappv1.AdminRestoreUserResponse, error) {
+	admin := util.UserFromContext(ctx)
+	user, err := s.userService.AdminRestoreUser(ctx, admin.ID.String(), req.UserId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			return nil, i18n.Error(codes.NotFound, services.ErrDeletedUserNotFound)
+		case errors.Is(err, services.ErrUnauthorized):
+			return nil, i18n.Error(codes.PermissionDenied, services.ErrUnauthorized)
+		case errors.Is(err, services.ErrUserExists):
+			return nil, i18n.Error(codes.AlreadyExists, services.ErrIdentityTaken)
+		default:
+			return nil, status.Error(codes.Internal, "failed to restore user")
+		}
+	}
+	return &salonappv1.AdminRestoreUserResponse{User: s.userToProto(user)}, nil
+}
+
 func (s *userServer) AddPhoneNumber(ctx context.Context, req *salonappv1.AddPhoneNumberRequest) (*salonappv1.AddPhoneNumberResponse, error) {
 	user := util.UserFromContext(ctx)
 	if req.PhoneNumber == "" {
@@ -161,9 +250,9 @@ func (s *userServer) AddPhoneNumber(ctx context.Context, req *salonappv1.AddPhon
 	if err := s.userService.AddPhoneNumber(ctx, user.ID.String(), req.PhoneNumber, req.Region); err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidState):
-			return nil, status.Error(codes.FailedPrecondition, "email must be verified")
+			return nil, i18n.Error(codes.FailedPrecondition, services.ErrEmailNotVerified)
 		case errors.Is(err, services.ErrUserExists):
-			return nil, status.Error(codes.AlreadyExists, "phone already in use")
+			return nil, i18n.Error(codes.AlreadyExists, services.ErrPhoneInUse)
 		default:
 			return nil, status.Error(codes.Internal, "failed to add phone number")
 		}
@@ -179,11 +268,11 @@ func (s *userServer) VerifyAddPhoneOTP(ctx context.Context, req *salonappv1.Veri
 	if err := s.userService.VerifyAddPhone(ctx, user.ID.String(), req.OtpCode); err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidOrExpiredCode):
-			return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidOrExpiredCode)
 		case errors.Is(err, services.ErrUserNotFound):
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
 		case errors.Is(err, services.ErrUserExists):
-			return nil, status.Error(codes.AlreadyExists, "phone already in use")
+			return nil, i18n.Error(codes.AlreadyExists, services.ErrPhoneInUse)
 		default:
 			return nil, status.Error(codes.Internal, "failed to verify phone")
 		}
@@ -199,9 +288,9 @@ func (s *userServer) AddEmail(ctx context.Context, req *salonappv1.AddEmailReque
 	if err := s.userService.AddEmail(ctx, user.ID.String(), req.Email); err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidState):
-			return nil, status.Error(codes.FailedPrecondition, "phone must be verified")
+			return nil, i18n.Error(codes.FailedPrecondition, services.ErrPhoneNotVerified)
 		case errors.Is(err, services.ErrUserExists):
-			return nil, status.Error(codes.AlreadyExists, "email already in use")
+			return nil, i18n.Error(codes.AlreadyExists, services.ErrEmailInUse)
 		default:
 			return nil, status.Error(codes.Internal, "failed to add email")
 		}
@@ -217,11 +306,11 @@ func (s *userServer) VerifyAddEmailOTP(ctx context.Context, req *salonappv1.Veri
 	if err := s.userService.VerifyAddEmail(ctx, user.ID.String(), req.OtpCode); err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidOrExpiredCode):
-			return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidOrExpiredCode)
 		case errors.Is(err, services.ErrUserNotFound):
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
 		case errors.Is(err, services.ErrUserExists):
-			return nil, status.Error(codes.AlreadyExists, "email already in use")
+			return nil, i18n.Error(codes.AlreadyExists, services.ErrEmailInUse)
 		default:
 			return nil, status.Error(codes.Internal, "failed to verify email")
 		}
@@ -243,9 +332,9 @@ func (s *userServer) LoginUser(ctx context.Context, req *salonappv1.LoginUserReq
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidCredentials):
-			return nil, status.Error(codes.Unauthenticated, "invalid username or password")
+			return nil, i18n.Error(codes.Unauthenticated, services.ErrInvalidCredentials)
 		case errors.Is(err, services.ErrUserNotActive) || errors.Is(err, services.ErrInvalidEmailNotVerified):
-			return nil, status.Error(codes.Unauthenticated, "user is not active")
+			return nil, i18n.Error(codes.Unauthenticated, services.ErrUserNotActive)
 		default:
 			return nil, status.Error(codes.Internal, "failed to login user")
 		}
@@ -266,10 +355,14 @@ func (s *userServer) ResendEmailVerification(ctx context.Context, req *salonappv
 		return nil, status.Error(codes.InvalidArgument, "email is required")
 	}
 	if err := s.userService.SendEmailVerification(ctx, req.Email); err != nil {
-		if errors.Is(err, services.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
+		case errors.Is(err, services.ErrEmailSuppressed):
+			return nil, i18n.Error(codes.FailedPrecondition, services.ErrEmailSuppressed)
+		default:
+			return nil, status.Error(codes.Internal, "failed to send verification email")
 		}
-		return nil, status.Error(codes.Internal, "failed to send verification email")
 	}
 	return &salonappv1.ResendEmailVerificationResponse{Success: true, Message: "verification email sent"}, nil
 }
@@ -281,7 +374,7 @@ func (s *userServer) RecoverPassword(ctx context.Context, req *salonappv1.Recove
 	if err := s.userService.RequestPasswordReset(ctx, req.Email); err != nil {
 		switch {
 		case errors.Is(err, services.ErrUserNotFound):
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
 		default:
 			return nil, status.Error(codes.Internal, "failed to send recovery email")
 		}
@@ -296,11 +389,11 @@ func (s *userServer) ResetPassword(ctx context.Context, req *salonappv1.ResetPas
 	if err := s.userService.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
 		switch {
 		case errors.Is(err, services.ErrWeakPassword):
-			return nil, status.Error(codes.InvalidArgument, "Password must be at least 8 characters")
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrWeakPassword)
 		case errors.Is(err, services.ErrInvalidOrExpiredCode), errors.Is(err, services.ErrInvalidToken):
-			return nil, status.Error(codes.InvalidArgument, "Invalid token")
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidToken)
 		case errors.Is(err, services.ErrUserNotFound):
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
 		default:
 			return nil, status.Error(codes.Internal, "failed to reset password")
 		}
@@ -308,6 +401,19 @@ func (s *userServer) ResetPassword(ctx context.Context, req *salonappv1.ResetPas
 	return &salonappv1.ResetPasswordResponse{Success: true, Message: "password reset successful"}, nil
 }
 
+func (s *userServer) SecureAccount(ctx context.Context, req *salonappv1.SecureAccountRequest) (*salonappv1.SecureAccountResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+	if err := s.userService.SecureAccount(ctx, req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidOrExpiredCode) {
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidToken)
+		}
+		return nil, status.Error(codes.Internal, "failed to secure account")
+	}
+	return &salonappv1.SecureAccountResponse{Success: true, Message: "all sessions have been signed out"}, nil
+}
+
 func (s *userServer) RequestPhoneOTP(ctx context.Context, req *salonappv1.RequestPhoneOTPRequest) (*salonappv1.RequestPhoneOTPResponse, error) {
 	if req.PhoneNumber == "" {
 		return nil, status.Error(codes.InvalidArgument, "phone_number is required")
@@ -317,7 +423,7 @@ func (s *userServer) RequestPhoneOTP(ctx context.Context, req *salonappv1.Reques
 	}
 	if err := s.userService.RequestPhoneOTP(ctx, req.PhoneNumber, req.Region); err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
 		}
 		return nil, status.Error(codes.Internal, "failed to generate otp")
 	}
@@ -335,9 +441,9 @@ func (s *userServer) VerifyPhoneOTP(ctx context.Context, req *salonappv1.VerifyP
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidOrExpiredCode):
-			return nil, status.Error(codes.InvalidArgument, "invalid or expired token or otp")
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidOrExpiredCode)
 		case errors.Is(err, services.ErrUserExists):
-			return nil, status.Error(codes.AlreadyExists, "user already exists")
+			return nil, i18n.Error(codes.AlreadyExists, services.ErrUserExists)
 		default:
 			return nil, status.Error(codes.Internal, "failed to verify registration")
 		}
@@ -360,9 +466,9 @@ func (s *userServer) VerifyEmailOTP(ctx context.Context, req *salonappv1.VerifyE
 	if err := s.userService.VerifyEmailOTP(ctx, req.Email, req.OtpCode); err != nil {
 		switch {
 		case errors.Is(err, services.ErrUserNotFound):
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
 		case errors.Is(err, services.ErrInvalidOrExpiredCode):
-			return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+			return nil, i18n.Error(codes.InvalidArgument, services.ErrInvalidOrExpiredCode)
 		default:
 			return nil, status.Error(codes.Internal, "failed to verify email")
 		}
@@ -380,9 +486,9 @@ func (s *userServer) LoginWithPhone(ctx context.Context, req *salonappv1.LoginWi
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrUserNotFound):
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, i18n.Error(codes.NotFound, services.ErrUserNotFound)
 		case errors.Is(err, services.ErrInvalidOrExpiredCode):
-			return nil, status.Error(codes.Unauthenticated, "invalid or expired code")
+			return nil, i18n.Error(codes.Unauthenticated, services.ErrInvalidOrExpiredCode)
 		default:
 			return nil, status.Error(codes.Internal, "failed to login with phone")
 		}
@@ -406,7 +512,7 @@ func (s *userServer) RegisterPhoneUser(ctx context.Context, req *salonappv1.Regi
 	token, err := s.userService.RegisterPhoneUser(ctx, req.PhoneNumber, req.FullName, req.Region)
 	if err != nil {
 		if errors.Is(err, services.ErrUserExists) {
-			return nil, status.Error(codes.AlreadyExists, "user already exists")
+			return nil, i18n.Error(codes.AlreadyExists, services.ErrUserExists)
 		}
 		return nil, status.Error(codes.Internal, "failed to register user")
 	}
@@ -415,21 +521,29 @@ func (s *userServer) RegisterPhoneUser(ctx context.Context, req *salonappv1.Regi
 
 func (s *userServer) userToProto(user *entities.User) *salonappv1.User {
 	protoUser := &salonappv1.User{
-		Id:              user.ID.String(),
-		Email:           user.Email,
-		PhoneNumber:     fromPtr(user.PhoneNumber),
-		FullName:        fromPtr(user.FullName),
-		IsActive:        user.IsActive,
-		IsEmailVerified: user.IsEmailVerified,
-		IsPhoneVerified: user.IsPhoneVerified,
-		CreatedAt:       timestamppb.New(user.CreatedAt),
-		UpdatedAt:       timestamppb.New(user.UpdatedAt),
-		Roles:           user.Roles,
+		Id:               user.ID.String(),
+		Email:            user.Email,
+		PhoneNumber:      fromPtr(user.PhoneNumber),
+		FullName:         fromPtr(user.FullName),
+		IsActive:         user.IsActive,
+		IsEmailVerified:  user.IsEmailVerified,
+		IsPhoneVerified:  user.IsPhoneVerified,
+		CreatedAt:        timestamppb.New(user.CreatedAt),
+		UpdatedAt:        timestamppb.New(user.UpdatedAt),
+		Roles:            user.Roles,
+		PreferredChannel: string(user.PreferredChannel),
+		Locale:           user.Locale,
 	}
 
 	if user.PhoneNumber != nil {
 		protoUser.PhoneNumber = *user.PhoneNumber
 	}
+	if user.LastLoginAt != nil {
+		protoUser.LastLoginAt = timestamppb.New(*user.LastLoginAt)
+	}
+	if user.DeletedAt != nil {
+		protoUser.DeletedAt = timestamppb.New(*user.DeletedAt)
+	}
 
 	protoUser.Roles = user.Roles
 
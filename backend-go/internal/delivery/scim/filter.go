@@ -0,0 +1,50 @@
+package scim
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// filterPattern matches the single attribute expressions identity providers send,
+// e.g. userName eq "jane@example.com". Logical operators are not supported.
+var filterPattern = regexp.MustCompile(`(?i)^\s*([a-z][\w.]*)\s+(eq|ne|co|sw|ew)\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+type filter struct {
+	attr  string
+	op    string
+	value string
+}
+
+func parseFilter(expr string) (*filter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	m := filterPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf("unsupported filter %q", expr)
+	}
+	return &filter{
+		attr:  strings.ToLower(m[1]),
+		op:    strings.ToLower(m[2]),
+		value: strings.ReplaceAll(m[3], `\"`, `"`),
+	}, nil
+}
+
+// match compares case-insensitively; none of the supported attributes are caseExact
+func (f *filter) match(v string) bool {
+	v, want := strings.ToLower(v), strings.ToLower(f.value)
+	switch f.op {
+	case "eq":
+		return v == want
+	case "ne":
+		return v != want
+	case "co":
+		return strings.Contains(v, want)
+	case "sw":
+		return strings.HasPrefix(v, want)
+	case "ew":
+		return strings.HasSuffix(v, want)
+	}
+	return false
+}
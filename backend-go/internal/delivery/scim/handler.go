@@ -0,0 +1,613 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+)
+
+// BasePath is where the SCIM API is mounted on the HTTP mux
+const BasePath = "/scim/v2"
+
+const maxPageSize = 200
+
+type orgContextKey struct{}
+
+// Handler serves SCIM 2.0 Users and Groups for the organization owning the bearer token
+type Handler struct {
+	svc *services.ScimService
+	mux *http.ServeMux
+}
+
+func NewHandler(svc *services.ScimService) http.Handler {
+	h := &Handler{svc: svc, mux: http.NewServeMux()}
+
+	h.mux.HandleFunc("GET "+BasePath+"/ServiceProviderConfig", h.serviceProviderConfig)
+
+	h.mux.HandleFunc("GET "+BasePath+"/Users", h.listUsers)
+	h.mux.HandleFunc("POST "+BasePath+"/Users", h.createUser)
+	h.mux.HandleFunc("GET "+BasePath+"/Users/{id}", h.getUser)
+	h.mux.HandleFunc("PUT "+BasePath+"/Users/{id}", h.replaceUser)
+	h.mux.HandleFunc("PATCH "+BasePath+"/Users/{id}", h.patchUser)
+	h.mux.HandleFunc("DELETE "+BasePath+"/Users/{id}", h.deleteUser)
+
+	h.mux.HandleFunc("GET "+BasePath+"/Groups", h.listGroups)
+	h.mux.HandleFunc("POST "+BasePath+"/Groups", h.createGroup)
+	h.mux.HandleFunc("GET "+BasePath+"/Groups/{id}", h.getGroup)
+	h.mux.HandleFunc("PUT "+BasePath+"/Groups/{id}", h.replaceGroup)
+	h.mux.HandleFunc("PATCH "+BasePath+"/Groups/{id}", h.patchGroup)
+	h.mux.HandleFunc("DELETE "+BasePath+"/Groups/{id}", h.deleteGroup)
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := ""
+	if parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2); len(parts) == 2 && strings.EqualFold(parts[0], "bearer") {
+		token = parts[1]
+	}
+	orgID, err := h.svc.Authenticate(r.Context(), token)
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidToken) {
+			log.Println(err)
+		}
+		writeError(w, http.StatusUnauthorized, "", "invalid bearer token")
+		return
+	}
+	ctx := context.WithValue(r.Context(), orgContextKey{}, orgID)
+	h.mux.ServeHTTP(w, r.WithContext(ctx))
+}
+
+func orgIDFromContext(ctx context.Context) uuid.UUID {
+	id, _ := ctx.Value(orgContextKey{}).(uuid.UUID)
+	return id
+}
+
+func (h *Handler) serviceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"schemas":               []string{schemaServiceProviderConfig},
+		"patch":                 map[string]bool{"supported": true},
+		"bulk":                  map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":                map[string]any{"supported": true, "maxResults": maxPageSize},
+		"changePassword":        map[string]bool{"supported": false},
+		"sort":                  map[string]bool{"supported": false},
+		"etag":                  map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]string{{"type": "oauthbearertoken", "name": "OAuth Bearer Token", "description": "Per-organization SCIM token"}},
+	})
+}
+
+// ---------- Users ----------
+
+func (h *Handler) listUsers(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+	start, count := pagination(r)
+	var filter entities.DirectoryUserFilter
+	if f != nil {
+		attr, ok := userFilterAttributes[f.attr]
+		if !ok {
+			// No user has a value for an attribute that is not exposed
+			writePage(w, start, 0, []any{})
+			return
+		}
+		filter = entities.DirectoryUserFilter{Attribute: attr, Operator: f.op, Value: f.value}
+	}
+	users, total, err := h.svc.ListUsers(r.Context(), orgIDFromContext(r.Context()), filter, int32(start-1), int32(count))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resources := make([]any, 0, len(users))
+	for _, u := range users {
+		resources = append(resources, toUser(r, u))
+	}
+	writePage(w, start, total, resources)
+}
+
+// userFilterAttributes maps the user attributes a filter may name to what they expose
+var userFilterAttributes = map[string]entities.DirectoryUserAttribute{
+	"username":       entities.DirectoryUserEmail,
+	"emails.value":   entities.DirectoryUserEmail,
+	"emails":         entities.DirectoryUserEmail,
+	"id":             entities.DirectoryUserID,
+	"displayname":    entities.DirectoryUserFullName,
+	"name.formatted": entities.DirectoryUserFullName,
+}
+
+func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.svc.GetUser(r.Context(), orgIDFromContext(r.Context()), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUser(r, u))
+}
+
+func (h *Handler) createUser(w http.ResponseWriter, r *http.Request) {
+	var in user
+	if !decode(w, r, &in) {
+		return
+	}
+	if in.email() == "" {
+		writeError(w, http.StatusBadRequest, "invalidValue", "userName is required")
+		return
+	}
+	u, err := h.svc.CreateUser(r.Context(), orgIDFromContext(r.Context()), in.email(), in.fullName(), in.Active == nil || *in.Active)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toUser(r, u))
+}
+
+func (h *Handler) replaceUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in user
+	if !decode(w, r, &in) {
+		return
+	}
+	u, err := h.svc.ReplaceUser(r.Context(), orgIDFromContext(r.Context()), id, in.email(), in.fullName(), in.Active == nil || *in.Active)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUser(r, u))
+}
+
+func (h *Handler) patchUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in patchRequest
+	if !decode(w, r, &in) {
+		return
+	}
+	orgID := orgIDFromContext(r.Context())
+	current, err := h.svc.GetUser(r.Context(), orgID, id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Apply the operations to the current attributes, then store them as a replace
+	email := current.User.Email
+	fullName := ""
+	if current.User.FullName != nil {
+		fullName = *current.User.FullName
+	}
+	active := current.User.IsActive
+	for _, op := range in.Operations {
+		if !strings.EqualFold(op.Op, "replace") && !strings.EqualFold(op.Op, "add") {
+			writeError(w, http.StatusBadRequest, "invalidValue", fmt.Sprintf("unsupported operation %q", op.Op))
+			return
+		}
+		values := map[string]json.RawMessage{}
+		if op.Path == "" {
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				writeError(w, http.StatusBadRequest, "invalidValue", "value must be an object when path is omitted")
+				return
+			}
+		} else {
+			values[op.Path] = op.Value
+		}
+		for path, value := range values {
+			switch strings.ToLower(path) {
+			case "active":
+				v, ok := boolValue(value)
+				if !ok {
+					writeError(w, http.StatusBadRequest, "invalidValue", "active must be a boolean")
+					return
+				}
+				active = v
+			case "username", `emails[type eq "work"].value`:
+				if v, ok := stringValue(value); ok {
+					email = v
+				}
+			case "displayname", "name.formatted":
+				if v, ok := stringValue(value); ok {
+					fullName = v
+				}
+			case "name":
+				var n name
+				if err := json.Unmarshal(value, &n); err == nil && n.fullName() != "" {
+					fullName = n.fullName()
+				}
+			}
+			// Attributes we do not store (externalId, title, ...) are accepted and ignored
+		}
+	}
+
+	u, err := h.svc.ReplaceUser(r.Context(), orgID, id, email, fullName, active)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUser(r, u))
+}
+
+func (h *Handler) deleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteUser(r.Context(), orgIDFromContext(r.Context()), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ---------- Groups ----------
+
+// Groups are the organization roles; the role name is both id and displayName
+
+func (h *Handler) listGroups(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+	members, err := h.svc.ListMembers(r.Context(), orgIDFromContext(r.Context()))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	resources := make([]any, 0, len(services.ScimGroups))
+	for _, role := range services.ScimGroups {
+		if f != nil {
+			switch f.attr {
+			case "displayname", "id":
+				if !f.match(string(role)) {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		resources = append(resources, toGroup(r, role, members))
+	}
+	writeList(w, r, resources)
+}
+
+func (h *Handler) getGroup(w http.ResponseWriter, r *http.Request) {
+	role, ok := groupRole(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	members, err := h.svc.ListMembers(r.Context(), orgIDFromContext(r.Context()))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toGroup(r, role, members))
+}
+
+// createGroup links a directory group to the role of the same name; arbitrary groups cannot be created
+func (h *Handler) createGroup(w http.ResponseWriter, r *http.Request) {
+	var in group
+	if !decode(w, r, &in) {
+		return
+	}
+	role := entities.OrganizationRole(strings.ToLower(in.DisplayName))
+	if !services.IsScimGroup(role) {
+		writeError(w, http.StatusBadRequest, "invalidValue", fmt.Sprintf("displayName must be one of %v", services.ScimGroups))
+		return
+	}
+	if !h.setGroupMembers(w, r, role, in.Members) {
+		return
+	}
+	h.writeGroup(w, r, http.StatusCreated, role)
+}
+
+func (h *Handler) replaceGroup(w http.ResponseWriter, r *http.Request) {
+	role, ok := groupRole(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	var in group
+	if !decode(w, r, &in) {
+		return
+	}
+	if !h.setGroupMembers(w, r, role, in.Members) {
+		return
+	}
+	h.writeGroup(w, r, http.StatusOK, role)
+}
+
+func (h *Handler) patchGroup(w http.ResponseWriter, r *http.Request) {
+	role, ok := groupRole(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	var in patchRequest
+	if !decode(w, r, &in) {
+		return
+	}
+	orgID := orgIDFromContext(r.Context())
+	for _, op := range in.Operations {
+		path := strings.ToLower(op.Path)
+		switch {
+		case strings.EqualFold(op.Op, "replace") && path == "members":
+			var members []multiValue
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				writeError(w, http.StatusBadRequest, "invalidValue", "members must be an array")
+				return
+			}
+			if !h.setGroupMembers(w, r, role, members) {
+				return
+			}
+		case strings.EqualFold(op.Op, "add") && path == "members":
+			var members []multiValue
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				writeError(w, http.StatusBadRequest, "invalidValue", "members must be an array")
+				return
+			}
+			for _, m := range members {
+				if !h.setGroupMember(w, r, orgID, role, m.Value, true) {
+					return
+				}
+			}
+		case strings.EqualFold(op.Op, "remove") && strings.HasPrefix(path, "members"):
+			// Either members[value eq "id"] or members with the ids in value
+			ids := []string{}
+			if f, err := parseFilter(strings.TrimSuffix(strings.TrimPrefix(op.Path[len("members"):], "["), "]")); err == nil && f != nil {
+				ids = append(ids, f.value)
+			} else {
+				var members []multiValue
+				if err := json.Unmarshal(op.Value, &members); err != nil {
+					writeError(w, http.StatusBadRequest, "invalidValue", "members must be an array")
+					return
+				}
+				for _, m := range members {
+					ids = append(ids, m.Value)
+				}
+			}
+			for _, id := range ids {
+				if !h.setGroupMember(w, r, orgID, role, id, false) {
+					return
+				}
+			}
+		case path == "displayname":
+			// Group names are fixed role names
+		default:
+			writeError(w, http.StatusBadRequest, "invalidPath", fmt.Sprintf("unsupported operation %q on %q", op.Op, op.Path))
+			return
+		}
+	}
+	h.writeGroup(w, r, http.StatusOK, role)
+}
+
+// deleteGroup empties the group, which turns its owners into employees; the role itself remains
+func (h *Handler) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	role, ok := groupRole(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if !h.setGroupMembers(w, r, role, nil) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setGroupMembers makes members the exact membership of the group
+func (h *Handler) setGroupMembers(w http.ResponseWriter, r *http.Request, role entities.OrganizationRole, members []multiValue) bool {
+	orgID := orgIDFromContext(r.Context())
+	current, err := h.svc.ListMembers(r.Context(), orgID)
+	if err != nil {
+		writeServiceError(w, err)
+		return false
+	}
+	want := map[string]bool{}
+	for _, m := range members {
+		want[m.Value] = true
+	}
+	// Add before removing so an owner swap never drops below one owner
+	for id := range want {
+		if !h.setGroupMember(w, r, orgID, role, id, true) {
+			return false
+		}
+	}
+	for _, m := range current {
+		if m.Role == role && !want[m.UserID.String()] {
+			if !h.setGroupMember(w, r, orgID, role, m.UserID.String(), false) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (h *Handler) setGroupMember(w http.ResponseWriter, r *http.Request, orgID uuid.UUID, role entities.OrganizationRole, rawID string, add bool) bool {
+	userID, err := uuid.Parse(rawID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidValue", fmt.Sprintf("invalid member %q", rawID))
+		return false
+	}
+	if err := h.svc.SetGroupMember(r.Context(), orgID, role, userID, add); err != nil {
+		writeServiceError(w, err)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) writeGroup(w http.ResponseWriter, r *http.Request, status int, role entities.OrganizationRole) {
+	members, err := h.svc.ListMembers(r.Context(), orgIDFromContext(r.Context()))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, status, toGroup(r, role, members))
+}
+
+func groupRole(w http.ResponseWriter, id string) (entities.OrganizationRole, bool) {
+	role := entities.OrganizationRole(id)
+	if !services.IsScimGroup(role) {
+		writeError(w, http.StatusNotFound, "", "group not found")
+		return "", false
+	}
+	return role, true
+}
+
+// ---------- helpers ----------
+
+func toUser(r *http.Request, d *entities.DirectoryUser) *user {
+	u := d.User
+	active := u.IsActive
+	out := &user{
+		Schemas:  []string{schemaUser},
+		ID:       u.ID.String(),
+		UserName: u.Email,
+		Emails:   []multiValue{{Value: u.Email, Type: "work", Primary: true}},
+		Active:   &active,
+		Meta: &meta{
+			ResourceType: "User",
+			Created:      u.CreatedAt.UTC().Format(time.RFC3339),
+			LastModified: u.UpdatedAt.UTC().Format(time.RFC3339),
+			Location:     location(r, "Users", u.ID.String()),
+		},
+	}
+	if u.FullName != nil {
+		out.Name = &name{Formatted: *u.FullName}
+		out.DisplayName = *u.FullName
+	}
+	if u.PhoneNumber != nil {
+		out.PhoneNumbers = []multiValue{{Value: *u.PhoneNumber, Type: "mobile"}}
+	}
+	for _, role := range services.ScimGroups {
+		if d.HasGroup(role) {
+			out.Groups = append(out.Groups, multiValue{Value: string(role), Display: string(role), Ref: location(r, "Groups", string(role))})
+		}
+	}
+	return out
+}
+
+func toGroup(r *http.Request, role entities.OrganizationRole, members []*entities.OrganizationMember) *group {
+	g := &group{
+		Schemas:     []string{schemaGroup},
+		ID:          string(role),
+		DisplayName: string(role),
+		Meta:        &meta{ResourceType: "Group", Location: location(r, "Groups", string(role))},
+	}
+	for _, m := range members {
+		if m.Role == role {
+			g.Members = append(g.Members, multiValue{Value: m.UserID.String(), Display: m.Email, Ref: location(r, "Users", m.UserID.String())})
+		}
+	}
+	return g
+}
+
+func location(r *http.Request, resource, id string) string {
+	scheme := "https"
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	} else if r.TLS == nil {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s%s/%s/%s", scheme, r.Host, BasePath, resource, id)
+}
+
+// pagination reads the 1-based startIndex and the count from the query string
+func pagination(r *http.Request) (start, count int) {
+	start, _ = strconv.Atoi(r.URL.Query().Get("startIndex"))
+	start = min(max(start, 1), math.MaxInt32)
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count > maxPageSize {
+		count = maxPageSize
+	}
+	return start, max(count, 0)
+}
+
+// writeList applies the pagination from the query string to resources
+func writeList(w http.ResponseWriter, r *http.Request, resources []any) {
+	start, count := pagination(r)
+	total := len(resources)
+	from := min(start-1, total)
+	to := min(from+count, total)
+	writePage(w, start, total, resources[from:to])
+}
+
+// writePage writes the page of resources at start, out of total
+func writePage(w http.ResponseWriter, start, total int, resources []any) {
+	writeJSON(w, http.StatusOK, listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "", "resource not found")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalidSyntax", "request body is not valid JSON")
+		return false
+	}
+	return true
+}
+
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		writeError(w, http.StatusNotFound, "", "resource not found")
+	case errors.Is(err, services.ErrUserExists):
+		writeError(w, http.StatusConflict, "uniqueness", "userName is already in use")
+	case errors.Is(err, services.ErrUserInvited):
+		writeError(w, http.StatusConflict, "uniqueness", "userName belongs to an existing account, which was invited to join the organization")
+	case errors.Is(err, services.ErrAccountNotManaged):
+		writeError(w, http.StatusBadRequest, "mutability", "the account was not created by this organization, so its email and active state cannot be changed")
+	case errors.Is(err, services.ErrLastOwner):
+		writeError(w, http.StatusBadRequest, "mutability", "organization must keep at least one owner")
+	case errors.Is(err, services.ErrInvalidRole):
+		writeError(w, http.StatusNotFound, "", "group not found")
+	default:
+		log.Println(fmt.Errorf("scim request failed: %w", err))
+		writeError(w, http.StatusInternalServerError, "", "internal error")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, scimType, detail string) {
+	writeJSON(w, status, errorResponse{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
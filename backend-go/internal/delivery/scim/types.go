@@ -0,0 +1,142 @@
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+type name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// fullName prefers the formatted name and falls back to the name parts
+func (n *name) fullName() string {
+	if n == nil {
+		return ""
+	}
+	if n.Formatted != "" {
+		return n.Formatted
+	}
+	return strings.TrimSpace(n.GivenName + " " + n.FamilyName)
+}
+
+type multiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+type user struct {
+	Schemas      []string     `json:"schemas"`
+	ID           string       `json:"id,omitempty"`
+	ExternalID   string       `json:"externalId,omitempty"`
+	UserName     string       `json:"userName"`
+	Name         *name        `json:"name,omitempty"`
+	DisplayName  string       `json:"displayName,omitempty"`
+	Emails       []multiValue `json:"emails,omitempty"`
+	PhoneNumbers []multiValue `json:"phoneNumbers,omitempty"`
+	Active       *bool        `json:"active,omitempty"`
+	Groups       []multiValue `json:"groups,omitempty"`
+	Meta         *meta        `json:"meta,omitempty"`
+}
+
+// email returns userName, or the primary email when userName is not an address
+func (u *user) email() string {
+	if strings.Contains(u.UserName, "@") {
+		return u.UserName
+	}
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return u.UserName
+}
+
+func (u *user) fullName() string {
+	if n := u.Name.fullName(); n != "" {
+		return n
+	}
+	return u.DisplayName
+}
+
+type group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []multiValue `json:"members,omitempty"`
+	Meta        *meta        `json:"meta,omitempty"`
+}
+
+type listResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type errorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// boolValue accepts JSON booleans and the "True"/"False" strings some providers send
+func boolValue(raw json.RawMessage) (bool, bool) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, true
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if v, err := strconv.ParseBool(strings.ToLower(s)); err == nil {
+			return v, true
+		}
+	}
+	return false, false
+}
+
+func stringValue(raw json.RawMessage) (string, bool) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+)
+
+// Webhook URLs to configure at the payment providers
+const (
+	StripePath = "/v1/billing/webhook/stripe"
+	DokuPath   = "/v1/billing/webhook/doku"
+)
+
+// BillingHandler takes the webhooks of one payment provider. The signature covers the exact body, so
+// it cannot go through gRPC-Gateway, and it replaces user auth, so the handler is mounted outside the
+// auth middleware.
+type BillingHandler struct {
+	billingService *services.BillingService
+	provider       entities.PaymentProvider
+}
+
+func NewBillingHandler(billingService *services.BillingService, provider entities.PaymentProvider) http.Handler {
+	return &BillingHandler{billingService: billingService, provider: provider}
+}
+
+func (h *BillingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, ok := readWebhookBody(w, r)
+	if !ok {
+		return
+	}
+	err := h.billingService.HandleWebhook(r.Context(), h.provider, entities.WebhookRequest{
+		Payload: body,
+		Header:  r.Header,
+		Path:    r.URL.Path,
+	})
+	writeBillingWebhookResult(w, err)
+}
+
+func readWebhookBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return nil, false
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return nil, false
+	}
+	return body, true
+}
+
+// writeBillingWebhookResult answers 2xx only once the event is processed; anything else makes the provider retry
+func writeBillingWebhookResult(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusOK)
+	case errors.Is(err, services.ErrInvalidWebhook):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Println(err)
+		writeError(w, http.StatusInternalServerError, "failed to process webhook")
+	}
+}
@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+)
+
+// BouncePath is where email providers report bounces and complaints
+const BouncePath = "/v1/webhooks/email-bounces"
+
+// SecretHeader carries the shared secret configured at the provider
+const SecretHeader = "X-Webhook-Secret"
+
+const maxBodyBytes = 1 << 20
+
+// bounceEvent is the generic report format; providers with their own format are mapped to it
+// by a small relay or a payload template at the provider.
+type bounceEvent struct {
+	Type       string `json:"type"` // bounce or complaint
+	Email      string `json:"email"`
+	BounceType string `json:"bounce_type"` // hard or permanent, soft or transient; hard when empty
+	Detail     string `json:"detail"`
+}
+
+// BounceHandler accepts a single report or an array of them. It authenticates with a shared
+// secret instead of a user JWT, so it is mounted outside the auth middleware.
+type BounceHandler struct {
+	suppressionService *services.EmailSuppressionService
+	secret             string
+}
+
+func NewBounceHandler(suppressionService *services.EmailSuppressionService, secret string) http.Handler {
+	return &BounceHandler{suppressionService: suppressionService, secret: secret}
+}
+
+func (h *BounceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(SecretHeader)), []byte(h.secret)) != 1 {
+		writeError(w, http.StatusUnauthorized, "invalid webhook secret")
+		return
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(http.MaxBytesReader(w, r.Body, maxBodyBytes)); err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	var payload []bounceEvent
+	body := bytes.TrimSpace(buf.Bytes())
+	if bytes.HasPrefix(body, []byte("[")) {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+	} else {
+		var single bounceEvent
+		if err := json.Unmarshal(body, &single); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+		payload = []bounceEvent{single}
+	}
+
+	events := make([]entities.BounceEvent, 0, len(payload))
+	for _, e := range payload {
+		bounceType, ok := parseBounceType(e.Type, e.BounceType)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "type must be bounce or complaint")
+			return
+		}
+		events = append(events, entities.BounceEvent{
+			Email:  e.Email,
+			Type:   bounceType,
+			Detail: e.Detail,
+			Source: "webhook",
+		})
+	}
+
+	suppressed, err := h.suppressionService.RecordBounces(r.Context(), events)
+	if err != nil {
+		log.Println(err)
+		writeError(w, http.StatusInternalServerError, "failed to record bounces")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"received": len(events), "suppressed": suppressed})
+}
+
+func parseBounceType(eventType, bounceType string) (entities.BounceType, bool) {
+	switch strings.ToLower(eventType) {
+	case "complaint":
+		return entities.BounceComplaint, true
+	case "bounce":
+		switch strings.ToLower(bounceType) {
+		case "", "hard", "permanent":
+			return entities.BounceHard, true
+		case "soft", "transient":
+			return entities.BounceSoft, true
+		}
+	}
+	return "", false
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
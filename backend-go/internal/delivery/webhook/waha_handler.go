@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/waha"
+)
+
+// WahaPath is the webhook URL to configure in WAHA
+const WahaPath = "/v1/webhooks/waha"
+
+// WAHA signs each request body with the webhook's hmac key
+const (
+	wahaHMACHeader          = "X-Webhook-Hmac"
+	wahaHMACAlgorithmHeader = "X-Webhook-Hmac-Algorithm"
+)
+
+type wahaEvent struct {
+	Event   string          `json:"event"`
+	Session string          `json:"session"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type wahaMessage struct {
+	ID          string `json:"id"`
+	Timestamp   int64  `json:"timestamp"`
+	From        string `json:"from"`
+	FromMe      bool   `json:"fromMe"`
+	Participant string `json:"participant"`
+	Body        string `json:"body"`
+	HasMedia    bool   `json:"hasMedia"`
+	ReplyTo     *struct {
+		ID string `json:"id"`
+	} `json:"replyTo"`
+}
+
+type wahaAck struct {
+	ID      string `json:"id"`
+	Ack     int    `json:"ack"`
+	AckName string `json:"ackName"`
+}
+
+type wahaSessionStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// WahaHandler takes the message, message.ack and session.status events of WAHA.
+// Requests are authenticated by their HMAC signature, so it is mounted outside the auth middleware.
+type WahaHandler struct {
+	whatsAppService *services.WhatsAppService
+	hmacKey         []byte
+}
+
+func NewWahaHandler(whatsAppService *services.WhatsAppService, hmacKey string) http.Handler {
+	return &WahaHandler{whatsAppService: whatsAppService, hmacKey: []byte(hmacKey)}
+}
+
+func (h *WahaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	if !h.validSignature(r, body) {
+		writeError(w, http.StatusUnauthorized, "invalid webhook signature")
+		return
+	}
+	var event wahaEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	// Events of every salon's session arrive here, so they are handled in the system scope
+	ctx := util.WithSystemScope(r.Context())
+	switch event.Event {
+	case "message":
+		var m wahaMessage
+		if err := json.Unmarshal(event.Payload, &m); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid message payload")
+			return
+		}
+		if m.FromMe {
+			break
+		}
+		// Subscribers handle their own failures; a retry would run every one of them again
+		if err := h.whatsAppService.ReceiveMessage(ctx, inboundMessage(event.Session, m)); err != nil {
+			log.Println(err)
+		}
+	case "message.ack":
+		var a wahaAck
+		if err := json.Unmarshal(event.Payload, &a); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid ack payload")
+			return
+		}
+		// SERVER (1) only means the message left WAHA and is not recorded
+		ack := waha.AckFromLevel(a.Ack)
+		if ack == "" {
+			break
+		}
+		if err := h.whatsAppService.RecordAck(ctx, event.Session, waha.MessageKey(a.ID), ack, "whatsapp ack "+a.AckName); err != nil {
+			log.Println(err)
+			writeError(w, http.StatusInternalServerError, "failed to record ack")
+			return
+		}
+	case "session.status":
+		var st wahaSessionStatus
+		if err := json.Unmarshal(event.Payload, &st); err != nil || st.Status == "" {
+			writeError(w, http.StatusBadRequest, "invalid session status payload")
+			return
+		}
+		session := event.Session
+		if session == "" {
+			session = st.Name
+		}
+		if err := h.whatsAppService.SetSessionStatus(ctx, session, st.Status); err != nil {
+			log.Println(err)
+			writeError(w, http.StatusInternalServerError, "failed to record session status")
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WahaHandler) validSignature(r *http.Request, body []byte) bool {
+	if alg := r.Header.Get(wahaHMACAlgorithmHeader); alg != "" && alg != "sha512" {
+		return false
+	}
+	signature, err := hex.DecodeString(r.Header.Get(wahaHMACHeader))
+	if err != nil || len(signature) == 0 {
+		return false
+	}
+	mac := hmac.New(sha512.New, h.hmacKey)
+	mac.Write(body)
+	return hmac.Equal(signature, mac.Sum(nil))
+}
+
+func inboundMessage(session string, m wahaMessage) entities.WhatsAppMessageReceived {
+	sender := m.From
+	if m.Participant != "" {
+		sender = m.Participant
+	}
+	msg := entities.WhatsAppMessageReceived{
+		Session:    session,
+		MessageID:  waha.MessageKey(m.ID),
+		ChatID:     m.From,
+		From:       waha.PhoneFromChatID(sender),
+		Body:       m.Body,
+		HasMedia:   m.HasMedia,
+		ReceivedAt: time.Now(),
+	}
+	if m.Timestamp > 0 {
+		msg.ReceivedAt = time.Unix(m.Timestamp, 0)
+	}
+	if m.ReplyTo != nil {
+		msg.ReplyToID = waha.MessageKey(m.ReplyTo.ID)
+	}
+	return msg
+}
@@ -0,0 +1,82 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CampaignStatus string
+
+const (
+	// CampaignScheduled campaigns run at NextRunAt
+	CampaignScheduled CampaignStatus = "scheduled"
+	// CampaignCompleted campaigns have no runs left; messages of the last one may still be queued
+	CampaignCompleted CampaignStatus = "completed"
+	// CampaignPaused campaigns neither run nor send their queued messages until resumed
+	CampaignPaused    CampaignStatus = "paused"
+	CampaignCancelled CampaignStatus = "cancelled"
+)
+
+// CampaignChannels are the channels a campaign can be sent over
+var CampaignChannels = []MessageChannel{MessageChannelEmail, MessageChannelWhatsApp, MessageChannelSMS}
+
+// Campaign sends a template to an audience once at SendAt, or on every run of CronExpression.
+// It belongs to a salon, or, without OrganizationID, to the user who created it.
+type Campaign struct {
+	ID             uuid.UUID
+	OrganizationID *uuid.UUID
+	CreatedBy      *uuid.UUID
+	Name           string
+	Channel        MessageChannel
+	TemplateName   EmailTemplateEnum
+	// Category is reminder or marketing, so recipients' preferences apply
+	Category NotificationCategory
+	Audience CampaignAudience
+	// Fields fill the template; name is set to each recipient's name when it is known, and link
+	// and code are reserved for account messages
+	Fields         map[string]string
+	SendAt         *time.Time
+	CronExpression string
+	// Timezone is the IANA zone CronExpression is read in
+	Timezone string
+	// RatePerMinute spreads the messages of a run over time; campaigns created before it was
+	// required may have zero, which sends them all at once
+	RatePerMinute int
+	Status        CampaignStatus
+	NextRunAt     *time.Time
+	LastRunAt     *time.Time
+	Runs          int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// CampaignAudience is who a run is sent to; the parts are combined and each address gets one message
+type CampaignAudience struct {
+	// MemberRoles selects members of the campaign's salon with these roles
+	MemberRoles []OrganizationRole `json:"member_roles,omitempty"`
+	// Users selects active platform users; only superusers may send to them
+	Users *CampaignUserQuery `json:"users,omitempty"`
+	// Recipients are email addresses or phone numbers of the salon's members; superusers may list any
+	Recipients []string `json:"recipients,omitempty"`
+	// Region is the country phone numbers without a country code are read in, such as ID
+	Region string `json:"region,omitempty"`
+}
+
+// CampaignUserQuery matches users like the admin user list does
+type CampaignUserQuery struct {
+	Search          string `json:"search,omitempty"`
+	Role            string `json:"role,omitempty"`
+	IsEmailVerified *bool  `json:"is_email_verified,omitempty"`
+	IsPhoneVerified *bool  `json:"is_phone_verified,omitempty"`
+}
+
+// CampaignStats counts the outbox messages of every run of a campaign
+type CampaignStats struct {
+	Total     int
+	Queued    int // pending or being sent
+	Sent      int
+	Failed    int // dead, including those of a cancelled campaign and recipients who opted out
+	Delivered int
+	Read      int
+}
@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientInfo describes the client that issued the current request
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+	Locale    string // most preferred language of the Accept-Language header, empty when not sent
+}
+
+type UserDevice struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Fingerprint string
+	UserAgent   string
+	IPPrefix    string
+	Country     string
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+}
@@ -0,0 +1,38 @@
+package entities
+
+import "time"
+
+type SuppressionReason string
+
+const (
+	SuppressionReasonBounce    SuppressionReason = "bounce"
+	SuppressionReasonComplaint SuppressionReason = "complaint"
+)
+
+// EmailSuppression is an address no email is sent to until an admin lifts it
+type EmailSuppression struct {
+	Email     string // lowercased
+	Reason    SuppressionReason
+	Detail    string // diagnostic from the report, such as the SMTP status
+	Source    string // webhook or mailbox
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type BounceType string
+
+const (
+	// BounceHard is a permanent failure such as an unknown mailbox
+	BounceHard BounceType = "hard"
+	// BounceSoft is a temporary failure such as a full mailbox; the outbox retries those
+	BounceSoft      BounceType = "soft"
+	BounceComplaint BounceType = "complaint"
+)
+
+// BounceEvent is a bounce or spam complaint reported for one recipient
+type BounceEvent struct {
+	Email  string
+	Type   BounceType
+	Detail string
+	Source string
+}
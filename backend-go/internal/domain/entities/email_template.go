@@ -1,25 +1,51 @@
 package entities
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// DefaultLocale is used when neither the user nor the request names a language with a template variant
+const DefaultLocale = "en"
+
 type EmailTemplateEnum string
 
 const (
 	EmailTemplateVerificationEmail EmailTemplateEnum = "verification_email"
 	EmailTemplateVerificationPhone EmailTemplateEnum = "verification_phone"
 	EmailTemplatePasswordReset     EmailTemplateEnum = "password_reset"
+	EmailTemplateNewDeviceLogin    EmailTemplateEnum = "new_device_login"
+	EmailTemplateNewDeviceLoginWA  EmailTemplateEnum = "new_device_login_phone"
+	EmailTemplateOrgInvitation     EmailTemplateEnum = "organization_invitation"
+	EmailTemplateOrgInvitationWA   EmailTemplateEnum = "organization_invitation_phone"
+	EmailTemplateWelcomeUser       EmailTemplateEnum = "welcome_user"
+	EmailTemplateWelcomeUserWA     EmailTemplateEnum = "welcome_user_phone"
+	EmailTemplateWeddingInviteWA   EmailTemplateEnum = "wedding_invitation_phone"
+	EmailTemplateBookingConfirmWA  EmailTemplateEnum = "booking_confirmation_phone"
 )
 
+// IsPhone reports whether the template is sent as a text message to a phone number
+func (e EmailTemplateEnum) IsPhone() bool {
+	return e == EmailTemplateVerificationPhone || strings.HasSuffix(string(e), "_phone")
+}
+
 type EmailTemplate struct {
 	ID        uuid.UUID
 	Name      EmailTemplateEnum
+	Locale    string
+	Version   int
 	Subject   string
 	Body      string
 	IsActive  bool
+	CreatedBy *uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// WhatsApp holds the media, location and buttons of phone templates, filled in like the body
+	WhatsApp *WhatsAppContent
+	// Campaign marks templates written for campaigns, the only ones a campaign may send
+	Campaign bool
+	// LatestVersion is only set when listing templates
+	LatestVersion int
 }
@@ -0,0 +1,84 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is something that happened which other modules may react to, see services.EventBus
+type Event interface {
+	EventName() string
+}
+
+// WhatsAppMessageReceived is a message someone sent to one of our WhatsApp sessions
+type WhatsAppMessageReceived struct {
+	Session   string
+	MessageID string
+	ChatID    string
+	// From is the sender's E.164 phone number; empty when WhatsApp hides it
+	From string
+	Body string
+	// ReplyToID is the id of the message this one quotes, empty when it quotes none
+	ReplyToID  string
+	HasMedia   bool
+	ReceivedAt time.Time
+}
+
+func (WhatsAppMessageReceived) EventName() string { return "whatsapp.message.received" }
+
+// WhatsAppSessionStatusChanged is reported when a session starts, stops or needs its QR code scanned again
+type WhatsAppSessionStatusChanged struct {
+	Session        string
+	Status         string
+	PreviousStatus string
+}
+
+func (WhatsAppSessionStatusChanged) EventName() string { return "whatsapp.session.status_changed" }
+
+// PaymentStatusChanged is published when a provider reports a payment as paid or failed
+type PaymentStatusChanged struct {
+	PaymentID      uuid.UUID
+	UserID         uuid.UUID
+	Provider       PaymentProvider
+	TransactionID  string
+	Amount         float64
+	Currency       string
+	Status         PaymentStatus
+	PreviousStatus PaymentStatus
+}
+
+func (PaymentStatusChanged) EventName() string { return "billing.payment.status_changed" }
+
+// SubscriptionExpired is published when a subscription runs past the end of its period
+type SubscriptionExpired struct {
+	SubscriptionID uuid.UUID
+	UserID         uuid.UUID
+	PeriodEnd      time.Time
+}
+
+func (SubscriptionExpired) EventName() string { return "billing.subscription.expired" }
+
+// SubscriptionTrialEnding is published when Stripe reports that a subscription's trial ends soon
+type SubscriptionTrialEnding struct {
+	SubscriptionID uuid.UUID
+	UserID         uuid.UUID
+	TrialEnd       time.Time
+}
+
+func (SubscriptionTrialEnding) EventName() string { return "billing.subscription.trial_ending" }
+
+// RSVPReceived is published when a guest answers a wedding invitation. Nothing publishes it
+// yet: guests and weddings have no service or storage behind their repositories, and the one
+// that records RSVPs is expected to publish this once it exists.
+type RSVPReceived struct {
+	WeddingID uuid.UUID
+	// OwnerID is the user the wedding belongs to
+	OwnerID   uuid.UUID
+	GuestID   uuid.UUID
+	GuestName string
+	Status    RSVPStatus
+	Message   string
+}
+
+func (RSVPReceived) EventName() string { return "wedding.rsvp.received" }
@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationCategory groups messages and in-app notifications by what they are about
+type NotificationCategory string
+
+const (
+	// NotificationCategoryTransactional covers one-time codes, password resets, security alerts and
+	// invitations, which are sent whatever the recipient's preferences
+	NotificationCategoryTransactional NotificationCategory = "transactional"
+	NotificationCategoryPayment       NotificationCategory = "payment"
+	NotificationCategorySubscription  NotificationCategory = "subscription"
+	NotificationCategoryRSVP          NotificationCategory = "rsvp"
+	NotificationCategoryReminder      NotificationCategory = "reminder"
+	NotificationCategoryMarketing     NotificationCategory = "marketing"
+)
+
+// OptionalNotificationCategories are the categories recipients may opt out of, per channel
+var OptionalNotificationCategories = []NotificationCategory{
+	NotificationCategoryRSVP,
+	NotificationCategoryReminder,
+	NotificationCategoryMarketing,
+}
+
+// PreferenceChannels are the channels a preference can be set for
+var PreferenceChannels = []MessageChannel{
+	MessageChannelEmail,
+	MessageChannelWhatsApp,
+	MessageChannelSMS,
+	MessageChannelInApp,
+}
+
+// Optional reports whether recipients may opt out of the category. Messages without a category are transactional.
+func (c NotificationCategory) Optional() bool {
+	return slices.Contains(OptionalNotificationCategories, c)
+}
+
+// Notification is an entry in a user's in-app inbox
+type Notification struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	Category NotificationCategory
+	Title    string
+	Body     string
+	// Data holds the ids and values the notification is about, for clients to link to or localize it
+	Data      map[string]any
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NotificationPreference turns a category on or off on one channel, for a user or, when
+// UserID is nil, for the email address or phone number in Recipient
+type NotificationPreference struct {
+	UserID    *uuid.UUID
+	Recipient string
+	Category  NotificationCategory
+	Channel   MessageChannel
+	Enabled   bool
+	UpdatedAt time.Time
+}
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationRole is a role a user holds within a single organization (salon)
+type OrganizationRole string
+
+const (
+	OrganizationRoleOwner    OrganizationRole = "salon_owner"
+	OrganizationRoleEmployee OrganizationRole = "salon_employee"
+)
+
+type Organization struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedBy *uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type OrganizationMember struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+	Role           OrganizationRole
+	Email          string
+	FullName       *string
+	PhoneNumber    *string
+	JoinedAt       time.Time
+}
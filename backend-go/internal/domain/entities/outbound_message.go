@@ -0,0 +1,81 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MessageChannel string
+
+const (
+	MessageChannelEmail    MessageChannel = "email"
+	MessageChannelWhatsApp MessageChannel = "whatsapp"
+	MessageChannelSMS      MessageChannel = "sms"
+	// MessageChannelInApp is the in-app inbox of the user a message is for
+	MessageChannelInApp MessageChannel = "in_app"
+)
+
+// NotificationKind selects the channel route for messages sent to a phone number
+type NotificationKind string
+
+const (
+	NotificationKindOTP     NotificationKind = "otp"
+	NotificationKindAccount NotificationKind = "account"
+)
+
+type OutboundMessageStatus string
+
+const (
+	OutboundMessagePending OutboundMessageStatus = "pending"
+	OutboundMessageSending OutboundMessageStatus = "sending"
+	OutboundMessageSent    OutboundMessageStatus = "sent"
+	// Dead messages exhausted their attempts and are only retried on request
+	OutboundMessageDead OutboundMessageStatus = "dead"
+)
+
+// OutboundMessage is an email or WhatsApp message waiting in, or delivered from, the outbox
+type OutboundMessage struct {
+	ID      uuid.UUID
+	Channel MessageChannel
+	// FallbackChannels are tried in order when Channel is down or fails
+	FallbackChannels []MessageChannel
+	Recipient        string // email address or E.164 phone number
+	Subject          string
+	Body             string // cleared once the message is sent, as are WhatsApp and Email
+	UserID           *uuid.UUID
+	Status           OutboundMessageStatus
+	Attempts         int
+	MaxAttempts      int
+	NextAttemptAt    time.Time
+	LastError        string
+	CreatedAt        time.Time
+	SentAt           *time.Time
+	// ExternalID is the id the provider gave the message, set by channels that report delivery
+	ExternalID  string
+	DeliveredAt *time.Time
+	ReadAt      *time.Time
+	// WhatsApp is sent instead of the plain body when the message goes out over WhatsApp
+	WhatsApp *WhatsAppContent
+	// Email is sent along with the body when the message goes out as email
+	Email *EmailContent
+	// OrganizationID is the salon the message is sent on behalf of, whose own WhatsApp number is used when it has one
+	OrganizationID *uuid.UUID
+	// Sender is the account the message went out from, such as a WAHA session
+	Sender string
+	// Category decides whether the recipient's preferences apply; empty is transactional
+	Category NotificationCategory
+	// CampaignID is the campaign whose run created the message
+	CampaignID *uuid.UUID
+	// UnsubscribeURL is set by the Notifier while it delivers email the recipient may opt out of; it is not stored
+	UnsubscribeURL string
+}
+
+// MessageAck is a delivery receipt reported by the provider of a sent message
+type MessageAck string
+
+const (
+	MessageAckDelivered MessageAck = "delivered"
+	MessageAckRead      MessageAck = "read"
+	MessageAckFailed    MessageAck = "failed"
+)
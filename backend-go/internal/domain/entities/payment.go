@@ -14,8 +14,13 @@ const (
 	PaymentStatusCompleted PaymentStatus = "completed"
 	PaymentStatusPaid      PaymentStatus = "paid"
 	PaymentStatusFailed    PaymentStatus = "failed"
+	PaymentStatusRefunded  PaymentStatus = "refunded"
 	PaymentStatusExpired   PaymentStatus = "expired"
 	PaymentStatusActive    PaymentStatus = "active"
+	// Subscription statuses synced from Stripe besides active, pending and expired
+	PaymentStatusTrialing PaymentStatus = "trialing"
+	PaymentStatusPastDue  PaymentStatus = "past_due"
+	PaymentStatusCanceled PaymentStatus = "canceled"
 )
 
 type PaymentProvider string
@@ -38,6 +43,82 @@ type Payment struct {
 	CreatedAt       time.Time
 }
 
+// CheckoutRequest is a payment to start with a provider
+type CheckoutRequest struct {
+	UserID uuid.UUID
+	// Amount is in the currency's smallest unit, such as whole rupiah. Gateways that sell a fixed
+	// price, like Stripe's subscription, ignore it.
+	Amount int64
+	// Reference is our own id of the payment, such as an invoice number
+	Reference  string
+	SuccessURL string
+	CancelURL  string
+	// ExpiresIn is how long the user has to pay, zero for the provider's default
+	ExpiresIn time.Duration
+}
+
+// Checkout is a payment started with a provider, which the user completes at URL
+type Checkout struct {
+	URL           string
+	TransactionID string
+	Amount        float64
+	Currency      string
+	// Metadata is saved on the payment
+	Metadata map[string]any
+}
+
+// PaymentUpdate is the state of a payment as its provider reports it
+type PaymentUpdate struct {
+	TransactionID string
+	Status        PaymentStatus
+	// Amount and Currency are checked against the payment's when the provider reports them
+	Amount   *float64
+	Currency *string
+	// AmountRequired makes a missing Amount or Currency a mismatch, for providers that always report them
+	AmountRequired bool
+	Metadata       map[string]any
+}
+
+// ProviderSubscription is the current state of a subscription at its provider
+type ProviderSubscription struct {
+	ID         string
+	CustomerID string
+	// UserID is who bought the subscription when the provider was told at checkout, otherwise nil
+	UserID           *uuid.UUID
+	Status           PaymentStatus
+	CurrentPeriodEnd *time.Time
+	TrialEnd         *time.Time
+}
+
+// WebhookRequest is a webhook delivery as received
+type WebhookRequest struct {
+	Payload []byte
+	// Header holds the request headers, which carry the signature
+	Header map[string][]string
+	// Path is the path the delivery was posted to
+	Path string
+}
+
+// WebhookUpdate is what a payment webhook event reports
+type WebhookUpdate struct {
+	// Payment is set when the event changes the status of a payment
+	Payment *PaymentUpdate
+	// Subscription is set when the event is about a subscription
+	Subscription *ProviderSubscription
+	// TrialEnding is set when the event warns that Subscription's trial ends soon
+	TrialEnding bool
+}
+
+// DokuNotificationHeaders are the headers DOKU signs a notification with
+type DokuNotificationHeaders struct {
+	ClientID         string
+	RequestID        string
+	RequestTimestamp string
+	Signature        string
+	// RequestTarget is the path the notification was posted to, which the signature covers too
+	RequestTarget string
+}
+
 type Subscription struct {
 	ID                   uuid.UUID
 	UserID               uuid.UUID
@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScimToken struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	Description    string
+	CreatedAt      time.Time
+	LastUsedAt     *time.Time
+	RevokedAt      *time.Time
+}
+
+// DirectoryUser is an organization member as exposed to directory provisioning
+type DirectoryUser struct {
+	User   *User
+	Member *OrganizationMember
+}
+
+// HasGroup reports whether the user belongs to the directory group mapped to role, which is
+// their role within the organization
+func (d *DirectoryUser) HasGroup(role OrganizationRole) bool {
+	return d.Member != nil && d.Member.Role == role
+}
+
+// DirectoryUserAttribute is a user attribute the directory can filter on
+type DirectoryUserAttribute string
+
+const (
+	DirectoryUserEmail    DirectoryUserAttribute = "email"
+	DirectoryUserID       DirectoryUserAttribute = "id"
+	DirectoryUserFullName DirectoryUserAttribute = "full_name"
+)
+
+// DirectoryUserFilter narrows the directory's user list to the users whose Attribute compares to Value,
+// ignoring case, by Operator: eq, ne, co, sw or ew. The zero value matches every member.
+type DirectoryUserFilter struct {
+	Attribute DirectoryUserAttribute
+	Operator  string
+	Value     string
+}
@@ -1,7 +1,34 @@
 package entities
 
+// Message is an email handed to a Sender. Body is HTML; TextBody is its plain-text
+// alternative and is derived from Body when empty.
 type Message struct {
-	To      string
-	Subject string
-	Body    string
+	To          string
+	Cc          []string
+	Bcc         []string
+	ReplyTo     string
+	Subject     string
+	Body        string
+	TextBody    string
+	Headers     map[string]string // extra headers, e.g. X-Entity-Ref-ID
+	Attachments []Attachment
+	// UnsubscribeURL adds one-click List-Unsubscribe headers (RFC 8058); set it on marketing mail
+	UnsubscribeURL string
+}
+
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"` // detected from Filename when empty, e.g. application/pdf
+	Data        []byte `json:"data"`
+}
+
+// EmailContent is what an email in the outbox carries besides its subject and HTML body
+type EmailContent struct {
+	Cc      []string `json:"cc,omitempty"`
+	Bcc     []string `json:"bcc,omitempty"`
+	ReplyTo string   `json:"reply_to,omitempty"`
+	// TextBody is the plain-text alternative, derived from the HTML body when empty
+	TextBody    string            `json:"text_body,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
 }
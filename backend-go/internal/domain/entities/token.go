@@ -17,7 +17,7 @@ type TokenClaims struct {
 	Email     string    `json:"email"`
 	Roles     []string  `json:"roles"`
 	ExpiresAt int64     `json:"exp"`
-	IssuedAt  int64     `json:"iat"`
+	IssuedAt  time.Time `json:"iat"`
 	Type      string    `json:"type"` // "access" or "refresh"
 }
 
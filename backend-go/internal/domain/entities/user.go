@@ -7,20 +7,66 @@ import (
 )
 
 type User struct {
-	ID              uuid.UUID
-	Email           string
-	PhoneNumber     *string
-	FullName        *string
-	HashedPassword  *string
-	IsActive        bool
-	IsEmailVerified bool
-	IsPhoneVerified bool
-	IsTOTPEnabled   bool
-	TOTPSecret      *string
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
-	LastLoginAt     *time.Time
-	Roles           []string
+	ID                uuid.UUID
+	Email             string
+	PhoneNumber       *string
+	FullName          *string
+	HashedPassword    *string
+	IsActive          bool
+	IsEmailVerified   bool
+	IsPhoneVerified   bool
+	IsTOTPEnabled     bool
+	TOTPSecret        *string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	LastLoginAt       *time.Time
+	SessionsRevokedAt *time.Time
+	DeletedAt         *time.Time
+	PreferredChannel  MessageChannel // empty when the user has no preference
+	Locale            string         // BCP 47 language tag, empty to follow the language of each request
+	ProvisionedByOrg  *uuid.UUID     // organization whose SCIM directory created the account
+	Roles             []string
+}
+
+// ManagedBy reports whether the account was created by the directory of orgID, which may then change it
+func (u *User) ManagedBy(orgID uuid.UUID) bool {
+	return u.ProvisionedByOrg != nil && *u.ProvisionedByOrg == orgID
+}
+
+// SessionRevoked reports whether a token issued at issuedAt predates the user signing out all sessions.
+// A token issued in the same millisecond counts as revoked.
+func (u *User) SessionRevoked(issuedAt time.Time) bool {
+	return u.SessionsRevokedAt != nil && !issuedAt.After(*u.SessionsRevokedAt)
+}
+
+type UserSortField string
+
+const (
+	UserSortCreatedAt   UserSortField = "created_at"
+	UserSortEmail       UserSortField = "email"
+	UserSortFullName    UserSortField = "full_name"
+	UserSortLastLoginAt UserSortField = "last_login_at"
+)
+
+// UserFilter narrows and orders the admin user list; zero values match every user
+type UserFilter struct {
+	Search          string // substring of email, full name or phone number
+	Role            string
+	IsActive        *bool
+	IsEmailVerified *bool
+	IsPhoneVerified *bool
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	OAuthProvider   string
+	Deleted         bool // list soft deleted users instead of live ones
+	SortBy          UserSortField
+	SortDesc        bool
+}
+
+// UserCursor is the position of the last user of a page in the list order
+type UserCursor struct {
+	SortKey string    `json:"k"`
+	ID      uuid.UUID `json:"id"`
 }
 
 type Role struct {
@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserImportStatus string
+
+const (
+	UserImportStatusPending   UserImportStatus = "pending"
+	UserImportStatusRunning   UserImportStatus = "running"
+	UserImportStatusCompleted UserImportStatus = "completed"
+	UserImportStatusFailed    UserImportStatus = "failed"
+)
+
+// Outcome of a single imported row
+type UserImportRowStatus string
+
+const (
+	UserImportRowCreated     UserImportRowStatus = "created"
+	UserImportRowWouldCreate UserImportRowStatus = "would_create"
+	UserImportRowSkipped     UserImportRowStatus = "skipped"
+	UserImportRowInvalid     UserImportRowStatus = "invalid"
+)
+
+// UserImportRow is one user record read from an uploaded CSV or XLSX file
+type UserImportRow struct {
+	Line     int
+	Email    string
+	Phone    string
+	FullName string
+	Roles    []string
+}
+
+type UserImportRowResult struct {
+	Line   int                 `json:"line"`
+	Email  string              `json:"email,omitempty"`
+	Phone  string              `json:"phone_number,omitempty"`
+	Status UserImportRowStatus `json:"status"`
+	UserID string              `json:"user_id,omitempty"`
+	Errors []string            `json:"errors,omitempty"`
+}
+
+type UserImportOptions struct {
+	DryRun      bool
+	Region      string // default region for phone numbers without a country code
+	SendWelcome bool
+}
+
+type UserImportJob struct {
+	ID            uuid.UUID
+	CreatedBy     *uuid.UUID
+	Status        UserImportStatus
+	DryRun        bool
+	TotalRows     int
+	ProcessedRows int
+	CreatedCount  int
+	SkippedCount  int
+	ErrorCount    int
+	Report        []UserImportRowResult
+	Error         string
+	CreatedAt     time.Time
+	FinishedAt    *time.Time
+}
@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserSessionRevoked(t *testing.T) {
+	revokedAt := time.Date(2026, 1, 2, 3, 4, 5, 500_000_000, time.UTC)
+	u := &User{SessionsRevokedAt: &revokedAt}
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		want     bool
+	}{
+		{"earlier second", revokedAt.Add(-time.Second), true},
+		{"same second, before", revokedAt.Add(-100 * time.Millisecond), true},
+		{"same millisecond", revokedAt, true},
+		{"same second, after", revokedAt.Add(100 * time.Millisecond), false},
+	}
+	for _, tt := range tests {
+		if got := u.SessionRevoked(tt.issuedAt); got != tt.want {
+			t.Errorf("%s: SessionRevoked = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if (&User{}).SessionRevoked(revokedAt) {
+		t.Error("user who never revoked sessions has a revoked session")
+	}
+}
@@ -14,6 +14,8 @@ const (
 	VerificationTypePhone             VerificationType = "phone"
 	VerificationTypePasswordReset     VerificationType = "password_reset"
 	VerificationTypePhoneRegistration VerificationType = "phone_registration"
+	VerificationTypeSecureAccount     VerificationType = "secure_account"
+	VerificationTypeOrgInvitation     VerificationType = "org_invitation"
 )
 
 const (
@@ -22,6 +24,8 @@ const (
 	VerificationPurposeAddPhone          VerificationPurpose = "add_phone"
 	VerificationPurposePhoneOTP          VerificationPurpose = "phone_otp"
 	VerificationPurposePasswordReset     VerificationPurpose = "password_reset"
+	VerificationPurposeSecureAccount     VerificationPurpose = "secure_account"
+	VerificationPurposeOrgInvitation     VerificationPurpose = "org_invitation"
 )
 
 type VerificationCode struct {
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEventStatus string
+
+const (
+	// WebhookEventReceived events are recorded but not processed yet
+	WebhookEventReceived  WebhookEventStatus = "received"
+	WebhookEventProcessed WebhookEventStatus = "processed"
+	// WebhookEventFailed events are processed again when the provider retries or an admin replays them
+	WebhookEventFailed WebhookEventStatus = "failed"
+)
+
+// WebhookEvent is a delivery from a payment provider, recorded once per provider event id
+type WebhookEvent struct {
+	ID       uuid.UUID
+	Provider PaymentProvider
+	// EventID is the provider's id of the event; retries of a delivery share it
+	EventID   string
+	EventType string
+	// Payload is the body as received, verified before it was recorded
+	Payload     []byte
+	Status      WebhookEventStatus
+	Attempts    int
+	LastError   string
+	ProcessedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,79 @@
+package entities
+
+type WhatsAppMediaKind string
+
+const (
+	WhatsAppMediaImage WhatsAppMediaKind = "image"
+	WhatsAppMediaFile  WhatsAppMediaKind = "file"
+)
+
+// WhatsAppMedia references a file WAHA downloads and attaches to the message
+type WhatsAppMedia struct {
+	Kind     WhatsAppMediaKind `json:"kind"`
+	URL      string            `json:"url"`
+	MimeType string            `json:"mimetype,omitempty"`
+	Filename string            `json:"filename,omitempty"`
+}
+
+// WhatsAppLocation is a map pin. The coordinates are decimal degrees kept as text,
+// so templates can fill them in like any other field.
+type WhatsAppLocation struct {
+	Latitude  string `json:"latitude"`
+	Longitude string `json:"longitude"`
+	Title     string `json:"title,omitempty"`
+}
+
+type WhatsAppButtonType string
+
+const (
+	// WhatsAppButtonReply sends its text back as a reply to the message, as RSVP answers do
+	WhatsAppButtonReply WhatsAppButtonType = "reply"
+	WhatsAppButtonURL   WhatsAppButtonType = "url"
+	WhatsAppButtonCall  WhatsAppButtonType = "call"
+)
+
+type WhatsAppButton struct {
+	Type  WhatsAppButtonType `json:"type"`
+	Text  string             `json:"text"`
+	URL   string             `json:"url,omitempty"`
+	Phone string             `json:"phone,omitempty"`
+}
+
+type WhatsAppListRow struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+type WhatsAppListSection struct {
+	Title string            `json:"title"`
+	Rows  []WhatsAppListRow `json:"rows"`
+}
+
+// WhatsAppList is a menu opened by Button; the chosen row comes back as a reply
+type WhatsAppList struct {
+	Button   string                `json:"button"`
+	Sections []WhatsAppListSection `json:"sections"`
+}
+
+// WhatsAppContent is what a WhatsApp message carries besides its text, which stays in the
+// message body so SMS and other text-only channels can still deliver it.
+// A message has buttons or a list, not both.
+type WhatsAppContent struct {
+	Media    *WhatsAppMedia    `json:"media,omitempty"`
+	Location *WhatsAppLocation `json:"location,omitempty"`
+	Buttons  []WhatsAppButton  `json:"buttons,omitempty"`
+	List     *WhatsAppList     `json:"list,omitempty"`
+	Footer   string            `json:"footer,omitempty"`
+}
+
+// WhatsAppPresence is what a chat shows about us, such as typing while a reply is prepared
+type WhatsAppPresence string
+
+const (
+	WhatsAppPresenceOnline    WhatsAppPresence = "online"
+	WhatsAppPresenceOffline   WhatsAppPresence = "offline"
+	WhatsAppPresenceTyping    WhatsAppPresence = "typing"
+	WhatsAppPresenceRecording WhatsAppPresence = "recording"
+	WhatsAppPresencePaused    WhatsAppPresence = "paused"
+)
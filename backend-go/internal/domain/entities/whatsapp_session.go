@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WAHA session statuses
+const (
+	WhatsAppSessionStarting = "STARTING"
+	// WhatsAppSessionScanQRCode waits for the phone to scan the pairing QR code
+	WhatsAppSessionScanQRCode = "SCAN_QR_CODE"
+	// WhatsAppSessionWorking is the WAHA status of a session that can send and receive
+	WhatsAppSessionWorking = "WORKING"
+	WhatsAppSessionFailed  = "FAILED"
+	WhatsAppSessionStopped = "STOPPED"
+)
+
+// WhatsAppSession is the last state WAHA reported for one of its sessions
+type WhatsAppSession struct {
+	Name            string
+	Status          string // STARTING, SCAN_QR_CODE, WORKING, FAILED or STOPPED
+	StatusChangedAt time.Time
+	LastEventAt     time.Time
+	// OrganizationID is the salon sending from the session, nil for the platform session
+	OrganizationID *uuid.UUID
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// BounceMailbox reads the bounce and complaint reports delivered to a mailbox
+type BounceMailbox interface {
+	// Read passes the reports that arrived since the last read handle accepted. Until handle
+	// returns nil the same reports are passed again by the next call.
+	Read(ctx context.Context, handle func(ctx context.Context, events []entities.BounceEvent) error) error
+}
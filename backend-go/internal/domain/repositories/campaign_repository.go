@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type CampaignRepository interface {
+	TxProvider[CampaignRepository]
+
+	Create(ctx context.Context, c *entities.Campaign) (*entities.Campaign, error)
+	// GetByID returns nil when no campaign has the id
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Campaign, error)
+	// GetForUpdate locks the campaign until the transaction ends; it returns nil when no campaign has the id
+	GetForUpdate(ctx context.Context, id uuid.UUID) (*entities.Campaign, error)
+	// List returns a page of the organization's campaigns, or of those the user created without an
+	// organization when orgID is nil, newest first, and how many there are
+	List(ctx context.Context, orgID *uuid.UUID, createdBy uuid.UUID, offset, limit int32) ([]*entities.Campaign, int, error)
+	// ListDue returns the id and organization of campaigns whose next run is due
+	ListDue(ctx context.Context, limit int) ([]*entities.Campaign, error)
+	// LockDue locks a due campaign for its run. It returns nil when the campaign is no longer due or
+	// another transaction is running it.
+	LockDue(ctx context.Context, id uuid.UUID) (*entities.Campaign, error)
+	// CompleteRun records a run and when the next one is, with status completed when there is none
+	CompleteRun(ctx context.Context, id uuid.UUID, next *time.Time, status entities.CampaignStatus) (*entities.Campaign, error)
+	SetStatus(ctx context.Context, id uuid.UUID, status entities.CampaignStatus, next *time.Time) (*entities.Campaign, error)
+	Stats(ctx context.Context, id uuid.UUID) (*entities.CampaignStats, error)
+	// CancelMessages dead-letters the campaign's messages that are still waiting and returns how many there were
+	CancelMessages(ctx context.Context, id uuid.UUID) (int, error)
+	// RespaceMessages spreads the campaign's waiting messages from now on, spacing apart
+	RespaceMessages(ctx context.Context, id uuid.UUID, spacing time.Duration) error
+}
@@ -1,6 +1,10 @@
 package repositories
 
-import "context"
+import (
+    "context"
+
+    "github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
 
 // DokuClient defines Jokul Checkout API operations needed by our app
 type DokuClient interface {
@@ -9,5 +13,10 @@ type DokuClient interface {
     // invoiceNumber: merchant invoice identifier
     // paymentDueMinutes: expiration time in minutes
     CreatePayment(ctx context.Context, amountIDR int64, invoiceNumber string, paymentDueMinutes int) (paymentURL string, transactionID string, idrAmount int64, currency string, err error)
+    // VerifyNotification checks the signature of a payment notification against its exact body,
+    // and that its Request-Timestamp is within a few minutes of now
+    VerifyNotification(headers entities.DokuNotificationHeaders, body []byte) bool
+    // GetPaymentStatus returns DOKU's transaction status of an invoice, such as SUCCESS, PENDING, FAILED or EXPIRED
+    GetPaymentStatus(ctx context.Context, invoiceNumber string) (status string, idrAmount int64, currency string, err error)
 }
 
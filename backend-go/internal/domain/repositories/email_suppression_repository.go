@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type EmailSuppressionRepository interface {
+	TxProvider[EmailSuppressionRepository]
+
+	// Suppress adds the address to the list, or refreshes the entry of an address already on it
+	Suppress(ctx context.Context, s *entities.EmailSuppression) (*entities.EmailSuppression, error)
+	// Get returns nil when the address is not suppressed; addresses compare case-insensitively
+	Get(ctx context.Context, email string) (*entities.EmailSuppression, error)
+	// List returns a page of entries, newest first, and the number of entries matching search
+	List(ctx context.Context, search string, offset, limit int32) ([]*entities.EmailSuppression, int, error)
+	// Delete reports whether the address was suppressed
+	Delete(ctx context.Context, email string) (bool, error)
+}
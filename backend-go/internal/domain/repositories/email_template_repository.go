@@ -2,12 +2,28 @@ package repositories
 
 import (
 	"context"
+	"errors"
 
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 )
 
+// ErrTemplateVersionConflict is returned when another version of the template was saved concurrently
+var ErrTemplateVersionConflict = errors.New("template was changed concurrently, try again")
+
 type EmailTemplateRepository interface {
 	TxProvider[EmailTemplateRepository]
 
-	GetByName(ctx context.Context, name entities.EmailTemplateEnum) (*entities.EmailTemplate, error)
+	// GetByName returns the active variant for the first of locales that has one
+	GetByName(ctx context.Context, name entities.EmailTemplateEnum, locales []string) (*entities.EmailTemplate, error)
+	// List returns the active version of every template variant along with its latest version number
+	List(ctx context.Context) ([]*entities.EmailTemplate, error)
+	// ListVersions returns every version of a template variant, newest first
+	ListVersions(ctx context.Context, name entities.EmailTemplateEnum, locale string) ([]*entities.EmailTemplate, error)
+	// GetVersion returns nil when the template variant has no such version
+	GetVersion(ctx context.Context, name entities.EmailTemplateEnum, locale string, version int) (*entities.EmailTemplate, error)
+	// CreateVersion stores tpl as the next, inactive, version of its template variant
+	CreateVersion(ctx context.Context, tpl *entities.EmailTemplate) (*entities.EmailTemplate, error)
+	// Activate makes version the active one, returning nil when the variant has no such version.
+	// Call it in a transaction, the previously active version is deactivated first.
+	Activate(ctx context.Context, name entities.EmailTemplateEnum, locale string, version int) (*entities.EmailTemplate, error)
 }
@@ -0,0 +1,7 @@
+package repositories
+
+// GeoIPResolver resolves an IP address to its ISO 3166-1 alpha-2 country code
+type GeoIPResolver interface {
+	// CountryCode returns an empty string when the address is unknown
+	CountryCode(ip string) (string, error)
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// NotificationChannel delivers outbox messages over one transport
+type NotificationChannel interface {
+	// Send sets msg.ExternalID when the provider reports delivery receipts against an id of its own
+	Send(ctx context.Context, msg *entities.OutboundMessage) error
+	// Ready reports whether the channel can deliver right now.
+	// A channel that is not ready is skipped in favour of the message's fallback channels.
+	Ready(ctx context.Context) bool
+}
+
+// RateLimitedError is returned by Send when the sender has to wait before it sends again.
+// The message is put back in the outbox until then rather than falling back to another channel.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited, retry in %s", e.RetryAfter)
+}
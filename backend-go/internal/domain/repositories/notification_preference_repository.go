@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type NotificationPreferenceRepository interface {
+	TxProvider[NotificationPreferenceRepository]
+
+	// ListByUser returns the preferences the user has set; categories and channels without one are enabled
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.NotificationPreference, error)
+	// ListByRecipient returns the preferences set through unsubscribe links by someone who is not a user
+	ListByRecipient(ctx context.Context, recipient string) ([]*entities.NotificationPreference, error)
+	// Set saves p for its user, or for its recipient when it has no user
+	Set(ctx context.Context, p *entities.NotificationPreference) error
+}
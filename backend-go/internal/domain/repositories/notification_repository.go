@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type NotificationRepository interface {
+	TxProvider[NotificationRepository]
+
+	Create(ctx context.Context, n *entities.Notification) (*entities.Notification, error)
+	// List returns a page of the user's notifications, newest first
+	List(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset, limit int32) ([]*entities.Notification, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+	// MarkRead marks the user's notifications with the given ids read and returns how many were unread
+	MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int, error)
+	// MarkAllRead marks every notification of the user read and returns how many were unread
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error)
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type OrganizationRepository interface {
+	TxProvider[OrganizationRepository]
+
+	Create(ctx context.Context, org *entities.Organization) (*entities.Organization, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Organization, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Organization, error)
+	AddMember(ctx context.Context, orgID, userID uuid.UUID, role entities.OrganizationRole) (*entities.OrganizationMember, error)
+	GetMember(ctx context.Context, orgID, userID uuid.UUID) (*entities.OrganizationMember, error)
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]*entities.OrganizationMember, error)
+	// LockMembersByRole returns how many members have role, locking them until the transaction
+	// ends so changes to them wait for it
+	LockMembersByRole(ctx context.Context, orgID uuid.UUID, role entities.OrganizationRole) (int, error)
+	RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error
+}
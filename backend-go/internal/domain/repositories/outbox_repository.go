@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type OutboxRepository interface {
+	TxProvider[OutboxRepository]
+
+	Enqueue(ctx context.Context, msg *entities.OutboundMessage) (*entities.OutboundMessage, error)
+	// Claim leases up to limit due messages; a lease that runs out makes the message due again
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*entities.OutboundMessage, error)
+	// MarkSent records the channel that delivered the message, the id it got there and the account it was sent from, if any.
+	// The message's content is cleared.
+	MarkSent(ctx context.Context, id uuid.UUID, channel entities.MessageChannel, externalID, sender string) error
+	// GetByExternalID returns nil when no message sent over channel has the id
+	GetByExternalID(ctx context.Context, channel entities.MessageChannel, externalID string) (*entities.OutboundMessage, error)
+	// RecordAck applies a delivery receipt and reports whether a message matched it
+	RecordAck(ctx context.Context, channel entities.MessageChannel, externalID string, ack entities.MessageAck, detail string) (bool, error)
+	Reschedule(ctx context.Context, id uuid.UUID, next time.Time, lastError string) error
+	// Defer makes the message due again at next without counting the attempt that was just made
+	Defer(ctx context.Context, id uuid.UUID, next time.Time) error
+	MarkDead(ctx context.Context, id uuid.UUID, lastError string) error
+	// Requeue returns nil when no dead message that still has its content has the id
+	Requeue(ctx context.Context, id uuid.UUID) (*entities.OutboundMessage, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.OutboundMessage, error)
+	List(ctx context.Context, status entities.OutboundMessageStatus, userID *uuid.UUID, offset, limit int32) ([]*entities.OutboundMessage, error)
+	CountByStatus(ctx context.Context) (map[entities.OutboundMessageStatus]int, error)
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// ErrPaymentOperationUnsupported is returned by gateways whose provider cannot refund or cancel a payment
+var ErrPaymentOperationUnsupported = errors.New("payment provider does not support this operation")
+
+// PaymentGateway takes payments through one provider
+type PaymentGateway interface {
+	// CreateCheckout starts a payment and returns where the user completes it
+	CreateCheckout(ctx context.Context, req entities.CheckoutRequest) (*entities.Checkout, error)
+	// GetPaymentStatus fetches the current state of a payment started with CreateCheckout
+	GetPaymentStatus(ctx context.Context, p *entities.Payment) (*entities.PaymentUpdate, error)
+	// VerifyWebhook checks the signature of a delivery and returns the event it carries. EventID is
+	// the same for every retry of the event.
+	VerifyWebhook(ctx context.Context, req entities.WebhookRequest) (*entities.WebhookEvent, error)
+	// ParseWebhook reads a recorded event into the changes it reports. It may fetch current state
+	// from the provider, since events can arrive out of order.
+	ParseWebhook(ctx context.Context, e *entities.WebhookEvent) (*entities.WebhookUpdate, error)
+	// Refund returns the full amount of a paid payment, ending the subscription it paid for if any
+	Refund(ctx context.Context, p *entities.Payment) error
+	// Cancel stops a pending payment from being completed
+	Cancel(ctx context.Context, p *entities.Payment) error
+}
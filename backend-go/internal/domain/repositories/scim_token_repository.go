@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type ScimTokenRepository interface {
+	TxProvider[ScimTokenRepository]
+
+	Create(ctx context.Context, orgID uuid.UUID, tokenHash, description string) (*entities.ScimToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*entities.ScimToken, error)
+	Touch(ctx context.Context, id uuid.UUID) error
+}
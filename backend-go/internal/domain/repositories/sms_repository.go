@@ -0,0 +1,9 @@
+package repositories
+
+import "context"
+
+// SMSClient sends text messages through an SMS gateway
+type SMSClient interface {
+	// SendSMS sends text to an E.164 phone number
+	SendSMS(ctx context.Context, phone string, text string) error
+}
@@ -10,7 +10,10 @@ import (
 type SubscriptionRepository interface {
 	TxProvider[SubscriptionRepository]
 
+	// Upsert saves s as the user's subscription. It returns nil, leaving the stored one as it is,
+	// when s is another subscription than the stored one and that one has not ended.
 	Upsert(ctx context.Context, s *entities.Subscription) (*entities.Subscription, error)
 	GetByUser(ctx context.Context, userID uuid.UUID) (*entities.Subscription, error)
+	GetByStripeCustomer(ctx context.Context, customerID string) (*entities.Subscription, error)
 	ListAll(ctx context.Context) ([]*entities.Subscription, error)
 }
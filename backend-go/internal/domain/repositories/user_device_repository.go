@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type UserDeviceRepository interface {
+	TxProvider[UserDeviceRepository]
+
+	GetByFingerprint(ctx context.Context, userID uuid.UUID, fingerprint string) (*entities.UserDevice, error)
+	Create(ctx context.Context, d *entities.UserDevice) (*entities.UserDevice, error)
+	Touch(ctx context.Context, id uuid.UUID) error
+	CountByUser(ctx context.Context, userID uuid.UUID) (int, error)
+	Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+}
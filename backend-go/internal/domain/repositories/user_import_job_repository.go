@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type UserImportJobRepository interface {
+	TxProvider[UserImportJobRepository]
+
+	Create(ctx context.Context, job *entities.UserImportJob) (*entities.UserImportJob, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.UserImportJob, error)
+	UpdateProgress(ctx context.Context, job *entities.UserImportJob) error
+	Finish(ctx context.Context, job *entities.UserImportJob) error
+	// FailStale marks unfinished jobs without a heartbeat since before as failed with reason
+	FailStale(ctx context.Context, before time.Time, reason string) (int64, error)
+}
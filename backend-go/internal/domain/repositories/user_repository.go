@@ -2,25 +2,49 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 
 	"github.com/google/uuid"
 )
 
+// ErrUserConflict is returned when a live user already holds the email or phone number
+var ErrUserConflict = errors.New("email or phone number belongs to another user")
+
 type UserRepository interface {
 	TxProvider[UserRepository]
 
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
 	GetByPhone(ctx context.Context, phone string) (*entities.User, error)
-	ListUsers(ctx context.Context, offset, limit int32) ([]*entities.User, int, error)
+	// ListUsers returns a page of users after the cursor and the cursor of the next page, which is nil on the last page
+	ListUsers(ctx context.Context, filter entities.UserFilter, offset, limit int32, after *entities.UserCursor) ([]*entities.User, *entities.UserCursor, error)
+	// CountUsers returns the number of users matching the filter
+	CountUsers(ctx context.Context, filter entities.UserFilter) (int, error)
+	// ListDirectoryUsers returns a page of the organization's members matching the filter, in the order they joined.
+	// Their global roles are not loaded.
+	ListDirectoryUsers(ctx context.Context, orgID uuid.UUID, filter entities.DirectoryUserFilter, offset, limit int32) ([]*entities.DirectoryUser, error)
+	// CountDirectoryUsers returns the number of the organization's members matching the filter
+	CountDirectoryUsers(ctx context.Context, orgID uuid.UUID, filter entities.DirectoryUserFilter) (int, error)
 	Create(ctx context.Context, user *entities.User) (*entities.User, error)
 	UpdateProfile(ctx context.Context, userID uuid.UUID, fullName *string, hashedPassword *string) (*entities.User, error)
 	UpdateUser(ctx context.Context, user *entities.User) (*entities.User, error)
 	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) (*entities.User, error)
 	UpdatePhone(ctx context.Context, userID uuid.UUID, phone string) (*entities.User, error)
+	// SetPreferredChannel clears the preference when channel is empty
+	SetPreferredChannel(ctx context.Context, userID uuid.UUID, channel entities.MessageChannel) (*entities.User, error)
+	// SetLocale clears the preference when locale is empty
+	SetLocale(ctx context.Context, userID uuid.UUID, locale string) (*entities.User, error)
 	SetUserRoles(ctx context.Context, userID uuid.UUID, roles []entities.RoleEnum) error
 	SetPhoneVerified(ctx context.Context, userID uuid.UUID) error
 	SetEmailVerified(ctx context.Context, userID uuid.UUID) error
+	UpdateLastLogin(ctx context.Context, userID uuid.UUID, at time.Time) error
+	RevokeSessions(ctx context.Context, userID uuid.UUID) error
+	// SoftDelete hides the user from every lookup and reports whether a live user was deleted
+	SoftDelete(ctx context.Context, userID uuid.UUID) (bool, error)
+	// Restore undoes SoftDelete, returning nil when no deleted user has the id
+	// and ErrUserConflict when the email or phone number was registered again meanwhile
+	Restore(ctx context.Context, userID uuid.UUID) (*entities.User, error)
 }
@@ -16,5 +16,7 @@ type VerificationCodeRepository interface {
 	GetLatestUnused(ctx context.Context, userID uuid.UUID, vType entities.VerificationType) (*entities.VerificationCode, error)
 	GetByCode(ctx context.Context, userID uuid.UUID, vType entities.VerificationType, code string) (*entities.VerificationCode, error)
 	GetByCodeOnly(ctx context.Context, vType entities.VerificationType, code string) (*entities.VerificationCode, error)
+	// GetPendingAddPhone returns the newest live code confirming phone as a user's new number, nil when there is none
+	GetPendingAddPhone(ctx context.Context, phone string) (*entities.VerificationCode, error)
 	MarkUsed(ctx context.Context, id uuid.UUID) error
 }
@@ -1,11 +1,41 @@
 package repositories
 
-import "context"
+import (
+	"context"
 
-// WahaClient defines WhatsApp sender via WAHA API
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// WahaClient defines WhatsApp sender via WAHA API. Every call acts on one session, the
+// configured one unless the client came from Session.
+// Phone numbers should be numeric; the client formats them to chatId `<number>@c.us`.
+// The Send methods return the id of the message sent, empty when WAHA did not report one.
 type WahaClient interface {
-    // SendText sends a text message to the given international phone number
-    // Phone should be numeric; client formats to chatId `<number>@c.us`
-    SendText(ctx context.Context, phone string, text string) error
-}
+	// Session returns a client acting on the named session
+	Session(name string) WahaClient
+	SessionName() string
 
+	// SendText sends a text message to the given international phone number
+	SendText(ctx context.Context, phone string, text string) (string, error)
+	// SendImage sends an image shown inline, with caption as its text
+	SendImage(ctx context.Context, phone string, media entities.WhatsAppMedia, caption string) (string, error)
+	// SendFile sends a document the recipient downloads, with caption as its text
+	SendFile(ctx context.Context, phone string, media entities.WhatsAppMedia, caption string) (string, error)
+	SendLocation(ctx context.Context, phone string, location entities.WhatsAppLocation) (string, error)
+	// SendButtons sends text with up to three buttons below it; an image header is optional
+	SendButtons(ctx context.Context, phone string, text, footer string, buttons []entities.WhatsAppButton, header *entities.WhatsAppMedia) (string, error)
+	// SendList sends text with a button that opens a menu of rows to reply with
+	SendList(ctx context.Context, phone string, text, footer string, list entities.WhatsAppList) (string, error)
+	// SetPresence changes what the chat shows about us, such as typing
+	SetPresence(ctx context.Context, phone string, presence entities.WhatsAppPresence) error
+	// MessageStatus returns the latest receipt of a message sent to phone, empty while it has none
+	MessageStatus(ctx context.Context, phone, messageID string) (entities.MessageAck, error)
+	// SessionStatus returns the WAHA status of the sending session, WORKING when it can send
+	SessionStatus(ctx context.Context) (string, error)
+	// StartSession creates the session, or starts it again when it exists, with our webhook configured
+	StartSession(ctx context.Context) error
+	// StopSession stops the session; logout also unlinks the phone, which then has to pair again
+	StopSession(ctx context.Context, logout bool) error
+	// QRCode returns the PNG image of the code to pair a phone with, while the session is in SCAN_QR_CODE
+	QRCode(ctx context.Context) ([]byte, error)
+}
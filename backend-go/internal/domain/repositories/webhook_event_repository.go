@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type WebhookEventRepository interface {
+	TxProvider[WebhookEventRepository]
+
+	// Record saves a delivery, or returns the event already saved under its provider and event id
+	Record(ctx context.Context, e *entities.WebhookEvent) (*entities.WebhookEvent, error)
+	// GetByID and GetForUpdate return nil when no event has the id
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.WebhookEvent, error)
+	GetForUpdate(ctx context.Context, id uuid.UUID) (*entities.WebhookEvent, error)
+	MarkProcessed(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, lastError string) error
+	List(ctx context.Context, provider entities.PaymentProvider, status entities.WebhookEventStatus, offset, limit int32) ([]*entities.WebhookEvent, int, error)
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+type WhatsAppSessionRepository interface {
+	TxProvider[WhatsAppSessionRepository]
+
+	// SetStatus records the status of a session and returns the status it had before, empty for a new session
+	SetStatus(ctx context.Context, name, status string) (*entities.WhatsAppSession, string, error)
+	// Touch notes that the session sent an event, which shows its webhook is alive
+	Touch(ctx context.Context, name string) error
+	List(ctx context.Context) ([]*entities.WhatsAppSession, error)
+	// CreateForOrganization registers the session of a salon, keeping the status of one that already exists
+	CreateForOrganization(ctx context.Context, name string, orgID uuid.UUID, status string) (*entities.WhatsAppSession, error)
+	GetByOrganization(ctx context.Context, orgID uuid.UUID) (*entities.WhatsAppSession, error)
+	// TakeSendSlot lets one message go out from the session every spacing, across instances.
+	// It returns zero when the message may be sent now, and otherwise how long to wait.
+	TakeSendSlot(ctx context.Context, session string, spacing time.Duration) (time.Duration, error)
+}
@@ -2,180 +2,368 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/stripe/stripe-go/v78"
+	"github.com/jackc/pgx/v5"
 
-	"github.com/williamchand/fullstack-fastapi/backend-go/config"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
-	stripeinfra "github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/stripe"
 )
 
+const maxListWebhookEventsLimit = 100
+
+// BillingService takes payments through the gateway of each configured provider
 type BillingService struct {
-	cfg     *config.Config
-	subs    repositories.SubscriptionRepository
-	payRepo repositories.PaymentRepository
-	stripe  *stripeinfra.Client
-	doku    repositories.DokuClient
+	subs        repositories.SubscriptionRepository
+	payRepo     repositories.PaymentRepository
+	webhookRepo repositories.WebhookEventRepository
+	txManager   repositories.TransactionManager
+	gateways    map[entities.PaymentProvider]repositories.PaymentGateway
+	bus         *EventBus
+	// events holds what is published while a webhook event is processed, until its transaction commits
+	events *[]entities.Event
+}
+
+// NewBillingService takes payments through gateways; providers missing from it return ErrPaymentProviderUnavailable
+func NewBillingService(
+	subs repositories.SubscriptionRepository,
+	pay repositories.PaymentRepository,
+	webhookRepo repositories.WebhookEventRepository,
+	txManager repositories.TransactionManager,
+	gateways map[entities.PaymentProvider]repositories.PaymentGateway,
+	bus *EventBus,
+) *BillingService {
+	return &BillingService{subs: subs, payRepo: pay, webhookRepo: webhookRepo, txManager: txManager, gateways: gateways, bus: bus}
 }
 
-func NewBillingService(cfg *config.Config, subs repositories.SubscriptionRepository, pay repositories.PaymentRepository, stripeClient *stripeinfra.Client, dokuClient repositories.DokuClient) *BillingService {
-	return &BillingService{cfg: cfg, subs: subs, payRepo: pay, stripe: stripeClient, doku: dokuClient}
+func (b *BillingService) gateway(provider entities.PaymentProvider) (repositories.PaymentGateway, error) {
+	gw, ok := b.gateways[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentProviderUnavailable, provider)
+	}
+	return gw, nil
 }
 
-func (b *BillingService) CreateCheckoutSession(ctx context.Context, userID uuid.UUID, successURL, cancelURL string) (string, string, error) {
-	s, err := b.stripe.CreateCheckoutSession(b.cfg.Stripe.PriceID, successURL, cancelURL, map[string]string{"user_id": userID.String()})
+// CreateCheckout starts a payment with a provider and records it as pending
+func (b *BillingService) CreateCheckout(ctx context.Context, provider entities.PaymentProvider, req entities.CheckoutRequest) (*entities.Checkout, error) {
+	gw, err := b.gateway(provider)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
-	// Get price info for amount/currency
-	pr, err := b.stripe.GetPrice(b.cfg.Stripe.PriceID)
+	c, err := gw.CreateCheckout(ctx, req)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
-	amount := float64(pr.UnitAmount) / 100.0
-	currency := string(pr.Currency)
 	_, err = b.payRepo.Create(ctx, &entities.Payment{
-		UserID:        userID,
-		Provider:      entities.PaymentProviderStripe,
-		Amount:        amount,
-		Currency:      currency,
+		UserID:        req.UserID,
+		Provider:      provider,
+		Amount:        c.Amount,
+		Currency:      c.Currency,
 		Status:        entities.PaymentStatusPending,
-		TransactionID: s.ID,
-		ExtraMetadata: map[string]any{"checkout_url": s.URL},
+		TransactionID: c.TransactionID,
+		ExtraMetadata: c.Metadata,
 	})
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
-	return s.URL, s.ID, nil
+	return c, nil
 }
 
-func (b *BillingService) HandleWebhook(ctx context.Context, payload []byte, sig string) error {
-	evt, err := b.stripe.ConstructEvent(payload, sig, b.cfg.Stripe.WebhookSecret)
+// HandleWebhook verifies a delivery from a provider and processes its event once, however often the
+// provider retries it
+func (b *BillingService) HandleWebhook(ctx context.Context, provider entities.PaymentProvider, req entities.WebhookRequest) error {
+	gw, err := b.gateway(provider)
 	if err != nil {
 		return err
 	}
-	switch evt.Type {
-	case stripe.EventTypeCheckoutSessionCompleted:
-		var s stripe.CheckoutSession
-		err := json.Unmarshal(evt.Data.Raw, &s)
+	e, err := gw.VerifyWebhook(ctx, req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	return b.receiveWebhook(ctx, e)
+}
+
+// receiveWebhook records a delivery and processes it unless an earlier delivery of the event was.
+// The error of a failed event is returned so the provider retries it.
+func (b *BillingService) receiveWebhook(ctx context.Context, e *entities.WebhookEvent) error {
+	recorded, err := b.webhookRepo.Record(ctx, e)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook event: %w", err)
+	}
+	if recorded.Status == entities.WebhookEventProcessed {
+		return nil
+	}
+	return b.processWebhookEvent(ctx, recorded.ID, false)
+}
+
+// ListWebhookEvents returns a page of recorded webhook events, newest first
+func (b *BillingService) ListWebhookEvents(ctx context.Context, provider entities.PaymentProvider, status entities.WebhookEventStatus, offset, limit int32) ([]*entities.WebhookEvent, int, error) {
+	switch status {
+	case "", entities.WebhookEventReceived, entities.WebhookEventProcessed, entities.WebhookEventFailed:
+	default:
+		return nil, 0, ErrInvalidWebhookEventStatus
+	}
+	if limit <= 0 || limit > maxListWebhookEventsLimit {
+		limit = maxListWebhookEventsLimit
+	}
+	return b.webhookRepo.List(ctx, provider, status, offset, limit)
+}
+
+// ReplayWebhookEvent processes a recorded event again, even one that was processed, and returns it
+// with the outcome recorded
+func (b *BillingService) ReplayWebhookEvent(ctx context.Context, id uuid.UUID) (*entities.WebhookEvent, error) {
+	if err := b.processWebhookEvent(ctx, id, true); err != nil {
+		if errors.Is(err, ErrWebhookEventNotFound) {
+			return nil, err
+		}
+		log.Println(fmt.Errorf("replaying webhook event %s: %w", id, err))
+	}
+	e, err := b.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, ErrWebhookEventNotFound
+	}
+	return e, nil
+}
+
+// processWebhookEvent applies an event and marks it processed in one transaction, holding the event's
+// row so concurrent deliveries wait and then skip it. A failure rolls the changes back and is recorded
+// on the event.
+func (b *BillingService) processWebhookEvent(ctx context.Context, id uuid.UUID, replay bool) error {
+	var events []entities.Event
+	err := b.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		webhookRepo := b.webhookRepo.WithTx(tx)
+		e, err := webhookRepo.GetForUpdate(ctx, id)
 		if err != nil {
 			return err
 		}
-		// Update payment status
-		_, _ = b.payRepo.UpdateStatus(ctx, s.ID, entities.PaymentStatusPaid, nil, nil, map[string]any{"customer": s.Customer.ID})
-	case stripe.EventTypeCheckoutSessionExpired:
-		var s stripe.CheckoutSession
-		err := json.Unmarshal(evt.Data.Raw, &s)
-		if err != nil {
+		if e == nil {
+			return ErrWebhookEventNotFound
+		}
+		if e.Status == entities.WebhookEventProcessed && !replay {
+			return nil
+		}
+		if err := b.withTx(tx, &events).applyWebhookEvent(ctx, e); err != nil {
 			return err
 		}
-		_, _ = b.payRepo.UpdateStatus(ctx, s.ID, entities.PaymentStatusFailed, nil, nil, map[string]any{"reason": entities.PaymentStatusExpired})
+		return webhookRepo.MarkProcessed(ctx, e.ID)
+	})
+	if err != nil {
+		if errors.Is(err, ErrWebhookEventNotFound) {
+			return err
+		}
+		if markErr := b.webhookRepo.MarkFailed(ctx, id, err.Error()); markErr != nil {
+			log.Println(fmt.Errorf("failed to mark webhook event %s failed: %w", id, markErr))
+		}
+		return err
+	}
+	for _, e := range events {
+		b.publish(ctx, e)
 	}
 	return nil
 }
 
-func (b *BillingService) GetSubscriptionStatus(ctx context.Context, userID uuid.UUID) (*entities.Subscription, error) {
-	return b.subs.GetByUser(ctx, userID)
+// withTx returns a copy of the service that works in tx and holds its events in events
+func (b *BillingService) withTx(tx pgx.Tx, events *[]entities.Event) *BillingService {
+	c := *b
+	c.subs = b.subs.WithTx(tx)
+	c.payRepo = b.payRepo.WithTx(tx)
+	c.events = events
+	return &c
 }
 
-// CreateDokuPayment initiates a DOKU Jokul checkout and records a pending payment
-func (b *BillingService) CreateDokuPayment(ctx context.Context, userID uuid.UUID, amountIDR int64, invoiceNumber string, paymentDueMinutes int) (string, string, error) {
-	if b.doku == nil {
-		return "", "", fmt.Errorf("doku client not configured")
-	}
-	url, txid, amt, currency, err := b.doku.CreatePayment(ctx, amountIDR, invoiceNumber, paymentDueMinutes)
+// applyWebhookEvent applies what the gateway of the event's provider reads from it
+func (b *BillingService) applyWebhookEvent(ctx context.Context, e *entities.WebhookEvent) error {
+	gw, err := b.gateway(e.Provider)
 	if err != nil {
-		return "", "", err
+		return err
 	}
-	amount := float64(amt) // IDR has no decimals
-	_, err = b.payRepo.Create(ctx, &entities.Payment{
-		UserID:        userID,
-		Provider:      entities.PaymentProviderDoku,
-		Amount:        amount,
-		Currency:      currency,
-		Status:        entities.PaymentStatusPending,
-		TransactionID: txid,
-		ExtraMetadata: map[string]any{"payment_url": url, "invoice_number": invoiceNumber},
-	})
+	u, err := gw.ParseWebhook(ctx, e)
 	if err != nil {
-		return "", "", err
+		return err
+	}
+	if u.Payment != nil {
+		if err := b.applyPaymentUpdate(ctx, u.Payment); err != nil {
+			return err
+		}
 	}
-	return url, txid, nil
+	if u.Subscription != nil {
+		sub, err := b.syncSubscription(ctx, u.Subscription)
+		if err != nil {
+			return err
+		}
+		if sub != nil && u.TrialEnding {
+			b.publish(ctx, entities.SubscriptionTrialEnding{SubscriptionID: sub.ID, UserID: sub.UserID, TrialEnd: *u.Subscription.TrialEnd})
+		}
+	}
+	return nil
 }
 
-// HandleDokuNotification processes DOKU HTTP notification and updates payment status
-func (b *BillingService) HandleDokuNotification(ctx context.Context, invoiceNumber, sessionID, currency, amountStr string, statusStr entities.PaymentStatus) error {
-	txid := sessionID
-	if txid == "" {
-		txid = invoiceNumber
+// applyPaymentUpdate applies the status a webhook reported, once the amount and currency it reported
+// are found to match the payment's. Payments that were not made here, such as a checkout started from
+// the Stripe dashboard, are skipped since retrying cannot find them.
+func (b *BillingService) applyPaymentUpdate(ctx context.Context, u *entities.PaymentUpdate) error {
+	p, err := b.payRepo.GetByTransaction(ctx, u.TransactionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("skipping webhook update of unknown payment %s", u.TransactionID)
+		return nil
+	}
+	if err != nil {
+		return err
 	}
-	if txid == "" {
-		return fmt.Errorf("missing transaction identifier")
+	if err := checkPaymentAmount(p, u); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
 	}
-	var amount *float64
-	if amountStr != "" {
-		// DOKU sends amount as string, convert to float
-		var a float64
-		_, err := fmt.Sscanf(amountStr, "%f", &a)
-		if err == nil {
-			amount = &a
+	_, err = b.updatePaymentStatus(ctx, p.TransactionID, u.Status, u.Amount, u.Currency, u.Metadata)
+	return err
+}
+
+// syncSubscription saves the current state of a provider subscription. Its user is the one the
+// provider knows, then the one of the subscription already saved for its customer; a subscription
+// of no known user, such as one made in the Stripe dashboard, is skipped, and so is an update of a
+// subscription the user has since replaced.
+func (b *BillingService) syncSubscription(ctx context.Context, s *entities.ProviderSubscription) (*entities.Subscription, error) {
+	var userID uuid.UUID
+	if s.UserID != nil {
+		userID = *s.UserID
+	} else if s.CustomerID != "" {
+		existing, err := b.subs.GetByStripeCustomer(ctx, s.CustomerID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		if existing != nil {
+			userID = existing.UserID
 		}
 	}
-	var curr *string
-	if currency != "" {
-		curr = &currency
+	if userID == uuid.Nil {
+		log.Printf("skipping subscription %s: no user found", s.ID)
+		return nil, nil
+	}
+
+	// Stripe is the only provider that sells subscriptions, so they are saved under its ids
+	sub := &entities.Subscription{
+		UserID:               userID,
+		StripeSubscriptionID: &s.ID,
+		Status:               s.Status,
+		CurrentPeriodEnd:     s.CurrentPeriodEnd,
 	}
-	// Map status
-	st := entities.PaymentStatusPending
-	switch statusStr {
-	case entities.PaymentStatusSuccess, entities.PaymentStatusPaid, entities.PaymentStatusCompleted:
-		st = entities.PaymentStatusPaid
-	case entities.PaymentStatusFailed, entities.PaymentStatusExpired:
-		st = entities.PaymentStatusFailed
+	if s.CustomerID != "" {
+		sub.StripeCustomerID = &s.CustomerID
 	}
-	_, err := b.payRepo.UpdateStatus(ctx, txid, st, amount, curr, map[string]any{"provider": entities.PaymentProviderDoku})
-	return err
+	saved, err := b.subs.Upsert(ctx, sub)
+	if err == nil && saved == nil {
+		log.Printf("skipping update of subscription %s: user %s has another current subscription", s.ID, userID)
+	}
+	return saved, err
+}
+
+func (b *BillingService) GetSubscriptionStatus(ctx context.Context, userID uuid.UUID) (*entities.Subscription, error) {
+	return b.subs.GetByUser(ctx, userID)
+}
+
+// checkPaymentAmount rejects a provider report whose amount or currency differs from what was charged,
+// such as a notification for another payment
+func checkPaymentAmount(p *entities.Payment, u *entities.PaymentUpdate) error {
+	amount, currency := u.Amount, u.Currency
+	if u.AmountRequired && (amount == nil || currency == nil) {
+		return errors.New("amount or currency is missing")
+	}
+	if amount != nil && math.Abs(*amount-p.Amount) > 0.005 {
+		return fmt.Errorf("amount %.2f does not match payment amount %.2f", *amount, p.Amount)
+	}
+	if currency != nil && p.Currency != "" && !strings.EqualFold(*currency, p.Currency) {
+		return fmt.Errorf("currency %s does not match payment currency %s", *currency, p.Currency)
+	}
+	return nil
 }
 
 // RefreshPaymentStatus checks provider for latest status and updates payment; returns status string
 func (b *BillingService) RefreshPaymentStatus(ctx context.Context, userID uuid.UUID, txid string, provider entities.PaymentProvider) (string, error) {
-	p, err := b.payRepo.GetByTransaction(ctx, txid)
+	p, err := b.userPayment(ctx, userID, txid, provider)
+	if err != nil {
+		return "", err
+	}
+	gw, err := b.gateway(p.Provider)
+	if err != nil {
+		return "", err
+	}
+	u, err := gw.GetPaymentStatus(ctx, p)
 	if err != nil {
 		return "", err
 	}
-	if p.UserID != userID {
-		return "", fmt.Errorf("not owner")
+	if err := checkPaymentAmount(p, u); err != nil {
+		return "", err
 	}
-	switch provider {
-	case entities.PaymentProviderStripe:
-		sess, err := b.stripe.GetCheckoutSession(txid)
-		if err != nil {
+	if u.Status != entities.PaymentStatusPending {
+		if _, err := b.updatePaymentStatus(ctx, p.TransactionID, u.Status, u.Amount, u.Currency, u.Metadata); err != nil {
 			return "", err
 		}
-		var newStatus entities.PaymentStatus
-		switch sess.Status {
-		case stripe.CheckoutSessionStatusComplete:
-			newStatus = entities.PaymentStatusPaid
-			_, _ = b.payRepo.UpdateStatus(ctx, txid, newStatus, nil, nil, map[string]any{"provider": entities.PaymentProviderStripe})
-		case stripe.CheckoutSessionStatusExpired:
-			newStatus = entities.PaymentStatusFailed
-			_, _ = b.payRepo.UpdateStatus(ctx, txid, newStatus, nil, nil, map[string]any{"provider": entities.PaymentProviderStripe, "reason": entities.PaymentStatusExpired})
-		default:
-			// pending
-			newStatus = entities.PaymentStatusPending
-		}
-		return string(newStatus), nil
-	case entities.PaymentProviderDoku:
-		// DOKU doesn't provide a simple status fetch in Jokul; rely on notification
-		// Here we just return current stored status
-		return string(p.Status), nil
 	}
-	return string(p.Status), nil
+	return string(u.Status), nil
+}
+
+// CancelPayment stops a pending payment of the user from being completed and marks it failed
+func (b *BillingService) CancelPayment(ctx context.Context, userID uuid.UUID, txid string, provider entities.PaymentProvider) (*entities.Payment, error) {
+	p, err := b.userPayment(ctx, userID, txid, provider)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != entities.PaymentStatusPending {
+		return nil, fmt.Errorf("%w: payment is %s", ErrPaymentStatus, p.Status)
+	}
+	gw, err := b.gateway(p.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.Cancel(ctx, p); err != nil {
+		return nil, err
+	}
+	return b.updatePaymentStatus(ctx, p.TransactionID, entities.PaymentStatusFailed, nil, nil, map[string]any{"reason": entities.PaymentStatusCanceled})
+}
+
+// RefundPayment returns the full amount of a paid payment and marks it refunded
+func (b *BillingService) RefundPayment(ctx context.Context, txid string, provider entities.PaymentProvider) (*entities.Payment, error) {
+	p, err := b.payRepo.GetByTransaction(ctx, txid)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && p.Provider != provider) {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != entities.PaymentStatusPaid {
+		return nil, fmt.Errorf("%w: payment is %s", ErrPaymentStatus, p.Status)
+	}
+	gw, err := b.gateway(p.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.Refund(ctx, p); err != nil {
+		return nil, err
+	}
+	return b.updatePaymentStatus(ctx, p.TransactionID, entities.PaymentStatusRefunded, nil, nil, map[string]any{"refunded_at": time.Now().UTC().Format(time.RFC3339)})
+}
+
+// userPayment returns a payment the user made with provider
+func (b *BillingService) userPayment(ctx context.Context, userID uuid.UUID, txid string, provider entities.PaymentProvider) (*entities.Payment, error) {
+	p, err := b.payRepo.GetByTransaction(ctx, txid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.UserID != userID || p.Provider != provider {
+		return nil, ErrPaymentNotFound
+	}
+	return p, nil
 }
 
 // CheckDailySubscriptions scans and updates subscription statuses based on period end
@@ -188,7 +376,9 @@ func (b *BillingService) CheckDailySubscriptions(ctx context.Context) (updated i
 	for _, s := range subs {
 		if s.CurrentPeriodEnd != nil && now.After(*s.CurrentPeriodEnd) && s.Status != entities.PaymentStatusExpired {
 			s.Status = entities.PaymentStatusExpired
-			_, _ = b.subs.Upsert(ctx, s)
+			if _, err := b.subs.Upsert(ctx, s); err == nil {
+				b.publish(ctx, entities.SubscriptionExpired{SubscriptionID: s.ID, UserID: s.UserID, PeriodEnd: *s.CurrentPeriodEnd})
+			}
 			expired++
 		} else {
 			updated++
@@ -196,3 +386,41 @@ func (b *BillingService) CheckDailySubscriptions(ctx context.Context) (updated i
 	}
 	return
 }
+
+// updatePaymentStatus applies the status a provider reported and publishes PaymentStatusChanged
+// when the payment was in another status before
+func (b *BillingService) updatePaymentStatus(ctx context.Context, txid string, status entities.PaymentStatus, amount *float64, currency *string, metadata map[string]any) (*entities.Payment, error) {
+	prev, err := b.payRepo.GetByTransaction(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	p, err := b.payRepo.UpdateStatus(ctx, txid, status, amount, currency, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != prev.Status {
+		b.publish(ctx, entities.PaymentStatusChanged{
+			PaymentID:      p.ID,
+			UserID:         p.UserID,
+			Provider:       p.Provider,
+			TransactionID:  p.TransactionID,
+			Amount:         p.Amount,
+			Currency:       p.Currency,
+			Status:         p.Status,
+			PreviousStatus: prev.Status,
+		})
+	}
+	return p, nil
+}
+
+// publish only logs failed subscribers; the change they react to is already saved. While a webhook event
+// is processed, events are held until it commits.
+func (b *BillingService) publish(ctx context.Context, e entities.Event) {
+	if b.events != nil {
+		*b.events = append(*b.events, e)
+		return
+	}
+	if err := b.bus.Publish(ctx, e); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+// fakeGateway stands in for a payment provider. Every delivery carries the event eventID and reports update.
+type fakeGateway struct {
+	checkout *entities.Checkout
+	eventID  string
+	update   *entities.WebhookUpdate
+	refunded []string
+	canceled []string
+}
+
+func (g *fakeGateway) CreateCheckout(context.Context, entities.CheckoutRequest) (*entities.Checkout, error) {
+	return g.checkout, nil
+}
+
+func (g *fakeGateway) GetPaymentStatus(context.Context, *entities.Payment) (*entities.PaymentUpdate, error) {
+	return g.update.Payment, nil
+}
+
+func (g *fakeGateway) VerifyWebhook(_ context.Context, req entities.WebhookRequest) (*entities.WebhookEvent, error) {
+	if string(req.Payload) == "forged" {
+		return nil, errors.New("signature mismatch")
+	}
+	return &entities.WebhookEvent{Provider: entities.PaymentProviderDoku, EventID: g.eventID, Payload: req.Payload}, nil
+}
+
+func (g *fakeGateway) ParseWebhook(context.Context, *entities.WebhookEvent) (*entities.WebhookUpdate, error) {
+	return g.update, nil
+}
+
+func (g *fakeGateway) Refund(_ context.Context, p *entities.Payment) error {
+	g.refunded = append(g.refunded, p.TransactionID)
+	return nil
+}
+
+func (g *fakeGateway) Cancel(_ context.Context, p *entities.Payment) error {
+	g.canceled = append(g.canceled, p.TransactionID)
+	return nil
+}
+
+type fakePayments struct {
+	repositories.PaymentRepository
+	byTx    map[string]*entities.Payment
+	updates int
+}
+
+func (f *fakePayments) WithTx(pgx.Tx) repositories.PaymentRepository { return f }
+
+func (f *fakePayments) Create(_ context.Context, p *entities.Payment) (*entities.Payment, error) {
+	p.ID = uuid.New()
+	f.byTx[p.TransactionID] = p
+	return p, nil
+}
+
+func (f *fakePayments) GetByTransaction(_ context.Context, txid string) (*entities.Payment, error) {
+	p, ok := f.byTx[txid]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	c := *p
+	return &c, nil
+}
+
+func (f *fakePayments) UpdateStatus(_ context.Context, txid string, status entities.PaymentStatus, _ *float64, _ *string, _ map[string]any) (*entities.Payment, error) {
+	p, ok := f.byTx[txid]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	f.updates++
+	p.Status = status
+	c := *p
+	return &c, nil
+}
+
+type fakeWebhookEvents struct {
+	repositories.WebhookEventRepository
+	events []*entities.WebhookEvent
+}
+
+func (f *fakeWebhookEvents) WithTx(pgx.Tx) repositories.WebhookEventRepository { return f }
+
+func (f *fakeWebhookEvents) Record(_ context.Context, e *entities.WebhookEvent) (*entities.WebhookEvent, error) {
+	for _, saved := range f.events {
+		if saved.Provider == e.Provider && saved.EventID == e.EventID {
+			saved.Attempts++
+			c := *saved
+			return &c, nil
+		}
+	}
+	e.ID, e.Status, e.Attempts = uuid.New(), entities.WebhookEventReceived, 1
+	f.events = append(f.events, e)
+	c := *e
+	return &c, nil
+}
+
+func (f *fakeWebhookEvents) GetForUpdate(_ context.Context, id uuid.UUID) (*entities.WebhookEvent, error) {
+	for _, e := range f.events {
+		if e.ID == id {
+			c := *e
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeWebhookEvents) MarkProcessed(_ context.Context, id uuid.UUID) error {
+	for _, saved := range f.events {
+		if saved.ID == id {
+			saved.Status, saved.LastError = entities.WebhookEventProcessed, ""
+		}
+	}
+	return nil
+}
+
+func (f *fakeWebhookEvents) MarkFailed(_ context.Context, id uuid.UUID, lastError string) error {
+	for _, saved := range f.events {
+		if saved.ID == id {
+			saved.Status, saved.LastError = entities.WebhookEventFailed, lastError
+		}
+	}
+	return nil
+}
+
+type fakeSubscriptions struct {
+	repositories.SubscriptionRepository
+}
+
+func (f *fakeSubscriptions) WithTx(pgx.Tx) repositories.SubscriptionRepository { return f }
+
+// fakeTxManager runs fn without a transaction; the fakes ignore the tx they are given
+type fakeTxManager struct {
+	repositories.TransactionManager
+}
+
+func (fakeTxManager) ExecuteInTransaction(_ context.Context, fn func(tx pgx.Tx) error) error {
+	return fn(nil)
+}
+
+type billingTest struct {
+	svc      *BillingService
+	gw       *fakeGateway
+	payments *fakePayments
+	webhooks *fakeWebhookEvents
+	// changed holds the PaymentStatusChanged events published
+	changed []entities.PaymentStatusChanged
+}
+
+func newTestBillingService(t *testing.T) *billingTest {
+	t.Helper()
+	bt := &billingTest{
+		gw: &fakeGateway{
+			checkout: &entities.Checkout{URL: "https://pay.example/INV-1", TransactionID: "INV-1", Amount: 150000, Currency: "IDR"},
+			eventID:  "evt-1",
+		},
+		payments: &fakePayments{byTx: map[string]*entities.Payment{}},
+		webhooks: &fakeWebhookEvents{},
+	}
+	bus := NewEventBus()
+	Subscribe(bus, func(_ context.Context, e entities.PaymentStatusChanged) error {
+		bt.changed = append(bt.changed, e)
+		return nil
+	})
+	bt.svc = NewBillingService(&fakeSubscriptions{}, bt.payments, bt.webhooks, fakeTxManager{},
+		map[entities.PaymentProvider]repositories.PaymentGateway{entities.PaymentProviderDoku: bt.gw}, bus)
+	return bt
+}
+
+// checkout starts the payment INV-1 of userID
+func (bt *billingTest) checkout(t *testing.T, userID uuid.UUID) {
+	t.Helper()
+	if _, err := bt.svc.CreateCheckout(context.Background(), entities.PaymentProviderDoku, entities.CheckoutRequest{UserID: userID, Amount: 150000, Reference: "INV-1"}); err != nil {
+		t.Fatalf("CreateCheckout: %v", err)
+	}
+}
+
+// paidUpdate reports INV-1 paid with amount and currency
+func paidUpdate(amount *float64, currency *string) *entities.WebhookUpdate {
+	return &entities.WebhookUpdate{Payment: &entities.PaymentUpdate{
+		TransactionID:  "INV-1",
+		Status:         entities.PaymentStatusPaid,
+		Amount:         amount,
+		Currency:       currency,
+		AmountRequired: true,
+	}}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestBillingCreateCheckoutRecordsPendingPayment(t *testing.T) {
+	bt := newTestBillingService(t)
+	userID := uuid.New()
+	bt.checkout(t, userID)
+
+	p := bt.payments.byTx["INV-1"]
+	if p == nil {
+		t.Fatal("no payment recorded")
+	}
+	if p.Status != entities.PaymentStatusPending || p.UserID != userID || p.Amount != 150000 || p.Currency != "IDR" || p.Provider != entities.PaymentProviderDoku {
+		t.Errorf("payment = %+v, want a pending IDR 150000 doku payment of the user", p)
+	}
+
+	if _, err := bt.svc.CreateCheckout(context.Background(), entities.PaymentProviderStripe, entities.CheckoutRequest{UserID: userID}); !errors.Is(err, ErrPaymentProviderUnavailable) {
+		t.Errorf("checkout with an unconfigured provider: err = %v, want ErrPaymentProviderUnavailable", err)
+	}
+}
+
+func TestBillingWebhookIsAppliedOnce(t *testing.T) {
+	bt := newTestBillingService(t)
+	bt.checkout(t, uuid.New())
+	bt.gw.update = paidUpdate(ptr(150000.0), ptr("idr"))
+
+	// The provider retries a delivery it saw no answer to
+	for i := 0; i < 2; i++ {
+		if err := bt.svc.HandleWebhook(context.Background(), entities.PaymentProviderDoku, entities.WebhookRequest{Payload: []byte("paid")}); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+
+	if got := bt.payments.byTx["INV-1"].Status; got != entities.PaymentStatusPaid {
+		t.Errorf("payment status = %s, want paid", got)
+	}
+	if bt.payments.updates != 1 {
+		t.Errorf("payment updated %d times, want 1", bt.payments.updates)
+	}
+	if len(bt.changed) != 1 || bt.changed[0].PreviousStatus != entities.PaymentStatusPending {
+		t.Errorf("published %+v, want one change from pending", bt.changed)
+	}
+	if len(bt.webhooks.events) != 1 || bt.webhooks.events[0].Status != entities.WebhookEventProcessed || bt.webhooks.events[0].Attempts != 2 {
+		t.Errorf("recorded events %+v, want one processed event delivered twice", bt.webhooks.events)
+	}
+
+	if err := bt.svc.HandleWebhook(context.Background(), entities.PaymentProviderDoku, entities.WebhookRequest{Payload: []byte("forged")}); !errors.Is(err, ErrInvalidWebhook) {
+		t.Errorf("forged delivery: err = %v, want ErrInvalidWebhook", err)
+	}
+}
+
+func TestBillingWebhookRejectsAmountMismatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   *float64
+		currency *string
+	}{
+		{"other amount", ptr(1500.0), ptr("IDR")},
+		{"other currency", ptr(150000.0), ptr("USD")},
+		{"missing amount", nil, ptr("IDR")},
+		{"missing currency", ptr(150000.0), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bt := newTestBillingService(t)
+			bt.checkout(t, uuid.New())
+			bt.gw.update = paidUpdate(tt.amount, tt.currency)
+
+			err := bt.svc.HandleWebhook(context.Background(), entities.PaymentProviderDoku, entities.WebhookRequest{Payload: []byte("paid")})
+			if !errors.Is(err, ErrInvalidWebhook) {
+				t.Fatalf("err = %v, want ErrInvalidWebhook", err)
+			}
+			if got := bt.payments.byTx["INV-1"].Status; got != entities.PaymentStatusPending {
+				t.Errorf("payment status = %s, want pending", got)
+			}
+			if len(bt.changed) != 0 {
+				t.Errorf("published %+v, want nothing", bt.changed)
+			}
+			if e := bt.webhooks.events[0]; e.Status != entities.WebhookEventFailed || e.LastError == "" {
+				t.Errorf("event = %+v, want failed with its error", e)
+			}
+		})
+	}
+}
+
+func TestBillingRefundPayment(t *testing.T) {
+	bt := newTestBillingService(t)
+	bt.checkout(t, uuid.New())
+
+	if _, err := bt.svc.RefundPayment(context.Background(), "INV-1", entities.PaymentProviderDoku); !errors.Is(err, ErrPaymentStatus) {
+		t.Fatalf("refunding a pending payment: err = %v, want ErrPaymentStatus", err)
+	}
+	if len(bt.gw.refunded) != 0 {
+		t.Fatalf("gateway refunded %v", bt.gw.refunded)
+	}
+
+	bt.payments.byTx["INV-1"].Status = entities.PaymentStatusPaid
+	p, err := bt.svc.RefundPayment(context.Background(), "INV-1", entities.PaymentProviderDoku)
+	if err != nil {
+		t.Fatalf("RefundPayment: %v", err)
+	}
+	if p.Status != entities.PaymentStatusRefunded {
+		t.Errorf("payment status = %s, want refunded", p.Status)
+	}
+	if len(bt.gw.refunded) != 1 || bt.gw.refunded[0] != "INV-1" {
+		t.Errorf("gateway refunded %v, want [INV-1]", bt.gw.refunded)
+	}
+
+	if _, err := bt.svc.RefundPayment(context.Background(), "INV-1", entities.PaymentProviderStripe); !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("refunding under another provider: err = %v, want ErrPaymentNotFound", err)
+	}
+}
+
+func TestBillingCancelPayment(t *testing.T) {
+	bt := newTestBillingService(t)
+	userID := uuid.New()
+	bt.checkout(t, userID)
+
+	if _, err := bt.svc.CancelPayment(context.Background(), uuid.New(), "INV-1", entities.PaymentProviderDoku); !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("canceling another user's payment: err = %v, want ErrPaymentNotFound", err)
+	}
+
+	p, err := bt.svc.CancelPayment(context.Background(), userID, "INV-1", entities.PaymentProviderDoku)
+	if err != nil {
+		t.Fatalf("CancelPayment: %v", err)
+	}
+	if p.Status != entities.PaymentStatusFailed {
+		t.Errorf("payment status = %s, want failed", p.Status)
+	}
+	if len(bt.gw.canceled) != 1 || bt.gw.canceled[0] != "INV-1" {
+		t.Errorf("gateway canceled %v, want [INV-1]", bt.gw.canceled)
+	}
+
+	if _, err := bt.svc.CancelPayment(context.Background(), userID, "INV-1", entities.PaymentProviderDoku); !errors.Is(err, ErrPaymentStatus) {
+		t.Errorf("canceling a failed payment: err = %v, want ErrPaymentStatus", err)
+	}
+}
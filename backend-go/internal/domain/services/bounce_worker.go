@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+// BounceMailboxWorker suppresses the recipients of bounces and complaints found in a mailbox
+type BounceMailboxWorker struct {
+	interval    time.Duration
+	mailbox     repositories.BounceMailbox
+	suppression *EmailSuppressionService
+}
+
+func NewBounceMailboxWorker(interval time.Duration, mailbox repositories.BounceMailbox, suppression *EmailSuppressionService) *BounceMailboxWorker {
+	return &BounceMailboxWorker{
+		interval:    interval,
+		mailbox:     mailbox,
+		suppression: suppression,
+	}
+}
+
+// Run polls the mailbox until ctx is cancelled
+func (w *BounceMailboxWorker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.poll(ctx); err != nil {
+			log.Println(fmt.Errorf("failed to process bounce mailbox: %w", err))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll records the new reports. The mailbox only moves past them once they are recorded, so
+// reports are read again after a database error.
+func (w *BounceMailboxWorker) poll(ctx context.Context) error {
+	return w.mailbox.Read(ctx, func(ctx context.Context, events []entities.BounceEvent) error {
+		suppressed, err := w.suppression.RecordBounces(ctx, events)
+		if suppressed > 0 {
+			log.Printf("suppressed %d addresses from %d bounce reports", suppressed, len(events))
+		}
+		return err
+	})
+}
@@ -0,0 +1,557 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/config"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+const (
+	maxListCampaignsLimit = 100
+	// campaignAudiencePage is how many users are read at a time while a run is materialized
+	campaignAudiencePage = 500
+)
+
+// campaignReservedFields are filled by the platform in account messages, such as password reset
+// links and one-time codes, and cannot be set by a campaign
+var campaignReservedFields = []string{"link", "code"}
+
+// CampaignService schedules reminders and promotions, and runs them by turning each run into
+// outbox messages. A campaign belongs to the salon selected for the request, or to the user who
+// created it when none is.
+type CampaignService struct {
+	cfg          *config.Config
+	campaignRepo repositories.CampaignRepository
+	emailTplRepo repositories.EmailTemplateRepository
+	orgRepo      repositories.OrganizationRepository
+	userRepo     repositories.UserRepository
+	outboxRepo   repositories.OutboxRepository
+	txManager    repositories.TransactionManager
+	notifier     *Notifier
+}
+
+func NewCampaignService(
+	cfg *config.Config,
+	campaignRepo repositories.CampaignRepository,
+	emailTplRepo repositories.EmailTemplateRepository,
+	orgRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	outboxRepo repositories.OutboxRepository,
+	txManager repositories.TransactionManager,
+	notifier *Notifier,
+) *CampaignService {
+	return &CampaignService{
+		cfg:          cfg,
+		campaignRepo: campaignRepo,
+		emailTplRepo: emailTplRepo,
+		orgRepo:      orgRepo,
+		userRepo:     userRepo,
+		outboxRepo:   outboxRepo,
+		txManager:    txManager,
+		notifier:     notifier,
+	}
+}
+
+// CreateCampaign schedules c for the salon orgID, or for the user when orgID is nil.
+// Phone numbers among the recipients are stored in E.164.
+func (s *CampaignService) CreateCampaign(ctx context.Context, user *entities.User, orgID *uuid.UUID, c *entities.Campaign) (*entities.Campaign, error) {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidCampaign)
+	}
+	if !slices.Contains(entities.CampaignChannels, c.Channel) {
+		return nil, fmt.Errorf("%w: channel must be email, whatsapp or sms", ErrInvalidCampaign)
+	}
+	if c.Category != entities.NotificationCategoryReminder && c.Category != entities.NotificationCategoryMarketing {
+		return nil, fmt.Errorf("%w: category must be reminder or marketing", ErrInvalidCampaign)
+	}
+	if c.RatePerMinute <= 0 {
+		return nil, fmt.Errorf("%w: rate_per_minute must be positive", ErrInvalidCampaign)
+	}
+	for _, field := range campaignReservedFields {
+		if _, ok := c.Fields[field]; ok {
+			return nil, fmt.Errorf("%w: fields cannot set %s", ErrInvalidCampaign, field)
+		}
+	}
+	if c.TemplateName.IsPhone() != (c.Channel != entities.MessageChannelEmail) {
+		return nil, fmt.Errorf("%w: email campaigns need an email template, whatsapp and sms ones a phone template", ErrInvalidCampaign)
+	}
+	tpl, err := s.emailTplRepo.GetByName(ctx, c.TemplateName, util.LocaleFallbacks())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	if !tpl.Campaign {
+		return nil, ErrCampaignTemplateDenied
+	}
+	if err := s.validateAudience(ctx, user, orgID, c); err != nil {
+		return nil, err
+	}
+	next, err := firstCampaignRun(c, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	c.OrganizationID = orgID
+	c.CreatedBy = &user.ID
+	c.NextRunAt = next
+	return s.campaignRepo.Create(ctx, c)
+}
+
+// validateAudience lets superusers send to any address. Others can only list members of their
+// salon as recipients, since a campaign goes out from the platform's address and number.
+func (s *CampaignService) validateAudience(ctx context.Context, user *entities.User, orgID *uuid.UUID, c *entities.Campaign) error {
+	a := &c.Audience
+	if len(a.MemberRoles) == 0 && a.Users == nil && len(a.Recipients) == 0 {
+		return fmt.Errorf("%w: audience is empty", ErrInvalidCampaign)
+	}
+	for _, role := range a.MemberRoles {
+		if orgID == nil {
+			return fmt.Errorf("%w: member_roles needs an organization", ErrInvalidCampaign)
+		}
+		if role != entities.OrganizationRoleOwner && role != entities.OrganizationRoleEmployee {
+			return fmt.Errorf("%w: unknown member role %q", ErrInvalidCampaign, role)
+		}
+	}
+	superuser := util.HasRole(user, string(entities.RoleSuperuser))
+	if a.Users != nil && !superuser {
+		return ErrCampaignAudienceDenied
+	}
+	if limit := s.cfg.Campaign.MaxAudience; len(a.Recipients) > limit {
+		return fmt.Errorf("%w: at most %d recipients", ErrInvalidCampaign, limit)
+	}
+	for i, r := range a.Recipients {
+		address, ok := campaignAddress(c.Channel, r, a.Region)
+		if !ok {
+			return fmt.Errorf("%w: recipient %q is not a valid %s address", ErrInvalidCampaign, r, c.Channel)
+		}
+		a.Recipients[i] = address
+	}
+	if len(a.Recipients) == 0 || superuser {
+		return nil
+	}
+	if orgID == nil {
+		return ErrCampaignRecipientDenied
+	}
+	members, err := s.orgRepo.ListMembers(ctx, *orgID)
+	if err != nil {
+		return fmt.Errorf("failed to list organization members: %w", err)
+	}
+	known := make(map[string]bool, len(members))
+	for _, m := range members {
+		known[strings.ToLower(m.Email)] = true
+		if m.PhoneNumber != nil {
+			known[*m.PhoneNumber] = true
+		}
+	}
+	for _, address := range a.Recipients {
+		if !known[address] {
+			return fmt.Errorf("%w: %s", ErrCampaignRecipientDenied, address)
+		}
+	}
+	return nil
+}
+
+// campaignAddress normalizes an email address, or a phone number for whatsapp and sms
+func campaignAddress(channel entities.MessageChannel, address, region string) (string, bool) {
+	if channel == entities.MessageChannelEmail {
+		addr, err := mail.ParseAddress(address)
+		if err != nil {
+			return "", false
+		}
+		return strings.ToLower(addr.Address), true
+	}
+	return util.NormalizeE164(address, region)
+}
+
+// firstCampaignRun validates the schedule and returns when the campaign first runs.
+// A send_at in the past runs right away.
+func firstCampaignRun(c *entities.Campaign, now time.Time) (*time.Time, error) {
+	if (c.SendAt == nil) == (c.CronExpression == "") {
+		return nil, fmt.Errorf("%w: set either send_at or cron_expression", ErrInvalidCampaignSchedule)
+	}
+	if c.Timezone == "" {
+		c.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidCampaignSchedule, c.Timezone)
+	}
+	if c.SendAt != nil {
+		return c.SendAt, nil
+	}
+	schedule, err := util.ParseCron(c.CronExpression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCampaignSchedule, err)
+	}
+	next := schedule.Next(now.In(loc))
+	if next.IsZero() {
+		return nil, fmt.Errorf("%w: cron_expression never runs", ErrInvalidCampaignSchedule)
+	}
+	return &next, nil
+}
+
+// nextCampaignRun returns the run after now of a recurring campaign, nil for a one-off one
+func nextCampaignRun(c *entities.Campaign, now time.Time) *time.Time {
+	if c.CronExpression == "" {
+		return nil
+	}
+	schedule, err := util.ParseCron(c.CronExpression)
+	if err != nil {
+		return nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	next := schedule.Next(now.In(loc))
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// ListCampaigns returns a page of the salon's campaigns, or of the user's own when orgID is nil, newest first
+func (s *CampaignService) ListCampaigns(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID, offset, limit int32) ([]*entities.Campaign, int, error) {
+	if limit <= 0 || limit > maxListCampaignsLimit {
+		limit = maxListCampaignsLimit
+	}
+	return s.campaignRepo.List(ctx, orgID, userID, offset, limit)
+}
+
+// GetCampaign returns a campaign of the salon, or of the user when orgID is nil, with its delivery stats
+func (s *CampaignService) GetCampaign(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID, id uuid.UUID) (*entities.Campaign, *entities.CampaignStats, error) {
+	c, err := s.campaignRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !campaignOwnedBy(c, userID, orgID) {
+		return nil, nil, ErrCampaignNotFound
+	}
+	stats, err := s.campaignRepo.Stats(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, stats, nil
+}
+
+// PauseCampaign stops a campaign from running and holds its queued messages
+func (s *CampaignService) PauseCampaign(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID, id uuid.UUID) (*entities.Campaign, error) {
+	return s.changeStatus(ctx, userID, orgID, id, func(repo repositories.CampaignRepository, c *entities.Campaign) (*entities.Campaign, error) {
+		if c.Status != entities.CampaignScheduled && c.Status != entities.CampaignCompleted {
+			return nil, ErrCampaignStatus
+		}
+		return repo.SetStatus(ctx, c.ID, entities.CampaignPaused, c.NextRunAt)
+	})
+}
+
+// ResumeCampaign lets a paused campaign run again. Runs of a recurring campaign missed while it was
+// paused are skipped, and its queued messages are spread out again from now.
+func (s *CampaignService) ResumeCampaign(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID, id uuid.UUID) (*entities.Campaign, error) {
+	return s.changeStatus(ctx, userID, orgID, id, func(repo repositories.CampaignRepository, c *entities.Campaign) (*entities.Campaign, error) {
+		if c.Status != entities.CampaignPaused {
+			return nil, ErrCampaignStatus
+		}
+		now := time.Now()
+		next := c.NextRunAt
+		if next != nil && next.Before(now) && c.CronExpression != "" {
+			next = nextCampaignRun(c, now)
+		}
+		status := entities.CampaignScheduled
+		if next == nil {
+			status = entities.CampaignCompleted
+		}
+		if err := repo.RespaceMessages(ctx, c.ID, campaignSpacing(c)); err != nil {
+			return nil, fmt.Errorf("failed to respace campaign messages: %w", err)
+		}
+		return repo.SetStatus(ctx, c.ID, status, next)
+	})
+}
+
+// CancelCampaign ends a campaign for good; its messages that have not gone out yet are dropped
+func (s *CampaignService) CancelCampaign(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID, id uuid.UUID) (*entities.Campaign, error) {
+	return s.changeStatus(ctx, userID, orgID, id, func(repo repositories.CampaignRepository, c *entities.Campaign) (*entities.Campaign, error) {
+		if c.Status == entities.CampaignCancelled {
+			return nil, ErrCampaignStatus
+		}
+		if _, err := repo.CancelMessages(ctx, c.ID); err != nil {
+			return nil, fmt.Errorf("failed to cancel campaign messages: %w", err)
+		}
+		return repo.SetStatus(ctx, c.ID, entities.CampaignCancelled, nil)
+	})
+}
+
+// changeStatus applies change to the campaign while it is locked, so it cannot race a run
+func (s *CampaignService) changeStatus(
+	ctx context.Context,
+	userID uuid.UUID,
+	orgID *uuid.UUID,
+	id uuid.UUID,
+	change func(repositories.CampaignRepository, *entities.Campaign) (*entities.Campaign, error),
+) (*entities.Campaign, error) {
+	var updated *entities.Campaign
+	err := s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		repo := s.campaignRepo.WithTx(tx)
+		c, err := repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !campaignOwnedBy(c, userID, orgID) {
+			return ErrCampaignNotFound
+		}
+		updated, err = change(repo, c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func campaignOwnedBy(c *entities.Campaign, userID uuid.UUID, orgID *uuid.UUID) bool {
+	if c == nil {
+		return false
+	}
+	if orgID != nil {
+		return c.OrganizationID != nil && *c.OrganizationID == *orgID
+	}
+	return c.OrganizationID == nil && c.CreatedBy != nil && *c.CreatedBy == userID
+}
+
+// campaignSpacing is the time between two messages of a run, zero when they all go at once
+func campaignSpacing(c *entities.Campaign) time.Duration {
+	if c.RatePerMinute <= 0 {
+		return 0
+	}
+	return time.Minute / time.Duration(c.RatePerMinute)
+}
+
+// Run looks for due campaigns until ctx is cancelled. Several instances can run side by side;
+// each run is made by one of them.
+func (s *CampaignService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Campaign.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.RunDue(ctx); err != nil {
+			log.Println(fmt.Errorf("failed to run due campaigns: %w", err))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue makes the runs of the campaigns that are due. A run that fails is tried again on the next poll.
+func (s *CampaignService) RunDue(ctx context.Context) error {
+	due, err := s.campaignRepo.ListDue(ctx, max(s.cfg.Campaign.BatchSize, 1))
+	if err != nil {
+		return err
+	}
+	for _, c := range due {
+		if err := s.runCampaign(ctx, c); err != nil {
+			log.Println(fmt.Errorf("failed to run campaign %s: %w", c.ID, err))
+		}
+	}
+	return nil
+}
+
+// runCampaign queues a message for every address of the audience and moves the campaign to its
+// next run, in one transaction. The transaction is scoped to the campaign's salon so its members
+// can be read.
+func (s *CampaignService) runCampaign(ctx context.Context, due *entities.Campaign) error {
+	if due.OrganizationID != nil {
+		ctx = util.WithOrganizationID(ctx, *due.OrganizationID)
+	}
+	return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		campaignRepo := s.campaignRepo.WithTx(tx)
+		c, err := campaignRepo.LockDue(ctx, due.ID)
+		if err != nil || c == nil {
+			return err
+		}
+		audience, err := s.audience(ctx, tx, c)
+		if err != nil {
+			return err
+		}
+
+		outboxRepo := s.outboxRepo.WithTx(tx)
+		templates := make(map[string]*entities.EmailTemplate)
+		start, spacing := time.Now(), campaignSpacing(c)
+		for i, r := range audience {
+			msg, err := s.campaignMessage(ctx, c, r, templates)
+			if err != nil {
+				return err
+			}
+			msg.NextAttemptAt = start.Add(time.Duration(i) * spacing)
+			if _, err := outboxRepo.Enqueue(ctx, msg); err != nil {
+				return fmt.Errorf("failed to enqueue campaign message: %w", err)
+			}
+		}
+
+		next := nextCampaignRun(c, start)
+		status := entities.CampaignScheduled
+		if next == nil {
+			status = entities.CampaignCompleted
+		}
+		_, err = campaignRepo.CompleteRun(ctx, c.ID, next, status)
+		return err
+	})
+}
+
+// campaignRecipient is one address of a run's audience
+type campaignRecipient struct {
+	userID  *uuid.UUID
+	name    string
+	locale  string
+	address string
+}
+
+// audience resolves the campaign's audience into addresses for its channel, each once, up to
+// the configured maximum. Members and users without a phone number are left out of whatsapp
+// and sms campaigns.
+func (s *CampaignService) audience(ctx context.Context, tx pgx.Tx, c *entities.Campaign) ([]campaignRecipient, error) {
+	var out []campaignRecipient
+	limit := max(s.cfg.Campaign.MaxAudience, 1)
+	seen := make(map[string]bool)
+	add := func(r campaignRecipient) {
+		key := strings.ToLower(r.address)
+		if r.address == "" || seen[key] || len(out) >= limit {
+			return
+		}
+		seen[key] = true
+		out = append(out, r)
+	}
+	defer func() {
+		if len(out) >= limit {
+			log.Printf("campaign %s reached the audience limit of %d, the rest of its audience is left out", c.ID, limit)
+		}
+	}()
+	phone := c.Channel != entities.MessageChannelEmail
+	addressOf := func(email string, phoneNumber *string) string {
+		if !phone {
+			return email
+		}
+		if phoneNumber == nil {
+			return ""
+		}
+		return *phoneNumber
+	}
+
+	if len(c.Audience.MemberRoles) > 0 && c.OrganizationID != nil {
+		members, err := s.orgRepo.WithTx(tx).ListMembers(ctx, *c.OrganizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization members: %w", err)
+		}
+		for _, m := range members {
+			if !slices.Contains(c.Audience.MemberRoles, m.Role) {
+				continue
+			}
+			userID := m.UserID
+			r := campaignRecipient{userID: &userID, address: addressOf(m.Email, m.PhoneNumber)}
+			if m.FullName != nil {
+				r.name = *m.FullName
+			}
+			add(r)
+		}
+	}
+
+	if q := c.Audience.Users; q != nil {
+		active := true
+		filter := entities.UserFilter{
+			Search:          q.Search,
+			Role:            q.Role,
+			IsActive:        &active,
+			IsEmailVerified: q.IsEmailVerified,
+			IsPhoneVerified: q.IsPhoneVerified,
+			SortBy:          entities.UserSortCreatedAt,
+		}
+		userRepo := s.userRepo.WithTx(tx)
+		var after *entities.UserCursor
+		for {
+			users, cursor, err := userRepo.ListUsers(ctx, filter, 0, campaignAudiencePage, after)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list users: %w", err)
+			}
+			for _, u := range users {
+				userID := u.ID
+				r := campaignRecipient{userID: &userID, locale: u.Locale, address: addressOf(u.Email, u.PhoneNumber)}
+				if u.FullName != nil {
+					r.name = *u.FullName
+				}
+				add(r)
+			}
+			if cursor == nil || len(users) < campaignAudiencePage || len(out) >= limit {
+				break
+			}
+			after = cursor
+		}
+	}
+
+	for _, address := range c.Audience.Recipients {
+		add(campaignRecipient{address: address})
+	}
+	return out, nil
+}
+
+// campaignMessage renders the campaign's template for one recipient, in their language when it has a
+// variant. templates caches the variants already loaded during the run by locale.
+func (s *CampaignService) campaignMessage(ctx context.Context, c *entities.Campaign, r campaignRecipient, templates map[string]*entities.EmailTemplate) (*entities.OutboundMessage, error) {
+	tpl, ok := templates[r.locale]
+	if !ok {
+		var err error
+		if tpl, err = s.emailTplRepo.GetByName(ctx, c.TemplateName, util.LocaleFallbacks(r.locale)); err != nil {
+			return nil, fmt.Errorf("failed to load template: %w", err)
+		}
+		if !tpl.Campaign {
+			return nil, fmt.Errorf("%w: %s in %s", ErrCampaignTemplateDenied, tpl.Name, tpl.Locale)
+		}
+		templates[r.locale] = tpl
+	}
+
+	fields := maps.Clone(c.Fields)
+	if fields == nil {
+		fields = make(map[string]string)
+	}
+	for _, field := range campaignReservedFields {
+		delete(fields, field)
+	}
+	if r.name != "" {
+		fields["name"] = r.name
+	}
+
+	var (
+		msg *entities.OutboundMessage
+		err error
+	)
+	if c.Channel == entities.MessageChannelEmail {
+		msg, err = s.notifier.emailMessage(tpl, r.userID, r.address, fields)
+	} else {
+		msg, err = s.notifier.phoneMessage(tpl, entities.NotificationKindAccount, r.userID, "", r.address, fields)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// A campaign goes out over its own channel only, a promotion is not worth an SMS fallback
+	msg.Channel = c.Channel
+	msg.FallbackChannels = nil
+	msg.Category = c.Category
+	msg.OrganizationID = c.OrganizationID
+	msg.CampaignID = &c.ID
+	return msg, nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+type fakeCampaigns struct {
+	repositories.CampaignRepository
+	created []*entities.Campaign
+}
+
+func (f *fakeCampaigns) Create(_ context.Context, c *entities.Campaign) (*entities.Campaign, error) {
+	f.created = append(f.created, c)
+	return c, nil
+}
+
+type fakeTemplates struct {
+	repositories.EmailTemplateRepository
+	byName map[entities.EmailTemplateEnum]*entities.EmailTemplate
+}
+
+func (f *fakeTemplates) GetByName(_ context.Context, name entities.EmailTemplateEnum, _ []string) (*entities.EmailTemplate, error) {
+	if tpl, ok := f.byName[name]; ok {
+		return tpl, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+type fakeMembers struct {
+	repositories.OrganizationRepository
+	members []*entities.OrganizationMember
+}
+
+func (f *fakeMembers) ListMembers(context.Context, uuid.UUID) ([]*entities.OrganizationMember, error) {
+	return f.members, nil
+}
+
+func newTestCampaignService(t *testing.T) (*CampaignService, *fakeCampaigns) {
+	t.Helper()
+	cfg := outboxTestConfig()
+	cfg.Campaign.MaxAudience = 3
+	templates := &fakeTemplates{byName: map[entities.EmailTemplateEnum]*entities.EmailTemplate{
+		"spring_promo":       {Name: "spring_promo", Locale: "en", Subject: "Spring", Body: "Hi {{.name}}, see {{.link}}", Campaign: true},
+		"spring_promo_phone": {Name: "spring_promo_phone", Locale: "en", Body: "Hi {{.name}}", Campaign: true},
+		entities.EmailTemplateEnum("password_reset"): {Name: "password_reset", Locale: "en", Subject: "Reset", Body: "{{.link}}"},
+	}}
+	phone := "+628111111111"
+	members := &fakeMembers{members: []*entities.OrganizationMember{
+		{Email: "Owner@Salon.example", Role: entities.OrganizationRoleOwner},
+		{Email: "stylist@salon.example", PhoneNumber: &phone, Role: entities.OrganizationRoleEmployee},
+	}}
+	notifier, err := NewNotifier(cfg, templates, fakeSuppressions{}, nil, map[entities.MessageChannel]repositories.NotificationChannel{
+		entities.MessageChannelEmail: &fakeChannel{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	campaigns := &fakeCampaigns{}
+	return NewCampaignService(cfg, campaigns, templates, members, nil, nil, nil, notifier), campaigns
+}
+
+func TestCreateCampaignLimitsWhatCanBeSent(t *testing.T) {
+	orgID := uuid.New()
+	owner := &entities.User{ID: uuid.New(), Roles: []string{string(entities.RoleCustomer)}}
+	superuser := &entities.User{ID: uuid.New(), Roles: []string{string(entities.RoleSuperuser)}}
+	sendAt := time.Now().Add(time.Hour)
+	campaign := func(change func(*entities.Campaign)) *entities.Campaign {
+		c := &entities.Campaign{
+			Name:          "Spring promo",
+			Channel:       entities.MessageChannelEmail,
+			TemplateName:  "spring_promo",
+			Category:      entities.NotificationCategoryMarketing,
+			Audience:      entities.CampaignAudience{Recipients: []string{"owner@salon.example"}},
+			SendAt:        &sendAt,
+			RatePerMinute: 10,
+		}
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		user    *entities.User
+		orgID   *uuid.UUID
+		c       *entities.Campaign
+		wantErr error
+	}{
+		{"members of the salon", owner, &orgID, campaign(func(c *entities.Campaign) {
+			c.Audience.Recipients = []string{"OWNER@salon.example", "stylist@salon.example"}
+		}), nil},
+		{"salon members by phone", owner, &orgID, campaign(func(c *entities.Campaign) {
+			c.Channel, c.TemplateName = entities.MessageChannelWhatsApp, "spring_promo_phone"
+			c.Audience.Recipients = []string{"08111111111"}
+			c.Audience.Region = "ID"
+		}), nil},
+		{"phone outside the salon", owner, &orgID, campaign(func(c *entities.Campaign) {
+			c.Channel, c.TemplateName = entities.MessageChannelWhatsApp, "spring_promo_phone"
+			c.Audience.Recipients = []string{"+628999999999"}
+		}), ErrCampaignRecipientDenied},
+		{"address outside the salon", owner, &orgID, campaign(func(c *entities.Campaign) {
+			c.Audience.Recipients = []string{"victim@example.com"}
+		}), ErrCampaignRecipientDenied},
+		{"recipients without a salon", owner, nil, campaign(nil), ErrCampaignRecipientDenied},
+		{"superuser sends to anyone", superuser, nil, campaign(func(c *entities.Campaign) {
+			c.Audience.Recipients = []string{"anyone@example.com"}
+		}), nil},
+		{"account template", superuser, nil, campaign(func(c *entities.Campaign) {
+			c.TemplateName = "password_reset"
+		}), ErrCampaignTemplateDenied},
+		{"link field", owner, &orgID, campaign(func(c *entities.Campaign) {
+			c.Fields = map[string]string{"link": "https://phish.example"}
+		}), ErrInvalidCampaign},
+		{"code field", owner, &orgID, campaign(func(c *entities.Campaign) {
+			c.Fields = map[string]string{"code": "123456"}
+		}), ErrInvalidCampaign},
+		{"no rate", owner, &orgID, campaign(func(c *entities.Campaign) {
+			c.RatePerMinute = 0
+		}), ErrInvalidCampaign},
+		{"too many recipients", superuser, nil, campaign(func(c *entities.Campaign) {
+			c.Audience.Recipients = []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"}
+		}), ErrInvalidCampaign},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, campaigns := newTestCampaignService(t)
+			_, err := s.CreateCampaign(context.Background(), tt.user, tt.orgID, tt.c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateCampaign() = %v, want %v", err, tt.wantErr)
+			}
+			if (len(campaigns.created) == 1) != (tt.wantErr == nil) {
+				t.Errorf("%d campaigns created", len(campaigns.created))
+			}
+		})
+	}
+}
+
+func TestCampaignMessageIgnoresReservedFields(t *testing.T) {
+	s, _ := newTestCampaignService(t)
+	// A campaign stored before link and code were reserved
+	c := &entities.Campaign{
+		ID:           uuid.New(),
+		Channel:      entities.MessageChannelEmail,
+		TemplateName: "spring_promo",
+		Category:     entities.NotificationCategoryMarketing,
+		Fields:       map[string]string{"link": "https://phish.example", "code": "123456"},
+	}
+	msg, err := s.campaignMessage(context.Background(), c, campaignRecipient{name: "Sari", address: "sari@example.com"}, map[string]*entities.EmailTemplate{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(msg.Body, "phish") || !strings.Contains(msg.Body, "Hi Sari") {
+		t.Errorf("body = %q", msg.Body)
+	}
+
+	c.TemplateName = "password_reset"
+	if _, err := s.campaignMessage(context.Background(), c, campaignRecipient{address: "sari@example.com"}, map[string]*entities.EmailTemplate{}); !errors.Is(err, ErrCampaignTemplateDenied) {
+		t.Errorf("campaignMessage() with an account template = %v, want ErrCampaignTemplateDenied", err)
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+const maxListEmailSuppressionsLimit = 500
+
+// EmailSuppressionService records bounces and complaints and lets admins review the addresses they blocked
+type EmailSuppressionService struct {
+	suppressionRepo repositories.EmailSuppressionRepository
+}
+
+func NewEmailSuppressionService(suppressionRepo repositories.EmailSuppressionRepository) *EmailSuppressionService {
+	return &EmailSuppressionService{suppressionRepo: suppressionRepo}
+}
+
+// RecordBounces suppresses the recipients of hard bounces and complaints and returns how many were suppressed.
+// Soft bounces are only logged, the outbox already retries them. Events without a valid address are skipped.
+func (s *EmailSuppressionService) RecordBounces(ctx context.Context, events []entities.BounceEvent) (int, error) {
+	suppressed := 0
+	for _, e := range events {
+		addr, err := mail.ParseAddress(strings.TrimSpace(e.Email))
+		if err != nil {
+			log.Printf("ignoring %s bounce report for invalid address %q", e.Source, e.Email)
+			continue
+		}
+		var reason entities.SuppressionReason
+		switch e.Type {
+		case entities.BounceHard:
+			reason = entities.SuppressionReasonBounce
+		case entities.BounceComplaint:
+			reason = entities.SuppressionReasonComplaint
+		default:
+			log.Printf("soft bounce for %s: %s", addr.Address, e.Detail)
+			continue
+		}
+		_, err = s.suppressionRepo.Suppress(ctx, &entities.EmailSuppression{
+			Email:  strings.ToLower(addr.Address),
+			Reason: reason,
+			Detail: e.Detail,
+			Source: e.Source,
+		})
+		if err != nil {
+			return suppressed, fmt.Errorf("failed to suppress %s: %w", addr.Address, err)
+		}
+		suppressed++
+	}
+	return suppressed, nil
+}
+
+// List returns a page of suppressed addresses, newest first, and the number matching search
+func (s *EmailSuppressionService) List(ctx context.Context, search string, offset, limit int32) ([]*entities.EmailSuppression, int, error) {
+	if limit <= 0 || limit > maxListEmailSuppressionsLimit {
+		limit = maxListEmailSuppressionsLimit
+	}
+	return s.suppressionRepo.List(ctx, strings.TrimSpace(search), max(offset, 0), limit)
+}
+
+// Lift lets email be sent to the address again, for instance once its owner fixed their mailbox
+func (s *EmailSuppressionService) Lift(ctx context.Context, email string) error {
+	deleted, err := s.suppressionRepo.Delete(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSuppressionNotFound
+	}
+	return nil
+}
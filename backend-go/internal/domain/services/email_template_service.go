@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+var templateNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// requiredTemplateFields lists the variables a template must use to be of any help to its recipient
+var requiredTemplateFields = map[entities.EmailTemplateEnum][]string{
+	entities.EmailTemplateVerificationEmail: {"code"},
+	entities.EmailTemplateVerificationPhone: {"code"},
+	entities.EmailTemplatePasswordReset:     {"link"},
+	entities.EmailTemplateNewDeviceLogin:    {"link"},
+	entities.EmailTemplateNewDeviceLoginWA:  {"link"},
+	entities.EmailTemplateOrgInvitation:     {"link"},
+	entities.EmailTemplateOrgInvitationWA:   {"link"},
+	entities.EmailTemplateWelcomeUser:       {"link"},
+	entities.EmailTemplateWelcomeUserWA:     {"link"},
+	entities.EmailTemplateWeddingInviteWA:   {"link"},
+	entities.EmailTemplateBookingConfirmWA:  {"link"},
+}
+
+// sampleTemplateFields fills previews and test messages; callers may override any of them
+var sampleTemplateFields = map[string]string{
+	"code":         "123456",
+	"link":         "https://example.com/action?token=sample-token",
+	"name":         "Jane Doe",
+	"organization": "Acme Salon",
+	"inviter":      "John Doe",
+	"role":         "member",
+	"device":       "Chrome on macOS",
+	"ip":           "203.0.113.7",
+	"country":      "ID",
+	"time":         "Mon, 02 Jan 2006 15:04:05 UTC",
+	"couple":       "Ayu & Budi",
+	"date":         "Sat, 14 Feb 2026 10:00",
+	"venue":        "Hotel Indonesia Kempinski",
+	"service":      "haircut",
+	"image_url":    "https://example.com/invitation.jpg",
+	"latitude":     "-6.1951",
+	"longitude":    "106.8230",
+}
+
+// EmailTemplateService lets admins edit templates. Every save is kept as a new version
+// and senders only ever see the version that was explicitly activated.
+type EmailTemplateService struct {
+	emailTplRepo repositories.EmailTemplateRepository
+	outboxRepo   repositories.OutboxRepository
+	txManager    repositories.TransactionManager
+	notifier     *Notifier
+}
+
+func NewEmailTemplateService(
+	emailTplRepo repositories.EmailTemplateRepository,
+	outboxRepo repositories.OutboxRepository,
+	txManager repositories.TransactionManager,
+	notifier *Notifier,
+) *EmailTemplateService {
+	return &EmailTemplateService{
+		emailTplRepo: emailTplRepo,
+		outboxRepo:   outboxRepo,
+		txManager:    txManager,
+		notifier:     notifier,
+	}
+}
+
+func (s *EmailTemplateService) ListTemplates(ctx context.Context) ([]*entities.EmailTemplate, error) {
+	return s.emailTplRepo.List(ctx)
+}
+
+func (s *EmailTemplateService) ListVersions(ctx context.Context, name entities.EmailTemplateEnum, locale string) ([]*entities.EmailTemplate, error) {
+	locale, err := templateLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.emailTplRepo.ListVersions(ctx, name, locale)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrTemplateNotFound
+	}
+	return versions, nil
+}
+
+// CreateTemplate adds a template, or a variant of one in another locale; its first version is active right away.
+// whatsApp is only allowed on phone templates, and campaign lets campaigns send the template.
+func (s *EmailTemplateService) CreateTemplate(ctx context.Context, actorID uuid.UUID, name entities.EmailTemplateEnum, locale, subject, body string, whatsApp *entities.WhatsAppContent, campaign bool) (*entities.EmailTemplate, error) {
+	locale, err := templateLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTemplate(name, subject, body, whatsApp); err != nil {
+		return nil, err
+	}
+	versions, err := s.emailTplRepo.ListVersions(ctx, name, locale)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) > 0 {
+		return nil, ErrTemplateExists
+	}
+	return s.saveVersion(ctx, &entities.EmailTemplate{Name: name, Locale: locale, Subject: subject, Body: body, WhatsApp: whatsApp, Campaign: campaign, CreatedBy: &actorID}, true)
+}
+
+// UpdateTemplate stores a new version of the template. It only replaces the active version when activate is set.
+// The version is for campaigns like the latest one unless campaign says otherwise.
+func (s *EmailTemplateService) UpdateTemplate(ctx context.Context, actorID uuid.UUID, name entities.EmailTemplateEnum, locale, subject, body string, whatsApp *entities.WhatsAppContent, campaign *bool, activate bool) (*entities.EmailTemplate, error) {
+	if err := validateTemplate(name, subject, body, whatsApp); err != nil {
+		return nil, err
+	}
+	versions, err := s.ListVersions(ctx, name, locale)
+	if err != nil {
+		return nil, err
+	}
+	forCampaigns := versions[0].Campaign
+	if campaign != nil {
+		forCampaigns = *campaign
+	}
+	return s.saveVersion(ctx, &entities.EmailTemplate{Name: name, Locale: versions[0].Locale, Subject: subject, Body: body, WhatsApp: whatsApp, Campaign: forCampaigns, CreatedBy: &actorID}, activate)
+}
+
+func (s *EmailTemplateService) saveVersion(ctx context.Context, tpl *entities.EmailTemplate, activate bool) (*entities.EmailTemplate, error) {
+	var saved *entities.EmailTemplate
+	err := s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		repoTx := s.emailTplRepo.WithTx(tx)
+
+		var err error
+		saved, err = repoTx.CreateVersion(ctx, tpl)
+		if err != nil || !activate {
+			return err
+		}
+		saved, err = repoTx.Activate(ctx, saved.Name, saved.Locale, saved.Version)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save email template: %w", err)
+	}
+	return saved, nil
+}
+
+// ActivateVersion switches senders to the given version, which can also roll back to an older one
+func (s *EmailTemplateService) ActivateVersion(ctx context.Context, name entities.EmailTemplateEnum, locale string, version int) (*entities.EmailTemplate, error) {
+	locale, err := templateLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	var tpl *entities.EmailTemplate
+	err = s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		tpl, err = s.emailTplRepo.WithTx(tx).Activate(ctx, name, locale, version)
+		if err != nil {
+			return err
+		}
+		if tpl == nil {
+			return ErrTemplateNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// Preview renders a saved version, or the active one when version is zero, with sample data.
+// A non-nil draft is rendered instead, so edits can be checked before they are saved.
+func (s *EmailTemplateService) Preview(ctx context.Context, name entities.EmailTemplateEnum, locale string, version int, draft *entities.EmailTemplate, fields map[string]string) (*entities.OutboundMessage, error) {
+	tpl := draft
+	if tpl == nil {
+		var err error
+		if tpl, err = s.getVersion(ctx, name, locale, version); err != nil {
+			return nil, err
+		}
+	} else if err := validateTemplate(name, draft.Subject, draft.Body, draft.WhatsApp); err != nil {
+		return nil, err
+	}
+	tpl.Name = name
+	return s.render(tpl, "", fields)
+}
+
+// SendTestMessage queues a version, or the active one when version is zero, filled with sample data.
+// Phone templates are sent to a phone number over the account route, all others by email.
+func (s *EmailTemplateService) SendTestMessage(ctx context.Context, name entities.EmailTemplateEnum, locale string, version int, recipient string, fields map[string]string) (*entities.OutboundMessage, error) {
+	if strings.TrimSpace(recipient) == "" {
+		return nil, ErrRecipientRequired
+	}
+	tpl, err := s.getVersion(ctx, name, locale, version)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := s.render(tpl, recipient, fields)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Subject != "" {
+		msg.Subject = "[Test] " + msg.Subject
+	}
+	return s.outboxRepo.Enqueue(ctx, msg)
+}
+
+func (s *EmailTemplateService) getVersion(ctx context.Context, name entities.EmailTemplateEnum, locale string, version int) (*entities.EmailTemplate, error) {
+	if version == 0 {
+		versions, err := s.ListVersions(ctx, name, locale)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range versions {
+			if v.IsActive {
+				return v, nil
+			}
+		}
+		return nil, ErrTemplateNotFound
+	}
+	locale, err := templateLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := s.emailTplRepo.GetVersion(ctx, name, locale, version)
+	if err != nil {
+		return nil, err
+	}
+	if tpl == nil {
+		return nil, ErrTemplateNotFound
+	}
+	return tpl, nil
+}
+
+func (s *EmailTemplateService) render(tpl *entities.EmailTemplate, recipient string, overrides map[string]string) (*entities.OutboundMessage, error) {
+	fields := maps.Clone(sampleTemplateFields)
+	maps.Copy(fields, overrides)
+
+	var (
+		msg *entities.OutboundMessage
+		err error
+	)
+	if tpl.Name.IsPhone() {
+		msg, err = s.notifier.phoneMessage(tpl, entities.NotificationKindAccount, nil, "", recipient, fields)
+	} else {
+		msg, err = s.notifier.emailMessage(tpl, nil, recipient, fields)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return msg, nil
+}
+
+// templateLocale canonicalizes the locale of a template variant, the default locale when empty
+func templateLocale(locale string) (string, error) {
+	if locale == "" {
+		return entities.DefaultLocale, nil
+	}
+	normalized, ok := util.NormalizeLocale(locale)
+	if !ok {
+		return "", ErrInvalidLocale
+	}
+	return normalized, nil
+}
+
+// validateTemplate rejects templates that would fail to render or leave out the variables
+// their recipients need, such as the code of a verification message
+func validateTemplate(name entities.EmailTemplateEnum, subject, body string, whatsApp *entities.WhatsAppContent) error {
+	if !templateNamePattern.MatchString(string(name)) {
+		return ErrInvalidTemplateName
+	}
+	if strings.TrimSpace(subject) == "" && !name.IsPhone() {
+		return fmt.Errorf("%w: subject is required", ErrInvalidTemplate)
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("%w: body is required", ErrInvalidTemplate)
+	}
+	tpl, err := template.New("body").Parse(body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	used := make(map[string]bool)
+	collectTemplateFields(tpl.Tree.Root, used)
+	for _, field := range requiredTemplateFields[name] {
+		if !used[field] {
+			return fmt.Errorf("%w: body must use {{.%s}}", ErrInvalidTemplate, field)
+		}
+	}
+	if whatsApp == nil {
+		return nil
+	}
+	if !name.IsPhone() {
+		return fmt.Errorf("%w: only phone templates have whatsapp content", ErrInvalidTemplate)
+	}
+	if err := validateWhatsAppContent(whatsApp); err != nil {
+		return fmt.Errorf("%w: whatsapp %v", ErrInvalidTemplate, err)
+	}
+	// Rendering with the sample fields catches template syntax errors in the content
+	if _, err := renderWhatsAppContent(whatsApp, sampleTemplateFields); err != nil {
+		return fmt.Errorf("%w: whatsapp %v", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+func collectTemplateFields(node parse.Node, used map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectTemplateFields(child, used)
+		}
+	case *parse.ActionNode:
+		collectTemplateFields(n.Pipe, used)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectTemplateFields(cmd, used)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectTemplateFields(arg, used)
+		}
+	case *parse.FieldNode:
+		used[n.Ident[0]] = true
+	case *parse.IfNode:
+		collectTemplateFields(&n.BranchNode, used)
+	case *parse.RangeNode:
+		collectTemplateFields(&n.BranchNode, used)
+	case *parse.WithNode:
+		collectTemplateFields(&n.BranchNode, used)
+	case *parse.BranchNode:
+		collectTemplateFields(n.Pipe, used)
+		collectTemplateFields(n.List, used)
+		collectTemplateFields(n.ElseList, used)
+	case *parse.TemplateNode:
+		collectTemplateFields(n.Pipe, used)
+	}
+}
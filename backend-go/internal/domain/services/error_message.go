@@ -3,14 +3,67 @@ package services
 import "errors"
 
 var (
-	ErrUserNotActive           = errors.New("user is not active")
-	ErrInvalidCredentials      = errors.New("invalid credentials")
-	ErrUserNotFound            = errors.New("user not found")
-	ErrInvalidEmailNotVerified = errors.New("invalid email not verified")
-	ErrInvalidRefreshToken     = errors.New("invalid refresh token")
-	ErrUserExists              = errors.New("user already exists")
-	ErrInvalidRole             = errors.New("invalid role")
-	ErrNoRolesProvided         = errors.New("no roles provided")
-	ErrInvalidOAuthCode        = errors.New("invalid oauth code")
-	ErrOAuthUnauthorized       = errors.New("invalid oauth unauthorized")
+	ErrUserNotActive              = errors.New("user is not active")
+	ErrInvalidCredentials         = errors.New("invalid username or password")
+	ErrUserNotFound               = errors.New("user not found")
+	ErrInvalidEmailNotVerified    = errors.New("invalid email not verified")
+	ErrInvalidRefreshToken        = errors.New("invalid refresh token")
+	ErrUserExists                 = errors.New("user already exists")
+	ErrInvalidRole                = errors.New("role must be salon_owner or salon_employee")
+	ErrNoRolesProvided            = errors.New("no roles provided")
+	ErrInvalidOAuthCode           = errors.New("invalid authorization code")
+	ErrOAuthUnauthorized          = errors.New("authentication failed")
+	ErrOrganizationNotFound       = errors.New("organization not found")
+	ErrMemberNotFound             = errors.New("organization member not found")
+	ErrLastOwner                  = errors.New("organization must keep at least one owner")
+	ErrInvitationMismatch         = errors.New("invitation was sent to a different account")
+	ErrUserInvited                = errors.New("an account with this email exists and was invited to join")
+	ErrAccountNotManaged          = errors.New("account was not created by this organization")
+	ErrImportJobNotFound          = errors.New("import job not found")
+	ErrInvalidSortField           = errors.New("sort_by must be created_at, email, full_name or last_login_at")
+	ErrInvalidPageToken           = errors.New("invalid page_token")
+	ErrCannotDeleteSelf           = errors.New("cannot delete your own account")
+	ErrOutboundMessageNotFound    = errors.New("outbound message not found")
+	ErrInvalidMessageStatus       = errors.New("status must be pending, sending, sent or dead")
+	ErrMessageNotDead             = errors.New("only dead messages can be retried")
+	ErrMessageContentCleared      = errors.New("message was sent and its content cleared, it cannot be retried")
+	ErrMessageNotOnWhatsApp       = errors.New("only messages sent over whatsapp have a status to refresh")
+	ErrWhatsAppSessionNotFound    = errors.New("whatsapp session not found")
+	ErrWhatsAppNotPairing         = errors.New("whatsapp session is not waiting for a qr code scan")
+	ErrWhatsAppUnavailable        = errors.New("whatsapp gateway unavailable")
+	ErrInvalidChannel             = errors.New("preferred_channel must be whatsapp or sms")
+	ErrTemplateNotFound           = errors.New("email template not found")
+	ErrTemplateExists             = errors.New("email template already exists")
+	ErrInvalidTemplate            = errors.New("invalid email template")
+	ErrInvalidTemplateName        = errors.New("name must be lowercase letters, digits and underscores")
+	ErrRecipientRequired          = errors.New("recipient is required")
+	ErrInvalidLocale              = errors.New("invalid locale")
+	ErrEmailSuppressed            = errors.New("email address is suppressed")
+	ErrSuppressionNotFound        = errors.New("email address is not suppressed")
+	ErrNoNotificationsSelected    = errors.New("ids or all is required")
+	ErrInvalidPreference          = errors.New("preferences can only be set for rsvp, reminder and marketing on email, whatsapp, sms or in_app")
+	ErrInvalidUnsubscribeToken    = errors.New("invalid unsubscribe link")
+	ErrCampaignNotFound           = errors.New("campaign not found")
+	ErrInvalidCampaign            = errors.New("invalid campaign")
+	ErrInvalidCampaignSchedule    = errors.New("invalid campaign schedule")
+	ErrCampaignAudienceDenied     = errors.New("only superusers can send campaigns to platform users")
+	ErrCampaignRecipientDenied    = errors.New("campaign recipients must be members of the organization")
+	ErrCampaignTemplateDenied     = errors.New("template is not for campaigns")
+	ErrCampaignStatus             = errors.New("campaign status does not allow this change")
+	ErrInvalidWebhook             = errors.New("invalid webhook")
+	ErrWebhookEventNotFound       = errors.New("webhook event not found")
+	ErrInvalidWebhookEventStatus  = errors.New("status must be received, processed or failed")
+	ErrPaymentProviderUnavailable = errors.New("payment provider is not configured")
+	ErrPaymentNotFound            = errors.New("payment not found")
+	ErrPaymentStatus              = errors.New("payment status does not allow this change")
+
+	// The servers report these where the context says more than the error the service returned,
+	// such as which of the user's addresses is taken
+	ErrEmailInUse          = errors.New("email already in use")
+	ErrPhoneInUse          = errors.New("phone already in use")
+	ErrIdentityTaken       = errors.New("email or phone number is now used by another user")
+	ErrEmailNotVerified    = errors.New("email must be verified")
+	ErrPhoneNotVerified    = errors.New("phone must be verified")
+	ErrInvalidPhoneNumber  = errors.New("invalid phone number")
+	ErrDeletedUserNotFound = errors.New("deleted user not found")
 )
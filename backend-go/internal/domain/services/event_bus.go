@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// EventBus hands domain events to the modules subscribed to them. Handlers run synchronously,
+// in the order they subscribed, so a handler that is slow should hand its work off itself.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]func(context.Context, entities.Event) error
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: make(map[string][]func(context.Context, entities.Event) error)}
+}
+
+// Subscribe registers handler for events of type E
+func Subscribe[E entities.Event](bus *EventBus, handler func(context.Context, E) error) {
+	var zero E
+	name := zero.EventName()
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.handlers[name] = append(bus.handlers[name], func(ctx context.Context, e entities.Event) error {
+		return handler(ctx, e.(E))
+	})
+}
+
+// Publish runs every handler of the event, also when an earlier one fails, and returns their errors joined
+func (b *EventBus) Publish(ctx context.Context, e entities.Event) error {
+	b.mu.RLock()
+	handlers := b.handlers[e.EventName()]
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s handler: %w", e.EventName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+const (
+	maxListNotificationsLimit = 100
+	// inboxStreamBuffer is how far a stream may fall behind before updates are dropped for it;
+	// a client that missed some catches up from ListNotifications
+	inboxStreamBuffer = 16
+	// maxNotificationTitle is the length of notification.title
+	maxNotificationTitle = 255
+)
+
+// InboxService keeps the in-app notifications of each user and passes new ones to the streams
+// the user has open. Streams live in memory, so only those on the instance that created a
+// notification hear of it right away; the inbox itself is shared through the database.
+type InboxService struct {
+	notificationRepo repositories.NotificationRepository
+	prefRepo         repositories.NotificationPreferenceRepository
+
+	mu      sync.Mutex
+	streams map[uuid.UUID]map[chan *entities.Notification]struct{}
+}
+
+func NewInboxService(notificationRepo repositories.NotificationRepository, prefRepo repositories.NotificationPreferenceRepository) *InboxService {
+	return &InboxService{
+		notificationRepo: notificationRepo,
+		prefRepo:         prefRepo,
+		streams:          make(map[uuid.UUID]map[chan *entities.Notification]struct{}),
+	}
+}
+
+// Notify adds a notification to the user's inbox and passes it to their open streams.
+// It returns nil when the user turned the notification's category off in the app.
+func (s *InboxService) Notify(ctx context.Context, n *entities.Notification) (*entities.Notification, error) {
+	optedOut, err := optedOutChannels(ctx, s.prefRepo, &n.UserID, "", n.Category)
+	if err != nil {
+		return nil, err
+	}
+	if optedOut[entities.MessageChannelInApp] {
+		return nil, nil
+	}
+	created, err := s.notificationRepo.Create(ctx, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notification: %w", err)
+	}
+	s.publish(created.UserID, created)
+	return created, nil
+}
+
+// List returns a page of the user's notifications, newest first, and how many are unread
+func (s *InboxService) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset, limit int32) ([]*entities.Notification, int, error) {
+	if limit <= 0 || limit > maxListNotificationsLimit {
+		limit = maxListNotificationsLimit
+	}
+	list, err := s.notificationRepo.List(ctx, userID, unreadOnly, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, unread, nil
+}
+
+func (s *InboxService) UnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+// MarkRead marks the given notifications of the user read, or all of them, and returns how many were unread.
+// Ids of notifications that belong to someone else are ignored.
+func (s *InboxService) MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, all bool) (int, error) {
+	var (
+		n   int
+		err error
+	)
+	switch {
+	case all:
+		n, err = s.notificationRepo.MarkAllRead(ctx, userID)
+	case len(ids) > 0:
+		n, err = s.notificationRepo.MarkRead(ctx, userID, ids)
+	default:
+		return 0, ErrNoNotificationsSelected
+	}
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		// Lets the user's other sessions update their unread count
+		s.publish(userID, nil)
+	}
+	return n, nil
+}
+
+// Subscribe returns the notifications created for the user from now on, with a nil entry
+// each time some of theirs are marked read. Call cancel once the stream is done with it.
+func (s *InboxService) Subscribe(userID uuid.UUID) (<-chan *entities.Notification, func()) {
+	ch := make(chan *entities.Notification, inboxStreamBuffer)
+
+	s.mu.Lock()
+	if s.streams[userID] == nil {
+		s.streams[userID] = make(map[chan *entities.Notification]struct{})
+	}
+	s.streams[userID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	cancel := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.streams[userID], ch)
+		if len(s.streams[userID]) == 0 {
+			delete(s.streams, userID)
+		}
+	}
+	return ch, cancel
+}
+
+func (s *InboxService) publish(userID uuid.UUID, n *entities.Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.streams[userID] {
+		select {
+		case ch <- n:
+		default:
+		}
+	}
+}
+
+// inAppChannel delivers outbox messages to the inbox of the user they are for
+type inAppChannel struct {
+	inbox *InboxService
+}
+
+// NewInAppChannel lets the Notifier route messages, such as account messages to a phone number, to the in-app inbox
+func NewInAppChannel(inbox *InboxService) repositories.NotificationChannel {
+	return inAppChannel{inbox: inbox}
+}
+
+func (c inAppChannel) Send(ctx context.Context, msg *entities.OutboundMessage) error {
+	if msg.UserID == nil {
+		return errors.New("message has no user to notify")
+	}
+	// Text messages have no subject, their first line stands in as the title
+	title, _, _ := strings.Cut(strings.TrimSpace(cmp.Or(msg.Subject, msg.Body)), "\n")
+	if r := []rune(title); len(r) > maxNotificationTitle {
+		title = string(r[:maxNotificationTitle-1]) + "…"
+	}
+	_, err := c.inbox.Notify(ctx, &entities.Notification{
+		UserID:   *msg.UserID,
+		Category: cmp.Or(msg.Category, entities.NotificationCategoryTransactional),
+		Title:    title,
+		Body:     msg.Body,
+		Data:     map[string]any{"outbound_message_id": msg.ID.String()},
+	})
+	return err
+}
+
+// Ready is always true, the inbox lives in the database
+func (c inAppChannel) Ready(ctx context.Context) bool {
+	return true
+}
+
+// NotifyPaymentStatus tells the payer that their payment went through or failed
+func (s *InboxService) NotifyPaymentStatus(ctx context.Context, e entities.PaymentStatusChanged) error {
+	n := &entities.Notification{
+		UserID:   e.UserID,
+		Category: entities.NotificationCategoryPayment,
+		Data: map[string]any{
+			"payment_id":     e.PaymentID.String(),
+			"provider":       string(e.Provider),
+			"transaction_id": e.TransactionID,
+			"status":         string(e.Status),
+		},
+	}
+	switch e.Status {
+	case entities.PaymentStatusPaid:
+		n.Title = "Payment received"
+		n.Body = fmt.Sprintf("Your payment%s was received.", formatPaymentAmount(e.Amount, e.Currency))
+	case entities.PaymentStatusFailed:
+		n.Title = "Payment failed"
+		n.Body = fmt.Sprintf("Your payment%s did not go through. No money was taken; you can try again.", formatPaymentAmount(e.Amount, e.Currency))
+	default:
+		return nil
+	}
+	_, err := s.Notify(ctx, n)
+	return err
+}
+
+// NotifySubscriptionExpired tells the subscriber that their plan ran out
+func (s *InboxService) NotifySubscriptionExpired(ctx context.Context, e entities.SubscriptionExpired) error {
+	_, err := s.Notify(ctx, &entities.Notification{
+		UserID:   e.UserID,
+		Category: entities.NotificationCategorySubscription,
+		Title:    "Subscription expired",
+		Body:     fmt.Sprintf("Your subscription ended on %s. Renew it to keep your plan's features.", e.PeriodEnd.UTC().Format("2 January 2006")),
+		Data: map[string]any{
+			"subscription_id": e.SubscriptionID.String(),
+			"period_end":      e.PeriodEnd.UTC().Format(time.RFC3339),
+		},
+	})
+	return err
+}
+
+// NotifyTrialEnding reminds the subscriber to add a payment method before their trial ends
+func (s *InboxService) NotifyTrialEnding(ctx context.Context, e entities.SubscriptionTrialEnding) error {
+	_, err := s.Notify(ctx, &entities.Notification{
+		UserID:   e.UserID,
+		Category: entities.NotificationCategorySubscription,
+		Title:    "Trial ending soon",
+		Body:     fmt.Sprintf("Your free trial ends on %s. Add a payment method to keep your plan's features.", e.TrialEnd.UTC().Format("2 January 2006")),
+		Data: map[string]any{
+			"subscription_id": e.SubscriptionID.String(),
+			"trial_end":       e.TrialEnd.UTC().Format(time.RFC3339),
+		},
+	})
+	return err
+}
+
+// NotifyRSVP tells the couple how a guest answered their invitation
+func (s *InboxService) NotifyRSVP(ctx context.Context, e entities.RSVPReceived) error {
+	guest := e.GuestName
+	if guest == "" {
+		guest = "A guest"
+	}
+	var title string
+	switch e.Status {
+	case entities.RSVPYes:
+		title = guest + " will attend"
+	case entities.RSVPNo:
+		title = guest + " can't attend"
+	case entities.RSVPMaybe:
+		title = guest + " might attend"
+	default:
+		return nil
+	}
+	_, err := s.Notify(ctx, &entities.Notification{
+		UserID:   e.OwnerID,
+		Category: entities.NotificationCategoryRSVP,
+		Title:    title,
+		Body:     e.Message,
+		Data: map[string]any{
+			"wedding_id": e.WeddingID.String(),
+			"guest_id":   e.GuestID.String(),
+			"status":     string(e.Status),
+		},
+	})
+	return err
+}
+
+// formatPaymentAmount renders " of IDR 150000", or nothing when the amount is unknown
+func formatPaymentAmount(amount float64, currency string) string {
+	if amount <= 0 {
+		return ""
+	}
+	return " of " + strings.TrimSpace(strings.ToUpper(currency)+" "+strconv.FormatFloat(amount, 'f', -1, 64))
+}
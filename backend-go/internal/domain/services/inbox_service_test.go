@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+type fakeNotifications struct {
+	repositories.NotificationRepository
+	created []*entities.Notification
+}
+
+func (f *fakeNotifications) Create(ctx context.Context, n *entities.Notification) (*entities.Notification, error) {
+	f.created = append(f.created, n)
+	return n, nil
+}
+
+func TestNotifierRoutesToInApp(t *testing.T) {
+	notifications := &fakeNotifications{}
+	inbox := NewInboxService(notifications, nil)
+	cfg := outboxTestConfig()
+	cfg.Notify.PhoneRoute = []string{"whatsapp", "in_app"}
+	notifier, err := NewNotifier(cfg, nil, fakeSuppressions{}, nil, map[entities.MessageChannel]repositories.NotificationChannel{
+		entities.MessageChannelWhatsApp: &fakeChannel{down: true},
+		entities.MessageChannelInApp:    NewInAppChannel(inbox),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One-time codes prove the phone is the user's, the inbox cannot
+	if route := notifier.route(entities.NotificationKindOTP, ""); len(route) != 1 || route[0] != entities.MessageChannelWhatsApp {
+		t.Errorf("OTP route = %v, want [whatsapp]", route)
+	}
+	route := notifier.route(entities.NotificationKindAccount, "")
+
+	userID := uuid.New()
+	msg := &entities.OutboundMessage{
+		ID:               uuid.New(),
+		Channel:          route[0],
+		FallbackChannels: route[1:],
+		Recipient:        "+6281200000001",
+		Body:             "Welcome to the salon\nYour account is ready.",
+		UserID:           &userID,
+	}
+	channel, err := notifier.Deliver(context.Background(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channel != entities.MessageChannelInApp {
+		t.Fatalf("delivered over %q, want in_app", channel)
+	}
+	if len(notifications.created) != 1 {
+		t.Fatalf("created %d notifications, want 1", len(notifications.created))
+	}
+	n := notifications.created[0]
+	if n.UserID != userID || n.Title != "Welcome to the salon" || n.Body != msg.Body || n.Category != entities.NotificationCategoryTransactional {
+		t.Errorf("notification = %+v", n)
+	}
+
+	// A message for no user has no inbox to fall back to
+	msg.UserID = nil
+	if _, err := notifier.Deliver(context.Background(), msg); err == nil || errors.Is(err, errNoSender) {
+		t.Errorf("message without a user: %v, want the whatsapp failure", err)
+	}
+	if len(notifications.created) != 1 {
+		t.Errorf("message without a user created a notification")
+	}
+}
+
+func TestNotifierRejectsInAppOTPRoute(t *testing.T) {
+	cfg := outboxTestConfig()
+	cfg.Notify.OTPRoute = []string{"in_app"}
+	if _, err := NewNotifier(cfg, nil, nil, nil, nil); err == nil || !strings.Contains(err.Error(), "NOTIFY_OTP_ROUTE") {
+		t.Errorf("NewNotifier() = %v, want an invalid NOTIFY_OTP_ROUTE", err)
+	}
+}
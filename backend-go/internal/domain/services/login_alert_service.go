@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/config"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+// LoginAlertService tracks the devices a user signs in from and warns them about new ones
+type LoginAlertService struct {
+	cfg              *config.Config
+	userRepo         repositories.UserRepository
+	deviceRepo       repositories.UserDeviceRepository
+	verificationRepo repositories.VerificationCodeRepository
+	notifier         *Notifier
+	txManager        repositories.TransactionManager
+	outboxRepo       repositories.OutboxRepository
+	geoip            repositories.GeoIPResolver
+}
+
+func NewLoginAlertService(
+	cfg *config.Config,
+	userRepo repositories.UserRepository,
+	deviceRepo repositories.UserDeviceRepository,
+	verificationRepo repositories.VerificationCodeRepository,
+	notifier *Notifier,
+	txManager repositories.TransactionManager,
+	outboxRepo repositories.OutboxRepository,
+	geoip repositories.GeoIPResolver,
+) *LoginAlertService {
+	return &LoginAlertService{
+		cfg:              cfg,
+		userRepo:         userRepo,
+		deviceRepo:       deviceRepo,
+		verificationRepo: verificationRepo,
+		notifier:         notifier,
+		txManager:        txManager,
+		outboxRepo:       outboxRepo,
+		geoip:            geoip,
+	}
+}
+
+// RecordLogin stores the login time and notifies the user when the client is an unknown device.
+// The very first device a user signs in from is remembered silently.
+func (s *LoginAlertService) RecordLogin(ctx context.Context, user *entities.User) error {
+	now := time.Now()
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID, now); err != nil {
+		return fmt.Errorf("failed to update last login: %w", err)
+	}
+	user.LastLoginAt = &now
+
+	client := util.ClientInfoFromContext(ctx)
+	prefix := ipPrefix(client.IPAddress)
+	country := s.countryCode(client.IPAddress)
+	fingerprint := deviceFingerprint(client.UserAgent, prefix, country)
+
+	known, err := s.deviceRepo.GetByFingerprint(ctx, user.ID, fingerprint)
+	if err != nil {
+		return fmt.Errorf("failed to load device: %w", err)
+	}
+	if known != nil {
+		return s.deviceRepo.Touch(ctx, known.ID)
+	}
+
+	count, err := s.deviceRepo.CountByUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count devices: %w", err)
+	}
+	device, err := s.deviceRepo.Create(ctx, &entities.UserDevice{
+		UserID:      user.ID,
+		Fingerprint: fingerprint,
+		UserAgent:   client.UserAgent,
+		IPPrefix:    prefix,
+		Country:     country,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save device: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+	return s.notifyNewDevice(ctx, user, device, client, now)
+}
+
+// SecureAccount signs out every session of the user owning the token and forgets the reported device
+func (s *LoginAlertService) SecureAccount(ctx context.Context, token string) error {
+	v, err := s.verificationRepo.GetByCodeOnly(ctx, entities.VerificationTypeSecureAccount, token)
+	if err != nil || v == nil || v.UserID == nil {
+		return ErrInvalidOrExpiredCode
+	}
+	if v.UsedAt != nil || time.Now().After(v.ExpiresAt) {
+		return ErrInvalidOrExpiredCode
+	}
+	if err := s.userRepo.RevokeSessions(ctx, *v.UserID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if deviceID, ok := v.ExtraMetadata["device_id"].(string); ok {
+		if id, err := uuid.Parse(deviceID); err == nil {
+			if err := s.deviceRepo.Delete(ctx, *v.UserID, id); err != nil {
+				return fmt.Errorf("failed to remove device: %w", err)
+			}
+		}
+	}
+	if err := s.verificationRepo.MarkUsed(ctx, v.ID); err != nil {
+		return fmt.Errorf("failed to mark token used: %w", err)
+	}
+	return nil
+}
+
+func (s *LoginAlertService) notifyNewDevice(ctx context.Context, user *entities.User, device *entities.UserDevice, client entities.ClientInfo, at time.Time) error {
+	token := util.GenerateSecureToken(32)
+	v := &entities.VerificationCode{
+		UserID:    &user.ID,
+		Code:      token,
+		Type:      entities.VerificationTypeSecureAccount,
+		ExpiresAt: at.Add(7 * 24 * time.Hour),
+		ExtraMetadata: map[string]any{
+			"purpose":   entities.VerificationPurposeSecureAccount,
+			"device_id": device.ID.String(),
+		},
+	}
+
+	fields := map[string]string{
+		"device":  describeDevice(client.UserAgent),
+		"ip":      client.IPAddress,
+		"country": device.Country,
+		"time":    at.UTC().Format(time.RFC1123),
+		"link":    fmt.Sprintf("%s/secure-account?token=%s", s.cfg.BaseURL, token),
+	}
+	if fields["country"] == "" {
+		fields["country"] = "unknown"
+	}
+
+	var msgs []*entities.OutboundMessage
+	if user.Email != "" {
+		msg, err := s.notifier.Email(ctx, &user.ID, user.Locale, user.Email, entities.EmailTemplateNewDeviceLogin, fields)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+	if user.PhoneNumber != nil && user.IsPhoneVerified {
+		msg, err := s.notifier.Phone(ctx, entities.NotificationKindAccount, &user.ID, user.PreferredChannel, user.Locale, *user.PhoneNumber, entities.EmailTemplateNewDeviceLoginWA, fields)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		if err := s.verificationRepo.WithTx(tx).Create(ctx, v); err != nil {
+			return fmt.Errorf("failed to save verification code: %w", err)
+		}
+		outboxRepoTx := s.outboxRepo.WithTx(tx)
+		for _, msg := range msgs {
+			if _, err := outboxRepoTx.Enqueue(ctx, msg); err != nil {
+				return fmt.Errorf("failed to enqueue new device alert: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *LoginAlertService) countryCode(ip string) string {
+	if s.geoip == nil || ip == "" {
+		return ""
+	}
+	code, err := s.geoip.CountryCode(ip)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to resolve country: %w", err))
+		return ""
+	}
+	return code
+}
+
+// ipPrefix truncates an address to its network: /24 for IPv4 and /64 for IPv6,
+// so a device keeps its fingerprint when the ISP rotates the host part.
+func ipPrefix(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}).String()
+}
+
+func deviceFingerprint(userAgent, prefix, country string) string {
+	sum := sha256.Sum256([]byte(userAgent + "|" + prefix + "|" + country))
+	return hex.EncodeToString(sum[:])
+}
+
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "an unknown device"
+	}
+	if len(userAgent) > 120 {
+		return userAgent[:120]
+	}
+	return userAgent
+}
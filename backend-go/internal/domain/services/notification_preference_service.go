@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/config"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+// NotificationPreferenceService lets users turn the optional categories on and off per channel,
+// and anyone who got such a message by email opt out of it through the link it carries.
+// Transactional messages are sent regardless.
+type NotificationPreferenceService struct {
+	cfg       *config.Config
+	prefRepo  repositories.NotificationPreferenceRepository
+	txManager repositories.TransactionManager
+}
+
+func NewNotificationPreferenceService(cfg *config.Config, prefRepo repositories.NotificationPreferenceRepository, txManager repositories.TransactionManager) *NotificationPreferenceService {
+	return &NotificationPreferenceService{cfg: cfg, prefRepo: prefRepo, txManager: txManager}
+}
+
+// Preferences returns every optional category on every channel for the user, enabled unless they turned it off
+func (s *NotificationPreferenceService) Preferences(ctx context.Context, userID uuid.UUID) ([]*entities.NotificationPreference, error) {
+	saved, err := s.prefRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var prefs []*entities.NotificationPreference
+	for _, category := range entities.OptionalNotificationCategories {
+		for _, channel := range entities.PreferenceChannels {
+			p := &entities.NotificationPreference{UserID: &userID, Category: category, Channel: channel, Enabled: true}
+			if i := slices.IndexFunc(saved, func(sp *entities.NotificationPreference) bool {
+				return sp.Category == category && sp.Channel == channel
+			}); i >= 0 {
+				p.Enabled = saved[i].Enabled
+				p.UpdatedAt = saved[i].UpdatedAt
+			}
+			prefs = append(prefs, p)
+		}
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences saves the given preferences of the user and returns all of them. Categories and
+// channels left out keep their current setting.
+func (s *NotificationPreferenceService) UpdatePreferences(ctx context.Context, userID uuid.UUID, prefs []*entities.NotificationPreference) ([]*entities.NotificationPreference, error) {
+	for _, p := range prefs {
+		if !p.Category.Optional() || !slices.Contains(entities.PreferenceChannels, p.Channel) {
+			return nil, ErrInvalidPreference
+		}
+	}
+	err := s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		prefRepo := s.prefRepo.WithTx(tx)
+		for _, p := range prefs {
+			if err := prefRepo.Set(ctx, &entities.NotificationPreference{
+				UserID:   &userID,
+				Category: p.Category,
+				Channel:  p.Channel,
+				Enabled:  p.Enabled,
+			}); err != nil {
+				return fmt.Errorf("failed to save notification preference: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Preferences(ctx, userID)
+}
+
+// Unsubscribe turns off the category and channel an unsubscribe link was signed for, and returns the preference it saved
+func (s *NotificationPreferenceService) Unsubscribe(ctx context.Context, token string) (*entities.NotificationPreference, error) {
+	t, err := verifyUnsubscribeToken(s.cfg.Notify.UnsubscribeSecret, token)
+	if err != nil {
+		return nil, err
+	}
+	p := &entities.NotificationPreference{
+		UserID:    t.UserID,
+		Recipient: t.Recipient,
+		Category:  t.Category,
+		Channel:   t.Channel,
+		Enabled:   false,
+	}
+	if err := s.prefRepo.Set(ctx, p); err != nil {
+		return nil, fmt.Errorf("failed to save notification preference: %w", err)
+	}
+	return p, nil
+}
+
+// optedOutChannels returns the channels the user, or the recipient when there is no user, turned the category off on
+func optedOutChannels(ctx context.Context, prefRepo repositories.NotificationPreferenceRepository, userID *uuid.UUID, recipient string, category entities.NotificationCategory) (map[entities.MessageChannel]bool, error) {
+	if !category.Optional() {
+		return nil, nil
+	}
+	var (
+		prefs []*entities.NotificationPreference
+		err   error
+	)
+	if userID != nil {
+		prefs, err = prefRepo.ListByUser(ctx, *userID)
+	} else {
+		prefs, err = prefRepo.ListByRecipient(ctx, preferenceRecipient(recipient))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification preferences: %w", err)
+	}
+	off := make(map[entities.MessageChannel]bool)
+	for _, p := range prefs {
+		if p.Category == category && !p.Enabled {
+			off[p.Channel] = true
+		}
+	}
+	return off, nil
+}
+
+// preferenceRecipient drops the display name from an email recipient, so preferences match on the address alone
+func preferenceRecipient(recipient string) string {
+	if addr, err := mail.ParseAddress(recipient); err == nil {
+		return addr.Address
+	}
+	return strings.TrimSpace(recipient)
+}
+
+// unsubscribeToken is what an unsubscribe link opts out of; it names the recipient only when the message had no user
+type unsubscribeToken struct {
+	UserID    *uuid.UUID                    `json:"u,omitempty"`
+	Recipient string                        `json:"r,omitempty"`
+	Category  entities.NotificationCategory `json:"c"`
+	Channel   entities.MessageChannel       `json:"ch"`
+}
+
+// signUnsubscribeToken encodes t followed by its HMAC-SHA256 under secret
+func signUnsubscribeToken(secret string, t unsubscribeToken) string {
+	b, _ := json.Marshal(t)
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(unsubscribeMAC(secret, payload))
+}
+
+func verifyUnsubscribeToken(secret, token string) (*unsubscribeToken, error) {
+	if secret == "" {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, unsubscribeMAC(secret, payload)) {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	var t unsubscribeToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	if !t.Category.Optional() || !slices.Contains(entities.PreferenceChannels, t.Channel) || (t.UserID == nil && t.Recipient == "") {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	return &t, nil
+}
+
+func unsubscribeMAC(secret, payload string) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+const maxListOutboundMessagesLimit = 500
+
+// NotificationService exposes the delivery state of the outbox to admins. They see the messages of
+// every salon, so it reads in the system scope of row level security.
+type NotificationService struct {
+	outboxRepo repositories.OutboxRepository
+}
+
+func NewNotificationService(outboxRepo repositories.OutboxRepository) *NotificationService {
+	return &NotificationService{outboxRepo: outboxRepo}
+}
+
+// ListOutboundMessages returns a page of messages, newest first, and the number of messages per status
+func (s *NotificationService) ListOutboundMessages(ctx context.Context, status entities.OutboundMessageStatus, userID *uuid.UUID, offset, limit int32) ([]*entities.OutboundMessage, map[entities.OutboundMessageStatus]int, error) {
+	switch status {
+	case "", entities.OutboundMessagePending, entities.OutboundMessageSending, entities.OutboundMessageSent, entities.OutboundMessageDead:
+	default:
+		return nil, nil, ErrInvalidMessageStatus
+	}
+	if limit <= 0 || limit > maxListOutboundMessagesLimit {
+		limit = maxListOutboundMessagesLimit
+	}
+	ctx = util.WithSystemScope(ctx)
+	msgs, err := s.outboxRepo.List(ctx, status, userID, offset, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts, err := s.outboxRepo.CountByStatus(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return msgs, counts, nil
+}
+
+func (s *NotificationService) GetOutboundMessage(ctx context.Context, id uuid.UUID) (*entities.OutboundMessage, error) {
+	ctx = util.WithSystemScope(ctx)
+	msg, err := s.outboxRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, ErrOutboundMessageNotFound
+	}
+	return msg, nil
+}
+
+// RetryOutboundMessage requeues a dead-lettered message. Messages still in flight are left alone,
+// as are messages the provider failed after they were sent, whose content was cleared.
+func (s *NotificationService) RetryOutboundMessage(ctx context.Context, id uuid.UUID) (*entities.OutboundMessage, error) {
+	ctx = util.WithSystemScope(ctx)
+	msg, err := s.outboxRepo.Requeue(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if msg != nil {
+		return msg, nil
+	}
+	msg, err = s.GetOutboundMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Status == entities.OutboundMessageDead && msg.SentAt != nil {
+		return nil, ErrMessageContentCleared
+	}
+	return nil, ErrMessageNotDead
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/config"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+// errNoSender dead-letters messages whose channels are not configured, retrying would not help
+var errNoSender = errors.New("no sender configured for channel")
+
+// errSuppressed dead-letters email to addresses that bounced or complained
+var errSuppressed = errors.New("recipient address is suppressed")
+
+// errUnsubscribed dead-letters messages whose recipient turned their category off on every channel of the route
+var errUnsubscribed = errors.New("recipient unsubscribed from category")
+
+// phoneChannels can carry the same text message to a phone number, so they may fall back to each other
+var phoneChannels = []entities.MessageChannel{entities.MessageChannelWhatsApp, entities.MessageChannelSMS}
+
+// accountChannels may carry messages about a user's account. Besides the phone the message can land in
+// the user's in-app inbox, which does not prove they hold the number and so is left out of OTP routes.
+var accountChannels = append(slices.Clip(phoneChannels), entities.MessageChannelInApp)
+
+// Notifier renders templates into outbox messages and delivers them over pluggable channels.
+// Messages to a phone number follow the route configured for their kind, starting with the
+// user's preferred channel when it is part of the route. Email to suppressed addresses is never sent,
+// and reminders and marketing skip the channels their recipient turned the category off on.
+type Notifier struct {
+	cfg             *config.Config
+	emailTplRepo    repositories.EmailTemplateRepository
+	suppressionRepo repositories.EmailSuppressionRepository
+	prefRepo        repositories.NotificationPreferenceRepository
+	channels        map[entities.MessageChannel]repositories.NotificationChannel
+	routes          map[entities.NotificationKind][]entities.MessageChannel
+}
+
+// NewNotifier fails on routes naming unknown channels. Channels missing from channels may still
+// be routed to; they are skipped at delivery, so routes can stay the same across environments.
+func NewNotifier(
+	cfg *config.Config,
+	emailTplRepo repositories.EmailTemplateRepository,
+	suppressionRepo repositories.EmailSuppressionRepository,
+	prefRepo repositories.NotificationPreferenceRepository,
+	channels map[entities.MessageChannel]repositories.NotificationChannel,
+) (*Notifier, error) {
+	phoneRoute, err := parseRoute(cfg.Notify.PhoneRoute, accountChannels)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFY_PHONE_ROUTE: %w", err)
+	}
+	otpNames := cfg.Notify.OTPRoute
+	if len(otpNames) == 0 {
+		otpNames = slices.DeleteFunc(slices.Clone(cfg.Notify.PhoneRoute), func(name string) bool {
+			return entities.MessageChannel(strings.ToLower(strings.TrimSpace(name))) == entities.MessageChannelInApp
+		})
+	}
+	otpRoute, err := parseRoute(otpNames, phoneChannels)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFY_OTP_ROUTE: %w", err)
+	}
+	return &Notifier{
+		cfg:             cfg,
+		emailTplRepo:    emailTplRepo,
+		suppressionRepo: suppressionRepo,
+		prefRepo:        prefRepo,
+		channels:        channels,
+		routes: map[entities.NotificationKind][]entities.MessageChannel{
+			entities.NotificationKindOTP:     otpRoute,
+			entities.NotificationKindAccount: phoneRoute,
+		},
+	}, nil
+}
+
+// parseRoute reads channel names, each of which must be one of allowed
+func parseRoute(names []string, allowed []entities.MessageChannel) ([]entities.MessageChannel, error) {
+	var route []entities.MessageChannel
+	for _, name := range names {
+		ch := entities.MessageChannel(strings.ToLower(strings.TrimSpace(name)))
+		if !slices.Contains(allowed, ch) {
+			return nil, fmt.Errorf("unknown channel %q", name)
+		}
+		if !slices.Contains(route, ch) {
+			route = append(route, ch)
+		}
+	}
+	if len(route) == 0 {
+		return nil, errors.New("route is empty")
+	}
+	return route, nil
+}
+
+// Email renders an email template into a message; enqueue it in the transaction that stores the data it refers to.
+// locale is the recipient's language, or empty to use the language of the current request.
+func (n *Notifier) Email(ctx context.Context, userID *uuid.UUID, locale, to string, templateName entities.EmailTemplateEnum, fields map[string]string) (*entities.OutboundMessage, error) {
+	tpl, err := n.emailTplRepo.GetByName(ctx, templateName, templateLocales(ctx, locale))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email template: %w", err)
+	}
+	return n.emailMessage(tpl, userID, to, fields)
+}
+
+func (n *Notifier) emailMessage(tpl *entities.EmailTemplate, userID *uuid.UUID, to string, fields map[string]string) (*entities.OutboundMessage, error) {
+	body, err := util.FillTextTemplate(tpl.Body, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render email template: %w", err)
+	}
+	return &entities.OutboundMessage{
+		Channel:     entities.MessageChannelEmail,
+		Recipient:   to,
+		Subject:     tpl.Subject,
+		Body:        body,
+		UserID:      userID,
+		MaxAttempts: n.cfg.Outbox.MaxAttempts,
+	}, nil
+}
+
+// Phone renders a text template into a message routed by kind.
+// preferred is the user's preferred channel, or empty; locale is picked as for Email.
+func (n *Notifier) Phone(ctx context.Context, kind entities.NotificationKind, userID *uuid.UUID, preferred entities.MessageChannel, locale, phone string, templateName entities.EmailTemplateEnum, fields map[string]string) (*entities.OutboundMessage, error) {
+	tpl, err := n.emailTplRepo.GetByName(ctx, templateName, templateLocales(ctx, locale))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	return n.phoneMessage(tpl, kind, userID, preferred, phone, fields)
+}
+
+func (n *Notifier) phoneMessage(tpl *entities.EmailTemplate, kind entities.NotificationKind, userID *uuid.UUID, preferred entities.MessageChannel, phone string, fields map[string]string) (*entities.OutboundMessage, error) {
+	body, err := util.FillTextTemplate(tpl.Body, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fill template: %w", err)
+	}
+	content, err := renderWhatsAppContent(tpl.WhatsApp, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fill whatsapp content: %w", err)
+	}
+	if content != nil {
+		if err := validateWhatsAppContent(content); err != nil {
+			return nil, fmt.Errorf("invalid whatsapp content: %w", err)
+		}
+	}
+	route := n.route(kind, preferred)
+	return &entities.OutboundMessage{
+		Channel:          route[0],
+		FallbackChannels: route[1:],
+		Recipient:        phone,
+		Body:             body,
+		UserID:           userID,
+		MaxAttempts:      n.cfg.Outbox.MaxAttempts,
+		WhatsApp:         content,
+	}, nil
+}
+
+// templateLocales is the order in which template variants are tried:
+// the recipient's locale, the language of the current request, then the default locale
+func templateLocales(ctx context.Context, locale string) []string {
+	return util.LocaleFallbacks(locale, util.ClientInfoFromContext(ctx).Locale)
+}
+
+func (n *Notifier) route(kind entities.NotificationKind, preferred entities.MessageChannel) []entities.MessageChannel {
+	route := n.routes[kind]
+	if len(route) == 0 {
+		route = n.routes[entities.NotificationKindAccount]
+	}
+	i := slices.Index(route, preferred)
+	if i <= 0 {
+		return route
+	}
+	reordered := []entities.MessageChannel{preferred}
+	reordered = append(reordered, route[:i]...)
+	return append(reordered, route[i+1:]...)
+}
+
+// Deliver sends msg on the first ready channel of its route, moving on to the next channel
+// when a send fails. Channels the recipient opted out of for the message's category are left out.
+// It returns the channel that delivered the message.
+func (n *Notifier) Deliver(ctx context.Context, msg *entities.OutboundMessage) (entities.MessageChannel, error) {
+	if msg.Channel == entities.MessageChannelEmail {
+		suppression, err := n.EmailSuppression(ctx, msg.Recipient)
+		if err != nil {
+			return "", err
+		}
+		if suppression != nil {
+			return "", fmt.Errorf("%w: %s", errSuppressed, suppression.Reason)
+		}
+	}
+
+	optedOut, err := optedOutChannels(ctx, n.prefRepo, msg.UserID, msg.Recipient, msg.Category)
+	if err != nil {
+		return "", err
+	}
+	route := slices.DeleteFunc(append([]entities.MessageChannel{msg.Channel}, msg.FallbackChannels...), func(ch entities.MessageChannel) bool {
+		return optedOut[ch]
+	})
+	if len(route) == 0 {
+		return "", fmt.Errorf("%w: %s", errUnsubscribed, msg.Category)
+	}
+	if route[0] == entities.MessageChannelEmail && msg.Category.Optional() {
+		n.addUnsubscribeLink(msg)
+	}
+
+	var errs []error
+	for _, ch := range route {
+		channel, ok := n.channels[ch]
+		// Only messages for a user have an inbox to land in
+		if !ok || (ch == entities.MessageChannelInApp && msg.UserID == nil) {
+			continue
+		}
+		if !channel.Ready(ctx) {
+			errs = append(errs, fmt.Errorf("%s: channel not ready", ch))
+			continue
+		}
+		if err := channel.Send(ctx, msg); err != nil {
+			// The message waits for this channel rather than going out over the next one
+			var limited *repositories.RateLimitedError
+			if errors.As(err, &limited) {
+				return "", fmt.Errorf("%s: %w", ch, err)
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", ch, err))
+			continue
+		}
+		return ch, nil
+	}
+	if len(errs) == 0 {
+		return "", fmt.Errorf("%w: %s", errNoSender, msg.Channel)
+	}
+	return "", errors.Join(errs...)
+}
+
+// addUnsubscribeLink lets the recipient of an email opt out of its category by email: the body links to
+// the page that confirms it, and the List-Unsubscribe headers point mail clients at the one-click API.
+// Nothing is added while NOTIFY_UNSUBSCRIBE_SECRET is unset.
+func (n *Notifier) addUnsubscribeLink(msg *entities.OutboundMessage) {
+	if n.cfg.Notify.UnsubscribeSecret == "" {
+		return
+	}
+	t := unsubscribeToken{UserID: msg.UserID, Category: msg.Category, Channel: entities.MessageChannelEmail}
+	if t.UserID == nil {
+		t.Recipient = preferenceRecipient(msg.Recipient)
+	}
+	token := url.QueryEscape(signUnsubscribeToken(n.cfg.Notify.UnsubscribeSecret, t))
+
+	oneClick := n.cfg.Notify.UnsubscribeURL
+	if oneClick == "" {
+		oneClick = n.cfg.BaseURL + "/v1/unsubscribe"
+	}
+	msg.UnsubscribeURL = oneClick + "?token=" + token
+	page := fmt.Sprintf("%s/unsubscribe?token=%s", n.cfg.BaseURL, token)
+	msg.Body += fmt.Sprintf("\n<p style=\"font-size:12px;color:#888888\">Don't want these emails? <a href=\"%s\">Unsubscribe</a>.</p>", html.EscapeString(page))
+	if msg.Email != nil && msg.Email.TextBody != "" {
+		msg.Email.TextBody += "\n\nDon't want these emails? Unsubscribe: " + page
+	}
+}
+
+// EmailSuppression returns why email to the address is suppressed, or nil when it may be sent.
+// to may carry a display name.
+func (n *Notifier) EmailSuppression(ctx context.Context, to string) (*entities.EmailSuppression, error) {
+	email := to
+	if addr, err := mail.ParseAddress(to); err == nil {
+		email = addr.Address
+	}
+	suppression, err := n.suppressionRepo.Get(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email suppression: %w", err)
+	}
+	return suppression, nil
+}
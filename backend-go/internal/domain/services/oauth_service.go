@@ -7,12 +7,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/williamchand/fullstack-fastapi/backend-go/config"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -35,11 +37,12 @@ type OAuthConfigService struct {
 }
 
 type OAuthService struct {
-	config    map[string]OAuthConfigService
-	oauthRepo repositories.OAuthRepository
-	userRepo  repositories.UserRepository
-	txManager repositories.TransactionManager
-	jwtRepo   repositories.JWTRepository
+	config      map[string]OAuthConfigService
+	oauthRepo   repositories.OAuthRepository
+	userRepo    repositories.UserRepository
+	txManager   repositories.TransactionManager
+	jwtRepo     repositories.JWTRepository
+	loginAlerts *LoginAlertService
 }
 
 func NewOAuthService(
@@ -48,6 +51,7 @@ func NewOAuthService(
 	userRepo repositories.UserRepository,
 	txManager repositories.TransactionManager,
 	jwtRepo repositories.JWTRepository,
+	loginAlerts *LoginAlertService,
 ) *OAuthService {
 	config := map[string]OAuthConfigService{}
 	config["google"] = OAuthConfigService{
@@ -64,11 +68,12 @@ func NewOAuthService(
 		infoURL: "https://www.googleapis.com/oauth2/v2/userinfo",
 	}
 	return &OAuthService{
-		config:    config,
-		oauthRepo: oauthRepo,
-		userRepo:  userRepo,
-		txManager: txManager,
-		jwtRepo:   jwtRepo,
+		config:      config,
+		oauthRepo:   oauthRepo,
+		userRepo:    userRepo,
+		txManager:   txManager,
+		jwtRepo:     jwtRepo,
+		loginAlerts: loginAlerts,
 	}
 }
 
@@ -131,6 +136,12 @@ func (s *OAuthService) HandleCallback(ctx context.Context, provider string, code
 		return nil, err
 	}
 
+	if s.loginAlerts != nil {
+		if err := s.loginAlerts.RecordLogin(ctx, user); err != nil {
+			log.Println(fmt.Errorf("failed to record login: %w", err))
+		}
+	}
+
 	accessToken, err := s.jwtRepo.GenerateToken(user.ID, user.Email, user.Roles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
@@ -216,6 +227,10 @@ func (s *OAuthService) createUserFromOAuth(
 		IsActive:        true,
 		IsEmailVerified: userInfo.VerifiedEmail,
 		Roles:           []string{string(entities.RoleCustomer)},
+		Locale:          util.ClientInfoFromContext(ctx).Locale,
+	}
+	if locale, ok := util.NormalizeLocale(userInfo.Locale); ok {
+		user.Locale = locale
 	}
 
 	// Create the user in database
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/config"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+// OrganizationService manages salons, their members and member invitations
+type OrganizationService struct {
+	cfg              *config.Config
+	orgRepo          repositories.OrganizationRepository
+	verificationRepo repositories.VerificationCodeRepository
+	notifier         *Notifier
+	txManager        repositories.TransactionManager
+	outboxRepo       repositories.OutboxRepository
+	scimTokenRepo    repositories.ScimTokenRepository
+}
+
+func NewOrganizationService(
+	cfg *config.Config,
+	orgRepo repositories.OrganizationRepository,
+	verificationRepo repositories.VerificationCodeRepository,
+	notifier *Notifier,
+	txManager repositories.TransactionManager,
+	outboxRepo repositories.OutboxRepository,
+	scimTokenRepo repositories.ScimTokenRepository,
+) *OrganizationService {
+	return &OrganizationService{
+		cfg:              cfg,
+		orgRepo:          orgRepo,
+		verificationRepo: verificationRepo,
+		notifier:         notifier,
+		txManager:        txManager,
+		outboxRepo:       outboxRepo,
+		scimTokenRepo:    scimTokenRepo,
+	}
+}
+
+// CreateOrganization creates a salon and makes the creator its owner
+func (s *OrganizationService) CreateOrganization(ctx context.Context, userID uuid.UUID, name string) (*entities.Organization, error) {
+	var org *entities.Organization
+	err := s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		orgRepoTx := s.orgRepo.WithTx(tx)
+
+		var err error
+		org, err = orgRepoTx.Create(ctx, &entities.Organization{Name: name, CreatedBy: &userID})
+		if err != nil {
+			return err
+		}
+		_, err = orgRepoTx.AddMember(ctx, org.ID, userID, entities.OrganizationRoleOwner)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	return org, nil
+}
+
+// ListOrganizations returns the organizations the user belongs to
+func (s *OrganizationService) ListOrganizations(ctx context.Context, userID uuid.UUID) ([]*entities.Organization, error) {
+	return s.orgRepo.ListByUser(ctx, userID)
+}
+
+// InviteMember sends an invitation link by email or, when only a phone number is given, over the phone route
+func (s *OrganizationService) InviteMember(ctx context.Context, inviter *entities.User, orgID uuid.UUID, email, phone, region string, role entities.OrganizationRole) error {
+	if role != entities.OrganizationRoleOwner && role != entities.OrganizationRoleEmployee {
+		return ErrInvalidRole
+	}
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to load organization: %w", err)
+	}
+	if org == nil {
+		return ErrOrganizationNotFound
+	}
+
+	metadata := map[string]any{
+		"purpose":         entities.VerificationPurposeOrgInvitation,
+		"organization_id": org.ID.String(),
+		"role":            string(role),
+		"invited_by":      inviter.ID.String(),
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email != "" {
+		metadata["email"] = email
+	} else {
+		normalized, ok := util.NormalizeE164(phone, region)
+		if !ok {
+			return ErrInvalidState
+		}
+		phone = normalized
+		metadata["phone_number"] = phone
+	}
+
+	inviterName := inviter.Email
+	if inviter.FullName != nil && *inviter.FullName != "" {
+		inviterName = *inviter.FullName
+	}
+	return s.sendInvitation(ctx, org, inviterName, email, phone, role, metadata, nil)
+}
+
+// InviteDirectoryUser invites an existing account that the organization's identity provider asked to
+// provision. The account joins as an employee only once its owner accepts. While an earlier invitation
+// to the organization is live no other is sent, since the provider retries provisioning.
+func (s *OrganizationService) InviteDirectoryUser(ctx context.Context, orgID uuid.UUID, user *entities.User) error {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to load organization: %w", err)
+	}
+	if org == nil {
+		return ErrOrganizationNotFound
+	}
+	pending, err := s.verificationRepo.GetLatestUnused(ctx, user.ID, entities.VerificationTypeOrgInvitation)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to load invitation: %w", err)
+	}
+	if pending != nil && time.Now().Before(pending.ExpiresAt) && pending.ExtraMetadata["organization_id"] == org.ID.String() {
+		return nil
+	}
+	metadata := map[string]any{
+		"purpose":         entities.VerificationPurposeOrgInvitation,
+		"organization_id": org.ID.String(),
+		"role":            string(entities.OrganizationRoleEmployee),
+		"email":           user.Email,
+		"invited_by":      "scim",
+	}
+	return s.sendInvitation(ctx, org, org.Name, user.Email, "", entities.OrganizationRoleEmployee, metadata, &user.ID)
+}
+
+// sendInvitation queues the invitation message and saves its token, for userID when the invited account is known
+func (s *OrganizationService) sendInvitation(ctx context.Context, org *entities.Organization, inviterName, email, phone string, role entities.OrganizationRole, metadata map[string]any, userID *uuid.UUID) error {
+	token := util.GenerateSecureToken(32)
+	fields := map[string]string{
+		"organization": org.Name,
+		"inviter":      inviterName,
+		"role":         strings.ReplaceAll(string(role), "_", " "),
+		"link":         fmt.Sprintf("%s/accept-invitation?token=%s", s.cfg.BaseURL, token),
+	}
+
+	var msg *entities.OutboundMessage
+	var err error
+	if email != "" {
+		msg, err = s.notifier.Email(ctx, nil, "", email, entities.EmailTemplateOrgInvitation, fields)
+	} else {
+		msg, err = s.notifier.Phone(ctx, entities.NotificationKindAccount, nil, "", "", phone, entities.EmailTemplateOrgInvitationWA, fields)
+	}
+	if err != nil {
+		return err
+	}
+	// Sent from the salon's own WhatsApp number when it has one
+	msg.OrganizationID = &org.ID
+
+	expiresAt := time.Now().Add(invitationTTL)
+	return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		verificationRepoTx := s.verificationRepo.WithTx(tx)
+		var err error
+		if userID != nil {
+			err = verificationRepoTx.Create(ctx, &entities.VerificationCode{
+				UserID:        userID,
+				Code:          token,
+				Type:          entities.VerificationTypeOrgInvitation,
+				ExtraMetadata: metadata,
+				ExpiresAt:     expiresAt,
+			})
+		} else {
+			_, err = verificationRepoTx.CreateNoUser(ctx, token, entities.VerificationTypeOrgInvitation, metadata, expiresAt)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save invitation: %w", err)
+		}
+		if _, err := s.outboxRepo.WithTx(tx).Enqueue(ctx, msg); err != nil {
+			return fmt.Errorf("failed to enqueue invitation: %w", err)
+		}
+		return nil
+	})
+}
+
+// AcceptInvitation adds the user to the inviting organization.
+// The invitation must have been sent to the user's email or verified phone number.
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, user *entities.User, token string) (*entities.Organization, error) {
+	v, err := s.verificationRepo.GetByCodeOnly(ctx, entities.VerificationTypeOrgInvitation, token)
+	if err != nil || v == nil {
+		return nil, ErrInvalidOrExpiredCode
+	}
+	if v.UsedAt != nil || time.Now().After(v.ExpiresAt) {
+		return nil, ErrInvalidOrExpiredCode
+	}
+
+	invitedEmail, _ := v.ExtraMetadata["email"].(string)
+	invitedPhone, _ := v.ExtraMetadata["phone_number"].(string)
+	switch {
+	case invitedEmail != "" && strings.EqualFold(invitedEmail, user.Email):
+	case invitedPhone != "" && user.PhoneNumber != nil && user.IsPhoneVerified && *user.PhoneNumber == invitedPhone:
+	default:
+		return nil, ErrInvitationMismatch
+	}
+
+	orgIDStr, _ := v.ExtraMetadata["organization_id"].(string)
+	orgID, err := uuid.Parse(orgIDStr)
+	if err != nil {
+		return nil, ErrInvalidOrExpiredCode
+	}
+	roleStr, _ := v.ExtraMetadata["role"].(string)
+	role := entities.OrganizationRole(roleStr)
+
+	// The invitation grants access to the inviting organization's rows
+	ctx = util.WithOrganizationID(ctx, orgID)
+
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organization: %w", err)
+	}
+	if org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	// An invitation never demotes an existing owner
+	existing, err := s.orgRepo.GetMember(ctx, orgID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load member: %w", err)
+	}
+	if existing != nil && existing.Role == entities.OrganizationRoleOwner {
+		role = entities.OrganizationRoleOwner
+	}
+
+	err = s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		if _, err := s.orgRepo.WithTx(tx).AddMember(ctx, orgID, user.ID, role); err != nil {
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+		if err := s.verificationRepo.WithTx(tx).MarkUsed(ctx, v.ID); err != nil {
+			return fmt.Errorf("failed to mark invitation used: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// ListMembers returns all members of the organization
+func (s *OrganizationService) ListMembers(ctx context.Context, orgID uuid.UUID) ([]*entities.OrganizationMember, error) {
+	return s.orgRepo.ListMembers(ctx, orgID)
+}
+
+// RemoveMember removes a user from the organization, keeping at least one owner
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		orgRepo := s.orgRepo.WithTx(tx)
+		member, err := orgRepo.GetMember(ctx, orgID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to load member: %w", err)
+		}
+		if member == nil {
+			return ErrMemberNotFound
+		}
+		if err := ensureOwnerRemains(ctx, orgRepo, orgID, member); err != nil {
+			return err
+		}
+		return orgRepo.RemoveMember(ctx, orgID, userID)
+	})
+}
+
+// ensureOwnerRemains refuses to remove or demote member when it is the organization's last owner.
+// The owners stay locked until the transaction of orgRepo ends, so two owners removing each other
+// at once are checked one after the other and the second is refused.
+func ensureOwnerRemains(ctx context.Context, orgRepo repositories.OrganizationRepository, orgID uuid.UUID, member *entities.OrganizationMember) error {
+	if member.Role != entities.OrganizationRoleOwner {
+		return nil
+	}
+	owners, err := orgRepo.LockMembersByRole(ctx, orgID, entities.OrganizationRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to lock owners: %w", err)
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// CreateScimToken issues a bearer token for the organization's SCIM provisioning.
+// Only a hash is stored, so the returned token cannot be shown again.
+func (s *OrganizationService) CreateScimToken(ctx context.Context, orgID uuid.UUID, description string) (string, error) {
+	token := util.GenerateSecureToken(32)
+	if _, err := s.scimTokenRepo.Create(ctx, orgID, hashScimToken(token), description); err != nil {
+		return "", fmt.Errorf("failed to save scim token: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/config"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+// OutboxWorker delivers queued messages through the Notifier.
+// Several instances can run side by side, each message is leased to one worker at a time.
+type OutboxWorker struct {
+	cfg        *config.Config
+	outboxRepo repositories.OutboxRepository
+	notifier   *Notifier
+}
+
+func NewOutboxWorker(cfg *config.Config, outboxRepo repositories.OutboxRepository, notifier *Notifier) *OutboxWorker {
+	return &OutboxWorker{
+		cfg:        cfg,
+		outboxRepo: outboxRepo,
+		notifier:   notifier,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled. Deliveries in flight are allowed to finish;
+// messages claimed but not started become due again once their lease runs out.
+func (w *OutboxWorker) Run(ctx context.Context) error {
+	workers := max(w.cfg.Outbox.Workers, 1)
+	batchSize := max(w.cfg.Outbox.BatchSize, workers)
+
+	jobs := make(chan *entities.OutboundMessage)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range jobs {
+				w.deliver(context.WithoutCancel(ctx), msg)
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(jobs)
+
+	ticker := time.NewTicker(w.cfg.Outbox.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Keep draining while batches come back full
+		for {
+			claimed, err := w.dispatch(ctx, jobs, batchSize)
+			if err != nil {
+				log.Println(fmt.Errorf("failed to claim outbound messages: %w", err))
+			}
+			if err != nil || claimed < batchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *OutboxWorker) dispatch(ctx context.Context, jobs chan<- *entities.OutboundMessage, batchSize int) (int, error) {
+	msgs, err := w.outboxRepo.Claim(ctx, batchSize, w.cfg.Outbox.Lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, msg := range msgs {
+		select {
+		case jobs <- msg:
+		case <-ctx.Done():
+			return len(msgs), nil
+		}
+	}
+	return len(msgs), nil
+}
+
+func (w *OutboxWorker) deliver(ctx context.Context, msg *entities.OutboundMessage) {
+	channel, sendErr := w.notifier.Deliver(ctx, msg)
+
+	var (
+		err     error
+		limited *repositories.RateLimitedError
+	)
+	switch {
+	case sendErr == nil:
+		err = w.outboxRepo.MarkSent(ctx, msg.ID, channel, msg.ExternalID, msg.Sender)
+	case errors.As(sendErr, &limited):
+		err = w.outboxRepo.Defer(ctx, msg.ID, time.Now().Add(limited.RetryAfter))
+	case errors.Is(sendErr, errNoSender) || errors.Is(sendErr, errSuppressed) || errors.Is(sendErr, errUnsubscribed) || msg.Attempts >= msg.MaxAttempts:
+		log.Println(fmt.Errorf("giving up on %s message %s after %d attempts: %w", msg.Channel, msg.ID, msg.Attempts, sendErr))
+		err = w.outboxRepo.MarkDead(ctx, msg.ID, sendErr.Error())
+	default:
+		err = w.outboxRepo.Reschedule(ctx, msg.ID, time.Now().Add(w.backoff(msg.Attempts)), sendErr.Error())
+	}
+	if err != nil {
+		log.Println(fmt.Errorf("failed to update outbound message %s: %w", msg.ID, err))
+	}
+}
+
+// backoff doubles the delay with every attempt up to the configured maximum.
+// Up to a quarter is added as jitter so messages failing together do not retry together.
+func (w *OutboxWorker) backoff(attempts int) time.Duration {
+	delay := w.cfg.Outbox.BaseBackoff
+	for i := 1; i < attempts && delay < w.cfg.Outbox.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, w.cfg.Outbox.MaxBackoff)
+	if delay <= 0 {
+		return 0
+	}
+	return delay + rand.N(delay/4+1)
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/config"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+// fakeOutbox serves claims from a queue and records what the worker did with each message
+type fakeOutbox struct {
+	repositories.OutboxRepository
+
+	mu       sync.Mutex
+	queue    []*entities.OutboundMessage
+	claims   []int
+	leases   []time.Duration
+	sent     map[uuid.UUID]entities.MessageChannel
+	retries  map[uuid.UUID]time.Time
+	deferred map[uuid.UUID]time.Time
+	dead     map[uuid.UUID]string
+	lastErr  map[uuid.UUID]string
+	done     chan uuid.UUID
+}
+
+func newFakeOutbox(msgs ...*entities.OutboundMessage) *fakeOutbox {
+	return &fakeOutbox{
+		queue:    msgs,
+		sent:     map[uuid.UUID]entities.MessageChannel{},
+		retries:  map[uuid.UUID]time.Time{},
+		deferred: map[uuid.UUID]time.Time{},
+		dead:     map[uuid.UUID]string{},
+		lastErr:  map[uuid.UUID]string{},
+		done:     make(chan uuid.UUID, len(msgs)+1),
+	}
+}
+
+func (f *fakeOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]*entities.OutboundMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.claims = append(f.claims, limit)
+	f.leases = append(f.leases, lease)
+	n := min(limit, len(f.queue))
+	batch := f.queue[:n]
+	f.queue = f.queue[n:]
+	for _, msg := range batch {
+		msg.Attempts++
+		msg.Status = entities.OutboundMessageSending
+	}
+	return batch, nil
+}
+
+func (f *fakeOutbox) MarkSent(ctx context.Context, id uuid.UUID, channel entities.MessageChannel, externalID, sender string) error {
+	f.mu.Lock()
+	f.sent[id] = channel
+	f.mu.Unlock()
+	f.done <- id
+	return nil
+}
+
+func (f *fakeOutbox) Reschedule(ctx context.Context, id uuid.UUID, next time.Time, lastError string) error {
+	f.mu.Lock()
+	f.retries[id], f.lastErr[id] = next, lastError
+	f.mu.Unlock()
+	f.done <- id
+	return nil
+}
+
+func (f *fakeOutbox) Defer(ctx context.Context, id uuid.UUID, next time.Time) error {
+	f.mu.Lock()
+	f.deferred[id] = next
+	f.mu.Unlock()
+	f.done <- id
+	return nil
+}
+
+func (f *fakeOutbox) MarkDead(ctx context.Context, id uuid.UUID, lastError string) error {
+	f.mu.Lock()
+	f.dead[id] = lastError
+	f.mu.Unlock()
+	f.done <- id
+	return nil
+}
+
+// fakeChannel fails sends with err, or accepts them and hands out an external id
+type fakeChannel struct {
+	mu    sync.Mutex
+	err   error
+	down  bool
+	count int
+}
+
+func (c *fakeChannel) Send(ctx context.Context, msg *entities.OutboundMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count++
+	if c.err != nil {
+		return c.err
+	}
+	msg.ExternalID = "ext-" + msg.ID.String()
+	return nil
+}
+
+func (c *fakeChannel) Ready(ctx context.Context) bool { return !c.down }
+
+type fakeSuppressions struct {
+	repositories.EmailSuppressionRepository
+	suppressed map[string]bool
+}
+
+func (f fakeSuppressions) Get(ctx context.Context, email string) (*entities.EmailSuppression, error) {
+	if f.suppressed[email] {
+		return &entities.EmailSuppression{Email: email, Reason: "bounce"}, nil
+	}
+	return nil, nil
+}
+
+func outboxTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Outbox.Workers = 2
+	cfg.Outbox.BatchSize = 2
+	cfg.Outbox.PollInterval = time.Hour
+	cfg.Outbox.Lease = 5 * time.Minute
+	cfg.Outbox.MaxAttempts = 3
+	cfg.Outbox.BaseBackoff = 30 * time.Second
+	cfg.Outbox.MaxBackoff = time.Hour
+	cfg.Notify.PhoneRoute = []string{"whatsapp", "sms"}
+	return cfg
+}
+
+func newTestOutboxWorker(t *testing.T, outbox *fakeOutbox, channels map[entities.MessageChannel]repositories.NotificationChannel) *OutboxWorker {
+	t.Helper()
+	cfg := outboxTestConfig()
+	notifier, err := NewNotifier(cfg, nil, fakeSuppressions{suppressed: map[string]bool{"bounced@example.com": true}}, nil, channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewOutboxWorker(cfg, outbox, notifier)
+}
+
+func testMessage(channel entities.MessageChannel, recipient string, fallback ...entities.MessageChannel) *entities.OutboundMessage {
+	return &entities.OutboundMessage{
+		ID:               uuid.New(),
+		Channel:          channel,
+		FallbackChannels: fallback,
+		Recipient:        recipient,
+		Body:             "your code is 123456",
+		Status:           entities.OutboundMessagePending,
+		MaxAttempts:      3,
+	}
+}
+
+func TestOutboxWorkerBackoff(t *testing.T) {
+	w := &OutboxWorker{cfg: outboxTestConfig()}
+	tests := []struct {
+		attempts int
+		base     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{8, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		// Jitter adds up to a quarter of the delay
+		for range 20 {
+			got := w.backoff(tt.attempts)
+			if got < tt.base || got > tt.base+tt.base/4 {
+				t.Fatalf("backoff(%d) = %s, want between %s and %s", tt.attempts, got, tt.base, tt.base+tt.base/4)
+			}
+		}
+	}
+}
+
+func TestOutboxWorkerDeliver(t *testing.T) {
+	failing := errors.New("gateway timeout")
+	tests := []struct {
+		name     string
+		msg      *entities.OutboundMessage
+		attempts int
+		whatsapp *fakeChannel
+		sms      *fakeChannel
+		check    func(t *testing.T, o *fakeOutbox, msg *entities.OutboundMessage)
+	}{
+		{
+			name:     "sent",
+			msg:      testMessage(entities.MessageChannelWhatsApp, "+6281200000001"),
+			attempts: 1,
+			whatsapp: &fakeChannel{},
+			check: func(t *testing.T, o *fakeOutbox, msg *entities.OutboundMessage) {
+				if o.sent[msg.ID] != entities.MessageChannelWhatsApp {
+					t.Errorf("sent over %q, want whatsapp", o.sent[msg.ID])
+				}
+			},
+		},
+		{
+			name:     "falls back to the next channel",
+			msg:      testMessage(entities.MessageChannelWhatsApp, "+6281200000001", entities.MessageChannelSMS),
+			attempts: 1,
+			whatsapp: &fakeChannel{down: true},
+			sms:      &fakeChannel{},
+			check: func(t *testing.T, o *fakeOutbox, msg *entities.OutboundMessage) {
+				if o.sent[msg.ID] != entities.MessageChannelSMS {
+					t.Errorf("sent over %q, want sms", o.sent[msg.ID])
+				}
+			},
+		},
+		{
+			name:     "retried with backoff",
+			msg:      testMessage(entities.MessageChannelWhatsApp, "+6281200000001"),
+			attempts: 2,
+			whatsapp: &fakeChannel{err: failing},
+			check: func(t *testing.T, o *fakeOutbox, msg *entities.OutboundMessage) {
+				next, ok := o.retries[msg.ID]
+				if !ok {
+					t.Fatal("message was not rescheduled")
+				}
+				if wait := time.Until(next); wait < 55*time.Second || wait > 76*time.Second {
+					t.Errorf("second attempt retried in %s, want about a minute", wait)
+				}
+				if o.lastErr[msg.ID] == "" {
+					t.Error("last error was not recorded")
+				}
+			},
+		},
+		{
+			name:     "dead after max attempts",
+			msg:      testMessage(entities.MessageChannelWhatsApp, "+6281200000001"),
+			attempts: 3,
+			whatsapp: &fakeChannel{err: failing},
+			check: func(t *testing.T, o *fakeOutbox, msg *entities.OutboundMessage) {
+				if _, ok := o.dead[msg.ID]; !ok {
+					t.Error("message was not dead-lettered")
+				}
+			},
+		},
+		{
+			name:     "rate limited is deferred",
+			msg:      testMessage(entities.MessageChannelWhatsApp, "+6281200000001", entities.MessageChannelSMS),
+			attempts: 3,
+			whatsapp: &fakeChannel{err: &repositories.RateLimitedError{RetryAfter: 10 * time.Second}},
+			sms:      &fakeChannel{},
+			check: func(t *testing.T, o *fakeOutbox, msg *entities.OutboundMessage) {
+				if _, ok := o.deferred[msg.ID]; !ok {
+					t.Error("message was not deferred")
+				}
+				if _, ok := o.sent[msg.ID]; ok {
+					t.Error("rate limited message fell back to sms")
+				}
+			},
+		},
+		{
+			name:     "dead without a configured channel",
+			msg:      testMessage(entities.MessageChannelSMS, "+6281200000001"),
+			attempts: 1,
+			check: func(t *testing.T, o *fakeOutbox, msg *entities.OutboundMessage) {
+				if _, ok := o.dead[msg.ID]; !ok {
+					t.Error("message was not dead-lettered")
+				}
+			},
+		},
+		{
+			name:     "dead to a suppressed address",
+			msg:      testMessage(entities.MessageChannelEmail, "bounced@example.com"),
+			attempts: 1,
+			check: func(t *testing.T, o *fakeOutbox, msg *entities.OutboundMessage) {
+				if _, ok := o.dead[msg.ID]; !ok {
+					t.Error("message was not dead-lettered")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channels := map[entities.MessageChannel]repositories.NotificationChannel{}
+			if tt.whatsapp != nil {
+				channels[entities.MessageChannelWhatsApp] = tt.whatsapp
+			}
+			if tt.sms != nil {
+				channels[entities.MessageChannelSMS] = tt.sms
+			}
+			outbox := newFakeOutbox()
+			tt.msg.Attempts = tt.attempts
+			newTestOutboxWorker(t, outbox, channels).deliver(context.Background(), tt.msg)
+			if n := len(outbox.sent) + len(outbox.retries) + len(outbox.deferred) + len(outbox.dead); n != 1 {
+				t.Fatalf("message was updated %d times, want once", n)
+			}
+			tt.check(t, outbox, tt.msg)
+		})
+	}
+}
+
+func TestOutboxWorkerRunDrainsFullBatches(t *testing.T) {
+	var msgs []*entities.OutboundMessage
+	for range 5 {
+		msgs = append(msgs, testMessage(entities.MessageChannelWhatsApp, "+6281200000001"))
+	}
+	outbox := newFakeOutbox(msgs...)
+	whatsapp := &fakeChannel{}
+	w := newTestOutboxWorker(t, outbox, map[entities.MessageChannel]repositories.NotificationChannel{entities.MessageChannelWhatsApp: whatsapp})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- w.Run(ctx) }()
+	for range msgs {
+		select {
+		case <-outbox.done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("messages were not delivered")
+		}
+	}
+	cancel()
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+
+	// Batches of two come back full twice, the third is short and ends the drain until the next poll
+	if len(outbox.claims) != 3 {
+		t.Errorf("claimed %d times, want 3", len(outbox.claims))
+	}
+	for i := range outbox.claims {
+		if outbox.claims[i] != 2 || outbox.leases[i] != 5*time.Minute {
+			t.Errorf("claim %d asked for %d messages leased for %s, want 2 for 5m", i, outbox.claims[i], outbox.leases[i])
+		}
+	}
+	if len(outbox.sent) != len(msgs) || whatsapp.count != len(msgs) {
+		t.Errorf("sent %d of %d messages with %d sends", len(outbox.sent), len(msgs), whatsapp.count)
+	}
+}
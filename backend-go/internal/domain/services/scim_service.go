@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+// ScimGroups are the organization roles an identity provider may assign through SCIM groups.
+// Global roles, such as superuser, belong to the account and are not provisionable.
+var ScimGroups = []entities.OrganizationRole{
+	entities.OrganizationRoleOwner,
+	entities.OrganizationRoleEmployee,
+}
+
+// ScimService provisions an organization's staff from an external directory
+type ScimService struct {
+	userRepo      repositories.UserRepository
+	orgRepo       repositories.OrganizationRepository
+	scimTokenRepo repositories.ScimTokenRepository
+	txManager     repositories.TransactionManager
+	orgService    *OrganizationService
+}
+
+func NewScimService(
+	userRepo repositories.UserRepository,
+	orgRepo repositories.OrganizationRepository,
+	scimTokenRepo repositories.ScimTokenRepository,
+	txManager repositories.TransactionManager,
+	orgService *OrganizationService,
+) *ScimService {
+	return &ScimService{
+		userRepo:      userRepo,
+		orgRepo:       orgRepo,
+		scimTokenRepo: scimTokenRepo,
+		txManager:     txManager,
+		orgService:    orgService,
+	}
+}
+
+// Authenticate resolves a SCIM bearer token to its organization
+func (s *ScimService) Authenticate(ctx context.Context, token string) (uuid.UUID, error) {
+	if token == "" {
+		return uuid.Nil, ErrInvalidToken
+	}
+	// The organization is not known until the token is found
+	ctx = util.WithSystemScope(ctx)
+	t, err := s.scimTokenRepo.GetByHash(ctx, hashScimToken(token))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to load scim token: %w", err)
+	}
+	if t == nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+	if err := s.scimTokenRepo.Touch(ctx, t.ID); err != nil {
+		log.Println(fmt.Errorf("failed to touch scim token: %w", err))
+	}
+	return t.OrganizationID, nil
+}
+
+// ListUsers returns a page of the organization's members matching the filter and how many match in all
+func (s *ScimService) ListUsers(ctx context.Context, orgID uuid.UUID, filter entities.DirectoryUserFilter, offset, limit int32) ([]*entities.DirectoryUser, int, error) {
+	ctx = util.WithOrganizationID(ctx, orgID)
+	total, err := s.userRepo.CountDirectoryUsers(ctx, orgID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	users, err := s.userRepo.ListDirectoryUsers(ctx, orgID, filter, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// ListMembers returns every member of the organization, whose roles are the directory's groups
+func (s *ScimService) ListMembers(ctx context.Context, orgID uuid.UUID) ([]*entities.OrganizationMember, error) {
+	ctx = util.WithOrganizationID(ctx, orgID)
+	return s.orgRepo.ListMembers(ctx, orgID)
+}
+
+// GetUser returns a member of the organization
+func (s *ScimService) GetUser(ctx context.Context, orgID, userID uuid.UUID) (*entities.DirectoryUser, error) {
+	ctx = util.WithOrganizationID(ctx, orgID)
+	member, err := s.orgRepo.GetMember(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrUserNotFound
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return &entities.DirectoryUser{User: u, Member: member}, nil
+}
+
+// CreateUser provisions a user as an employee of the organization. An existing account with the
+// same email is not attached: it is invited, and joins only once it accepts.
+func (s *ScimService) CreateUser(ctx context.Context, orgID uuid.UUID, email, fullName string, active bool) (*entities.DirectoryUser, error) {
+	ctx = util.WithOrganizationID(ctx, orgID)
+	email = strings.ToLower(strings.TrimSpace(email))
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		member, err := s.orgRepo.GetMember(ctx, orgID, existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		if member != nil {
+			return nil, ErrUserExists
+		}
+		if err := s.orgService.InviteDirectoryUser(ctx, orgID, existing); err != nil {
+			return nil, fmt.Errorf("failed to invite user: %w", err)
+		}
+		return nil, ErrUserInvited
+	}
+
+	var userID uuid.UUID
+	err = s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		created, err := s.userRepo.WithTx(tx).Create(ctx, &entities.User{
+			Email:            email,
+			FullName:         &fullName,
+			IsActive:         active,
+			IsEmailVerified:  true, // asserted by the directory
+			ProvisionedByOrg: &orgID,
+		})
+		if err != nil {
+			return err
+		}
+		userID = created.ID
+		// The role within the organization is the membership; no global role is granted
+		_, err = s.orgRepo.WithTx(tx).AddMember(ctx, orgID, userID, entities.OrganizationRoleEmployee)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision user: %w", err)
+	}
+	return s.GetUser(ctx, orgID, userID)
+}
+
+// ReplaceUser overwrites the directory managed attributes of a member. Only accounts the organization
+// provisioned are managed by it; the name of any other is left to its owner, and a change of its email
+// or active state is refused, since it would affect the account everywhere. Such a member leaves the
+// organization through DeleteUser.
+func (s *ScimService) ReplaceUser(ctx context.Context, orgID, userID uuid.UUID, email, fullName string, active bool) (*entities.DirectoryUser, error) {
+	current, err := s.GetUser(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	ctx = util.WithOrganizationID(ctx, orgID)
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !current.User.ManagedBy(orgID) {
+		if (email != "" && email != current.User.Email) || active != current.User.IsActive {
+			return nil, ErrAccountNotManaged
+		}
+		return current, nil
+	}
+	if email != "" && email != current.User.Email {
+		other, err := s.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+		if other != nil {
+			return nil, ErrUserExists
+		}
+		if _, err := s.userRepo.UpdateEmail(ctx, userID, email); err != nil {
+			return nil, err
+		}
+	}
+	// UpdateUser rewrites roles too, so carry the current ones over
+	if _, err := s.userRepo.UpdateUser(ctx, &entities.User{
+		ID:       userID,
+		FullName: &fullName,
+		IsActive: active,
+		Roles:    current.User.Roles,
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, orgID, userID)
+}
+
+// DeleteUser removes the user from the organization; the account itself is kept
+func (s *ScimService) DeleteUser(ctx context.Context, orgID, userID uuid.UUID) error {
+	ctx = util.WithOrganizationID(ctx, orgID)
+	return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		orgRepo := s.orgRepo.WithTx(tx)
+		member, err := orgRepo.GetMember(ctx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			return ErrUserNotFound
+		}
+		if err := ensureOwnerRemains(ctx, orgRepo, orgID, member); err != nil {
+			return err
+		}
+		return orgRepo.RemoveMember(ctx, orgID, userID)
+	})
+}
+
+// SetGroupMember adds or removes a member from the group mapped to role, which changes only the
+// member's role within the organization. Leaving the owners group makes an owner an employee;
+// leaving the employees group changes nothing, as removal from the organization is DeleteUser.
+func (s *ScimService) SetGroupMember(ctx context.Context, orgID uuid.UUID, role entities.OrganizationRole, userID uuid.UUID, add bool) error {
+	if !IsScimGroup(role) {
+		return ErrInvalidRole
+	}
+	current, err := s.GetUser(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	ctx = util.WithOrganizationID(ctx, orgID)
+
+	switch {
+	case role == entities.OrganizationRoleOwner && add:
+		if current.Member.Role == entities.OrganizationRoleOwner {
+			return nil
+		}
+		_, err = s.orgRepo.AddMember(ctx, orgID, userID, entities.OrganizationRoleOwner)
+		return err
+	case role == entities.OrganizationRoleOwner && !add && current.Member.Role == entities.OrganizationRoleOwner,
+		role == entities.OrganizationRoleEmployee && add && current.Member.Role == entities.OrganizationRoleOwner:
+		return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+			orgRepo := s.orgRepo.WithTx(tx)
+			if err := ensureOwnerRemains(ctx, orgRepo, orgID, current.Member); err != nil {
+				return err
+			}
+			_, err := orgRepo.AddMember(ctx, orgID, userID, entities.OrganizationRoleEmployee)
+			return err
+		})
+	}
+	return nil
+}
+
+// IsScimGroup reports whether role can be managed through SCIM
+func IsScimGroup(role entities.OrganizationRole) bool {
+	for _, g := range ScimGroups {
+		if g == role {
+			return true
+		}
+	}
+	return false
+}
+
+// hashScimToken is the stored form of a SCIM token; the plaintext is only shown once
+func hashScimToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,444 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+const (
+	// Imports up to this size finish within the request, larger ones run as a background job
+	importInlineRows = 100
+	// How often a background job reports progress
+	importProgressEvery = 25
+	// MaxImportRows caps a single upload
+	MaxImportRows = 10000
+
+	// A job whose heartbeat is older than this was left behind by an instance that went away
+	importStaleAfter = 10 * time.Minute
+
+	exportPageSize = 500
+	welcomeLinkTTL = 7 * 24 * time.Hour
+)
+
+// ImportRoleSeparator separates multiple roles within one cell on import and export
+const ImportRoleSeparator = ";"
+
+// importableRoles are the roles an admin may assign through a bulk import
+var importableRoles = map[string]bool{
+	string(entities.RoleCustomer):             true,
+	string(entities.RoleSalonOwner):           true,
+	string(entities.OrganizationRoleEmployee): true,
+}
+
+// ExportColumns is the header row written by ExportUsers.
+// The email, phone_number, full_name and roles columns are read back by ImportUsers.
+// Cells that a spreadsheet would evaluate as a formula are exported with a leading quote.
+var ExportColumns = []string{
+	"id", "email", "phone_number", "full_name", "roles",
+	"is_active", "is_email_verified", "is_phone_verified", "created_at", "last_login_at", "deleted_at",
+}
+
+// ImportUsers validates and creates users from an uploaded file.
+// Rows are deduplicated on email and E.164 phone number, both within the file and against existing accounts.
+// Small imports complete before returning; larger ones continue in the background and are polled with GetImportJob.
+func (s *UserService) ImportUsers(ctx context.Context, adminID uuid.UUID, rows []entities.UserImportRow, opts entities.UserImportOptions) (*entities.UserImportJob, error) {
+	job, err := s.importJobRepo.Create(ctx, &entities.UserImportJob{
+		CreatedBy: &adminID,
+		Status:    entities.UserImportStatusPending,
+		DryRun:    opts.DryRun,
+		TotalRows: len(rows),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	if len(rows) <= importInlineRows {
+		s.runImport(ctx, job, rows, opts)
+		return job, nil
+	}
+
+	background := *job
+	if !s.imports.start(func(ctx context.Context) { s.runImport(ctx, &background, rows, opts) }) {
+		job.Status = entities.UserImportStatusFailed
+		job.Error = "server is shutting down"
+		if err := s.importJobRepo.Finish(ctx, job); err != nil {
+			return nil, fmt.Errorf("failed to finish import job: %w", err)
+		}
+	}
+	return job, nil
+}
+
+// RunImports runs the background imports started by ImportUsers until ctx is done, then waits for
+// them to save their report. Jobs left unfinished by an instance that went away are marked failed first.
+func (s *UserService) RunImports(ctx context.Context) error {
+	n, err := s.importJobRepo.FailStale(ctx, time.Now().Add(-importStaleAfter), "import was interrupted by a server restart")
+	if err != nil {
+		log.Println(fmt.Errorf("failed to fail stale import jobs: %w", err))
+	} else if n > 0 {
+		log.Printf("marked %d interrupted import jobs failed", n)
+	}
+
+	s.imports.run(ctx)
+	<-ctx.Done()
+	s.imports.stop()
+	return nil
+}
+
+// importRunner runs imports in the background while RunImports runs
+type importRunner struct {
+	mu  sync.Mutex
+	ctx context.Context // nil unless RunImports is running
+	wg  sync.WaitGroup
+}
+
+func (r *importRunner) run(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ctx = ctx
+}
+
+// start runs fn in the background, and reports false when imports are not being run
+func (r *importRunner) start(fn func(ctx context.Context)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ctx := r.ctx
+	if ctx == nil || ctx.Err() != nil {
+		return false
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		fn(ctx)
+	}()
+	return true
+}
+
+// stop refuses new imports and waits for the running ones to finish
+func (r *importRunner) stop() {
+	r.mu.Lock()
+	r.ctx = nil
+	r.mu.Unlock()
+	r.wg.Wait()
+}
+
+// GetImportJob returns an import job with its progress and, once finished, the per-row report
+func (s *UserService) GetImportJob(ctx context.Context, id uuid.UUID) (*entities.UserImportJob, error) {
+	job, err := s.importJobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import job: %w", err)
+	}
+	if job == nil {
+		return nil, ErrImportJobNotFound
+	}
+	return job, nil
+}
+
+// ExportUsers writes the users matching the filter as CSV, in the order the filter selects
+func (s *UserService) ExportUsers(ctx context.Context, w io.Writer, filter entities.UserFilter) error {
+	if filter.SortBy == "" {
+		filter.SortBy = entities.UserSortCreatedAt
+	}
+	if !validUserSort[filter.SortBy] {
+		return ErrInvalidSortField
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(ExportColumns); err != nil {
+		return err
+	}
+	var after *entities.UserCursor
+	for {
+		users, next, err := s.userRepo.ListUsers(ctx, filter, 0, exportPageSize, after)
+		if err != nil {
+			return fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, u := range users {
+			if err := cw.Write(exportRecord(u)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		after = next
+	}
+}
+
+func (s *UserService) runImport(ctx context.Context, job *entities.UserImportJob, rows []entities.UserImportRow, opts entities.UserImportOptions) {
+	job.Status = entities.UserImportStatusRunning
+	if err := s.importJobRepo.UpdateProgress(ctx, job); err != nil {
+		log.Println(fmt.Errorf("failed to update import job: %w", err))
+	}
+
+	seen := make(map[string]int, len(rows))
+	job.Report = make([]entities.UserImportRowResult, 0, len(rows))
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			job.Status = entities.UserImportStatusFailed
+			job.Error = fmt.Sprintf("import was interrupted: %v", err)
+			break
+		}
+		result := s.importRow(ctx, row, opts, seen)
+		job.Report = append(job.Report, result)
+		switch result.Status {
+		case entities.UserImportRowCreated, entities.UserImportRowWouldCreate:
+			job.CreatedCount++
+		case entities.UserImportRowSkipped:
+			job.SkippedCount++
+		default:
+			job.ErrorCount++
+		}
+		job.ProcessedRows++
+		if job.ProcessedRows%importProgressEvery == 0 {
+			if err := s.importJobRepo.UpdateProgress(ctx, job); err != nil {
+				log.Println(fmt.Errorf("failed to update import job: %w", err))
+			}
+		}
+	}
+	if job.Status == entities.UserImportStatusRunning {
+		job.Status = entities.UserImportStatusCompleted
+	}
+
+	// The report must be saved even when the request that started the import has gone away
+	if err := s.importJobRepo.Finish(context.WithoutCancel(ctx), job); err != nil {
+		log.Println(fmt.Errorf("failed to finish import job: %w", err))
+	}
+	now := time.Now()
+	job.FinishedAt = &now
+}
+
+func (s *UserService) importRow(ctx context.Context, row entities.UserImportRow, opts entities.UserImportOptions, seen map[string]int) entities.UserImportRowResult {
+	result := entities.UserImportRowResult{Line: row.Line}
+	invalid := func(msg string, args ...any) entities.UserImportRowResult {
+		result.Status = entities.UserImportRowInvalid
+		result.Errors = append(result.Errors, fmt.Sprintf(msg, args...))
+		return result
+	}
+
+	email := strings.ToLower(strings.TrimSpace(row.Email))
+	rawPhone := strings.TrimSpace(row.Phone)
+	if email == "" && rawPhone == "" {
+		return invalid("email or phone_number is required")
+	}
+	if email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email {
+			invalid("invalid email %q", row.Email)
+		}
+		result.Email = email
+	}
+	var phone string
+	if rawPhone != "" {
+		normalized, ok := util.NormalizeE164(rawPhone, opts.Region)
+		if !ok {
+			invalid("invalid phone number %q", rawPhone)
+		}
+		phone = normalized
+		result.Phone = phone
+	}
+	roles := make([]entities.RoleEnum, 0, len(row.Roles))
+	for _, r := range row.Roles {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if r == "" {
+			continue
+		}
+		if !importableRoles[r] {
+			invalid("invalid role %q", r)
+			continue
+		}
+		roles = append(roles, entities.RoleEnum(r))
+	}
+	if len(roles) == 0 {
+		roles = []entities.RoleEnum{entities.RoleCustomer}
+	}
+	if result.Status == entities.UserImportRowInvalid {
+		return result
+	}
+
+	if email != "" {
+		if line, ok := seen["email:"+email]; ok {
+			return skipped(result, "duplicate of line %d", line)
+		}
+		seen["email:"+email] = row.Line
+	}
+	if phone != "" {
+		if line, ok := seen["phone:"+phone]; ok {
+			return skipped(result, "duplicate of line %d", line)
+		}
+		seen["phone:"+phone] = row.Line
+	}
+
+	if email != "" {
+		existing, err := s.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			return invalid("failed to check email: %v", err)
+		}
+		if existing != nil {
+			result.UserID = existing.ID.String()
+			return skipped(result, "email already registered")
+		}
+	}
+	if phone != "" {
+		existing, err := s.userRepo.GetByPhone(ctx, phone)
+		if err != nil {
+			return invalid("failed to check phone number: %v", err)
+		}
+		if existing != nil {
+			result.UserID = existing.ID.String()
+			return skipped(result, "phone number already registered")
+		}
+	}
+
+	if opts.DryRun {
+		result.Status = entities.UserImportRowWouldCreate
+		return result
+	}
+
+	fullName := strings.TrimSpace(row.FullName)
+	user := &entities.User{
+		Email:    email,
+		FullName: &fullName,
+		IsActive: true,
+	}
+	if phone != "" {
+		user.PhoneNumber = &phone
+	}
+	err := s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		userRepoTx := s.userRepo.WithTx(tx)
+		var err error
+		user, err = userRepoTx.Create(ctx, user)
+		if err != nil {
+			return err
+		}
+		return userRepoTx.SetUserRoles(ctx, user.ID, roles)
+	})
+	if err != nil {
+		return invalid("failed to create user: %v", err)
+	}
+	result.Status = entities.UserImportRowCreated
+	result.UserID = user.ID.String()
+
+	if opts.SendWelcome {
+		if err := s.sendWelcome(ctx, user); err != nil {
+			log.Println(fmt.Errorf("failed to send welcome message: %w", err))
+			result.Errors = append(result.Errors, "welcome message not sent")
+		}
+	}
+	return result
+}
+
+// sendWelcome queues a message inviting an imported user to sign in.
+// Email users get a link to choose a password, phone-only users are pointed to phone sign-in.
+func (s *UserService) sendWelcome(ctx context.Context, user *entities.User) error {
+	name := ""
+	if user.FullName != nil {
+		name = *user.FullName
+	}
+
+	if user.Email != "" {
+		token := util.GenerateSecureToken(32)
+		v := &entities.VerificationCode{
+			UserID:        &user.ID,
+			Code:          token,
+			Type:          entities.VerificationTypePasswordReset,
+			ExpiresAt:     time.Now().Add(welcomeLinkTTL),
+			ExtraMetadata: map[string]any{"purpose": entities.VerificationPurposePasswordReset},
+		}
+		msg, err := s.notifier.Email(ctx, &user.ID, user.Locale, user.Email, entities.EmailTemplateWelcomeUser, map[string]string{
+			"name": name,
+			"link": fmt.Sprintf("%s/reset-password?token=%s", s.cfg.BaseURL, token),
+		})
+		if err != nil {
+			return err
+		}
+		return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+			return s.saveCodeAndMessage(ctx, tx, v, msg)
+		})
+	}
+
+	msg, err := s.notifier.Phone(ctx, entities.NotificationKindAccount, &user.ID, user.PreferredChannel, user.Locale, *user.PhoneNumber, entities.EmailTemplateWelcomeUserWA, map[string]string{
+		"name": name,
+		"link": fmt.Sprintf("%s/login", s.cfg.BaseURL),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.outboxRepo.Enqueue(ctx, msg)
+	return err
+}
+
+func skipped(result entities.UserImportRowResult, msg string, args ...any) entities.UserImportRowResult {
+	result.Status = entities.UserImportRowSkipped
+	result.Errors = append(result.Errors, fmt.Sprintf(msg, args...))
+	return result
+}
+
+func exportRecord(u *entities.User) []string {
+	lastLogin, deletedAt := "", ""
+	if u.LastLoginAt != nil {
+		lastLogin = u.LastLoginAt.UTC().Format(time.RFC3339)
+	}
+	if u.DeletedAt != nil {
+		deletedAt = u.DeletedAt.UTC().Format(time.RFC3339)
+	}
+	record := []string{
+		u.ID.String(),
+		u.Email,
+		fromStringPtr(u.PhoneNumber),
+		fromStringPtr(u.FullName),
+		strings.Join(u.Roles, ImportRoleSeparator),
+		strconv.FormatBool(u.IsActive),
+		strconv.FormatBool(u.IsEmailVerified),
+		strconv.FormatBool(u.IsPhoneVerified),
+		u.CreatedAt.UTC().Format(time.RFC3339),
+		lastLogin,
+		deletedAt,
+	}
+	for i, cell := range record {
+		record[i] = neutralizeFormula(cell)
+	}
+	return record
+}
+
+// formulaPrefixes start a cell that spreadsheet applications evaluate as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// neutralizeFormula quotes a cell that a spreadsheet would evaluate, so a user's name cannot run as a
+// formula on an admin's machine. E.164 phone numbers start with + and are quoted too.
+func neutralizeFormula(cell string) string {
+	if cell != "" && strings.IndexByte(formulaPrefixes, cell[0]) >= 0 {
+		return "'" + cell
+	}
+	return cell
+}
+
+// RestoreFormula undoes the quoting of an exported cell, so an export can be imported again
+func RestoreFormula(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.IndexByte(formulaPrefixes, cell[1]) >= 0 {
+		return cell[1:]
+	}
+	return cell
+}
+
+func fromStringPtr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
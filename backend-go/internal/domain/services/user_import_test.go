@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+func TestNeutralizeFormula(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"", ""},
+		{"Ana", "Ana"},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+6281234567890", "'+6281234567890"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+	}
+	for _, tt := range tests {
+		got := neutralizeFormula(tt.cell)
+		if got != tt.want {
+			t.Errorf("neutralizeFormula(%q) = %q, want %q", tt.cell, got, tt.want)
+		}
+		if back := RestoreFormula(got); back != tt.cell {
+			t.Errorf("RestoreFormula(%q) = %q, want %q", got, back, tt.cell)
+		}
+	}
+}
+
+func TestRestoreFormulaKeepsOtherQuotes(t *testing.T) {
+	for _, cell := range []string{"'", "'quoted", "O'Brien"} {
+		if got := RestoreFormula(cell); got != cell {
+			t.Errorf("RestoreFormula(%q) = %q, want it unchanged", cell, got)
+		}
+	}
+}
+
+func TestExportRecordNeutralizesFormulas(t *testing.T) {
+	name, phone := "=cmd|' /C calc'!A0", "+6281234567890"
+	u := &entities.User{
+		ID:          uuid.New(),
+		Email:       "@evil@example.com",
+		PhoneNumber: &phone,
+		FullName:    &name,
+		Roles:       []string{"customer"},
+		CreatedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	record := exportRecord(u)
+	if len(record) != len(ExportColumns) {
+		t.Fatalf("record has %d cells, want %d", len(record), len(ExportColumns))
+	}
+	for i, cell := range record {
+		if cell != "" && formulaStart(cell[0]) {
+			t.Errorf("%s cell %q would be evaluated as a formula", ExportColumns[i], cell)
+		}
+	}
+	if record[3] != "'"+name {
+		t.Errorf("full_name = %q, want %q", record[3], "'"+name)
+	}
+}
+
+func formulaStart(c byte) bool {
+	switch c {
+	case '=', '+', '-', '@', '\t', '\r':
+		return true
+	}
+	return false
+}
+
+func TestImportRunner(t *testing.T) {
+	var r importRunner
+	if r.start(func(context.Context) {}) {
+		t.Fatal("start ran an import before run")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.run(ctx)
+	started, finished := make(chan struct{}), make(chan struct{})
+	if !r.start(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(finished)
+	}) {
+		t.Fatal("start refused an import while running")
+	}
+	<-started
+
+	cancel()
+	r.stop()
+	select {
+	case <-finished:
+	default:
+		t.Fatal("stop returned before the import finished")
+	}
+	if r.start(func(context.Context) {}) {
+		t.Fatal("start ran an import after stop")
+	}
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+const maxListUsersLimit = 500
+
+var validUserSort = map[entities.UserSortField]bool{
+	entities.UserSortCreatedAt:   true,
+	entities.UserSortEmail:       true,
+	entities.UserSortFullName:    true,
+	entities.UserSortLastLoginAt: true,
+}
+
+// userPageToken records the sort it was issued for, since a cursor is meaningless in any other order
+type userPageToken struct {
+	SortBy   entities.UserSortField `json:"s"`
+	SortDesc bool                   `json:"d"`
+	entities.UserCursor
+}
+
+func encodeUserPageToken(cursor *entities.UserCursor, filter entities.UserFilter) string {
+	b, _ := json.Marshal(userPageToken{SortBy: filter.SortBy, SortDesc: filter.SortDesc, UserCursor: *cursor})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeUserPageToken(token string, filter entities.UserFilter) (*entities.UserCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var t userPageToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	if t.SortBy != filter.SortBy || t.SortDesc != filter.SortDesc {
+		return nil, ErrInvalidPageToken
+	}
+	return &t.UserCursor, nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -24,10 +25,12 @@ type UserService struct {
 	oauthRepo        repositories.OAuthRepository
 	txManager        repositories.TransactionManager
 	jwtRepo          repositories.JWTRepository
-	emailTplRepo     repositories.EmailTemplateRepository
+	notifier         *Notifier
 	verificationRepo repositories.VerificationCodeRepository
-	smtpSender       repositories.Sender
-	wahaClient       repositories.WahaClient
+	outboxRepo       repositories.OutboxRepository
+	loginAlerts      *LoginAlertService
+	importJobRepo    repositories.UserImportJobRepository
+	imports          importRunner
 }
 
 func NewUserService(
@@ -36,10 +39,11 @@ func NewUserService(
 	oauthRepo repositories.OAuthRepository,
 	txManager repositories.TransactionManager,
 	jwtRepo repositories.JWTRepository,
-	emailTplRepo repositories.EmailTemplateRepository,
+	notifier *Notifier,
 	verificationRepo repositories.VerificationCodeRepository,
-	smtpSender repositories.Sender,
-	wahaClient repositories.WahaClient,
+	outboxRepo repositories.OutboxRepository,
+	loginAlerts *LoginAlertService,
+	importJobRepo repositories.UserImportJobRepository,
 ) *UserService {
 	return &UserService{
 		cfg:              cfg,
@@ -47,17 +51,18 @@ func NewUserService(
 		oauthRepo:        oauthRepo,
 		txManager:        txManager,
 		jwtRepo:          jwtRepo,
-		emailTplRepo:     emailTplRepo,
+		notifier:         notifier,
 		verificationRepo: verificationRepo,
-		smtpSender:       smtpSender,
-		wahaClient:       wahaClient,
+		outboxRepo:       outboxRepo,
+		loginAlerts:      loginAlerts,
+		importJobRepo:    importJobRepo,
 	}
 }
 
 var ErrInvalidState = fmt.Errorf("invalid state")
 var ErrInvalidCode = fmt.Errorf("invalid code")
 var ErrInvalidToken = fmt.Errorf("invalid token")
-var ErrWeakPassword = fmt.Errorf("weak password")
+var ErrWeakPassword = fmt.Errorf("password must be at least 8 characters")
 var ErrInvalidPreviousPassword = fmt.Errorf("invalid previous password")
 var ErrUnauthorized = fmt.Errorf("unauthorized")
 
@@ -70,8 +75,45 @@ func (s *UserService) GetUserByID(ctx context.Context, id string) (*entities.Use
 	return s.userRepo.GetByID(ctx, userID)
 }
 
-func (s *UserService) ListUsers(ctx context.Context, offset, limit int32) ([]*entities.User, int, error) {
-	return s.userRepo.ListUsers(ctx, offset, limit)
+// ListUsers returns a page of users matching the filter and the token of the next page.
+// A page token continues the listing after the previous page, in which case offset is ignored.
+// The users matching the filter are counted only when includeTotal is set; the total is 0 otherwise.
+func (s *UserService) ListUsers(ctx context.Context, filter entities.UserFilter, offset, limit int32, pageToken string, includeTotal bool) ([]*entities.User, int, string, error) {
+	if filter.SortBy == "" {
+		filter.SortBy = entities.UserSortCreatedAt
+	}
+	if !validUserSort[filter.SortBy] {
+		return nil, 0, "", ErrInvalidSortField
+	}
+	if limit <= 0 || limit > maxListUsersLimit {
+		limit = maxListUsersLimit
+	}
+
+	var after *entities.UserCursor
+	if pageToken != "" {
+		cursor, err := decodeUserPageToken(pageToken, filter)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		after = cursor
+		offset = 0
+	}
+
+	users, next, err := s.userRepo.ListUsers(ctx, filter, offset, limit, after)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	total := 0
+	if includeTotal {
+		if total, err = s.userRepo.CountUsers(ctx, filter); err != nil {
+			return nil, 0, "", err
+		}
+	}
+	nextToken := ""
+	if next != nil {
+		nextToken = encodeUserPageToken(next, filter)
+	}
+	return users, total, nextToken, nil
 }
 
 func (s *UserService) CreateUser(ctx context.Context, email, password, fullName string, roles []entities.RoleEnum, isActive bool) (*entities.User, error) {
@@ -92,8 +134,15 @@ func (s *UserService) CreateUser(ctx context.Context, email, password, fullName
 		FullName:        &fullName,
 		IsActive:        isActive,
 		IsEmailVerified: false,
+		Locale:          util.ClientInfoFromContext(ctx).Locale,
+	}
+
+	v, msg, err := s.newEmailVerification(ctx, user.Locale, email)
+	if err != nil {
+		return nil, err
 	}
 
+	// The verification email is queued with the user so it only goes out if the user is committed
 	err = s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
 		userRepoTx := s.userRepo.WithTx(tx)
 
@@ -106,10 +155,10 @@ func (s *UserService) CreateUser(ctx context.Context, email, password, fullName
 		if err != nil {
 			return err
 		}
-		return nil
+		v.UserID = &user.ID
+		msg.UserID = &user.ID
+		return s.saveCodeAndMessage(ctx, tx, v, msg)
 	})
-
-	err = s.SendEmailVerification(ctx, email)
 	if err != nil {
 		return nil, err
 	}
@@ -162,16 +211,8 @@ func (s *UserService) UpdateProfile(ctx context.Context, id string, fullName *st
 }
 
 func (s *UserService) AdminUpdateUser(ctx context.Context, adminID string, targetUserID string, fullName *string, password *string, roles []string, isActive *bool) (*entities.User, error) {
-	adminUUID, err := uuid.Parse(adminID)
-	if err != nil {
-		return nil, ErrUserNotFound
-	}
-	admin, err := s.userRepo.GetByID(ctx, adminUUID)
-	if err != nil || admin == nil {
-		return nil, ErrUserNotFound
-	}
-	if !util.HasRole(admin, string(entities.RoleSuperuser)) {
-		return nil, ErrUnauthorized
+	if _, err := s.requireSuperuser(ctx, adminID); err != nil {
+		return nil, err
 	}
 	targetUUID, err := uuid.Parse(targetUserID)
 	if err != nil {
@@ -212,6 +253,99 @@ func (s *UserService) AdminUpdateUser(ctx context.Context, adminID string, targe
 	return updated, nil
 }
 
+// AdminDeleteUser soft deletes a user. Payments and subscriptions stay attached to the deleted account,
+// while the email and phone number become free for a new registration.
+func (s *UserService) AdminDeleteUser(ctx context.Context, adminID string, targetUserID string) error {
+	admin, err := s.requireSuperuser(ctx, adminID)
+	if err != nil {
+		return err
+	}
+	targetUUID, err := uuid.Parse(targetUserID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if targetUUID == admin.ID {
+		return ErrCannotDeleteSelf
+	}
+	deleted, err := s.userRepo.SoftDelete(ctx, targetUUID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if !deleted {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// AdminRestoreUser brings back a soft deleted user, unless their email or phone number was registered again
+func (s *UserService) AdminRestoreUser(ctx context.Context, adminID string, targetUserID string) (*entities.User, error) {
+	if _, err := s.requireSuperuser(ctx, adminID); err != nil {
+		return nil, err
+	}
+	targetUUID, err := uuid.Parse(targetUserID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := s.userRepo.Restore(ctx, targetUUID)
+	if errors.Is(err, repositories.ErrUserConflict) {
+		return nil, ErrUserExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (s *UserService) requireSuperuser(ctx context.Context, adminID string) (*entities.User, error) {
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	admin, err := s.userRepo.GetByID(ctx, adminUUID)
+	if err != nil || admin == nil {
+		return nil, ErrUserNotFound
+	}
+	if !util.HasRole(admin, string(entities.RoleSuperuser)) {
+		return nil, ErrUnauthorized
+	}
+	return admin, nil
+}
+
+// SetPreferredChannel picks the channel tried first for messages to the user's phone.
+// An empty channel goes back to the configured route order.
+func (s *UserService) SetPreferredChannel(ctx context.Context, id string, channel entities.MessageChannel) (*entities.User, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	switch channel {
+	case "", entities.MessageChannelWhatsApp, entities.MessageChannelSMS:
+	default:
+		return nil, ErrInvalidChannel
+	}
+	return s.userRepo.SetPreferredChannel(ctx, userID, channel)
+}
+
+// SetLocale picks the language of the user's messages.
+// An empty locale follows the language of each request instead.
+func (s *UserService) SetLocale(ctx context.Context, id string, locale string) (*entities.User, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if locale != "" {
+		normalized, ok := util.NormalizeLocale(locale)
+		if !ok {
+			return nil, ErrInvalidLocale
+		}
+		locale = normalized
+	}
+	return s.userRepo.SetLocale(ctx, userID, locale)
+}
+
 func (s *UserService) AddPhoneNumber(ctx context.Context, id string, phone string, region string) error {
 	userID, err := uuid.Parse(id)
 	if err != nil {
@@ -246,10 +380,13 @@ func (s *UserService) AddPhoneNumber(ctx context.Context, id string, phone strin
 		ExpiresAt:     time.Now().Add(10 * time.Minute),
 		ExtraMetadata: map[string]any{"purpose": entities.VerificationPurposeAddPhone, "new_phone": normalized},
 	}
-	if err := s.verificationRepo.Create(ctx, v); err != nil {
+	msg, err := s.phoneOTPMessage(ctx, &user.ID, user.PreferredChannel, user.Locale, normalized, code)
+	if err != nil {
 		return err
 	}
-	return s.SendPhoneOTPViaWAHA(ctx, normalized, code, entities.EmailTemplateVerificationPhone)
+	return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		return s.saveCodeAndMessage(ctx, tx, v, msg)
+	})
 }
 
 func (s *UserService) VerifyAddPhone(ctx context.Context, id string, code string) error {
@@ -268,20 +405,55 @@ func (s *UserService) VerifyAddPhone(ctx context.Context, id string, code string
 	if v.UsedAt != nil || time.Now().After(v.ExpiresAt) {
 		return ErrInvalidOrExpiredCode
 	}
+	return s.completeAddPhone(ctx, user.ID, v)
+}
+
+// VerifyPhoneByReply confirms a number being added to an account when a WhatsApp message from
+// that number quotes the code's message or repeats the code. WhatsApp vouches for the sender,
+// so the reply proves the number is theirs as well as typing the code would.
+// Messages that match no pending verification are ignored.
+func (s *UserService) VerifyPhoneByReply(ctx context.Context, msg entities.WhatsAppMessageReceived) error {
+	if msg.From == "" {
+		return nil
+	}
+	v, err := s.verificationRepo.GetPendingAddPhone(ctx, msg.From)
+	if err != nil {
+		return err
+	}
+	if v == nil || v.UserID == nil {
+		return nil
+	}
+	if !strings.Contains(msg.Body, v.Code) {
+		if msg.ReplyToID == "" {
+			return nil
+		}
+		quoted, err := s.outboxRepo.GetByExternalID(ctx, entities.MessageChannelWhatsApp, msg.ReplyToID)
+		if err != nil {
+			return err
+		}
+		if quoted == nil || quoted.UserID == nil || *quoted.UserID != *v.UserID || quoted.Recipient != msg.From {
+			return nil
+		}
+	}
+	return s.completeAddPhone(ctx, *v.UserID, v)
+}
+
+// completeAddPhone moves the user to the number v was sent to
+func (s *UserService) completeAddPhone(ctx context.Context, userID uuid.UUID, v *entities.VerificationCode) error {
 	newPhone, _ := v.ExtraMetadata["new_phone"].(string)
 	if newPhone == "" {
 		return ErrInvalidState
 	}
-	if existing, _ := s.userRepo.GetByPhone(ctx, newPhone); existing != nil && existing.ID != user.ID {
+	if existing, _ := s.userRepo.GetByPhone(ctx, newPhone); existing != nil && existing.ID != userID {
 		return ErrUserExists
 	}
 	if err := s.verificationRepo.MarkUsed(ctx, v.ID); err != nil {
 		return err
 	}
-	if _, err := s.userRepo.UpdatePhone(ctx, user.ID, newPhone); err != nil {
+	if _, err := s.userRepo.UpdatePhone(ctx, userID, newPhone); err != nil {
 		return err
 	}
-	return s.userRepo.SetPhoneVerified(ctx, user.ID)
+	return s.userRepo.SetPhoneVerified(ctx, userID)
 }
 
 func (s *UserService) AddEmail(ctx context.Context, id string, email string) error {
@@ -314,17 +486,13 @@ func (s *UserService) AddEmail(ctx context.Context, id string, email string) err
 		ExpiresAt:     time.Now().Add(15 * time.Minute),
 		ExtraMetadata: map[string]any{"purpose": entities.VerificationPurposeAddEmail, "new_email": email},
 	}
-	if err := s.verificationRepo.Create(ctx, v); err != nil {
+	msg, err := s.notifier.Email(ctx, &user.ID, user.Locale, email, entities.EmailTemplateVerificationEmail, map[string]string{"code": code})
+	if err != nil {
 		return err
 	}
-	if s.smtpSender != nil {
-		tpl, tplErr := s.emailTplRepo.GetByName(ctx, entities.EmailTemplateVerificationEmail)
-		if tplErr == nil {
-			body, _ := util.FillTextTemplate(tpl.Body, map[string]string{"code": code})
-			go func() { _ = s.smtpSender.Send(entities.Message{To: email, Subject: tpl.Subject, Body: body}) }()
-		}
-	}
-	return nil
+	return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		return s.saveCodeAndMessage(ctx, tx, v, msg)
+	})
 }
 
 func (s *UserService) VerifyAddEmail(ctx context.Context, id string, code string) error {
@@ -391,6 +559,7 @@ func (s *UserService) Login(
 	if err != nil {
 		return nil, err
 	}
+	s.recordLogin(ctx, user)
 
 	accessToken, err := s.jwtRepo.GenerateToken(user.ID, user.Email, user.Roles)
 	if err != nil {
@@ -415,6 +584,18 @@ func (s *UserService) RefreshToken(
 	ctx context.Context,
 	refreshToken string,
 ) (*entities.TokenResult, error) {
+	claims, err := s.jwtRepo.ValidateToken(refreshToken)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || user == nil || !user.IsActive {
+		return nil, ErrInvalidRefreshToken
+	}
+	if user.SessionRevoked(claims.IssuedAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
 	newRefreshToken, err := s.jwtRepo.RefreshToken(refreshToken)
 	if err != nil {
 		return nil, ErrInvalidRefreshToken
@@ -423,6 +604,24 @@ func (s *UserService) RefreshToken(
 	return newRefreshToken, nil
 }
 
+// SecureAccount revokes all sessions using the token from a new device notification
+func (s *UserService) SecureAccount(ctx context.Context, token string) error {
+	if s.loginAlerts == nil {
+		return ErrInvalidOrExpiredCode
+	}
+	return s.loginAlerts.SecureAccount(ctx, token)
+}
+
+// recordLogin tracks the login device; failures must not block the login itself
+func (s *UserService) recordLogin(ctx context.Context, user *entities.User) {
+	if s.loginAlerts == nil {
+		return
+	}
+	if err := s.loginAlerts.RecordLogin(ctx, user); err != nil {
+		log.Println(fmt.Errorf("failed to record login: %w", err))
+	}
+}
+
 // Generate a numeric OTP code of given length
 func generateOTP(length int) (string, error) {
 	var b strings.Builder
@@ -436,47 +635,59 @@ func generateOTP(length int) (string, error) {
 	return b.String(), nil
 }
 
-// SendEmailVerification generates a verification code and sends an email using template
+// SendEmailVerification generates a verification code and queues the email using template
 func (s *UserService) SendEmailVerification(ctx context.Context, email string) error {
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil || user == nil {
 		return ErrUserNotFound
 	}
+	suppression, err := s.notifier.EmailSuppression(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	if suppression != nil {
+		return fmt.Errorf("%w: %s", ErrEmailSuppressed, suppression.Reason)
+	}
 
-	code, err := generateOTP(6)
+	v, msg, err := s.newEmailVerification(ctx, user.Locale, user.Email)
 	if err != nil {
-		return fmt.Errorf("failed to generate code: %w", err)
+		return err
 	}
+	v.UserID = &user.ID
+	msg.UserID = &user.ID
+	return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		return s.saveCodeAndMessage(ctx, tx, v, msg)
+	})
+}
 
-	expires := time.Now().Add(15 * time.Minute)
+// newEmailVerification creates an email verification code and renders its email.
+// The caller sets the user and stores both with saveCodeAndMessage.
+func (s *UserService) newEmailVerification(ctx context.Context, locale, email string) (*entities.VerificationCode, *entities.OutboundMessage, error) {
+	code, err := generateOTP(6)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate code: %w", err)
+	}
 	v := &entities.VerificationCode{
-		UserID:        &user.ID,
 		Code:          code,
 		Type:          entities.VerificationTypeEmail,
-		ExpiresAt:     expires,
+		ExpiresAt:     time.Now().Add(15 * time.Minute),
 		ExtraMetadata: map[string]any{"purpose": entities.VerificationPurposeEmailVerification},
 	}
-	if err := s.verificationRepo.Create(ctx, v); err != nil {
-		return fmt.Errorf("failed to save verification code: %w", err)
+	msg, err := s.notifier.Email(ctx, nil, locale, email, entities.EmailTemplateVerificationEmail, map[string]string{"code": code})
+	if err != nil {
+		return nil, nil, err
 	}
+	return v, msg, nil
+}
 
-	tpl, err := s.emailTplRepo.GetByName(ctx, entities.EmailTemplateVerificationEmail)
-	if err != nil {
-		return fmt.Errorf("failed to load email template: %w", err)
+// saveCodeAndMessage stores a verification code together with the message that carries it
+func (s *UserService) saveCodeAndMessage(ctx context.Context, tx pgx.Tx, v *entities.VerificationCode, msg *entities.OutboundMessage) error {
+	if err := s.verificationRepo.WithTx(tx).Create(ctx, v); err != nil {
+		return fmt.Errorf("failed to save verification code: %w", err)
 	}
-	fieldMap := map[string]string{
-		"code": code,
+	if _, err := s.outboxRepo.WithTx(tx).Enqueue(ctx, msg); err != nil {
+		return fmt.Errorf("failed to enqueue message: %w", err)
 	}
-	body, err := util.FillTextTemplate(tpl.Body, fieldMap)
-	if err != nil {
-		return fmt.Errorf("failed to load email template: %w", err)
-	}
-	msg := entities.Message{To: user.Email, Subject: tpl.Subject, Body: body}
-	go func() {
-		if err := s.smtpSender.Send(msg); err != nil {
-			log.Println(fmt.Errorf("failed to send email: %w", err))
-		}
-	}()
 	return nil
 }
 
@@ -498,26 +709,15 @@ func (s *UserService) RequestPasswordReset(ctx context.Context, email string) er
 		ExpiresAt:     expires,
 		ExtraMetadata: map[string]any{"purpose": entities.VerificationPurposePasswordReset},
 	}
-	if err := s.verificationRepo.Create(ctx, v); err != nil {
-		return fmt.Errorf("failed to save verification code: %w", err)
-	}
 
-	tpl, err := s.emailTplRepo.GetByName(ctx, entities.EmailTemplatePasswordReset)
-	if err != nil {
-		return fmt.Errorf("failed to load email template: %w", err)
-	}
 	link := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.BaseURL, token)
-	body, err := util.FillTextTemplate(tpl.Body, map[string]string{"link": link})
+	msg, err := s.notifier.Email(ctx, &user.ID, user.Locale, user.Email, entities.EmailTemplatePasswordReset, map[string]string{"link": link})
 	if err != nil {
-		return fmt.Errorf("failed to render email template: %w", err)
+		return err
 	}
-	msg := entities.Message{To: user.Email, Subject: tpl.Subject, Body: body}
-	go func() {
-		if err := s.smtpSender.Send(msg); err != nil {
-			log.Println(fmt.Errorf("failed to send email: %w", err))
-		}
-	}()
-	return nil
+	return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		return s.saveCodeAndMessage(ctx, tx, v, msg)
+	})
 }
 
 // ResetPassword validates token and updates user's password
@@ -572,10 +772,13 @@ func (s *UserService) RequestPhoneOTP(ctx context.Context, phone string, region
 		ExpiresAt:     expires,
 		ExtraMetadata: map[string]any{"purpose": entities.VerificationPurposePhoneOTP},
 	}
-	if err := s.verificationRepo.Create(ctx, v); err != nil {
-		return fmt.Errorf("failed to save verification code: %w", err)
+	msg, err := s.phoneOTPMessage(ctx, &user.ID, user.PreferredChannel, user.Locale, normalized, code)
+	if err != nil {
+		return err
 	}
-	return s.SendPhoneOTPViaWAHA(ctx, normalized, code, entities.EmailTemplateVerificationPhone)
+	return s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		return s.saveCodeAndMessage(ctx, tx, v, msg)
+	})
 }
 
 var ErrInvalidOrExpiredCode = fmt.Errorf("invalid or expired code")
@@ -626,6 +829,7 @@ func (s *UserService) LoginWithPhone(ctx context.Context, phone, code string, re
 	if err := s.userRepo.SetPhoneVerified(ctx, user.ID); err != nil {
 		return nil, fmt.Errorf("failed to set phone verified: %w", err)
 	}
+	s.recordLogin(ctx, user)
 	accessToken, err := s.jwtRepo.GenerateToken(user.ID, user.Email, user.Roles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
@@ -669,12 +873,18 @@ func (s *UserService) RegisterPhoneUser(ctx context.Context, phone, fullName, re
 		"code":     code,
 	}
 
-	_, err = s.verificationRepo.CreateNoUser(ctx, token, entities.VerificationTypePhoneRegistration, extraMetadata, time.Now().Add(24*time.Hour))
+	msg, err := s.phoneOTPMessage(ctx, nil, "", "", normalized, code)
 	if err != nil {
 		return "", err
 	}
-
-	err = s.SendPhoneOTPViaWAHA(ctx, normalized, code, entities.EmailTemplateVerificationPhone)
+	err = s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		_, err := s.verificationRepo.WithTx(tx).CreateNoUser(ctx, token, entities.VerificationTypePhoneRegistration, extraMetadata, time.Now().Add(24*time.Hour))
+		if err != nil {
+			return err
+		}
+		_, err = s.outboxRepo.WithTx(tx).Enqueue(ctx, msg)
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
@@ -714,6 +924,7 @@ func (s *UserService) VerifyRegisterPhoneUser(ctx context.Context, token, otpCod
 		IsActive:        true,
 		IsEmailVerified: false,
 		IsPhoneVerified: true, // Since verified via OTP
+		Locale:          util.ClientInfoFromContext(ctx).Locale,
 	}
 
 	err = s.txManager.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
@@ -757,33 +968,10 @@ func (s *UserService) VerifyRegisterPhoneUser(ctx context.Context, token, otpCod
 	}, nil
 }
 
-func (s *UserService) SendPhoneOTPViaWAHA(
-	ctx context.Context,
-	phone string,
-	code string,
-	templateName entities.EmailTemplateEnum,
-) error {
-	if s.wahaClient == nil {
-		return nil
-	}
-
-	tpl, err := s.emailTplRepo.GetByName(ctx, templateName)
-	if err != nil {
-		return fmt.Errorf("failed to get template: %w", err)
-	}
-
-	body, err := util.FillTextTemplate(tpl.Body, map[string]string{
+// phoneOTPMessage renders the message carrying a phone verification code.
+// It goes out on the OTP route, so whether that is WhatsApp or SMS is a matter of configuration.
+func (s *UserService) phoneOTPMessage(ctx context.Context, userID *uuid.UUID, preferred entities.MessageChannel, locale, phone, code string) (*entities.OutboundMessage, error) {
+	return s.notifier.Phone(ctx, entities.NotificationKindOTP, userID, preferred, locale, phone, entities.EmailTemplateVerificationPhone, map[string]string{
 		"code": code,
 	})
-	if err != nil {
-		return fmt.Errorf("failed to fill template: %w", err)
-	}
-
-	go func() {
-		if err := s.wahaClient.SendText(context.Background(), phone, body); err != nil {
-			log.Println(fmt.Errorf("failed to send WhatsApp OTP: %w", err))
-		}
-	}()
-
-	return nil
 }
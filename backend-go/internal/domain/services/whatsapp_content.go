@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+// WhatsApp limits on interactive messages
+const (
+	maxWhatsAppButtons    = 3
+	maxWhatsAppButtonText = 20
+	maxWhatsAppListRows   = 10
+)
+
+// renderWhatsAppContent fills the text of every part of a template's content, leaving the template untouched
+func renderWhatsAppContent(c *entities.WhatsAppContent, fields map[string]string) (*entities.WhatsAppContent, error) {
+	if c == nil {
+		return nil, nil
+	}
+	var errs []error
+	fill := func(s string) string {
+		out, err := util.FillTextTemplate(s, fields)
+		errs = append(errs, err)
+		return out
+	}
+
+	out := &entities.WhatsAppContent{Footer: fill(c.Footer)}
+	if c.Media != nil {
+		media := *c.Media
+		media.URL = fill(media.URL)
+		media.Filename = fill(media.Filename)
+		out.Media = &media
+	}
+	if c.Location != nil {
+		out.Location = &entities.WhatsAppLocation{
+			Latitude:  fill(c.Location.Latitude),
+			Longitude: fill(c.Location.Longitude),
+			Title:     fill(c.Location.Title),
+		}
+	}
+	for _, b := range c.Buttons {
+		out.Buttons = append(out.Buttons, entities.WhatsAppButton{
+			Type:  b.Type,
+			Text:  fill(b.Text),
+			URL:   fill(b.URL),
+			Phone: fill(b.Phone),
+		})
+	}
+	if c.List != nil {
+		list := &entities.WhatsAppList{Button: fill(c.List.Button)}
+		for _, section := range c.List.Sections {
+			rendered := entities.WhatsAppListSection{Title: fill(section.Title)}
+			for _, row := range section.Rows {
+				rendered.Rows = append(rendered.Rows, entities.WhatsAppListRow{
+					ID:          row.ID,
+					Title:       fill(row.Title),
+					Description: fill(row.Description),
+				})
+			}
+			list.Sections = append(list.Sections, rendered)
+		}
+		out.List = list
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// validateWhatsAppContent checks what WhatsApp would reject. Fields that are still
+// templates are only checked once rendered, so a URL or coordinate may be a placeholder.
+func validateWhatsAppContent(c *entities.WhatsAppContent) error {
+	if c.Media != nil {
+		if c.Media.Kind != entities.WhatsAppMediaImage && c.Media.Kind != entities.WhatsAppMediaFile {
+			return fmt.Errorf("media kind must be %q or %q", entities.WhatsAppMediaImage, entities.WhatsAppMediaFile)
+		}
+		if err := validateContentURL(c.Media.URL); err != nil {
+			return fmt.Errorf("media url: %w", err)
+		}
+	}
+	if c.Location != nil {
+		if err := validateCoordinate(c.Location.Latitude, 90); err != nil {
+			return fmt.Errorf("latitude: %w", err)
+		}
+		if err := validateCoordinate(c.Location.Longitude, 180); err != nil {
+			return fmt.Errorf("longitude: %w", err)
+		}
+	}
+	if len(c.Buttons) > 0 && c.List != nil {
+		return errors.New("a message has either buttons or a list")
+	}
+	if len(c.Buttons) > maxWhatsAppButtons {
+		return fmt.Errorf("at most %d buttons are allowed", maxWhatsAppButtons)
+	}
+	for _, b := range c.Buttons {
+		if strings.TrimSpace(b.Text) == "" {
+			return errors.New("button text is required")
+		}
+		if !isTemplated(b.Text) && len([]rune(b.Text)) > maxWhatsAppButtonText {
+			return fmt.Errorf("button text %q is longer than %d characters", b.Text, maxWhatsAppButtonText)
+		}
+		switch b.Type {
+		case entities.WhatsAppButtonReply:
+		case entities.WhatsAppButtonURL:
+			if err := validateContentURL(b.URL); err != nil {
+				return fmt.Errorf("button %q url: %w", b.Text, err)
+			}
+		case entities.WhatsAppButtonCall:
+			if strings.TrimSpace(b.Phone) == "" {
+				return fmt.Errorf("button %q needs a phone number", b.Text)
+			}
+		default:
+			return fmt.Errorf("button type must be reply, url or call, not %q", b.Type)
+		}
+	}
+	if c.List != nil {
+		if strings.TrimSpace(c.List.Button) == "" {
+			return errors.New("list button text is required")
+		}
+		rows := 0
+		for _, section := range c.List.Sections {
+			for _, row := range section.Rows {
+				if row.ID == "" || strings.TrimSpace(row.Title) == "" {
+					return errors.New("list rows need an id and a title")
+				}
+				rows++
+			}
+		}
+		if rows == 0 || rows > maxWhatsAppListRows {
+			return fmt.Errorf("a list has 1 to %d rows", maxWhatsAppListRows)
+		}
+	}
+	return nil
+}
+
+func isTemplated(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+func validateContentURL(s string) error {
+	if isTemplated(s) {
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an http or https URL")
+	}
+	return nil
+}
+
+func validateCoordinate(s string, limit float64) error {
+	if isTemplated(s) {
+		return nil
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || f < -limit || f > limit {
+		return fmt.Errorf("must be a number between -%g and %g", limit, limit)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+// WhatsAppService takes in what WAHA reports about our sessions: messages people send us,
+// receipts for the messages we sent, and changes in the state of each session.
+// It also runs the sessions salons send from with their own number.
+type WhatsAppService struct {
+	outboxRepo  repositories.OutboxRepository
+	sessionRepo repositories.WhatsAppSessionRepository
+	wahaClient  repositories.WahaClient
+	bus         *EventBus
+}
+
+func NewWhatsAppService(
+	outboxRepo repositories.OutboxRepository,
+	sessionRepo repositories.WhatsAppSessionRepository,
+	wahaClient repositories.WahaClient,
+	bus *EventBus,
+) *WhatsAppService {
+	return &WhatsAppService{
+		outboxRepo:  outboxRepo,
+		sessionRepo: sessionRepo,
+		wahaClient:  wahaClient,
+		bus:         bus,
+	}
+}
+
+// ReceiveMessage publishes the message to the modules subscribed to WhatsAppMessageReceived
+func (s *WhatsAppService) ReceiveMessage(ctx context.Context, msg entities.WhatsAppMessageReceived) error {
+	s.touch(ctx, msg.Session)
+	return s.bus.Publish(ctx, msg)
+}
+
+// RecordAck applies a receipt to the outbox message it belongs to. Receipts for messages sent
+// from the phone itself, rather than through the outbox, match nothing and are dropped.
+func (s *WhatsAppService) RecordAck(ctx context.Context, session, messageID string, ack entities.MessageAck, detail string) error {
+	s.touch(ctx, session)
+	if messageID == "" {
+		return nil
+	}
+	if _, err := s.outboxRepo.RecordAck(ctx, entities.MessageChannelWhatsApp, messageID, ack, detail); err != nil {
+		return fmt.Errorf("failed to record whatsapp ack: %w", err)
+	}
+	return nil
+}
+
+// RefreshMessageStatus asks WAHA for the receipt of a sent message, for when its webhook event was missed.
+// Admins refresh the messages of every salon, so it runs in the system scope.
+func (s *WhatsAppService) RefreshMessageStatus(ctx context.Context, id uuid.UUID) (*entities.OutboundMessage, error) {
+	ctx = util.WithSystemScope(ctx)
+	msg, err := s.outboxRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbound message: %w", err)
+	}
+	if msg == nil {
+		return nil, ErrOutboundMessageNotFound
+	}
+	if msg.Channel != entities.MessageChannelWhatsApp || msg.Status != entities.OutboundMessageSent || msg.ExternalID == "" {
+		return nil, ErrMessageNotOnWhatsApp
+	}
+	client := s.wahaClient
+	if msg.Sender != "" {
+		client = client.Session(msg.Sender)
+	}
+	ack, err := client.MessageStatus(ctx, msg.Recipient, msg.ExternalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get whatsapp message status: %w", err)
+	}
+	if ack == "" {
+		return msg, nil
+	}
+	if _, err := s.outboxRepo.RecordAck(ctx, entities.MessageChannelWhatsApp, msg.ExternalID, ack, "whatsapp status lookup"); err != nil {
+		return nil, fmt.Errorf("failed to record whatsapp ack: %w", err)
+	}
+	return s.outboxRepo.GetByID(ctx, id)
+}
+
+// SetSessionStatus records the new status and publishes WhatsAppSessionStatusChanged when it differs from the last one
+func (s *WhatsAppService) SetSessionStatus(ctx context.Context, session, status string) error {
+	_, previous, err := s.sessionRepo.SetStatus(ctx, session, status)
+	if err != nil {
+		return fmt.Errorf("failed to record whatsapp session status: %w", err)
+	}
+	if previous == status {
+		return nil
+	}
+	if status != entities.WhatsAppSessionWorking {
+		log.Printf("whatsapp session %s is %s, was %q", session, status, previous)
+	}
+	return s.bus.Publish(ctx, entities.WhatsAppSessionStatusChanged{
+		Session:        session,
+		Status:         status,
+		PreviousStatus: previous,
+	})
+}
+
+// ListSessions returns the last reported state of every session that sent a status event, for admins
+func (s *WhatsAppService) ListSessions(ctx context.Context) ([]*entities.WhatsAppSession, error) {
+	return s.sessionRepo.List(util.WithSystemScope(ctx))
+}
+
+// OrganizationSessionName is the WAHA session of a salon; WAHA allows letters, digits, - and _
+func OrganizationSessionName(orgID uuid.UUID) string {
+	return "org_" + strings.ReplaceAll(orgID.String(), "-", "")
+}
+
+// StartOrganizationSession starts the salon's session, creating it the first time.
+// A new session waits in SCAN_QR_CODE until the salon's phone is paired.
+func (s *WhatsAppService) StartOrganizationSession(ctx context.Context, orgID uuid.UUID) (*entities.WhatsAppSession, error) {
+	name := OrganizationSessionName(orgID)
+	if _, err := s.sessionRepo.CreateForOrganization(ctx, name, orgID, entities.WhatsAppSessionStarting); err != nil {
+		return nil, fmt.Errorf("failed to save whatsapp session: %w", err)
+	}
+	if err := s.wahaClient.Session(name).StartSession(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWhatsAppUnavailable, err)
+	}
+	return s.OrganizationSession(ctx, orgID)
+}
+
+// OrganizationSession returns the salon's session with its status fresh from WAHA,
+// which also covers status changes whose webhook events were missed
+func (s *WhatsAppService) OrganizationSession(ctx context.Context, orgID uuid.UUID) (*entities.WhatsAppSession, error) {
+	session, err := s.sessionRepo.GetByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get whatsapp session: %w", err)
+	}
+	if session == nil {
+		return nil, ErrWhatsAppSessionNotFound
+	}
+	status, err := s.wahaClient.Session(session.Name).SessionStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWhatsAppUnavailable, err)
+	}
+	if err := s.SetSessionStatus(ctx, session.Name, status); err != nil {
+		return nil, err
+	}
+	return s.sessionRepo.GetByOrganization(ctx, orgID)
+}
+
+// OrganizationQRCode returns the PNG code the salon scans with WhatsApp to link its phone
+func (s *WhatsAppService) OrganizationQRCode(ctx context.Context, orgID uuid.UUID) ([]byte, error) {
+	session, err := s.OrganizationSession(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != entities.WhatsAppSessionScanQRCode {
+		return nil, ErrWhatsAppNotPairing
+	}
+	png, err := s.wahaClient.Session(session.Name).QRCode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWhatsAppUnavailable, err)
+	}
+	return png, nil
+}
+
+// StopOrganizationSession stops the salon's session, after which its messages go out from the
+// platform number. logout also unlinks the phone, as when the salon changes numbers.
+func (s *WhatsAppService) StopOrganizationSession(ctx context.Context, orgID uuid.UUID, logout bool) error {
+	session, err := s.sessionRepo.GetByOrganization(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get whatsapp session: %w", err)
+	}
+	if session == nil {
+		return ErrWhatsAppSessionNotFound
+	}
+	if err := s.wahaClient.Session(session.Name).StopSession(ctx, logout); err != nil {
+		return fmt.Errorf("%w: %v", ErrWhatsAppUnavailable, err)
+	}
+	return s.SetSessionStatus(ctx, session.Name, entities.WhatsAppSessionStopped)
+}
+
+// touch is best effort, a missed update only makes a session look idle for a while
+func (s *WhatsAppService) touch(ctx context.Context, session string) {
+	if session == "" {
+		return
+	}
+	if err := s.sessionRepo.Touch(ctx, session); err != nil {
+		log.Println(fmt.Errorf("failed to touch whatsapp session %s: %w", session, err))
+	}
+}
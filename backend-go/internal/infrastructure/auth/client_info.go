@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// TrustedProxies are the hops whose X-Forwarded-For entries are believed
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies reads comma separated IPs and CIDRs, such as "10.0.0.0/8, 192.0.2.10"
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.Contains(part, "/") {
+			ip := net.ParseIP(part)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", part)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", part, err)
+		}
+		proxies = append(proxies, n)
+	}
+	return proxies, nil
+}
+
+// contains reports whether ip is a trusted proxy. Loopback always is, since grpc-gateway proxies
+// HTTP requests to the gRPC server from the same host.
+func (t TrustedProxies) contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	for _, n := range t {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// GRPCClientInfoInterceptor stores the caller's user agent, IP address and language in context.
+// Requests proxied by grpc-gateway carry the browser values in forwarded metadata.
+func GRPCClientInfoInterceptor(trusted TrustedProxies) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(util.WithClientInfo(ctx, clientInfoFromGRPCContext(ctx, trusted)), req)
+	}
+}
+
+func clientInfoFromGRPCContext(ctx context.Context, trusted TrustedProxies) entities.ClientInfo {
+	var ci entities.ClientInfo
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	// grpc-gateway prefixes forwarded HTTP headers it does not recognise
+	if ua := firstMetadata(md, "grpcgateway-user-agent"); ua != "" {
+		ci.UserAgent = ua
+	} else {
+		ci.UserAgent = firstMetadata(md, "user-agent")
+	}
+
+	if al := firstMetadata(md, "grpcgateway-accept-language"); al != "" {
+		ci.Locale = util.LocaleFromAcceptLanguage(al)
+	} else {
+		ci.Locale = util.LocaleFromAcceptLanguage(firstMetadata(md, "accept-language"))
+	}
+
+	var peerIP string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			peerIP = host
+		}
+	}
+	ci.IPAddress = clientIP(peerIP, firstMetadata(md, "x-forwarded-for"), trusted)
+	return ci
+}
+
+// clientIP is the address the request came from. X-Forwarded-For is "client, proxy1, proxy2" with
+// each proxy appending the address it was reached from, so it is read from the right and the first
+// hop that is not a trusted proxy is the client. Any entry left of it may be made up by the client.
+// The header is ignored unless peerIP, who sent it, is trusted.
+func clientIP(peerIP, xff string, trusted TrustedProxies) string {
+	if xff == "" || !trusted.contains(net.ParseIP(peerIP)) {
+		return peerIP
+	}
+	hops := strings.Split(xff, ",")
+	last := peerIP
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		ip := net.ParseIP(hop)
+		if ip == nil {
+			// A malformed entry cannot be traced further back
+			break
+		}
+		if !trusted.contains(ip) {
+			return hop
+		}
+		last = hop
+	}
+	return last
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,37 @@
+package auth
+
+import "testing"
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.0.2.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, peer, xff, want string
+	}{
+		{"no header", "203.0.113.5", "", "203.0.113.5"},
+		{"untrusted peer cannot forward", "203.0.113.5", "198.51.100.1", "203.0.113.5"},
+		{"gateway appends the http peer", "127.0.0.1", "203.0.113.5", "203.0.113.5"},
+		{"spoofed entry left of the real client", "127.0.0.1", "1.2.3.4, 203.0.113.5", "203.0.113.5"},
+		{"trusted load balancers are skipped", "127.0.0.1", "1.2.3.4, 203.0.113.5, 10.1.2.3, 192.0.2.10", "203.0.113.5"},
+		{"all hops trusted", "127.0.0.1", "10.0.0.1, 10.0.0.2", "10.0.0.1"},
+		{"malformed entry stops the walk", "127.0.0.1", "evil, 10.0.0.2", "10.0.0.2"},
+		{"ipv6 loopback gateway", "::1", "2001:db8::1", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clientIP(tt.peer, tt.xff, trusted); got != tt.want {
+				t.Errorf("clientIP(%q, %q) = %q, want %q", tt.peer, tt.xff, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxiesRejectsGarbage(t *testing.T) {
+	for _, s := range []string{"not-an-ip", "10.0.0.0/99"} {
+		if _, err := ParseTrustedProxies(s); err == nil {
+			t.Errorf("ParseTrustedProxies(%q) succeeded, want error", s)
+		}
+	}
+}
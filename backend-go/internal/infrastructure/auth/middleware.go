@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
 	"google.golang.org/grpc"
@@ -24,10 +26,12 @@ var (
 		"/v1/user":                   {"POST": true},
 		"/v1/password-recovery":      {"POST": true},
 		"/v1/reset-password":         {"POST": true},
+		"/v1/secure-account":         {"POST": true},
 		"/v1/login/phone":            {"POST": true},
 		"/v1/user/register-phone":    {"POST": true},
 		"/v1/user/request-phone-otp": {"POST": true},
 		"/v1/user/verify-phone-otp":  {"POST": true},
+		"/v1/unsubscribe":            {"POST": true},
 	}
 
 	// Public URL prefixes allowed without auth
@@ -47,6 +51,8 @@ var (
 		"/salonapp.v1.UserService/RegisterPhoneUser":       true,
 		"/salonapp.v1.UserService/RequestPhoneOTP":         true,
 		"/salonapp.v1.UserService/VerifyPhoneOTP":          true,
+		"/salonapp.v1.UserService/SecureAccount":           true,
+		"/salonapp.v1.NotificationService/Unsubscribe":     true,
 		"/salonapp.v1.OAuthService/GetOAuthURL":            true,
 	}
 	publicGRPCPrefixes = []string{
@@ -55,18 +61,66 @@ var (
 	}
 	grpcRoleRules = map[string][]string{
 		// "/salonapp.v1.UserService/GetUser": {string(entities.RoleSuperuser)},
+		"/salonapp.v1.OrganizationService/InviteMember":                 {string(entities.OrganizationRoleOwner)},
+		"/salonapp.v1.OrganizationService/RemoveMember":                 {string(entities.OrganizationRoleOwner)},
+		"/salonapp.v1.OrganizationService/ListMembers":                  {string(entities.OrganizationRoleOwner), string(entities.OrganizationRoleEmployee)},
+		"/salonapp.v1.OrganizationService/CreateScimToken":              {string(entities.OrganizationRoleOwner)},
+		"/salonapp.v1.OrganizationService/StartWhatsAppSession":         {string(entities.OrganizationRoleOwner)},
+		"/salonapp.v1.OrganizationService/GetWhatsAppSession":           {string(entities.OrganizationRoleOwner)},
+		"/salonapp.v1.OrganizationService/GetWhatsAppQRCode":            {string(entities.OrganizationRoleOwner)},
+		"/salonapp.v1.OrganizationService/StopWhatsAppSession":          {string(entities.OrganizationRoleOwner)},
+		"/salonapp.v1.NotificationService/ListOutboundMessages":         {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/GetOutboundMessage":           {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/RetryOutboundMessage":         {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/RefreshOutboundMessageStatus": {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/ListEmailTemplates":           {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/ListEmailTemplateVersions":    {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/CreateEmailTemplate":          {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/UpdateEmailTemplate":          {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/ActivateEmailTemplateVersion": {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/PreviewEmailTemplate":         {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/SendTestEmailTemplate":        {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/ListWhatsAppSessions":         {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/ListEmailSuppressions":        {string(entities.RoleSuperuser)},
+		"/salonapp.v1.NotificationService/DeleteEmailSuppression":       {string(entities.RoleSuperuser)},
+		"/salonapp.v1.BillingService/ListWebhookEvents":                 {string(entities.RoleSuperuser)},
+		"/salonapp.v1.BillingService/ReplayWebhookEvent":                {string(entities.RoleSuperuser)},
+		"/salonapp.v1.BillingService/RefundPayment":                     {string(entities.RoleSuperuser)},
+		// Salon owners run campaigns for their salon, customers such as couples for themselves
+		"/salonapp.v1.NotificationService/CreateCampaign": {string(entities.OrganizationRoleOwner), string(entities.RoleCustomer)},
+		"/salonapp.v1.NotificationService/ListCampaigns":  {string(entities.OrganizationRoleOwner), string(entities.RoleCustomer)},
+		"/salonapp.v1.NotificationService/GetCampaign":    {string(entities.OrganizationRoleOwner), string(entities.RoleCustomer)},
+		"/salonapp.v1.NotificationService/PauseCampaign":  {string(entities.OrganizationRoleOwner), string(entities.RoleCustomer)},
+		"/salonapp.v1.NotificationService/ResumeCampaign": {string(entities.OrganizationRoleOwner), string(entities.RoleCustomer)},
+		"/salonapp.v1.NotificationService/CancelCampaign": {string(entities.OrganizationRoleOwner), string(entities.RoleCustomer)},
+	}
+	// Methods that act on the organization selected by the X-Org-Id header
+	grpcOrgScopedMethods = map[string]bool{
+		"/salonapp.v1.OrganizationService/InviteMember":         true,
+		"/salonapp.v1.OrganizationService/RemoveMember":         true,
+		"/salonapp.v1.OrganizationService/ListMembers":          true,
+		"/salonapp.v1.OrganizationService/CreateScimToken":      true,
+		"/salonapp.v1.OrganizationService/StartWhatsAppSession": true,
+		"/salonapp.v1.OrganizationService/GetWhatsAppSession":   true,
+		"/salonapp.v1.OrganizationService/GetWhatsAppQRCode":    true,
+		"/salonapp.v1.OrganizationService/StopWhatsAppSession":  true,
 	}
 )
 
+// OrgIDHeader selects the organization whose roles apply to the request
+const OrgIDHeader = "X-Org-Id"
+
 type AuthMiddleware struct {
 	jwtRepository repositories.JWTRepository
 	userRepo      repositories.UserRepository // interface to get user by ID
+	orgRepo       repositories.OrganizationRepository
 }
 
-func NewAuthMiddleware(jwtRepository repositories.JWTRepository, userRepo repositories.UserRepository) *AuthMiddleware {
+func NewAuthMiddleware(jwtRepository repositories.JWTRepository, userRepo repositories.UserRepository, orgRepo repositories.OrganizationRepository) *AuthMiddleware {
 	return &AuthMiddleware{
 		jwtRepository: jwtRepository,
 		userRepo:      userRepo,
+		orgRepo:       orgRepo,
 	}
 }
 
@@ -95,6 +149,10 @@ func (m *AuthMiddleware) HTTPMiddleware(next http.Handler) http.Handler {
 			writeJSONError(w, http.StatusUnauthorized, "user not found or inactive")
 			return
 		}
+		if isSessionRevoked(user, claims) {
+			writeJSONError(w, http.StatusUnauthorized, "session revoked")
+			return
+		}
 
 		// Add user to context
 		ctx := util.WithUser(r.Context(), user)
@@ -113,9 +171,38 @@ func writeJSONError(w http.ResponseWriter, statusCode int, message string) {
 
 // GRPC interceptor for authentication
 func (m *AuthMiddleware) GRPCAuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := m.authenticateGRPC(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// GRPCStreamAuthInterceptor authenticates streaming calls the way GRPCAuthInterceptor does unary ones
+func (m *AuthMiddleware) GRPCStreamAuthInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := m.authenticateGRPC(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream carries the context with the caller's user and membership to stream handlers
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticateGRPC checks the caller's token and roles for method and returns ctx with the user,
+// and the organization membership when one is selected
+func (m *AuthMiddleware) authenticateGRPC(ctx context.Context, method string) (context.Context, error) {
 	// Skip auth for certain methods (like login)
-	if isPublicMethod(info.FullMethod) {
-		return handler(ctx, req)
+	if isPublicMethod(method) {
+		return ctx, nil
 	}
 
 	// Extract token from context
@@ -133,16 +220,63 @@ func (m *AuthMiddleware) GRPCAuthInterceptor(ctx context.Context, req any, info
 	if err != nil || !user.IsActive {
 		return nil, status.Error(codes.Unauthenticated, "user not found or inactive")
 	}
+	if isSessionRevoked(user, claims) {
+		return nil, status.Error(codes.Unauthenticated, "session revoked")
+	}
+
+	// Add user to context; membership lookups below run under the user's row level security scope
+	ctx = util.WithUser(ctx, user)
+
+	// ORGANIZATION CONTEXT
+	member, err := m.resolveOrganizationMember(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil && grpcOrgScopedMethods[method] {
+		return nil, status.Error(codes.InvalidArgument, "X-Org-Id header is required")
+	}
 
 	// ROLE AUTHORIZATION (NEW)
-	requiredRoles := RequiredGRPCRoles(info.FullMethod)
-	if !util.HasRole(user, requiredRoles...) {
+	requiredRoles := RequiredGRPCRoles(method)
+	if !util.HasOrganizationRole(user, member, requiredRoles...) {
 		return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
 	}
 
-	// Add user to context
-	ctx = util.WithUser(ctx, user)
-	return handler(ctx, req)
+	if member != nil {
+		ctx = util.WithOrganizationMember(ctx, member)
+	}
+	return ctx, nil
+}
+
+// resolveOrganizationMember loads the caller's membership in the organization named by x-org-id.
+// Superusers act as owners of any organization.
+func (m *AuthMiddleware) resolveOrganizationMember(ctx context.Context, user *entities.User) (*entities.OrganizationMember, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	raw := firstMetadata(md, strings.ToLower(OrgIDHeader))
+	if raw == "" {
+		return nil, nil
+	}
+	orgID, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid X-Org-Id header")
+	}
+
+	member, err := m.orgRepo.GetMember(ctx, orgID, user.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to resolve organization")
+	}
+	if member == nil {
+		if !util.HasRole(user, string(entities.RoleSuperuser)) {
+			return nil, status.Error(codes.PermissionDenied, "not a member of this organization")
+		}
+		member = &entities.OrganizationMember{OrganizationID: orgID, UserID: user.ID, Role: entities.OrganizationRoleOwner}
+	}
+	return member, nil
+}
+
+// isSessionRevoked reports whether the token was issued before the user signed out all sessions
+func isSessionRevoked(user *entities.User, claims *entities.TokenClaims) bool {
+	return user.SessionRevoked(claims.IssuedAt)
 }
 
 func RequiredGRPCRoles(method string) []string {
@@ -0,0 +1,165 @@
+package bounce
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// maxReportParts bounds the parts read from one report, real reports have three
+const maxReportParts = 16
+
+// ParseReport extracts the failed recipients of a delivery status notification (RFC 3464)
+// or the complained-about recipient of an abuse report (RFC 5965). Other messages, such as
+// auto-replies that land in the bounce mailbox, yield no events.
+func ParseReport(r io.Reader) ([]entities.BounceEvent, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" || params["boundary"] == "" {
+		return nil, nil
+	}
+
+	var (
+		events   []entities.BounceEvent
+		feedback textproto.MIMEHeader
+		original textproto.MIMEHeader
+	)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for range maxReportParts {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// A report cut off after its status part, such as the head of an oversized
+			// message, still names its recipients
+			if len(events) > 0 || feedback != nil {
+				break
+			}
+			return nil, fmt.Errorf("invalid report part: %w", err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			blocks, err := readFieldBlocks(part)
+			if err != nil {
+				return nil, err
+			}
+			// The first block describes the message, the others one recipient each
+			for _, block := range blocks[min(1, len(blocks)):] {
+				if e, ok := deliveryStatusEvent(block); ok {
+					events = append(events, e)
+				}
+			}
+		case "message/feedback-report":
+			blocks, err := readFieldBlocks(part)
+			if err != nil {
+				return nil, err
+			}
+			if len(blocks) > 0 {
+				feedback = blocks[0]
+			}
+		case "message/rfc822", "text/rfc822-headers":
+			if h, err := textproto.NewReader(bufio.NewReader(part)).ReadMIMEHeader(); err == nil || len(h) > 0 {
+				original = h
+			}
+		}
+	}
+
+	if feedback != nil {
+		if e, ok := complaintEvent(feedback, original); ok {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// deliveryStatusEvent turns the fields of one recipient into an event. Failures with a 5.x.x
+// status are permanent; delays and 4.x.x failures are soft bounces.
+func deliveryStatusEvent(fields textproto.MIMEHeader) (entities.BounceEvent, bool) {
+	email := addressField(fields.Get("Final-Recipient"))
+	if email == "" {
+		email = addressField(fields.Get("Original-Recipient"))
+	}
+	if email == "" {
+		return entities.BounceEvent{}, false
+	}
+	status := strings.TrimSpace(fields.Get("Status"))
+	var bounceType entities.BounceType
+	switch strings.ToLower(strings.TrimSpace(fields.Get("Action"))) {
+	case "failed":
+		bounceType = entities.BounceSoft
+		if strings.HasPrefix(status, "5.") {
+			bounceType = entities.BounceHard
+		}
+	case "delayed":
+		bounceType = entities.BounceSoft
+	default:
+		return entities.BounceEvent{}, false
+	}
+	detail := status
+	if diagnostic := strings.TrimSpace(fields.Get("Diagnostic-Code")); diagnostic != "" {
+		detail = strings.TrimSpace(status + " " + diagnostic)
+	}
+	return entities.BounceEvent{Email: email, Type: bounceType, Detail: detail, Source: "mailbox"}, true
+}
+
+// complaintEvent takes the recipient from Original-Rcpt-To, falling back to the To header of the
+// returned message, which is all some feedback loops include
+func complaintEvent(feedback, original textproto.MIMEHeader) (entities.BounceEvent, bool) {
+	email := addressField(feedback.Get("Original-Rcpt-To"))
+	if email == "" && original != nil {
+		if addr, err := mail.ParseAddress(original.Get("To")); err == nil {
+			email = addr.Address
+		}
+	}
+	if email == "" {
+		return entities.BounceEvent{}, false
+	}
+	feedbackType := strings.TrimSpace(feedback.Get("Feedback-Type"))
+	return entities.BounceEvent{Email: email, Type: entities.BounceComplaint, Detail: feedbackType, Source: "mailbox"}, true
+}
+
+// addressField strips the address type of fields such as "rfc822; user@example.com"
+func addressField(value string) string {
+	if _, addr, ok := strings.Cut(value, ";"); ok {
+		value = addr
+	}
+	return strings.Trim(strings.TrimSpace(value), "<>")
+}
+
+// readFieldBlocks reads header-style field blocks separated by blank lines
+func readFieldBlocks(r io.Reader) ([]textproto.MIMEHeader, error) {
+	data, err := io.ReadAll(io.LimitReader(r, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	var blocks []textproto.MIMEHeader
+	for _, chunk := range bytes.Split(data, []byte("\n\n")) {
+		chunk = bytes.TrimSpace(chunk)
+		if len(chunk) == 0 {
+			continue
+		}
+		tr := textproto.NewReader(bufio.NewReader(io.MultiReader(bytes.NewReader(chunk), strings.NewReader("\n\n"))))
+		fields, err := tr.ReadMIMEHeader()
+		if err != nil && len(fields) == 0 {
+			return nil, fmt.Errorf("invalid status fields: %w", err)
+		}
+		blocks = append(blocks, fields)
+	}
+	return blocks, nil
+}
@@ -0,0 +1,136 @@
+package bounce
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// maxReadBytes bounds what one Read takes from the mailbox; the rest is picked up by the next call
+const maxReadBytes = 16 << 20
+
+// Mailbox reads reports from an mbox file that the local MTA appends bounces to.
+// It remembers how far it got in memory, so a restart reads the whole file again;
+// suppressing an address twice is harmless. Truncating the file starts over from the top.
+type Mailbox struct {
+	path    string
+	maxRead int64
+	mu      sync.Mutex
+	offset  int64
+}
+
+func NewMailbox(path string) *Mailbox {
+	return &Mailbox{path: path, maxRead: maxReadBytes}
+}
+
+// Read parses the messages appended since the last accepted read and passes their reports to
+// handle. The offset moves past them only when handle succeeds, and not at all if ctx is
+// cancelled while parsing.
+func (m *Mailbox) Read(ctx context.Context, handle func(ctx context.Context, events []entities.BounceEvent) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.Open(m.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < m.offset {
+		m.offset = 0
+	}
+	if info.Size() == m.offset {
+		return nil
+	}
+	data, err := io.ReadAll(io.NewSectionReader(f, m.offset, min(info.Size()-m.offset, m.maxRead)))
+	if err != nil {
+		return fmt.Errorf("failed to read mailbox: %w", err)
+	}
+
+	truncated := info.Size()-m.offset > m.maxRead
+	messages, consumed := splitMbox(data, truncated)
+	if consumed == 0 && truncated {
+		// One message fills the whole read. Reports put the delivery status before the returned
+		// original, so its start is parsed and the rest skipped; the next read resumes at the
+		// following "From " line.
+		log.Printf("bounce mailbox message at offset %d is larger than %d bytes, reading only its start", m.offset, m.maxRead)
+		msg := data
+		if bytes.HasPrefix(msg, []byte("From ")) {
+			if i := bytes.IndexByte(msg, '\n'); i >= 0 {
+				msg = msg[i+1:]
+			}
+		}
+		messages, consumed = [][]byte{msg}, len(data)
+	}
+
+	var events []entities.BounceEvent
+	for _, msg := range messages {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		found, err := ParseReport(bytes.NewReader(msg))
+		if err != nil {
+			log.Println(fmt.Errorf("skipping unreadable message in bounce mailbox: %w", err))
+			continue
+		}
+		events = append(events, found...)
+	}
+	if len(events) > 0 {
+		if err := handle(ctx, events); err != nil {
+			return err
+		}
+	}
+	m.offset += int64(consumed)
+	return nil
+}
+
+// splitMbox splits data at the "From " lines that start each message and returns the messages
+// without those lines, along with the number of bytes they span. A trailing message the MTA may
+// still be writing, one not ended by a blank line, or one cut off by the read limit, is left for later.
+func splitMbox(data []byte, truncated bool) ([][]byte, int) {
+	var starts []int
+	for i := 0; i < len(data); {
+		if (i == 0 || data[i-1] == '\n') && bytes.HasPrefix(data[i:], []byte("From ")) {
+			starts = append(starts, i)
+		}
+		next := bytes.IndexByte(data[i:], '\n')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	if len(starts) == 0 {
+		return nil, 0
+	}
+
+	var messages [][]byte
+	consumed := starts[0]
+	for n, start := range starts {
+		end := len(data)
+		last := n == len(starts)-1
+		if !last {
+			end = starts[n+1]
+		} else if truncated || !(bytes.HasSuffix(data, []byte("\n\n")) || bytes.HasSuffix(data, []byte("\r\n\r\n"))) {
+			break
+		}
+		msg := data[start:end]
+		if i := bytes.IndexByte(msg, '\n'); i >= 0 {
+			messages = append(messages, msg[i+1:])
+		}
+		consumed = end
+	}
+	return messages, consumed
+}
@@ -0,0 +1,148 @@
+package bounce
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// report is an mbox entry holding a delivery status notification for a hard bounce to rcpt,
+// with the returned original padded to at least pad bytes
+func report(rcpt string, pad int) string {
+	return "From MAILER-DAEMON Mon Jan  1 00:00:00 2024\n" +
+		"From: Mail Delivery System <MAILER-DAEMON@example.com>\n" +
+		"To: noreply@example.com\n" +
+		"Subject: Undelivered Mail Returned to Sender\n" +
+		"MIME-Version: 1.0\n" +
+		"Content-Type: multipart/report; report-type=delivery-status; boundary=\"b\"\n" +
+		"\n" +
+		"--b\n" +
+		"Content-Type: text/plain\n" +
+		"\n" +
+		"The message could not be delivered.\n" +
+		"--b\n" +
+		"Content-Type: message/delivery-status\n" +
+		"\n" +
+		"Reporting-MTA: dns; mx.example.com\n" +
+		"\n" +
+		"Final-Recipient: rfc822; " + rcpt + "\n" +
+		"Action: failed\n" +
+		"Status: 5.1.1\n" +
+		"\n" +
+		"--b\n" +
+		"Content-Type: message/rfc822\n" +
+		"\n" +
+		"To: " + rcpt + "\n" +
+		"Subject: Hello\n" +
+		"\n" +
+		strings.Repeat("padding line\n", pad/13) +
+		"--b--\n" +
+		"\n"
+}
+
+func writeMailbox(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bounces")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readAll reads m until it stops moving and returns the recipients handed over
+func readAll(t *testing.T, m *Mailbox) []string {
+	t.Helper()
+	var emails []string
+	for range 100 {
+		before := m.offset
+		err := m.Read(context.Background(), func(_ context.Context, events []entities.BounceEvent) error {
+			for _, e := range events {
+				emails = append(emails, e.Email)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if m.offset == before {
+			return emails
+		}
+	}
+	t.Fatal("mailbox never stopped moving")
+	return nil
+}
+
+func TestMailboxRereadsUntilHandled(t *testing.T) {
+	m := NewMailbox(writeMailbox(t, report("a@example.com", 0)+report("b@example.com", 0)))
+
+	failed := errors.New("database down")
+	err := m.Read(context.Background(), func(context.Context, []entities.BounceEvent) error { return failed })
+	if !errors.Is(err, failed) {
+		t.Fatalf("Read = %v, want the handler's error", err)
+	}
+	if m.offset != 0 {
+		t.Fatalf("offset moved to %d after the handler failed", m.offset)
+	}
+
+	if got, want := readAll(t, m), []string{"a@example.com", "b@example.com"}; !slices.Equal(got, want) {
+		t.Errorf("reread %v, want %v", got, want)
+	}
+	if got := readAll(t, m); len(got) != 0 {
+		t.Errorf("handled reports were read again: %v", got)
+	}
+}
+
+func TestMailboxKeepsOffsetWhenCancelled(t *testing.T) {
+	m := NewMailbox(writeMailbox(t, report("a@example.com", 0)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err := m.Read(ctx, func(context.Context, []entities.BounceEvent) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Read = %v, want context.Canceled", err)
+	}
+	if called || m.offset != 0 {
+		t.Fatalf("cancelled read handled reports or moved the offset to %d", m.offset)
+	}
+
+	if got := readAll(t, m); !slices.Equal(got, []string{"a@example.com"}) {
+		t.Errorf("read %v after cancelling, want [a@example.com]", got)
+	}
+}
+
+func TestMailboxWaitsForUnfinishedMessage(t *testing.T) {
+	full := report("a@example.com", 0)
+	path := writeMailbox(t, full[:len(full)-20])
+	m := NewMailbox(path)
+
+	if got := readAll(t, m); len(got) != 0 {
+		t.Fatalf("read %v from a message still being written", got)
+	}
+	if err := os.WriteFile(path, []byte(full), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, m); !slices.Equal(got, []string{"a@example.com"}) {
+		t.Errorf("read %v once the message was complete, want [a@example.com]", got)
+	}
+}
+
+func TestMailboxSkipsOversizedMessage(t *testing.T) {
+	m := NewMailbox(writeMailbox(t, report("big@example.com", 4096)+report("next@example.com", 0)))
+	m.maxRead = 1024
+
+	// The status part of the oversized report fits in one read, the returned original does not
+	got := readAll(t, m)
+	if want := []string{"big@example.com", "next@example.com"}; !slices.Equal(got, want) {
+		t.Errorf("read %v, want %v", got, want)
+	}
+}
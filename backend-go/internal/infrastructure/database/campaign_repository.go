@@ -0,0 +1,190 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type campaignRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewCampaignRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.CampaignRepository {
+	return &campaignRepository{queries: queries, db: db}
+}
+
+func (r *campaignRepository) WithTx(tx pgx.Tx) repositories.CampaignRepository {
+	return &campaignRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *campaignRepository) Create(ctx context.Context, c *entities.Campaign) (*entities.Campaign, error) {
+	audience, _ := json.Marshal(c.Audience)
+	fields := []byte("{}")
+	if len(c.Fields) > 0 {
+		fields, _ = json.Marshal(c.Fields)
+	}
+	out, err := r.queries.CreateCampaign(ctx, dbgen.CreateCampaignParams{
+		OrganizationID: toPgUUIDPtr(c.OrganizationID),
+		CreatedBy:      toPgUUIDPtr(c.CreatedBy),
+		Name:           c.Name,
+		Channel:        string(c.Channel),
+		TemplateName:   string(c.TemplateName),
+		Category:       string(c.Category),
+		Audience:       audience,
+		Fields:         fields,
+		SendAt:         toPgTimestamptz(c.SendAt),
+		CronExpression: c.CronExpression,
+		Timezone:       c.Timezone,
+		RatePerMinute:  int32(c.RatePerMinute),
+		NextRunAt:      toPgTimestamptz(c.NextRunAt),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *campaignRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Campaign, error) {
+	return r.one(r.queries.GetCampaign(ctx, id))
+}
+
+func (r *campaignRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*entities.Campaign, error) {
+	return r.one(r.queries.GetCampaignForUpdate(ctx, id))
+}
+
+func (r *campaignRepository) List(ctx context.Context, orgID *uuid.UUID, createdBy uuid.UUID, offset, limit int32) ([]*entities.Campaign, int, error) {
+	rows, err := r.queries.ListCampaigns(ctx, dbgen.ListCampaignsParams{
+		OrganizationID: toPgUUIDPtr(orgID),
+		CreatedBy:      toPgUUIDPtr(&createdBy),
+		PageLimit:      limit,
+		PageOffset:     offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.queries.CountCampaigns(ctx, dbgen.CountCampaignsParams{
+		OrganizationID: toPgUUIDPtr(orgID),
+		CreatedBy:      toPgUUIDPtr(&createdBy),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]*entities.Campaign, 0, len(rows))
+	for i := range rows {
+		out = append(out, r.toEntity(&rows[i]))
+	}
+	return out, int(total), nil
+}
+
+func (r *campaignRepository) ListDue(ctx context.Context, limit int) ([]*entities.Campaign, error) {
+	rows, err := r.queries.ListDueCampaigns(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*entities.Campaign, 0, len(rows))
+	for _, row := range rows {
+		c := &entities.Campaign{ID: row.ID}
+		if row.OrganizationID.Valid {
+			orgID := uuid.UUID(row.OrganizationID.Bytes)
+			c.OrganizationID = &orgID
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (r *campaignRepository) LockDue(ctx context.Context, id uuid.UUID) (*entities.Campaign, error) {
+	return r.one(r.queries.LockDueCampaign(ctx, id))
+}
+
+func (r *campaignRepository) CompleteRun(ctx context.Context, id uuid.UUID, next *time.Time, status entities.CampaignStatus) (*entities.Campaign, error) {
+	return r.one(r.queries.CompleteCampaignRun(ctx, dbgen.CompleteCampaignRunParams{
+		NextRunAt: toPgTimestamptz(next),
+		Status:    string(status),
+		ID:        id,
+	}))
+}
+
+func (r *campaignRepository) SetStatus(ctx context.Context, id uuid.UUID, status entities.CampaignStatus, next *time.Time) (*entities.Campaign, error) {
+	return r.one(r.queries.SetCampaignStatus(ctx, dbgen.SetCampaignStatusParams{
+		Status:    string(status),
+		NextRunAt: toPgTimestamptz(next),
+		ID:        id,
+	}))
+}
+
+func (r *campaignRepository) Stats(ctx context.Context, id uuid.UUID) (*entities.CampaignStats, error) {
+	row, err := r.queries.GetCampaignStats(ctx, toPgUUIDPtr(&id))
+	if err != nil {
+		return nil, err
+	}
+	return &entities.CampaignStats{
+		Total:     int(row.Total),
+		Queued:    int(row.Queued),
+		Sent:      int(row.Sent),
+		Failed:    int(row.Failed),
+		Delivered: int(row.Delivered),
+		Read:      int(row.Read),
+	}, nil
+}
+
+func (r *campaignRepository) CancelMessages(ctx context.Context, id uuid.UUID) (int, error) {
+	n, err := r.queries.CancelCampaignMessages(ctx, toPgUUIDPtr(&id))
+	return int(n), err
+}
+
+func (r *campaignRepository) RespaceMessages(ctx context.Context, id uuid.UUID, spacing time.Duration) error {
+	return r.queries.RespaceCampaignMessages(ctx, dbgen.RespaceCampaignMessagesParams{
+		SpacingSeconds: spacing.Seconds(),
+		CampaignID:     toPgUUIDPtr(&id),
+	})
+}
+
+func (r *campaignRepository) one(c dbgen.Campaign, err error) (*entities.Campaign, error) {
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&c), nil
+}
+
+func (r *campaignRepository) toEntity(c *dbgen.Campaign) *entities.Campaign {
+	out := &entities.Campaign{
+		ID:             c.ID,
+		Name:           c.Name,
+		Channel:        entities.MessageChannel(c.Channel),
+		TemplateName:   entities.EmailTemplateEnum(c.TemplateName),
+		Category:       entities.NotificationCategory(c.Category),
+		SendAt:         fromPgTime(c.SendAt),
+		CronExpression: c.CronExpression,
+		Timezone:       c.Timezone,
+		RatePerMinute:  int(c.RatePerMinute),
+		Status:         entities.CampaignStatus(c.Status),
+		NextRunAt:      fromPgTime(c.NextRunAt),
+		LastRunAt:      fromPgTime(c.LastRunAt),
+		Runs:           int(c.Runs),
+		CreatedAt:      c.CreatedAt.Time,
+		UpdatedAt:      c.UpdatedAt.Time,
+	}
+	_ = json.Unmarshal(c.Audience, &out.Audience)
+	_ = json.Unmarshal(c.Fields, &out.Fields)
+	if c.OrganizationID.Valid {
+		orgID := uuid.UUID(c.OrganizationID.Bytes)
+		out.OrganizationID = &orgID
+	}
+	if c.CreatedBy.Valid {
+		createdBy := uuid.UUID(c.CreatedBy.Bytes)
+		out.CreatedBy = &createdBy
+	}
+	return out
+}
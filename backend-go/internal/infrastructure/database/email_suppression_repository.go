@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type emailSuppressionRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewEmailSuppressionRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.EmailSuppressionRepository {
+	return &emailSuppressionRepository{queries: queries, db: db}
+}
+
+func (r *emailSuppressionRepository) WithTx(tx pgx.Tx) repositories.EmailSuppressionRepository {
+	return &emailSuppressionRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *emailSuppressionRepository) Suppress(ctx context.Context, s *entities.EmailSuppression) (*entities.EmailSuppression, error) {
+	out, err := r.queries.UpsertEmailSuppression(ctx, dbgen.UpsertEmailSuppressionParams{
+		Email:  s.Email,
+		Reason: string(s.Reason),
+		Detail: s.Detail,
+		Source: s.Source,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *emailSuppressionRepository) Get(ctx context.Context, email string) (*entities.EmailSuppression, error) {
+	out, err := r.queries.GetEmailSuppression(ctx, email)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *emailSuppressionRepository) List(ctx context.Context, search string, offset, limit int32) ([]*entities.EmailSuppression, int, error) {
+	rows, err := r.queries.ListEmailSuppressions(ctx, dbgen.ListEmailSuppressionsParams{
+		Search:     toPgTextOmitEmpty(search),
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.queries.CountEmailSuppressions(ctx, toPgTextOmitEmpty(search))
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]*entities.EmailSuppression, 0, len(rows))
+	for i := range rows {
+		out = append(out, r.toEntity(&rows[i]))
+	}
+	return out, int(total), nil
+}
+
+func (r *emailSuppressionRepository) Delete(ctx context.Context, email string) (bool, error) {
+	n, err := r.queries.DeleteEmailSuppression(ctx, email)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *emailSuppressionRepository) toEntity(s *dbgen.EmailSuppression) *entities.EmailSuppression {
+	return &entities.EmailSuppression{
+		Email:     s.Email,
+		Reason:    entities.SuppressionReason(s.Reason),
+		Detail:    s.Detail,
+		Source:    s.Source,
+		CreatedAt: s.CreatedAt.Time,
+		UpdatedAt: s.UpdatedAt.Time,
+	}
+}
@@ -2,8 +2,11 @@ package database
 
 import (
 	"context"
+	"errors"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
@@ -25,18 +28,133 @@ func (r *emailTemplateRepository) WithTx(tx pgx.Tx) repositories.EmailTemplateRe
 	}
 }
 
-func (r *emailTemplateRepository) GetByName(ctx context.Context, name entities.EmailTemplateEnum) (*entities.EmailTemplate, error) {
-	tpl, err := r.queries.GetEmailTemplateByName(ctx, string(name))
+func (r *emailTemplateRepository) GetByName(ctx context.Context, name entities.EmailTemplateEnum, locales []string) (*entities.EmailTemplate, error) {
+	tpl, err := r.queries.GetActiveEmailTemplate(ctx, dbgen.GetActiveEmailTemplateParams{
+		Name:    string(name),
+		Locales: locales,
+	})
 	if err != nil {
 		return nil, err
 	}
-	return &entities.EmailTemplate{
-		ID:        tpl.ID,
-		Name:      entities.EmailTemplateEnum(tpl.Name),
-		Subject:   tpl.Subject,
-		Body:      tpl.Body,
-		IsActive:  tpl.IsActive,
-		CreatedAt: tpl.CreatedAt.Time,
-		UpdatedAt: tpl.UpdatedAt.Time,
-	}, nil
+	return r.toEntity(&tpl), nil
+}
+
+func (r *emailTemplateRepository) List(ctx context.Context) ([]*entities.EmailTemplate, error) {
+	rows, err := r.queries.ListActiveEmailTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tpls := make([]*entities.EmailTemplate, 0, len(rows))
+	for _, row := range rows {
+		tpl := r.toEntity(&dbgen.EmailTemplate{
+			ID:              row.ID,
+			Name:            row.Name,
+			Subject:         row.Subject,
+			Body:            row.Body,
+			IsActive:        row.IsActive,
+			CreatedAt:       row.CreatedAt,
+			UpdatedAt:       row.UpdatedAt,
+			Version:         row.Version,
+			CreatedBy:       row.CreatedBy,
+			Locale:          row.Locale,
+			WhatsappContent: row.WhatsappContent,
+			Campaign:        row.Campaign,
+		})
+		tpl.LatestVersion = int(row.LatestVersion)
+		tpls = append(tpls, tpl)
+	}
+	return tpls, nil
+}
+
+func (r *emailTemplateRepository) ListVersions(ctx context.Context, name entities.EmailTemplateEnum, locale string) ([]*entities.EmailTemplate, error) {
+	rows, err := r.queries.ListEmailTemplateVersions(ctx, dbgen.ListEmailTemplateVersionsParams{
+		Name:   string(name),
+		Locale: locale,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tpls := make([]*entities.EmailTemplate, 0, len(rows))
+	for i := range rows {
+		tpls = append(tpls, r.toEntity(&rows[i]))
+	}
+	return tpls, nil
+}
+
+func (r *emailTemplateRepository) GetVersion(ctx context.Context, name entities.EmailTemplateEnum, locale string, version int) (*entities.EmailTemplate, error) {
+	tpl, err := r.queries.GetEmailTemplateVersion(ctx, dbgen.GetEmailTemplateVersionParams{
+		Name:    string(name),
+		Locale:  locale,
+		Version: int32(version),
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&tpl), nil
+}
+
+func (r *emailTemplateRepository) CreateVersion(ctx context.Context, tpl *entities.EmailTemplate) (*entities.EmailTemplate, error) {
+	out, err := r.queries.CreateEmailTemplateVersion(ctx, dbgen.CreateEmailTemplateVersionParams{
+		Name:            string(tpl.Name),
+		Locale:          tpl.Locale,
+		Subject:         tpl.Subject,
+		Body:            tpl.Body,
+		WhatsappContent: toWhatsAppContentJSON(tpl.WhatsApp),
+		CreatedBy:       toPgUUIDPtr(tpl.CreatedBy),
+		Campaign:        tpl.Campaign,
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, repositories.ErrTemplateVersionConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *emailTemplateRepository) Activate(ctx context.Context, name entities.EmailTemplateEnum, locale string, version int) (*entities.EmailTemplate, error) {
+	err := r.queries.DeactivateEmailTemplate(ctx, dbgen.DeactivateEmailTemplateParams{
+		Name:   string(name),
+		Locale: locale,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := r.queries.ActivateEmailTemplateVersion(ctx, dbgen.ActivateEmailTemplateVersionParams{
+		Name:    string(name),
+		Locale:  locale,
+		Version: int32(version),
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&tpl), nil
+}
+
+func (r *emailTemplateRepository) toEntity(t *dbgen.EmailTemplate) *entities.EmailTemplate {
+	tpl := &entities.EmailTemplate{
+		ID:        t.ID,
+		Name:      entities.EmailTemplateEnum(t.Name),
+		Locale:    t.Locale,
+		Version:   int(t.Version),
+		Subject:   t.Subject,
+		Body:      t.Body,
+		IsActive:  t.IsActive,
+		CreatedAt: t.CreatedAt.Time,
+		UpdatedAt: t.UpdatedAt.Time,
+		WhatsApp:  fromWhatsAppContentJSON(t.WhatsappContent),
+		Campaign:  t.Campaign,
+	}
+	if t.CreatedBy.Valid {
+		createdBy := uuid.UUID(t.CreatedBy.Bytes)
+		tpl.CreatedBy = &createdBy
+	}
+	return tpl
 }
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type notificationPreferenceRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewNotificationPreferenceRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{queries: queries, db: db}
+}
+
+func (r *notificationPreferenceRepository) WithTx(tx pgx.Tx) repositories.NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *notificationPreferenceRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.NotificationPreference, error) {
+	rows, err := r.queries.ListUserNotificationPreferences(ctx, toPgUUIDPtr(&userID))
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(rows), nil
+}
+
+func (r *notificationPreferenceRepository) ListByRecipient(ctx context.Context, recipient string) ([]*entities.NotificationPreference, error) {
+	rows, err := r.queries.ListRecipientNotificationPreferences(ctx, recipient)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(rows), nil
+}
+
+func (r *notificationPreferenceRepository) Set(ctx context.Context, p *entities.NotificationPreference) error {
+	if p.UserID != nil {
+		return r.queries.UpsertUserNotificationPreference(ctx, dbgen.UpsertUserNotificationPreferenceParams{
+			UserID:   toPgUUIDPtr(p.UserID),
+			Category: string(p.Category),
+			Channel:  string(p.Channel),
+			Enabled:  p.Enabled,
+		})
+	}
+	return r.queries.UpsertRecipientNotificationPreference(ctx, dbgen.UpsertRecipientNotificationPreferenceParams{
+		Recipient: p.Recipient,
+		Category:  string(p.Category),
+		Channel:   string(p.Channel),
+		Enabled:   p.Enabled,
+	})
+}
+
+func (r *notificationPreferenceRepository) toEntities(rows []dbgen.NotificationPreference) []*entities.NotificationPreference {
+	out := make([]*entities.NotificationPreference, 0, len(rows))
+	for _, row := range rows {
+		p := &entities.NotificationPreference{
+			Recipient: row.Recipient,
+			Category:  entities.NotificationCategory(row.Category),
+			Channel:   entities.MessageChannel(row.Channel),
+			Enabled:   row.Enabled,
+			UpdatedAt: row.UpdatedAt.Time,
+		}
+		if row.UserID.Valid {
+			userID := uuid.UUID(row.UserID.Bytes)
+			p.UserID = &userID
+		}
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type notificationRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewNotificationRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.NotificationRepository {
+	return &notificationRepository{queries: queries, db: db}
+}
+
+func (r *notificationRepository) WithTx(tx pgx.Tx) repositories.NotificationRepository {
+	return &notificationRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, n *entities.Notification) (*entities.Notification, error) {
+	// data is NOT NULL; toPgJSON leaves a nil map empty, which is not valid JSON
+	data := toPgJSON(n.Data)
+	if len(data) == 0 {
+		data = []byte("{}")
+	}
+	out, err := r.queries.CreateNotification(ctx, dbgen.CreateNotificationParams{
+		UserID:   n.UserID,
+		Category: string(n.Category),
+		Title:    n.Title,
+		Body:     n.Body,
+		Data:     data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *notificationRepository) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset, limit int32) ([]*entities.Notification, error) {
+	rows, err := r.queries.ListNotifications(ctx, dbgen.ListNotificationsParams{
+		UserID:     userID,
+		UnreadOnly: unreadOnly,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*entities.Notification, 0, len(rows))
+	for i := range rows {
+		out = append(out, r.toEntity(&rows[i]))
+	}
+	return out, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	n, err := r.queries.CountUnreadNotifications(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int, error) {
+	n, err := r.queries.MarkNotificationsRead(ctx, dbgen.MarkNotificationsReadParams{UserID: userID, Ids: ids})
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	n, err := r.queries.MarkAllNotificationsRead(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (r *notificationRepository) toEntity(n *dbgen.Notification) *entities.Notification {
+	return &entities.Notification{
+		ID:        n.ID,
+		UserID:    n.UserID,
+		Category:  entities.NotificationCategory(n.Category),
+		Title:     n.Title,
+		Body:      n.Body,
+		Data:      fromPgJSON(n.Data),
+		ReadAt:    fromPgTime(n.ReadAt),
+		CreatedAt: n.CreatedAt.Time,
+	}
+}
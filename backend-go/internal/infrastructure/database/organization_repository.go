@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type organizationRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewOrganizationRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.OrganizationRepository {
+	return &organizationRepository{queries: queries, db: db}
+}
+
+func (r *organizationRepository) WithTx(tx pgx.Tx) repositories.OrganizationRepository {
+	return &organizationRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, org *entities.Organization) (*entities.Organization, error) {
+	out, err := r.queries.CreateOrganization(ctx, dbgen.CreateOrganizationParams{
+		Name:      org.Name,
+		CreatedBy: toPgUUIDPtr(org.CreatedBy),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Organization, error) {
+	out, err := r.queries.GetOrganizationByID(ctx, id)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *organizationRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Organization, error) {
+	rows, err := r.queries.ListOrganizationsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	orgs := make([]*entities.Organization, 0, len(rows))
+	for i := range rows {
+		orgs = append(orgs, r.toEntity(&rows[i]))
+	}
+	return orgs, nil
+}
+
+func (r *organizationRepository) AddMember(ctx context.Context, orgID, userID uuid.UUID, role entities.OrganizationRole) (*entities.OrganizationMember, error) {
+	out, err := r.queries.AddOrganizationMember(ctx, dbgen.AddOrganizationMemberParams{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Role:           string(role),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toMemberEntity(&out), nil
+}
+
+func (r *organizationRepository) GetMember(ctx context.Context, orgID, userID uuid.UUID) (*entities.OrganizationMember, error) {
+	out, err := r.queries.GetOrganizationMember(ctx, dbgen.GetOrganizationMemberParams{
+		OrganizationID: orgID,
+		UserID:         userID,
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toMemberEntity(&out), nil
+}
+
+func (r *organizationRepository) ListMembers(ctx context.Context, orgID uuid.UUID) ([]*entities.OrganizationMember, error) {
+	rows, err := r.queries.ListOrganizationMembers(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]*entities.OrganizationMember, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, &entities.OrganizationMember{
+			OrganizationID: row.OrganizationID,
+			UserID:         row.UserID,
+			Role:           entities.OrganizationRole(row.Role),
+			Email:          row.Email,
+			FullName:       fromPgText(row.FullName),
+			PhoneNumber:    fromPgText(row.PhoneNumber),
+			JoinedAt:       row.JoinedAt.Time,
+		})
+	}
+	return members, nil
+}
+
+func (r *organizationRepository) LockMembersByRole(ctx context.Context, orgID uuid.UUID, role entities.OrganizationRole) (int, error) {
+	ids, err := r.queries.LockOrganizationMembersByRole(ctx, dbgen.LockOrganizationMembersByRoleParams{
+		OrganizationID: orgID,
+		Role:           string(role),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	return r.queries.RemoveOrganizationMember(ctx, dbgen.RemoveOrganizationMemberParams{
+		OrganizationID: orgID,
+		UserID:         userID,
+	})
+}
+
+func (r *organizationRepository) toEntity(o *dbgen.Organization) *entities.Organization {
+	org := &entities.Organization{
+		ID:        o.ID,
+		Name:      o.Name,
+		CreatedAt: o.CreatedAt.Time,
+		UpdatedAt: o.UpdatedAt.Time,
+	}
+	if o.CreatedBy.Valid {
+		u := uuid.UUID(o.CreatedBy.Bytes)
+		org.CreatedBy = &u
+	}
+	return org
+}
+
+func (r *organizationRepository) toMemberEntity(m *dbgen.OrganizationMember) *entities.OrganizationMember {
+	return &entities.OrganizationMember{
+		OrganizationID: m.OrganizationID,
+		UserID:         m.UserID,
+		Role:           entities.OrganizationRole(m.Role),
+		JoinedAt:       m.JoinedAt.Time,
+	}
+}
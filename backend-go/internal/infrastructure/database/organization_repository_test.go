@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+func TestLockMembersByRoleSerializesOwnerRemoval(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := util.WithOrganizationID(context.Background(), f.orgA)
+	repo := NewOrganizationRepository(dbgen.New(f.pool), f.pool)
+	if _, err := repo.AddMember(ctx, f.orgA, f.userB, entities.OrganizationRoleOwner); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first transaction sees both owners and removes one while the second waits for it
+	locked, release, first := make(chan int), make(chan struct{}), make(chan error)
+	go func() {
+		first <- f.tm.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+			orgRepo := repo.WithTx(tx)
+			owners, err := orgRepo.LockMembersByRole(ctx, f.orgA, entities.OrganizationRoleOwner)
+			if err != nil {
+				return err
+			}
+			locked <- owners
+			<-release
+			return orgRepo.RemoveMember(ctx, f.orgA, f.userB)
+		})
+	}()
+	select {
+	case owners := <-locked:
+		if owners != 2 {
+			close(release)
+			t.Fatalf("first transaction locked %d owners, want 2", owners)
+		}
+	case err := <-first:
+		t.Fatalf("first transaction failed: %v", err)
+	}
+
+	second := make(chan int, 1)
+	secondErr := make(chan error, 1)
+	go func() {
+		secondErr <- f.tm.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+			owners, err := repo.WithTx(tx).LockMembersByRole(ctx, f.orgA, entities.OrganizationRoleOwner)
+			second <- owners
+			return err
+		})
+	}()
+	select {
+	case owners := <-second:
+		close(release)
+		t.Fatalf("second transaction counted %d owners while the first held them", owners)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-secondErr; err != nil {
+		t.Fatal(err)
+	}
+	if owners := <-second; owners != 1 {
+		t.Errorf("second transaction counted %d owners after the removal, want 1", owners)
+	}
+}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type outboxRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewOutboxRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.OutboxRepository {
+	return &outboxRepository{queries: queries, db: db}
+}
+
+func (r *outboxRepository) WithTx(tx pgx.Tx) repositories.OutboxRepository {
+	return &outboxRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *outboxRepository) Enqueue(ctx context.Context, msg *entities.OutboundMessage) (*entities.OutboundMessage, error) {
+	// A message without NextAttemptAt is due right away
+	var next *time.Time
+	if !msg.NextAttemptAt.IsZero() {
+		next = &msg.NextAttemptAt
+	}
+	out, err := r.queries.CreateOutboundMessage(ctx, dbgen.CreateOutboundMessageParams{
+		Channel:          string(msg.Channel),
+		FallbackChannels: channelNames(msg.FallbackChannels),
+		Recipient:        msg.Recipient,
+		Subject:          msg.Subject,
+		Body:             msg.Body,
+		UserID:           toPgUUIDPtr(msg.UserID),
+		MaxAttempts:      int32(msg.MaxAttempts),
+		WhatsappContent:  toWhatsAppContentJSON(msg.WhatsApp),
+		OrganizationID:   toPgUUIDPtr(msg.OrganizationID),
+		Category:         string(cmp.Or(msg.Category, entities.NotificationCategoryTransactional)),
+		CampaignID:       toPgUUIDPtr(msg.CampaignID),
+		Column12:         toPgTimestamptz(next),
+		EmailContent:     toEmailContentJSON(msg.Email),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *outboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*entities.OutboundMessage, error) {
+	rows, err := r.queries.ClaimOutboundMessages(ctx, dbgen.ClaimOutboundMessagesParams{
+		LeaseSeconds: int32(lease / time.Second),
+		BatchSize:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(rows), nil
+}
+
+func (r *outboxRepository) MarkSent(ctx context.Context, id uuid.UUID, channel entities.MessageChannel, externalID, sender string) error {
+	return r.queries.MarkOutboundMessageSent(ctx, dbgen.MarkOutboundMessageSentParams{
+		ID:         id,
+		Channel:    string(channel),
+		ExternalID: toPgTextOmitEmpty(externalID),
+		Sender:     toPgTextOmitEmpty(sender),
+	})
+}
+
+func (r *outboxRepository) GetByExternalID(ctx context.Context, channel entities.MessageChannel, externalID string) (*entities.OutboundMessage, error) {
+	out, err := r.queries.GetOutboundMessageByExternalID(ctx, dbgen.GetOutboundMessageByExternalIDParams{
+		Channel:    string(channel),
+		ExternalID: toPgTextOmitEmpty(externalID),
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *outboxRepository) RecordAck(ctx context.Context, channel entities.MessageChannel, externalID string, ack entities.MessageAck, detail string) (bool, error) {
+	var (
+		n   int64
+		err error
+	)
+	if ack == entities.MessageAckFailed {
+		n, err = r.queries.FailSentOutboundMessage(ctx, dbgen.FailSentOutboundMessageParams{
+			LastError:  detail,
+			Channel:    string(channel),
+			ExternalID: toPgTextOmitEmpty(externalID),
+		})
+	} else {
+		n, err = r.queries.RecordOutboundMessageAck(ctx, dbgen.RecordOutboundMessageAckParams{
+			Read:       ack == entities.MessageAckRead,
+			Channel:    string(channel),
+			ExternalID: toPgTextOmitEmpty(externalID),
+		})
+	}
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *outboxRepository) Reschedule(ctx context.Context, id uuid.UUID, next time.Time, lastError string) error {
+	return r.queries.RescheduleOutboundMessage(ctx, dbgen.RescheduleOutboundMessageParams{
+		ID:            id,
+		NextAttemptAt: toPgTimestamptz(&next),
+		LastError:     lastError,
+	})
+}
+
+func (r *outboxRepository) Defer(ctx context.Context, id uuid.UUID, next time.Time) error {
+	return r.queries.DeferOutboundMessage(ctx, dbgen.DeferOutboundMessageParams{
+		ID:            id,
+		NextAttemptAt: toPgTimestamptz(&next),
+	})
+}
+
+func (r *outboxRepository) MarkDead(ctx context.Context, id uuid.UUID, lastError string) error {
+	return r.queries.MarkOutboundMessageDead(ctx, dbgen.MarkOutboundMessageDeadParams{
+		ID:        id,
+		LastError: lastError,
+	})
+}
+
+func (r *outboxRepository) Requeue(ctx context.Context, id uuid.UUID) (*entities.OutboundMessage, error) {
+	out, err := r.queries.RequeueOutboundMessage(ctx, id)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *outboxRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.OutboundMessage, error) {
+	out, err := r.queries.GetOutboundMessage(ctx, id)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *outboxRepository) List(ctx context.Context, status entities.OutboundMessageStatus, userID *uuid.UUID, offset, limit int32) ([]*entities.OutboundMessage, error) {
+	rows, err := r.queries.ListOutboundMessages(ctx, dbgen.ListOutboundMessagesParams{
+		Status:     toPgTextOmitEmpty(string(status)),
+		UserID:     toPgUUIDPtr(userID),
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(rows), nil
+}
+
+func (r *outboxRepository) CountByStatus(ctx context.Context) (map[entities.OutboundMessageStatus]int, error) {
+	rows, err := r.queries.CountOutboundMessagesByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[entities.OutboundMessageStatus]int, len(rows))
+	for _, row := range rows {
+		counts[entities.OutboundMessageStatus(row.Status)] = int(row.Count)
+	}
+	return counts, nil
+}
+
+func (r *outboxRepository) toEntities(rows []dbgen.OutboundMessage) []*entities.OutboundMessage {
+	msgs := make([]*entities.OutboundMessage, 0, len(rows))
+	for i := range rows {
+		msgs = append(msgs, r.toEntity(&rows[i]))
+	}
+	return msgs
+}
+
+func (r *outboxRepository) toEntity(m *dbgen.OutboundMessage) *entities.OutboundMessage {
+	msg := &entities.OutboundMessage{
+		ID:            m.ID,
+		Channel:       entities.MessageChannel(m.Channel),
+		Recipient:     m.Recipient,
+		Subject:       m.Subject,
+		Body:          m.Body,
+		Status:        entities.OutboundMessageStatus(m.Status),
+		Attempts:      int(m.Attempts),
+		MaxAttempts:   int(m.MaxAttempts),
+		NextAttemptAt: m.NextAttemptAt.Time,
+		LastError:     m.LastError,
+		CreatedAt:     m.CreatedAt.Time,
+		SentAt:        fromPgTime(m.SentAt),
+		ExternalID:    m.ExternalID.String,
+		DeliveredAt:   fromPgTime(m.DeliveredAt),
+		ReadAt:        fromPgTime(m.ReadAt),
+		WhatsApp:      fromWhatsAppContentJSON(m.WhatsappContent),
+		Email:         fromEmailContentJSON(m.EmailContent),
+		Sender:        m.Sender.String,
+		Category:      entities.NotificationCategory(m.Category),
+	}
+	for _, ch := range m.FallbackChannels {
+		msg.FallbackChannels = append(msg.FallbackChannels, entities.MessageChannel(ch))
+	}
+	if m.UserID.Valid {
+		userID := uuid.UUID(m.UserID.Bytes)
+		msg.UserID = &userID
+	}
+	if m.OrganizationID.Valid {
+		orgID := uuid.UUID(m.OrganizationID.Bytes)
+		msg.OrganizationID = &orgID
+	}
+	if m.CampaignID.Valid {
+		campaignID := uuid.UUID(m.CampaignID.Bytes)
+		msg.CampaignID = &campaignID
+	}
+	return msg
+}
+
+func channelNames(channels []entities.MessageChannel) []string {
+	names := make([]string, len(channels))
+	for i, ch := range channels {
+		names[i] = string(ch)
+	}
+	return names
+}
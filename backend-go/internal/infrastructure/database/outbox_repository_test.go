@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+func TestOutboxClaim(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := util.WithSystemScope(context.Background())
+	repo := NewOutboxRepository(dbgen.New(f.pool), f.pool)
+
+	// Lease the messages the fixture seeded so only this test's messages are due
+	if _, err := repo.Claim(ctx, 100, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	var paused uuid.UUID
+	if err := f.pool.QueryRow(ctx, `UPDATE campaign SET status = 'paused' WHERE name = 'A' RETURNING id`).Scan(&paused); err != nil {
+		t.Fatal(err)
+	}
+	enqueue := func(msg *entities.OutboundMessage) *entities.OutboundMessage {
+		t.Helper()
+		msg.Channel, msg.Recipient, msg.Body, msg.MaxAttempts = entities.MessageChannelEmail, "c@example.com", "code 123456", 3
+		out, err := repo.Enqueue(ctx, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	due := enqueue(&entities.OutboundMessage{})
+	enqueue(&entities.OutboundMessage{NextAttemptAt: time.Now().Add(time.Hour)})
+	enqueue(&entities.OutboundMessage{CampaignID: &paused})
+
+	claimed, err := repo.Claim(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != due.ID {
+		t.Fatalf("claimed %d messages, want only the due one", len(claimed))
+	}
+	if claimed[0].Status != entities.OutboundMessageSending || claimed[0].Attempts != 1 {
+		t.Errorf("claimed message is %s after %d attempts, want sending after 1", claimed[0].Status, claimed[0].Attempts)
+	}
+	if again, err := repo.Claim(ctx, 10, time.Minute); err != nil || len(again) != 0 {
+		t.Fatalf("leased message was claimed again: %d, %v", len(again), err)
+	}
+
+	// A worker that died leaves its lease to run out, and the message is claimed again
+	if _, err := f.pool.Exec(ctx, `UPDATE outbound_message SET locked_until = now() - interval '1 second' WHERE id = $1`, due.ID); err != nil {
+		t.Fatal(err)
+	}
+	claimed, err = repo.Claim(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 || claimed[0].Attempts != 2 {
+		t.Fatalf("expired lease was not claimed again")
+	}
+
+	// A rescheduled message waits for its next attempt
+	if err := repo.Reschedule(ctx, due.ID, time.Now().Add(time.Hour), "timeout"); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := repo.Claim(ctx, 10, time.Minute); err != nil || len(again) != 0 {
+		t.Fatalf("rescheduled message was claimed early: %d, %v", len(again), err)
+	}
+}
+
+func TestOutboxSentMessagesLoseTheirContent(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := util.WithSystemScope(context.Background())
+	repo := NewOutboxRepository(dbgen.New(f.pool), f.pool)
+
+	msg, err := repo.Enqueue(ctx, &entities.OutboundMessage{
+		Channel:     entities.MessageChannelWhatsApp,
+		Recipient:   "+6281200000001",
+		Body:        "code 123456",
+		MaxAttempts: 3,
+		WhatsApp:    &entities.WhatsAppContent{Footer: "code 123456"},
+		Email:       &entities.EmailContent{TextBody: "code 123456", Attachments: []entities.Attachment{{Filename: "code.txt", Data: []byte("123456")}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dead-lettered message keeps its content so it can be retried
+	if err := repo.MarkDead(ctx, msg.ID, "timeout"); err != nil {
+		t.Fatal(err)
+	}
+	requeued, err := repo.Requeue(ctx, msg.ID)
+	if err != nil || requeued == nil {
+		t.Fatalf("dead message was not requeued: %v", err)
+	}
+	if requeued.Body != msg.Body || requeued.Attempts != 0 {
+		t.Errorf("requeued message has body %q after %d attempts", requeued.Body, requeued.Attempts)
+	}
+	if requeued.Email == nil || len(requeued.Email.Attachments) != 1 || string(requeued.Email.Attachments[0].Data) != "123456" {
+		t.Errorf("requeued message lost its email content: %+v", requeued.Email)
+	}
+
+	if err := repo.MarkSent(ctx, msg.ID, entities.MessageChannelWhatsApp, "wamid.1", "default"); err != nil {
+		t.Fatal(err)
+	}
+	sent, err := repo.GetByID(ctx, msg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Body != "" || sent.WhatsApp != nil || sent.Email != nil {
+		t.Errorf("sent message kept its content: %q, %+v, %+v", sent.Body, sent.WhatsApp, sent.Email)
+	}
+	if sent.Recipient != msg.Recipient || sent.ExternalID != "wamid.1" {
+		t.Errorf("sent message lost its delivery details")
+	}
+
+	// The provider failing the message afterwards dead-letters it, with nothing left to retry
+	if ok, err := repo.RecordAck(ctx, entities.MessageChannelWhatsApp, "wamid.1", entities.MessageAckFailed, "undeliverable"); err != nil || !ok {
+		t.Fatalf("failure receipt did not match: %v", err)
+	}
+	if requeued, err := repo.Requeue(ctx, msg.ID); err != nil || requeued != nil {
+		t.Errorf("message without content was requeued: %v", err)
+	}
+}
@@ -61,7 +61,7 @@ func (r *paymentRepository) UpdateStatus(ctx context.Context, txid string, statu
 		TransactionID: txid,
 		Status:        dbgen.PaymentStatus(status),
 		Amount:        toPgNumeric(amount),
-		Currency:      *currency,
+		Currency:      toPgText(currency),
 		ExtraMetadata: toPgJSON(metadata),
 	})
 	if err != nil {
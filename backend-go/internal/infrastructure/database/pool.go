@@ -27,6 +27,7 @@ func NewPool(ctx context.Context, connString string) (repositories.ConnectionPoo
 	config.MaxConnLifetime = time.Hour
 	config.MaxConnIdleTime = 30 * time.Minute
 	config.HealthCheckPeriod = time.Minute
+	config.PrepareConn = prepareTenantConn
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -0,0 +1,262 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+// rlsFixture is a migrated scratch database with two organizations, reached through the
+// application's pool as a role that row level security applies to
+type rlsFixture struct {
+	pool repositories.ConnectionPool
+	tm   *TransactionManager
+	orgA uuid.UUID
+	orgB uuid.UUID
+	// userA belongs to orgA, userB to orgB
+	userA uuid.UUID
+	userB uuid.UUID
+}
+
+// newRLSFixture needs TEST_DATABASE_URL to name a superuser connection, which creates the scratch
+// database and role and drops them when the test ends
+func newRLSFixture(t *testing.T) *rlsFixture {
+	t.Helper()
+	adminURL := os.Getenv("TEST_DATABASE_URL")
+	if adminURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, adminURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { admin.Close(context.Background()) })
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	dbName, role := "rls_test_"+suffix, "rls_app_"+suffix
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+dbName); err != nil {
+		t.Fatalf("create database: %v", err)
+	}
+	if _, err := admin.Exec(ctx, "CREATE ROLE "+role+" LOGIN PASSWORD 'rls' NOSUPERUSER NOBYPASSRLS"); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx := context.Background()
+		admin.Exec(ctx, "DROP DATABASE IF EXISTS "+dbName+" WITH (FORCE)")
+		admin.Exec(ctx, "DROP ROLE IF EXISTS "+role)
+	})
+
+	u, err := url.Parse(adminURL)
+	if err != nil {
+		t.Fatalf("TEST_DATABASE_URL must be a URL: %v", err)
+	}
+	u.Path = "/" + dbName
+	owner, err := pgx.Connect(ctx, u.String())
+	if err != nil {
+		t.Fatalf("connect to %s: %v", dbName, err)
+	}
+	t.Cleanup(func() { owner.Close(context.Background()) })
+
+	migrations, err := filepath.Glob("../../../db/migrations/*.up.sql")
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	sort.Strings(migrations)
+	for _, m := range migrations {
+		sql, err := os.ReadFile(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := owner.Exec(ctx, string(sql)); err != nil {
+			t.Fatalf("migrate %s: %v", filepath.Base(m), err)
+		}
+	}
+	for _, grant := range []string{
+		"GRANT USAGE ON SCHEMA public TO " + role,
+		"GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO " + role,
+		"GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO " + role,
+	} {
+		if _, err := owner.Exec(ctx, grant); err != nil {
+			t.Fatalf("grant: %v", err)
+		}
+	}
+
+	f := &rlsFixture{orgA: uuid.New(), orgB: uuid.New(), userA: uuid.New(), userB: uuid.New()}
+	// The superuser is not subject to row level security
+	seed := []struct {
+		sql  string
+		args []any
+	}{
+		{`INSERT INTO "user" (id, email) VALUES ($1, 'a@example.com'), ($2, 'b@example.com')`, []any{f.userA, f.userB}},
+		{`INSERT INTO organization (id, name, created_by) VALUES ($1, 'A', $3), ($2, 'B', $4)`, []any{f.orgA, f.orgB, f.userA, f.userB}},
+		{`INSERT INTO organization_member (organization_id, user_id, role) VALUES ($1, $3, $5), ($2, $4, $5)`, []any{f.orgA, f.orgB, f.userA, f.userB, string(entities.OrganizationRoleOwner)}},
+		{`INSERT INTO organization_scim_token (organization_id, token_hash) VALUES ($1, 'hash-a'), ($2, 'hash-b')`, []any{f.orgA, f.orgB}},
+		{`INSERT INTO whatsapp_session (name, status, organization_id) VALUES ('org-a', 'WORKING', $1), ('org-b', 'WORKING', $2), ('platform', 'WORKING', NULL)`, []any{f.orgA, f.orgB}},
+		{`INSERT INTO campaign (organization_id, created_by, name, channel, template_name, category, send_at)
+			VALUES ($1, $3, 'A', 'email', 'reminder', 'reminder', now()), ($2, $4, 'B', 'email', 'reminder', 'reminder', now()),
+			(NULL, $3, 'A personal', 'email', 'reminder', 'reminder', now()), (NULL, $4, 'B personal', 'email', 'reminder', 'reminder', now())`,
+			[]any{f.orgA, f.orgB, f.userA, f.userB}},
+		{`INSERT INTO outbound_message (channel, recipient, body, organization_id) VALUES ('email', 'a', 'a', $1), ('email', 'b', 'b', $2), ('email', 'p', 'p', NULL)`, []any{f.orgA, f.orgB}},
+	}
+	for _, s := range seed {
+		if _, err := owner.Exec(ctx, s.sql, s.args...); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	u.User = url.UserPassword(role, "rls")
+	pool, err := NewPool(ctx, u.String())
+	if err != nil {
+		t.Fatalf("app pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	f.pool, f.tm = pool, NewTransactionManager(pool)
+	return f
+}
+
+// count returns the rows of table the context sees, on a pooled connection and in a transaction
+func (f *rlsFixture) count(t *testing.T, ctx context.Context, table string) int {
+	t.Helper()
+	var pooled, inTx int
+	if err := f.pool.QueryRow(ctx, "SELECT count(*) FROM "+table).Scan(&pooled); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	err := f.tm.ExecuteInTransaction(ctx, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, "SELECT count(*) FROM "+table).Scan(&inTx)
+	})
+	if err != nil {
+		t.Fatalf("count %s in transaction: %v", table, err)
+	}
+	if pooled != inTx {
+		t.Fatalf("%s: pool sees %d rows, transaction sees %d", table, pooled, inTx)
+	}
+	return pooled
+}
+
+func TestRowLevelSecurityIsolatesOrganizations(t *testing.T) {
+	f := newRLSFixture(t)
+	ctxA := util.WithOrganizationID(context.Background(), f.orgA)
+
+	tests := []struct {
+		table    string
+		wantOrgA int
+	}{
+		{"organization_scim_token", 1},
+		{"whatsapp_session", 1},
+		{"campaign", 1},
+		// The platform message has no organization and is visible to every tenant
+		{"outbound_message", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			if got := f.count(t, ctxA, tt.table); got != tt.wantOrgA {
+				t.Errorf("org A sees %d rows, want %d", got, tt.wantOrgA)
+			}
+			where := fmt.Sprintf("organization_id = '%s'", f.orgB)
+
+			var n int
+			if err := f.pool.QueryRow(ctxA, "SELECT count(*) FROM "+tt.table+" WHERE "+where).Scan(&n); err != nil {
+				t.Fatal(err)
+			}
+			if n != 0 {
+				t.Errorf("org A selects %d rows of org B", n)
+			}
+
+			// Updating a row of org B matches nothing, and moving a row to org B is refused
+			tag, err := f.pool.Exec(ctxA, "UPDATE "+tt.table+" SET organization_id = organization_id WHERE "+where)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tag.RowsAffected() != 0 {
+				t.Errorf("org A updated %d rows of org B", tag.RowsAffected())
+			}
+			err = f.tm.ExecuteInTransaction(ctxA, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctxA, fmt.Sprintf("UPDATE %s SET organization_id = '%s' WHERE organization_id = '%s'", tt.table, f.orgB, f.orgA))
+				return err
+			})
+			if err == nil {
+				t.Error("org A moved its row to org B")
+			}
+		})
+	}
+}
+
+func TestRowLevelSecurityRejectsInsertsForOtherOrganizations(t *testing.T) {
+	f := newRLSFixture(t)
+	ctxA := util.WithOrganizationID(context.Background(), f.orgA)
+
+	inserts := map[string]string{
+		"organization_scim_token": `INSERT INTO organization_scim_token (organization_id, token_hash) VALUES ($1, 'hash-b2')`,
+		"whatsapp_session":        `INSERT INTO whatsapp_session (name, status, organization_id) VALUES ('org-b2', 'WORKING', $1)`,
+		"campaign":                `INSERT INTO campaign (organization_id, name, channel, template_name, category, send_at) VALUES ($1, 'B2', 'email', 'reminder', 'reminder', now())`,
+		"outbound_message":        `INSERT INTO outbound_message (channel, recipient, body, organization_id) VALUES ('email', 'b2', 'b2', $1)`,
+	}
+	for table, sql := range inserts {
+		t.Run(table, func(t *testing.T) {
+			if _, err := f.pool.Exec(ctxA, sql, f.orgB); err == nil {
+				t.Error("org A inserted a row for org B")
+			}
+		})
+	}
+}
+
+func TestRowLevelSecurityPersonalCampaigns(t *testing.T) {
+	f := newRLSFixture(t)
+	// Without an organization a user sees only the campaigns they created outside one
+	ctx := util.WithUser(context.Background(), &entities.User{ID: f.userA})
+	rows, err := f.pool.Query(ctx, "SELECT name FROM campaign ORDER BY name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "A personal" {
+		t.Errorf("user A sees campaigns %v, want [A personal]", names)
+	}
+}
+
+func TestRowLevelSecuritySystemScope(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := util.WithSystemScope(context.Background())
+
+	want := map[string]int{
+		"organization_scim_token": 2,
+		"whatsapp_session":        3,
+		"campaign":                4,
+		"outbound_message":        3,
+	}
+	for table, n := range want {
+		if got := f.count(t, ctx, table); got != n {
+			t.Errorf("system scope sees %d rows of %s, want %d", got, table, n)
+		}
+	}
+
+	// Without a tenant or the system scope nothing scoped to an organization is visible
+	if got := f.count(t, context.Background(), "organization_scim_token"); got != 0 {
+		t.Errorf("no tenant sees %d scim tokens, want 0", got)
+	}
+
+	// A pooled connection does not keep the system scope of its previous user
+	var system string
+	if err := f.pool.QueryRow(context.Background(), "SELECT current_setting('app.system', true)").Scan(&system); err != nil {
+		t.Fatal(err)
+	}
+	if system == "on" {
+		t.Error("app.system leaked to a request without the system scope")
+	}
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type scimTokenRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewScimTokenRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.ScimTokenRepository {
+	return &scimTokenRepository{queries: queries, db: db}
+}
+
+func (r *scimTokenRepository) WithTx(tx pgx.Tx) repositories.ScimTokenRepository {
+	return &scimTokenRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *scimTokenRepository) Create(ctx context.Context, orgID uuid.UUID, tokenHash, description string) (*entities.ScimToken, error) {
+	out, err := r.queries.CreateScimToken(ctx, dbgen.CreateScimTokenParams{
+		OrganizationID: orgID,
+		TokenHash:      tokenHash,
+		Description:    description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *scimTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*entities.ScimToken, error) {
+	out, err := r.queries.GetScimTokenByHash(ctx, tokenHash)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *scimTokenRepository) Touch(ctx context.Context, id uuid.UUID) error {
+	return r.queries.TouchScimToken(ctx, id)
+}
+
+func (r *scimTokenRepository) toEntity(t *dbgen.OrganizationScimToken) *entities.ScimToken {
+	return &entities.ScimToken{
+		ID:             t.ID,
+		OrganizationID: t.OrganizationID,
+		Description:    t.Description,
+		CreatedAt:      t.CreatedAt.Time,
+		LastUsedAt:     fromPgTime(t.LastUsedAt),
+		RevokedAt:      fromPgTime(t.RevokedAt),
+	}
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -31,6 +32,9 @@ func (r *subscriptionRepository) Upsert(ctx context.Context, s *entities.Subscri
 		Status:               string(s.Status),
 		CurrentPeriodEnd:     toPgTimestamptz(s.CurrentPeriodEnd),
 	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -45,6 +49,14 @@ func (r *subscriptionRepository) GetByUser(ctx context.Context, userID uuid.UUID
 	return r.toEntity(&out), nil
 }
 
+func (r *subscriptionRepository) GetByStripeCustomer(ctx context.Context, customerID string) (*entities.Subscription, error) {
+	out, err := r.queries.GetSubscriptionByStripeCustomer(ctx, toPgText(&customerID))
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
 func (r *subscriptionRepository) ListAll(ctx context.Context) ([]*entities.Subscription, error) {
 	rows, err := r.queries.ListAllSubscriptions(ctx)
 	if err != nil {
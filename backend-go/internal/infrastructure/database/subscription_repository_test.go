@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+func TestUpsertSubscriptionKeepsTheCurrentSubscription(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := context.Background()
+	repo := NewSubscriptionRepository(dbgen.New(f.pool), f.pool)
+
+	upsert := func(id string, status entities.PaymentStatus) *entities.Subscription {
+		t.Helper()
+		out, err := repo.Upsert(ctx, &entities.Subscription{UserID: f.userA, StripeSubscriptionID: &id, Status: status})
+		if err != nil {
+			t.Fatalf("upsert %s %s: %v", id, status, err)
+		}
+		return out
+	}
+	current := func() (string, entities.PaymentStatus) {
+		t.Helper()
+		s, err := repo.GetByUser(ctx, f.userA)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *s.StripeSubscriptionID, s.Status
+	}
+
+	upsert("sub_old", entities.PaymentStatusActive)
+	upsert("sub_old", entities.PaymentStatusCanceled)
+	if upsert("sub_new", entities.PaymentStatusActive) == nil {
+		t.Fatal("a new subscription did not replace the canceled one")
+	}
+
+	// The old subscription's events arrive late and must not touch the new one
+	if out := upsert("sub_old", entities.PaymentStatusCanceled); out != nil {
+		t.Errorf("late event of the replaced subscription returned %+v", out)
+	}
+	if id, status := current(); id != "sub_new" || status != entities.PaymentStatusActive {
+		t.Errorf("current subscription is %s %s, want sub_new active", id, status)
+	}
+
+	upsert("sub_new", entities.PaymentStatusPastDue)
+	if id, status := current(); id != "sub_new" || status != entities.PaymentStatusPastDue {
+		t.Errorf("current subscription is %s %s, want sub_new past_due", id, status)
+	}
+}
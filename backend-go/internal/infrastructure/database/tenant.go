@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+// setTenantSQL publishes the request's tenant to the row level security policies.
+// The third argument makes the settings transaction-local when true; app.system is on in the system scope.
+const setTenantSQL = "SELECT set_config('app.current_org_id', $1, $3), set_config('app.current_user_id', $2, $3), set_config('app.system', $4, $3)"
+
+// tenantFromContext returns the organization and user ids of the request, empty when absent,
+// and whether it runs in the system scope
+func tenantFromContext(ctx context.Context) (orgID, userID, system string) {
+	if id, ok := util.OrganizationIDFromContext(ctx); ok {
+		orgID = id.String()
+	}
+	if user := util.UserFromContext(ctx); user != nil {
+		userID = user.ID.String()
+	}
+	if util.IsSystemScope(ctx) {
+		system = "on"
+	}
+	return orgID, userID, system
+}
+
+// prepareTenantConn runs on every pool acquire so a connection never carries the
+// previous request's tenant; settings are always overwritten, including with empty values.
+func prepareTenantConn(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	orgID, userID, system := tenantFromContext(ctx)
+	if _, err := conn.Exec(ctx, setTenantSQL, orgID, userID, false, system); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setTenantLocal pins the tenant for the lifetime of a transaction
+func setTenantLocal(ctx context.Context, tx pgx.Tx) error {
+	orgID, userID, system := tenantFromContext(ctx)
+	_, err := tx.Exec(ctx, setTenantSQL, orgID, userID, true, system)
+	return err
+}
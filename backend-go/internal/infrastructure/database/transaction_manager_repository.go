@@ -23,6 +23,10 @@ func (tm *TransactionManager) ExecuteInTransaction(ctx context.Context, fn func(
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	if err := setTenantLocal(ctx, tx); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to set tenant: %w", err)
+	}
 
 	defer func() {
 		if p := recover(); p != nil {
@@ -47,5 +51,13 @@ func (tm *TransactionManager) ExecuteInTransaction(ctx context.Context, fn func(
 
 // GetTx returns a transaction that can be passed to repositories
 func (tm *TransactionManager) GetTx(ctx context.Context) (pgx.Tx, error) {
-	return tm.pool.Begin(ctx)
+	tx, err := tm.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := setTenantLocal(ctx, tx); err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to set tenant: %w", err)
+	}
+	return tx, nil
 }
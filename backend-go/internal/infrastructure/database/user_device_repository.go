@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type userDeviceRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewUserDeviceRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.UserDeviceRepository {
+	return &userDeviceRepository{queries: queries, db: db}
+}
+
+func (r *userDeviceRepository) WithTx(tx pgx.Tx) repositories.UserDeviceRepository {
+	return &userDeviceRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *userDeviceRepository) GetByFingerprint(ctx context.Context, userID uuid.UUID, fingerprint string) (*entities.UserDevice, error) {
+	out, err := r.queries.GetUserDeviceByFingerprint(ctx, dbgen.GetUserDeviceByFingerprintParams{
+		UserID:      userID,
+		Fingerprint: fingerprint,
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *userDeviceRepository) Create(ctx context.Context, d *entities.UserDevice) (*entities.UserDevice, error) {
+	out, err := r.queries.CreateUserDevice(ctx, dbgen.CreateUserDeviceParams{
+		UserID:      d.UserID,
+		Fingerprint: d.Fingerprint,
+		UserAgent:   d.UserAgent,
+		IpPrefix:    d.IPPrefix,
+		Country:     d.Country,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *userDeviceRepository) Touch(ctx context.Context, id uuid.UUID) error {
+	return r.queries.TouchUserDevice(ctx, id)
+}
+
+func (r *userDeviceRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	n, err := r.queries.CountUserDevices(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (r *userDeviceRepository) Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	return r.queries.DeleteUserDevice(ctx, dbgen.DeleteUserDeviceParams{ID: id, UserID: userID})
+}
+
+func (r *userDeviceRepository) toEntity(v *dbgen.UserDevice) *entities.UserDevice {
+	return &entities.UserDevice{
+		ID:          v.ID,
+		UserID:      v.UserID,
+		Fingerprint: v.Fingerprint,
+		UserAgent:   v.UserAgent,
+		IPPrefix:    v.IpPrefix,
+		Country:     v.Country,
+		FirstSeenAt: v.FirstSeenAt.Time,
+		LastSeenAt:  v.LastSeenAt.Time,
+	}
+}
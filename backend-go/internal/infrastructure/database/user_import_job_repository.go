@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type userImportJobRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewUserImportJobRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.UserImportJobRepository {
+	return &userImportJobRepository{queries: queries, db: db}
+}
+
+func (r *userImportJobRepository) WithTx(tx pgx.Tx) repositories.UserImportJobRepository {
+	return &userImportJobRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *userImportJobRepository) Create(ctx context.Context, job *entities.UserImportJob) (*entities.UserImportJob, error) {
+	out, err := r.queries.CreateUserImportJob(ctx, dbgen.CreateUserImportJobParams{
+		CreatedBy: toPgUUIDPtr(job.CreatedBy),
+		Status:    string(job.Status),
+		DryRun:    job.DryRun,
+		TotalRows: int32(job.TotalRows),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *userImportJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.UserImportJob, error) {
+	out, err := r.queries.GetUserImportJob(ctx, id)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *userImportJobRepository) UpdateProgress(ctx context.Context, job *entities.UserImportJob) error {
+	return r.queries.UpdateUserImportJobProgress(ctx, dbgen.UpdateUserImportJobProgressParams{
+		ID:            job.ID,
+		Status:        string(job.Status),
+		ProcessedRows: int32(job.ProcessedRows),
+		CreatedCount:  int32(job.CreatedCount),
+		SkippedCount:  int32(job.SkippedCount),
+		ErrorCount:    int32(job.ErrorCount),
+	})
+}
+
+func (r *userImportJobRepository) Finish(ctx context.Context, job *entities.UserImportJob) error {
+	report, err := json.Marshal(job.Report)
+	if err != nil {
+		return err
+	}
+	return r.queries.FinishUserImportJob(ctx, dbgen.FinishUserImportJobParams{
+		ID:            job.ID,
+		Status:        string(job.Status),
+		ProcessedRows: int32(job.ProcessedRows),
+		CreatedCount:  int32(job.CreatedCount),
+		SkippedCount:  int32(job.SkippedCount),
+		ErrorCount:    int32(job.ErrorCount),
+		Report:        report,
+		Error:         job.Error,
+	})
+}
+
+func (r *userImportJobRepository) FailStale(ctx context.Context, before time.Time, reason string) (int64, error) {
+	return r.queries.FailStaleUserImportJobs(ctx, dbgen.FailStaleUserImportJobsParams{
+		HeartbeatAt: toPgTimestamptz(&before),
+		Error:       reason,
+	})
+}
+
+func (r *userImportJobRepository) toEntity(j *dbgen.UserImportJob) *entities.UserImportJob {
+	job := &entities.UserImportJob{
+		ID:            j.ID,
+		Status:        entities.UserImportStatus(j.Status),
+		DryRun:        j.DryRun,
+		TotalRows:     int(j.TotalRows),
+		ProcessedRows: int(j.ProcessedRows),
+		CreatedCount:  int(j.CreatedCount),
+		SkippedCount:  int(j.SkippedCount),
+		ErrorCount:    int(j.ErrorCount),
+		Error:         j.Error,
+		CreatedAt:     j.CreatedAt.Time,
+		FinishedAt:    fromPgTime(j.FinishedAt),
+	}
+	if j.CreatedBy.Valid {
+		createdBy := uuid.UUID(j.CreatedBy.Bytes)
+		job.CreatedBy = &createdBy
+	}
+	_ = json.Unmarshal(j.Report, &job.Report)
+	return job
+}
@@ -2,6 +2,10 @@ package database
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
@@ -9,8 +13,13 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// Postgres error code for a unique index violation
+const uniqueViolation = "23505"
+
 type userRepository struct {
 	queries *dbgen.Queries
 	db      repositories.ConnectionPool
@@ -57,12 +66,14 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entitie
 
 func (r *userRepository) Create(ctx context.Context, user *entities.User) (*entities.User, error) {
 	params := dbgen.CreateUserParams{
-		Email:           user.Email,
-		IsActive:        user.IsActive,
-		IsEmailVerified: user.IsEmailVerified,
-		PhoneNumber:     toPgText(user.PhoneNumber),
-		FullName:        toPgText(user.FullName),
-		HashedPassword:  toPgText(user.HashedPassword),
+		Email:            user.Email,
+		IsActive:         user.IsActive,
+		IsEmailVerified:  user.IsEmailVerified,
+		PhoneNumber:      toPgText(user.PhoneNumber),
+		FullName:         toPgText(user.FullName),
+		HashedPassword:   toPgText(user.HashedPassword),
+		Locale:           toPgTextOmitEmpty(user.Locale),
+		ProvisionedByOrg: toPgUUIDPtr(user.ProvisionedByOrg),
 	}
 
 	dbUser, err := r.queries.CreateUser(ctx, params)
@@ -127,6 +138,30 @@ func (r *userRepository) UpdatePhone(ctx context.Context, userID uuid.UUID, phon
 	return r.toEntity(&dbUser, roles), nil
 }
 
+func (r *userRepository) SetPreferredChannel(ctx context.Context, userID uuid.UUID, channel entities.MessageChannel) (*entities.User, error) {
+	dbUser, err := r.queries.SetUserPreferredChannel(ctx, dbgen.SetUserPreferredChannelParams{
+		ID:               userID,
+		PreferredChannel: toPgTextOmitEmpty(string(channel)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	roles, _ := r.queries.GetUserRole(ctx, dbUser.ID)
+	return r.toEntity(&dbUser, roles), nil
+}
+
+func (r *userRepository) SetLocale(ctx context.Context, userID uuid.UUID, locale string) (*entities.User, error) {
+	dbUser, err := r.queries.SetUserLocale(ctx, dbgen.SetUserLocaleParams{
+		ID:     userID,
+		Locale: toPgTextOmitEmpty(locale),
+	})
+	if err != nil {
+		return nil, err
+	}
+	roles, _ := r.queries.GetUserRole(ctx, dbUser.ID)
+	return r.toEntity(&dbUser, roles), nil
+}
+
 func (r *userRepository) GetByPhone(ctx context.Context, phone string) (*entities.User, error) {
 	dbUser, err := r.queries.GetUserByPhone(ctx, toPgText(&phone))
 	if err == pgx.ErrNoRows {
@@ -142,24 +177,209 @@ func (r *userRepository) GetByPhone(ctx context.Context, phone string) (*entitie
 	return r.toEntity(&dbUser, dbRoles), nil
 }
 
-func (r *userRepository) ListUsers(ctx context.Context, offset, limit int32) ([]*entities.User, int, error) {
-	dbUsers, err := r.queries.ListUsers(ctx, dbgen.ListUsersParams{Limit: limit, Offset: offset})
-	if err != nil {
-		return nil, 0, err
+func (r *userRepository) ListUsers(ctx context.Context, filter entities.UserFilter, offset, limit int32, after *entities.UserCursor) ([]*entities.User, *entities.UserCursor, error) {
+	// The list queries differ only in their order, so their parameters convert into each other
+	byText := dbgen.ListUsersByEmailAscParams{
+		Deleted:         filter.Deleted,
+		Search:          toPgTextOmitEmpty(escapeLike(filter.Search)),
+		Role:            toPgTextOmitEmpty(filter.Role),
+		IsActive:        toPgBool(filter.IsActive),
+		IsEmailVerified: toPgBool(filter.IsEmailVerified),
+		IsPhoneVerified: toPgBool(filter.IsPhoneVerified),
+		CreatedAfter:    toPgTimestamptz(filter.CreatedAfter),
+		CreatedBefore:   toPgTimestamptz(filter.CreatedBefore),
+		OauthProvider:   toPgTextOmitEmpty(filter.OAuthProvider),
+		PageLimit:       limit,
+		PageOffset:      offset,
+	}
+	byTime := dbgen.ListUsersByCreatedAtAscParams{
+		Deleted:         byText.Deleted,
+		Search:          byText.Search,
+		Role:            byText.Role,
+		IsActive:        byText.IsActive,
+		IsEmailVerified: byText.IsEmailVerified,
+		IsPhoneVerified: byText.IsPhoneVerified,
+		CreatedAfter:    byText.CreatedAfter,
+		CreatedBefore:   byText.CreatedBefore,
+		OauthProvider:   byText.OauthProvider,
+		PageLimit:       limit,
+		PageOffset:      offset,
+	}
+	if after != nil {
+		byText.AfterID = toPgUUIDPtr(&after.ID)
+		byTime.AfterID = byText.AfterID
+		switch filter.SortBy {
+		case entities.UserSortEmail, entities.UserSortFullName:
+			byText.AfterKey = pgtype.Text{String: after.SortKey, Valid: true}
+		default:
+			t, err := time.Parse(time.RFC3339Nano, after.SortKey)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid cursor: %w", err)
+			}
+			byTime.AfterKey = toPgTimestamptz(&t)
+		}
+	}
+
+	var rows []dbgen.User
+	var err error
+	switch filter.SortBy {
+	case entities.UserSortEmail:
+		if filter.SortDesc {
+			rows, err = r.queries.ListUsersByEmailDesc(ctx, dbgen.ListUsersByEmailDescParams(byText))
+		} else {
+			rows, err = r.queries.ListUsersByEmailAsc(ctx, byText)
+		}
+	case entities.UserSortFullName:
+		if filter.SortDesc {
+			rows, err = r.queries.ListUsersByFullNameDesc(ctx, dbgen.ListUsersByFullNameDescParams(byText))
+		} else {
+			rows, err = r.queries.ListUsersByFullNameAsc(ctx, dbgen.ListUsersByFullNameAscParams(byText))
+		}
+	case entities.UserSortLastLoginAt:
+		if filter.SortDesc {
+			rows, err = r.queries.ListUsersByLastLoginAtDesc(ctx, dbgen.ListUsersByLastLoginAtDescParams(byTime))
+		} else {
+			rows, err = r.queries.ListUsersByLastLoginAtAsc(ctx, dbgen.ListUsersByLastLoginAtAscParams(byTime))
+		}
+	default:
+		if filter.SortDesc {
+			rows, err = r.queries.ListUsersByCreatedAtDesc(ctx, dbgen.ListUsersByCreatedAtDescParams(byTime))
+		} else {
+			rows, err = r.queries.ListUsersByCreatedAtAsc(ctx, byTime)
+		}
 	}
-	total, err := r.queries.CountUsers(ctx)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
-	users := make([]*entities.User, len(dbUsers))
-	for i, dbUser := range dbUsers {
-		dbRoles, err := r.queries.GetUserRole(ctx, dbUser.ID)
+
+	users := make([]*entities.User, len(rows))
+	for i := range rows {
+		dbRoles, err := r.queries.GetUserRole(ctx, rows[i].ID)
 		if err != nil {
-			return nil, 0, err
+			return nil, nil, err
+		}
+		users[i] = r.toEntity(&rows[i], dbRoles)
+	}
+	var next *entities.UserCursor
+	if len(users) > 0 && int32(len(users)) == limit {
+		last := users[len(users)-1]
+		next = &entities.UserCursor{SortKey: userSortKey(last, filter.SortBy), ID: last.ID}
+	}
+	return users, next, nil
+}
+
+// userSortKey is the cursor value of u in the given order: the raw text for the text orders,
+// which the queries lower, and the time as RFC 3339 otherwise
+func userSortKey(u *entities.User, sortBy entities.UserSortField) string {
+	switch sortBy {
+	case entities.UserSortEmail:
+		return u.Email
+	case entities.UserSortFullName:
+		if u.FullName == nil {
+			return ""
+		}
+		return *u.FullName
+	case entities.UserSortLastLoginAt:
+		if u.LastLoginAt == nil {
+			return time.Unix(0, 0).UTC().Format(time.RFC3339Nano)
+		}
+		return u.LastLoginAt.UTC().Format(time.RFC3339Nano)
+	}
+	return u.CreatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+func (r *userRepository) CountUsers(ctx context.Context, filter entities.UserFilter) (int, error) {
+	total, err := r.queries.CountUsers(ctx, dbgen.CountUsersParams{
+		Deleted:         filter.Deleted,
+		Search:          toPgTextOmitEmpty(escapeLike(filter.Search)),
+		Role:            toPgTextOmitEmpty(filter.Role),
+		IsActive:        toPgBool(filter.IsActive),
+		IsEmailVerified: toPgBool(filter.IsEmailVerified),
+		IsPhoneVerified: toPgBool(filter.IsPhoneVerified),
+		CreatedAfter:    toPgTimestamptz(filter.CreatedAfter),
+		CreatedBefore:   toPgTimestamptz(filter.CreatedBefore),
+		OauthProvider:   toPgTextOmitEmpty(filter.OAuthProvider),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(total), nil
+}
+
+func (r *userRepository) ListDirectoryUsers(ctx context.Context, orgID uuid.UUID, filter entities.DirectoryUserFilter, offset, limit int32) ([]*entities.DirectoryUser, error) {
+	rows, err := r.queries.ListDirectoryUsers(ctx, dbgen.ListDirectoryUsersParams{
+		OrganizationID: orgID,
+		FilterAttr:     toPgTextOmitEmpty(string(filter.Attribute)),
+		FilterOp:       filter.Operator,
+		FilterValue:    filter.Value,
+		PageLimit:      limit,
+		PageOffset:     offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*entities.DirectoryUser, len(rows))
+	for i := range rows {
+		u := r.toEntity(&rows[i].User, nil)
+		users[i] = &entities.DirectoryUser{
+			User: u,
+			Member: &entities.OrganizationMember{
+				OrganizationID: orgID,
+				UserID:         u.ID,
+				Role:           entities.OrganizationRole(rows[i].Role),
+				Email:          u.Email,
+				FullName:       u.FullName,
+				PhoneNumber:    u.PhoneNumber,
+				JoinedAt:       rows[i].JoinedAt.Time,
+			},
 		}
-		users[i] = r.toEntity(&dbUser, dbRoles)
 	}
-	return users, int(total), nil
+	return users, nil
+}
+
+func (r *userRepository) CountDirectoryUsers(ctx context.Context, orgID uuid.UUID, filter entities.DirectoryUserFilter) (int, error) {
+	total, err := r.queries.CountDirectoryUsers(ctx, dbgen.CountDirectoryUsersParams{
+		OrganizationID: orgID,
+		FilterAttr:     toPgTextOmitEmpty(string(filter.Attribute)),
+		FilterOp:       filter.Operator,
+		FilterValue:    filter.Value,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(total), nil
+}
+
+func (r *userRepository) SoftDelete(ctx context.Context, userID uuid.UUID) (bool, error) {
+	n, err := r.queries.SoftDeleteUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *userRepository) Restore(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	dbUser, err := r.queries.RestoreUser(ctx, userID)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, repositories.ErrUserConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	dbRoles, err := r.queries.GetUserRole(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&dbUser, dbRoles), nil
+}
+
+// escapeLike makes the wildcard characters of a search term match literally in ILIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 func (r *userRepository) SetPhoneVerified(ctx context.Context, userID uuid.UUID) error {
@@ -172,6 +392,14 @@ func (r *userRepository) SetEmailVerified(ctx context.Context, userID uuid.UUID)
 	return err
 }
 
+func (r *userRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	return r.queries.UpdateUserLastLogin(ctx, dbgen.UpdateUserLastLoginParams{ID: userID, LastLoginAt: toPgTimestamptz(&at)})
+}
+
+func (r *userRepository) RevokeSessions(ctx context.Context, userID uuid.UUID) error {
+	return r.queries.RevokeUserSessions(ctx, userID)
+}
+
 func (r *userRepository) GetRoles(ctx context.Context, roles []entities.RoleEnum) ([]int32, error) {
 	roleNames := []string{}
 	for _, r := range roles {
@@ -236,18 +464,27 @@ func (r *userRepository) SetUserRoles(ctx context.Context, userID uuid.UUID, rol
 
 func (r *userRepository) toEntity(dbUser *dbgen.User, dbRoles []dbgen.Role) *entities.User {
 	user := &entities.User{
-		ID:              dbUser.ID,
-		Email:           dbUser.Email,
-		PhoneNumber:     fromPgText(dbUser.PhoneNumber),
-		FullName:        fromPgText(dbUser.FullName),
-		HashedPassword:  fromPgText(dbUser.HashedPassword),
-		IsActive:        dbUser.IsActive,
-		IsEmailVerified: dbUser.IsEmailVerified,
-		IsPhoneVerified: dbUser.IsPhoneVerified,
-		IsTOTPEnabled:   dbUser.IsTotpEnabled,
-		TOTPSecret:      fromPgText(dbUser.TotpSecret),
-		CreatedAt:       dbUser.CreatedAt.Time,
-		UpdatedAt:       dbUser.UpdatedAt.Time,
+		ID:                dbUser.ID,
+		Email:             dbUser.Email,
+		PhoneNumber:       fromPgText(dbUser.PhoneNumber),
+		FullName:          fromPgText(dbUser.FullName),
+		HashedPassword:    fromPgText(dbUser.HashedPassword),
+		IsActive:          dbUser.IsActive,
+		IsEmailVerified:   dbUser.IsEmailVerified,
+		IsPhoneVerified:   dbUser.IsPhoneVerified,
+		IsTOTPEnabled:     dbUser.IsTotpEnabled,
+		TOTPSecret:        fromPgText(dbUser.TotpSecret),
+		CreatedAt:         dbUser.CreatedAt.Time,
+		UpdatedAt:         dbUser.UpdatedAt.Time,
+		LastLoginAt:       fromPgTime(dbUser.LastLoginAt),
+		SessionsRevokedAt: fromPgTime(dbUser.SessionsRevokedAt),
+		DeletedAt:         fromPgTime(dbUser.DeletedAt),
+		PreferredChannel:  entities.MessageChannel(dbUser.PreferredChannel.String),
+		Locale:            dbUser.Locale.String,
+	}
+	if dbUser.ProvisionedByOrg.Valid {
+		orgID := uuid.UUID(dbUser.ProvisionedByOrg.Bytes)
+		user.ProvisionedByOrg = &orgID
 	}
 	for _, role := range dbRoles {
 		user.Roles = append(user.Roles, role.Name)
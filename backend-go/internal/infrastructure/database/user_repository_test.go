@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+)
+
+// TestListUsersKeysetPages walks every order a page at a time and expects the users of one unpaged
+// list, so ties and NULL sort values neither repeat nor drop users at page boundaries
+func TestListUsersKeysetPages(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := context.Background()
+
+	// Equal creation times, emails differing only in case and missing names make ties
+	seed := `INSERT INTO "user" (email, full_name, created_at, last_login_at) VALUES
+		('Carol@example.com', 'carol', '2026-01-01T00:00:00Z', NULL),
+		('carol2@example.com', 'Carol', '2026-01-01T00:00:00Z', '2026-02-01T00:00:00.123456Z'),
+		('dave@example.com', NULL, '2026-01-01T00:00:00Z', NULL),
+		('erin@example.com', NULL, '2026-01-02T00:00:00Z', '2026-02-01T00:00:00.123456Z'),
+		('frank@example.com', 'frank', '2026-01-03T00:00:00Z', '2026-01-15T00:00:00Z')`
+	if _, err := f.pool.Exec(ctx, seed); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	repo := NewUserRepository(dbgen.New(f.pool), f.pool)
+
+	sorts := []entities.UserSortField{entities.UserSortCreatedAt, entities.UserSortEmail, entities.UserSortFullName, entities.UserSortLastLoginAt}
+	for _, sortBy := range sorts {
+		for _, desc := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s desc=%v", sortBy, desc), func(t *testing.T) {
+				filter := entities.UserFilter{SortBy: sortBy, SortDesc: desc}
+				all, _, err := repo.ListUsers(ctx, filter, 0, 100, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				total, err := repo.CountUsers(ctx, filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if total != len(all) {
+					t.Fatalf("CountUsers = %d, list has %d users", total, len(all))
+				}
+
+				var paged []uuid.UUID
+				var after *entities.UserCursor
+				for range len(all) + 1 {
+					users, next, err := repo.ListUsers(ctx, filter, 0, 2, after)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, u := range users {
+						paged = append(paged, u.ID)
+					}
+					if next == nil {
+						break
+					}
+					after = next
+				}
+				if len(paged) != len(all) {
+					t.Fatalf("pages hold %d users, want %d", len(paged), len(all))
+				}
+				for i, u := range all {
+					if paged[i] != u.ID {
+						t.Fatalf("user %d of the pages is %s, want %s", i, paged[i], u.ID)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestListDirectoryUsersFilters(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := util.WithOrganizationID(context.Background(), f.orgA)
+
+	// a@example.com is the fixture's owner of A; deleted members and those of B are never listed
+	seed := `WITH u AS (
+		INSERT INTO "user" (email, full_name, deleted_at) VALUES
+			('Carol@example.com', 'Carol Baker', NULL),
+			('dave@example.com', NULL, NULL),
+			('erin@example.org', 'Erin', NULL),
+			('gone@example.com', 'Gone', now())
+		RETURNING id
+	)
+	INSERT INTO organization_member (organization_id, user_id, role) SELECT $1, id, $2 FROM u`
+	if _, err := f.pool.Exec(ctx, seed, f.orgA, string(entities.OrganizationRoleEmployee)); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	repo := NewUserRepository(dbgen.New(f.pool), f.pool)
+
+	tests := []struct {
+		filter entities.DirectoryUserFilter
+		want   []string
+	}{
+		{entities.DirectoryUserFilter{}, []string{"a@example.com", "Carol@example.com", "dave@example.com", "erin@example.org"}},
+		{entities.DirectoryUserFilter{Attribute: entities.DirectoryUserEmail, Operator: "eq", Value: "CAROL@example.com"}, []string{"Carol@example.com"}},
+		{entities.DirectoryUserFilter{Attribute: entities.DirectoryUserEmail, Operator: "ne", Value: "dave@example.com"}, []string{"a@example.com", "Carol@example.com", "erin@example.org"}},
+		{entities.DirectoryUserFilter{Attribute: entities.DirectoryUserEmail, Operator: "co", Value: "ARO"}, []string{"Carol@example.com"}},
+		{entities.DirectoryUserFilter{Attribute: entities.DirectoryUserEmail, Operator: "sw", Value: "E"}, []string{"erin@example.org"}},
+		{entities.DirectoryUserFilter{Attribute: entities.DirectoryUserEmail, Operator: "ew", Value: "@example.com"}, []string{"a@example.com", "Carol@example.com", "dave@example.com"}},
+		{entities.DirectoryUserFilter{Attribute: entities.DirectoryUserID, Operator: "eq", Value: f.userA.String()}, []string{"a@example.com"}},
+		// Members without a name match no comparison of it
+		{entities.DirectoryUserFilter{Attribute: entities.DirectoryUserFullName, Operator: "ne", Value: "erin"}, []string{"Carol@example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s %s", tt.filter.Attribute, tt.filter.Operator, tt.filter.Value), func(t *testing.T) {
+			users, err := repo.ListDirectoryUsers(ctx, f.orgA, tt.filter, 0, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			total, err := repo.CountDirectoryUsers(ctx, f.orgA, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]bool{}
+			for _, u := range users {
+				got[u.User.Email] = true
+			}
+			if len(users) != len(tt.want) || total != len(tt.want) {
+				t.Fatalf("listed %d and counted %d members, want %v", len(users), total, tt.want)
+			}
+			for _, email := range tt.want {
+				if !got[email] {
+					t.Errorf("%s is missing", email)
+				}
+			}
+		})
+	}
+
+	all, err := repo.ListDirectoryUsers(ctx, f.orgA, entities.DirectoryUserFilter{}, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for offset := int32(0); offset < int32(len(all)); offset += 2 {
+		page, err := repo.ListDirectoryUsers(ctx, f.orgA, entities.DirectoryUserFilter{}, offset, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, u := range page {
+			if u.User.ID != all[int(offset)+i].User.ID {
+				t.Fatalf("member %d of the pages is %s, want %s", int(offset)+i, u.User.Email, all[int(offset)+i].User.Email)
+			}
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 )
 
 // Helper functions for pgtype conversion
@@ -17,6 +18,18 @@ func toPgText(s *string) pgtype.Text {
 	return pgtype.Text{String: *s, Valid: true}
 }
 
+// toPgTextOmitEmpty maps an empty string to NULL, for optional query filters
+func toPgTextOmitEmpty(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func toPgBool(b *bool) pgtype.Bool {
+	if b == nil {
+		return pgtype.Bool{Valid: false}
+	}
+	return pgtype.Bool{Bool: *b, Valid: true}
+}
+
 func fromPgText(t pgtype.Text) *string {
 	if !t.Valid {
 		return nil
@@ -54,6 +67,45 @@ func fromPgJSON(j []byte) map[string]any {
 	return m
 }
 
+// toWhatsAppContentJSON stores nil content as NULL rather than an empty object
+func toWhatsAppContentJSON(c *entities.WhatsAppContent) []byte {
+	if c == nil {
+		return nil
+	}
+	b, _ := json.Marshal(c)
+	return b
+}
+
+func fromWhatsAppContentJSON(j []byte) *entities.WhatsAppContent {
+	if len(j) == 0 {
+		return nil
+	}
+	var c entities.WhatsAppContent
+	if err := json.Unmarshal(j, &c); err != nil {
+		return nil
+	}
+	return &c
+}
+
+func toEmailContentJSON(c *entities.EmailContent) []byte {
+	if c == nil {
+		return nil
+	}
+	b, _ := json.Marshal(c)
+	return b
+}
+
+func fromEmailContentJSON(j []byte) *entities.EmailContent {
+	if len(j) == 0 {
+		return nil
+	}
+	var c entities.EmailContent
+	if err := json.Unmarshal(j, &c); err != nil {
+		return nil
+	}
+	return &c
+}
+
 func toPgNumeric(f *float64) pgtype.Numeric {
 	if f == nil {
 		return pgtype.Numeric{Valid: false}
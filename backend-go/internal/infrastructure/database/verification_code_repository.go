@@ -96,6 +96,17 @@ func (r *verificationCodeRepository) GetByCodeOnly(ctx context.Context, vType en
 	return r.toEntity(&res), nil
 }
 
+func (r *verificationCodeRepository) GetPendingAddPhone(ctx context.Context, phone string) (*entities.VerificationCode, error) {
+	res, err := r.queries.GetPendingAddPhoneVerification(ctx, phone)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&res), nil
+}
+
 func (r *verificationCodeRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
 	_, err := r.queries.MarkVerificationCodeUsed(ctx, id)
 	return err
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type webhookEventRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewWebhookEventRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.WebhookEventRepository {
+	return &webhookEventRepository{queries: queries, db: db}
+}
+
+func (r *webhookEventRepository) WithTx(tx pgx.Tx) repositories.WebhookEventRepository {
+	return &webhookEventRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *webhookEventRepository) Record(ctx context.Context, e *entities.WebhookEvent) (*entities.WebhookEvent, error) {
+	out, err := r.queries.RecordWebhookEvent(ctx, dbgen.RecordWebhookEventParams{
+		Provider:  string(e.Provider),
+		EventID:   e.EventID,
+		EventType: e.EventType,
+		Payload:   e.Payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&out), nil
+}
+
+func (r *webhookEventRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.WebhookEvent, error) {
+	return r.one(r.queries.GetWebhookEvent(ctx, id))
+}
+
+func (r *webhookEventRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*entities.WebhookEvent, error) {
+	return r.one(r.queries.GetWebhookEventForUpdate(ctx, id))
+}
+
+func (r *webhookEventRepository) MarkProcessed(ctx context.Context, id uuid.UUID) error {
+	return r.queries.MarkWebhookEventProcessed(ctx, id)
+}
+
+func (r *webhookEventRepository) MarkFailed(ctx context.Context, id uuid.UUID, lastError string) error {
+	return r.queries.MarkWebhookEventFailed(ctx, dbgen.MarkWebhookEventFailedParams{ID: id, LastError: lastError})
+}
+
+func (r *webhookEventRepository) List(ctx context.Context, provider entities.PaymentProvider, status entities.WebhookEventStatus, offset, limit int32) ([]*entities.WebhookEvent, int, error) {
+	rows, err := r.queries.ListWebhookEvents(ctx, dbgen.ListWebhookEventsParams{
+		Provider:   toPgTextOmitEmpty(string(provider)),
+		Status:     toPgTextOmitEmpty(string(status)),
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.queries.CountWebhookEvents(ctx, dbgen.CountWebhookEventsParams{
+		Provider: toPgTextOmitEmpty(string(provider)),
+		Status:   toPgTextOmitEmpty(string(status)),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]*entities.WebhookEvent, 0, len(rows))
+	for i := range rows {
+		out = append(out, r.toEntity(&rows[i]))
+	}
+	return out, int(total), nil
+}
+
+func (r *webhookEventRepository) one(e dbgen.WebhookEvent, err error) (*entities.WebhookEvent, error) {
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&e), nil
+}
+
+func (r *webhookEventRepository) toEntity(e *dbgen.WebhookEvent) *entities.WebhookEvent {
+	return &entities.WebhookEvent{
+		ID:          e.ID,
+		Provider:    entities.PaymentProvider(e.Provider),
+		EventID:     e.EventID,
+		EventType:   e.EventType,
+		Payload:     e.Payload,
+		Status:      entities.WebhookEventStatus(e.Status),
+		Attempts:    int(e.Attempts),
+		LastError:   e.LastError,
+		ProcessedAt: fromPgTime(e.ProcessedAt),
+		CreatedAt:   e.CreatedAt.Time,
+		UpdatedAt:   e.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
+)
+
+type whatsAppSessionRepository struct {
+	queries *dbgen.Queries
+	db      repositories.ConnectionPool
+}
+
+func NewWhatsAppSessionRepository(queries *dbgen.Queries, db repositories.ConnectionPool) repositories.WhatsAppSessionRepository {
+	return &whatsAppSessionRepository{queries: queries, db: db}
+}
+
+func (r *whatsAppSessionRepository) WithTx(tx pgx.Tx) repositories.WhatsAppSessionRepository {
+	return &whatsAppSessionRepository{queries: r.queries.WithTx(tx), db: r.db}
+}
+
+func (r *whatsAppSessionRepository) SetStatus(ctx context.Context, name, status string) (*entities.WhatsAppSession, string, error) {
+	row, err := r.queries.UpsertWhatsAppSessionStatus(ctx, dbgen.UpsertWhatsAppSessionStatusParams{
+		Name:   name,
+		Status: status,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return r.toEntity(&dbgen.WhatsappSession{
+		Name:            row.Name,
+		Status:          row.Status,
+		StatusChangedAt: row.StatusChangedAt,
+		LastEventAt:     row.LastEventAt,
+		OrganizationID:  row.OrganizationID,
+		CreatedAt:       row.CreatedAt,
+	}), row.PreviousStatus, nil
+}
+
+func (r *whatsAppSessionRepository) Touch(ctx context.Context, name string) error {
+	return r.queries.TouchWhatsAppSession(ctx, name)
+}
+
+func (r *whatsAppSessionRepository) List(ctx context.Context) ([]*entities.WhatsAppSession, error) {
+	rows, err := r.queries.ListWhatsAppSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*entities.WhatsAppSession, 0, len(rows))
+	for i := range rows {
+		sessions = append(sessions, r.toEntity(&rows[i]))
+	}
+	return sessions, nil
+}
+
+func (r *whatsAppSessionRepository) CreateForOrganization(ctx context.Context, name string, orgID uuid.UUID, status string) (*entities.WhatsAppSession, error) {
+	row, err := r.queries.CreateOrganizationWhatsAppSession(ctx, dbgen.CreateOrganizationWhatsAppSessionParams{
+		Name:           name,
+		Status:         status,
+		OrganizationID: toPgUUIDPtr(&orgID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&row), nil
+}
+
+func (r *whatsAppSessionRepository) GetByOrganization(ctx context.Context, orgID uuid.UUID) (*entities.WhatsAppSession, error) {
+	row, err := r.queries.GetWhatsAppSessionByOrganization(ctx, toPgUUIDPtr(&orgID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&row), nil
+}
+
+func (r *whatsAppSessionRepository) TakeSendSlot(ctx context.Context, session string, spacing time.Duration) (time.Duration, error) {
+	_, err := r.queries.TakeWhatsAppSendSlot(ctx, dbgen.TakeWhatsAppSendSlotParams{
+		Session:        session,
+		SpacingSeconds: spacing.Seconds(),
+	})
+	if err == nil {
+		return 0, nil
+	}
+	if err != pgx.ErrNoRows {
+		return 0, err
+	}
+	next, err := r.queries.GetWhatsAppSendSlot(ctx, session)
+	if err != nil {
+		return 0, err
+	}
+	// The slot may have opened since; waiting a moment still beats trying again right away
+	return max(time.Until(next.Time), time.Second), nil
+}
+
+func (r *whatsAppSessionRepository) toEntity(s *dbgen.WhatsappSession) *entities.WhatsAppSession {
+	session := &entities.WhatsAppSession{
+		Name:            s.Name,
+		Status:          s.Status,
+		StatusChangedAt: s.StatusChangedAt.Time,
+		LastEventAt:     s.LastEventAt.Time,
+	}
+	if s.OrganizationID.Valid {
+		orgID := uuid.UUID(s.OrganizationID.Bytes)
+		session.OrganizationID = &orgID
+	}
+	return session
+}
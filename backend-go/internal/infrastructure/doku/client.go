@@ -9,17 +9,24 @@ import (
     "encoding/json"
     "fmt"
     "net/http"
+    "net/url"
     "strings"
     "time"
 
     "github.com/google/uuid"
+    "github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 )
 
+// notificationMaxAge is how far a notification's Request-Timestamp may be from now, so a
+// captured notification cannot be replayed later
+const notificationMaxAge = 5 * time.Minute
+
 type Client struct {
     baseURL    string
     clientID   string
     secretKey  string
     httpClient *http.Client
+    now        func() time.Time
 }
 
 func New(baseURL, clientID, secretKey string) *Client {
@@ -28,6 +35,7 @@ func New(baseURL, clientID, secretKey string) *Client {
         clientID:   clientID,
         secretKey:  secretKey,
         httpClient: &http.Client{Timeout: 15 * time.Second},
+        now:        time.Now,
     }
 }
 
@@ -74,25 +82,10 @@ func (c *Client) CreatePayment(ctx context.Context, amountIDR int64, invoiceNumb
     timestamp := time.Now().UTC().Format("2006-01-02T15:04:05Z")
     path := "/checkout/v1/payment"
 
-    // Digest
-    digestHash := sha256.Sum256(bodyBytes)
-    digest := "SHA-256=" + base64.StdEncoding.EncodeToString(digestHash[:])
-
-    // Signature base string (per Jokul docs)
-    baseString := strings.Join([]string{
-        "Client-Id:" + c.clientID,
-        "Request-Id:" + requestID,
-        "Request-Timestamp:" + timestamp,
-        "Request-Target:" + path,
-        "Digest:" + digest,
-    }, "\n")
-    mac := hmac.New(sha256.New, []byte(c.secretKey))
-    mac.Write([]byte(baseString))
-    signature := "HMACSHA256=" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+    signature := c.sign(requestID, timestamp, path, bodyBytes)
 
     // Request
-    url := c.baseURL + path
-    req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(bodyBytes))
     if err != nil {
         return "", "", 0, "", fmt.Errorf("doku: request build failed: %w", err)
     }
@@ -134,3 +127,85 @@ func (c *Client) CreatePayment(ctx context.Context, amountIDR int64, invoiceNumb
     return paymentURL, txid, amountIDR, currency, nil
 }
 
+// sign computes the Signature header of a request (per Jokul docs); the Digest line is left out for requests without a body
+func (c *Client) sign(requestID, timestamp, target string, body []byte) string {
+    lines := []string{
+        "Client-Id:" + c.clientID,
+        "Request-Id:" + requestID,
+        "Request-Timestamp:" + timestamp,
+        "Request-Target:" + target,
+    }
+    if len(body) > 0 {
+        digestHash := sha256.Sum256(body)
+        lines = append(lines, "Digest:SHA-256="+base64.StdEncoding.EncodeToString(digestHash[:]))
+    }
+    mac := hmac.New(sha256.New, []byte(c.secretKey))
+    mac.Write([]byte(strings.Join(lines, "\n")))
+    return "HMACSHA256=" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyNotification checks that a notification was signed by DOKU with our secret key and
+// recently. DOKU signs notifications like requests, over the path of our notification URL.
+func (c *Client) VerifyNotification(headers entities.DokuNotificationHeaders, body []byte) bool {
+    if c.secretKey == "" || headers.ClientID != c.clientID || headers.Signature == "" {
+        return false
+    }
+    sentAt, err := time.Parse(time.RFC3339, headers.RequestTimestamp)
+    if err != nil {
+        return false
+    }
+    if age := c.now().Sub(sentAt); age > notificationMaxAge || age < -notificationMaxAge {
+        return false
+    }
+    expected := c.sign(headers.RequestID, headers.RequestTimestamp, headers.RequestTarget, body)
+    return hmac.Equal([]byte(expected), []byte(headers.Signature))
+}
+
+type statusResponse struct {
+    Order struct {
+        InvoiceNumber string      `json:"invoice_number"`
+        Amount        json.Number `json:"amount"`
+        Currency      string      `json:"currency"`
+    } `json:"order"`
+    Transaction struct {
+        Status string `json:"status"`
+    } `json:"transaction"`
+}
+
+// GetPaymentStatus asks DOKU for the transaction status of an invoice (order status inquiry).
+// status is DOKU's, such as SUCCESS, PENDING, FAILED or EXPIRED.
+func (c *Client) GetPaymentStatus(ctx context.Context, invoiceNumber string) (string, int64, string, error) {
+    requestID := uuid.NewString()
+    timestamp := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+    path := "/orders/v1/status/" + url.PathEscape(invoiceNumber)
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+    if err != nil {
+        return "", 0, "", fmt.Errorf("doku: request build failed: %w", err)
+    }
+    req.Header.Set("Accept", "application/json")
+    req.Header.Set("Client-Id", c.clientID)
+    req.Header.Set("Request-Id", requestID)
+    req.Header.Set("Request-Timestamp", timestamp)
+    req.Header.Set("Signature", c.sign(requestID, timestamp, path, nil))
+
+    resp, err := c.httpClient.Do(req)
+    if err != nil {
+        return "", 0, "", fmt.Errorf("doku: request failed: %w", err)
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode >= 300 {
+        return "", 0, "", fmt.Errorf("doku: non-2xx status %d", resp.StatusCode)
+    }
+
+    var sr statusResponse
+    if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
+        return "", 0, "", fmt.Errorf("doku: decode response failed: %w", err)
+    }
+    amount, _ := sr.Order.Amount.Int64()
+    currency := sr.Order.Currency
+    if currency == "" {
+        currency = "IDR"
+    }
+    return sr.Transaction.Status, amount, currency, nil
+}
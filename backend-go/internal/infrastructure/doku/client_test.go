@@ -0,0 +1,57 @@
+package doku
+
+import (
+	"testing"
+	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+func TestVerifyNotification(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	c := New("https://api.doku.example", "client-1", "secret")
+	c.now = func() time.Time { return now }
+	body := []byte(`{"order":{"invoice_number":"INV-1","amount":150000,"currency":"IDR"},"transaction":{"status":"SUCCESS"}}`)
+	const target = "/v1/webhooks/doku"
+
+	headers := func(sentAt time.Time) entities.DokuNotificationHeaders {
+		ts := sentAt.Format("2006-01-02T15:04:05Z")
+		return entities.DokuNotificationHeaders{
+			ClientID:         "client-1",
+			RequestID:        "req-1",
+			RequestTimestamp: ts,
+			Signature:        c.sign("req-1", ts, target, body),
+			RequestTarget:    target,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		headers entities.DokuNotificationHeaders
+		body    []byte
+		want    bool
+	}{
+		{"fresh", headers(now.Add(-time.Minute)), body, true},
+		{"clock a little ahead", headers(now.Add(time.Minute)), body, true},
+		{"replayed later", headers(now.Add(-time.Hour)), body, false},
+		{"from the future", headers(now.Add(time.Hour)), body, false},
+		{"changed body", headers(now), []byte(`{"order":{"invoice_number":"INV-1","amount":1}}`), false},
+		{"other client", func() entities.DokuNotificationHeaders {
+			h := headers(now)
+			h.ClientID = "client-2"
+			return h
+		}(), body, false},
+		{"unreadable timestamp", func() entities.DokuNotificationHeaders {
+			h := headers(now)
+			h.RequestTimestamp = "yesterday"
+			return h
+		}(), body, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.VerifyNotification(tt.headers, tt.body); got != tt.want {
+				t.Errorf("VerifyNotification() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
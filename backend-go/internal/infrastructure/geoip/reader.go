@@ -0,0 +1,38 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// Reader resolves countries from a local MaxMind GeoLite2/GeoIP2 Country database
+type Reader struct {
+	db *geoip2.Reader
+}
+
+// Open loads the .mmdb database at path
+func Open(path string) (*Reader, error) {
+	db, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: open database failed: %w", err)
+	}
+	return &Reader{db: db}, nil
+}
+
+func (r *Reader) CountryCode(ip string) (string, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", nil
+	}
+	rec, err := r.db.Country(parsed)
+	if err != nil {
+		return "", fmt.Errorf("geoip: lookup failed: %w", err)
+	}
+	return rec.Country.IsoCode, nil
+}
+
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
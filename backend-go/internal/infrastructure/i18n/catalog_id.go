@@ -0,0 +1,138 @@
+package i18n
+
+import (
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+)
+
+var indonesianErrors = map[error]string{
+	services.ErrUserNotActive:                   "Pengguna tidak aktif",
+	services.ErrInvalidCredentials:              "Nama pengguna atau kata sandi salah",
+	services.ErrUserNotFound:                    "Pengguna tidak ditemukan",
+	services.ErrInvalidEmailNotVerified:         "Email belum diverifikasi",
+	services.ErrInvalidRefreshToken:             "Refresh token tidak valid",
+	services.ErrUserExists:                      "Pengguna sudah terdaftar",
+	services.ErrInvalidRole:                     "Peran harus salon_owner atau salon_employee",
+	services.ErrNoRolesProvided:                 "Peran wajib diisi",
+	services.ErrInvalidOAuthCode:                "Kode otorisasi tidak valid",
+	services.ErrOAuthUnauthorized:               "Autentikasi gagal",
+	services.ErrOrganizationNotFound:            "Organisasi tidak ditemukan",
+	services.ErrMemberNotFound:                  "Anggota organisasi tidak ditemukan",
+	services.ErrLastOwner:                       "Organisasi harus memiliki setidaknya satu pemilik",
+	services.ErrInvitationMismatch:              "Undangan dikirim ke akun lain",
+	services.ErrUserInvited:                     "Akun dengan email ini sudah ada dan telah diundang untuk bergabung",
+	services.ErrAccountNotManaged:               "Akun ini tidak dibuat oleh organisasi ini",
+	services.ErrImportJobNotFound:               "Tugas impor tidak ditemukan",
+	services.ErrInvalidSortField:                "sort_by harus created_at, email, full_name atau last_login_at",
+	services.ErrInvalidPageToken:                "page_token tidak valid",
+	services.ErrCannotDeleteSelf:                "Anda tidak dapat menghapus akun Anda sendiri",
+	services.ErrOutboundMessageNotFound:         "Pesan keluar tidak ditemukan",
+	services.ErrInvalidMessageStatus:            "Status harus pending, sending, sent atau dead",
+	services.ErrMessageNotDead:                  "Hanya pesan yang gagal terkirim yang dapat dicoba ulang",
+	services.ErrMessageContentCleared:           "Pesan sudah terkirim dan isinya telah dihapus, pesan tidak dapat dicoba ulang",
+	services.ErrMessageNotOnWhatsApp:            "Hanya pesan yang dikirim lewat WhatsApp yang memiliki status untuk diperbarui",
+	services.ErrWhatsAppSessionNotFound:         "Sesi WhatsApp tidak ditemukan",
+	services.ErrWhatsAppNotPairing:              "Sesi WhatsApp tidak sedang menunggu pemindaian kode QR",
+	services.ErrWhatsAppUnavailable:             "Gateway WhatsApp tidak tersedia",
+	services.ErrInvalidChannel:                  "Saluran pilihan harus whatsapp atau sms",
+	services.ErrTemplateNotFound:                "Template email tidak ditemukan",
+	services.ErrTemplateExists:                  "Template email sudah ada",
+	services.ErrInvalidTemplate:                 "Template email tidak valid",
+	services.ErrInvalidTemplateName:             "Nama hanya boleh berisi huruf kecil, angka, dan garis bawah",
+	services.ErrRecipientRequired:               "Penerima wajib diisi",
+	services.ErrInvalidLocale:                   "Bahasa tidak valid",
+	services.ErrEmailSuppressed:                 "Alamat email diblokir karena email sebelumnya gagal terkirim atau dilaporkan sebagai spam",
+	services.ErrSuppressionNotFound:             "Alamat email tidak diblokir",
+	services.ErrNoNotificationsSelected:         "ids atau all wajib diisi",
+	services.ErrInvalidPreference:               "Preferensi hanya dapat diatur untuk rsvp, reminder, dan marketing pada email, whatsapp, sms, atau in_app",
+	services.ErrInvalidUnsubscribeToken:         "Tautan berhenti berlangganan tidak valid",
+	services.ErrCampaignNotFound:                "Kampanye tidak ditemukan",
+	services.ErrInvalidCampaign:                 "Kampanye tidak valid",
+	services.ErrInvalidCampaignSchedule:         "Jadwal kampanye tidak valid",
+	services.ErrCampaignAudienceDenied:          "Hanya superuser yang dapat mengirim kampanye ke pengguna platform",
+	services.ErrCampaignRecipientDenied:         "Penerima kampanye harus anggota organisasi",
+	services.ErrCampaignTemplateDenied:          "Template bukan untuk kampanye",
+	services.ErrCampaignStatus:                  "Status kampanye tidak mengizinkan perubahan ini",
+	services.ErrInvalidWebhook:                  "Webhook tidak valid",
+	services.ErrWebhookEventNotFound:            "Event webhook tidak ditemukan",
+	services.ErrInvalidWebhookEventStatus:       "Status harus received, processed atau failed",
+	services.ErrPaymentProviderUnavailable:      "Penyedia pembayaran belum dikonfigurasi",
+	services.ErrPaymentNotFound:                 "Pembayaran tidak ditemukan",
+	services.ErrPaymentStatus:                   "Status pembayaran tidak mengizinkan perubahan ini",
+	services.ErrEmailInUse:                      "Email sudah digunakan",
+	services.ErrPhoneInUse:                      "Nomor telepon sudah digunakan",
+	services.ErrIdentityTaken:                   "Email atau nomor telepon kini digunakan oleh pengguna lain",
+	services.ErrEmailNotVerified:                "Email harus diverifikasi",
+	services.ErrPhoneNotVerified:                "Nomor telepon harus diverifikasi",
+	services.ErrInvalidPhoneNumber:              "Nomor telepon tidak valid",
+	services.ErrDeletedUserNotFound:             "Pengguna yang dihapus tidak ditemukan",
+	services.ErrInvalidToken:                    "Token tidak valid",
+	services.ErrWeakPassword:                    "Kata sandi minimal 8 karakter",
+	services.ErrInvalidPreviousPassword:         "Kata sandi sebelumnya salah",
+	services.ErrUnauthorized:                    "Tidak diizinkan",
+	services.ErrInvalidOrExpiredCode:            "Kode tidak valid atau sudah kedaluwarsa",
+	repositories.ErrTemplateVersionConflict:     "Template diubah bersamaan, silakan coba lagi",
+	repositories.ErrPaymentOperationUnsupported: "Penyedia pembayaran tidak mendukung operasi ini",
+}
+
+var indonesian = map[string]string{
+	"invalid token":                            "Token tidak valid",
+	"invalid id":                               "ID tidak valid",
+	"invalid user_id":                          "user_id tidak valid",
+	"invalid notification id":                  "ID notifikasi tidak valid",
+	"version is required":                      "Versi wajib diisi",
+	"sort_order must be asc or desc":           "sort_order harus asc atau desc",
+	"invalid amount or invoice number":         "Jumlah atau nomor invoice tidak valid",
+	"X-Org-Id header is required":              "Header X-Org-Id wajib diisi",
+	"invalid X-Org-Id header":                  "Header X-Org-Id tidak valid",
+	"authentication required":                  "Autentikasi diperlukan",
+	"insufficient permissions":                 "Izin tidak mencukupi",
+	"session revoked":                          "Sesi telah dicabut",
+	"email and otp_code are required":          "Email dan kode OTP wajib diisi",
+	"email is required":                        "Email wajib diisi",
+	"email or phone_number is required":        "Email atau nomor telepon wajib diisi",
+	"name is required":                         "Nama wajib diisi",
+	"not a member of this organization":        "Anda bukan anggota organisasi ini",
+	"otp_code is required":                     "Kode OTP wajib diisi",
+	"phone_number and full_name are required":  "Nomor telepon dan nama lengkap wajib diisi",
+	"phone_number and otp_code are required":   "Nomor telepon dan kode OTP wajib diisi",
+	"phone_number is required":                 "Nomor telepon wajib diisi",
+	"region is required":                       "Wilayah wajib diisi",
+	"token and new_password are required":      "Token dan kata sandi baru wajib diisi",
+	"token is required":                        "Token wajib diisi",
+	"unsupported provider":                     "Penyedia tidak didukung",
+	"user not found or inactive":               "Pengguna tidak ditemukan atau tidak aktif",
+	"username and password are required":       "Nama pengguna dan kata sandi wajib diisi",
+	"verification_token is required":           "Token verifikasi wajib diisi",
+	"failed to accept invitation":              "Gagal menerima undangan",
+	"failed to add email":                      "Gagal menambahkan email",
+	"failed to add phone number":               "Gagal menambahkan nomor telepon",
+	"failed to create organization":            "Gagal membuat organisasi",
+	"failed to generate otp":                   "Gagal membuat OTP",
+	"failed to get user":                       "Gagal mengambil data pengguna",
+	"failed to invite member":                  "Gagal mengundang anggota",
+	"failed to list members":                   "Gagal mengambil daftar anggota",
+	"failed to list organizations":             "Gagal mengambil daftar organisasi",
+	"failed to login user":                     "Gagal masuk",
+	"failed to login with phone":               "Gagal masuk dengan nomor telepon",
+	"failed to refresh token":                  "Gagal memperbarui token",
+	"failed to register user":                  "Gagal mendaftarkan pengguna",
+	"failed to remove member":                  "Gagal menghapus anggota",
+	"failed to reset password":                 "Gagal mengatur ulang kata sandi",
+	"failed to secure account":                 "Gagal mengamankan akun",
+	"failed to send recovery email":            "Gagal mengirim email pemulihan",
+	"failed to send verification email":        "Gagal mengirim email verifikasi",
+	"failed to update locale":                  "Gagal memperbarui bahasa",
+	"failed to update preferred channel":       "Gagal memperbarui saluran pilihan",
+	"failed to update user":                    "Gagal memperbarui pengguna",
+	"failed to verify email":                   "Gagal memverifikasi email",
+	"failed to verify phone":                   "Gagal memverifikasi nomor telepon",
+	"failed to verify registration":            "Gagal memverifikasi pendaftaran",
+	"failed to generate OAuth URL":             "Gagal membuat URL OAuth",
+	"failed to handle OAuth callback":          "Gagal memproses callback OAuth",
+	"transaction_id and provider are required": "ID transaksi dan penyedia pembayaran wajib diisi",
+	"failed to create checkout session":        "Gagal membuat sesi pembayaran",
+	"failed to create doku payment":            "Gagal membuat pembayaran DOKU",
+	"failed to refresh payment status":         "Gagal memperbarui status pembayaran",
+	"failed to cancel payment":                 "Gagal membatalkan pembayaran",
+}
@@ -0,0 +1,112 @@
+package i18n
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorCatalogs maps a locale to translations of the domain errors returned to clients, keyed on
+// their sentinel errors so a reworded message keeps its translation
+var errorCatalogs = map[string]map[error]string{
+	"id": indonesianErrors,
+}
+
+// messageCatalogs maps a locale to translations of the messages the servers write themselves,
+// such as request validation and authentication failures, which have no sentinel error.
+// Messages missing from both catalogs are returned in English.
+var messageCatalogs = map[string]map[string]string{
+	"id": indonesian,
+}
+
+// statusError is a status error that keeps the error it reports, so it can be translated
+type statusError struct {
+	st  *status.Status
+	err error
+}
+
+// Error returns a status error with code c and the message of err, to be translated by the
+// sentinel errors err is or wraps
+func Error(c codes.Code, err error) error {
+	return &statusError{st: status.New(c, err.Error()), err: err}
+}
+
+func (e *statusError) Error() string              { return e.st.Err().Error() }
+func (e *statusError) GRPCStatus() *status.Status { return e.st }
+func (e *statusError) Unwrap() error              { return e.err }
+
+// Translate returns the message of the status error err in the first locale of the fallback chain
+// of locale that translates it. Errors made by Error are looked up by the sentinel errors they wrap,
+// innermost last, and any detail added around the sentinel's message is kept as it is.
+func Translate(locale string, err error) string {
+	st, _ := status.FromError(err)
+	msg := st.Message()
+	var se *statusError
+	errors.As(err, &se)
+	for _, l := range util.LocaleFallbacks(locale) {
+		if se != nil {
+			for e := se.err; e != nil; e = errors.Unwrap(e) {
+				for sentinel, translated := range errorCatalogs[l] {
+					if e == sentinel {
+						return strings.Replace(msg, sentinel.Error(), translated, 1)
+					}
+				}
+			}
+		}
+		if translated, ok := messageCatalogs[l][msg]; ok {
+			return translated
+		}
+	}
+	return msg
+}
+
+// localeKey carries a *callerLocale from GRPCErrorInterceptor to GRPCUserLocaleInterceptor
+type localeKey struct{}
+
+// callerLocale is the locale the authenticated caller stored on their account
+type callerLocale struct {
+	locale string
+}
+
+// GRPCErrorInterceptor translates the message of returned status errors into the locale the caller
+// stored on their account or, without one, the language of the request's Accept-Language header.
+// Chain it after GRPCClientInfoInterceptor and before the auth interceptor so authentication errors
+// are translated too, and GRPCUserLocaleInterceptor after the auth interceptor.
+func GRPCErrorInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	caller := &callerLocale{}
+	resp, err := handler(context.WithValue(ctx, localeKey{}, caller), req)
+	if err == nil {
+		return resp, nil
+	}
+	locale := cmp.Or(caller.locale, util.ClientInfoFromContext(ctx).Locale)
+	if locale == "" {
+		return resp, err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return resp, err
+	}
+	translated := Translate(locale, err)
+	if translated == st.Message() {
+		return resp, err
+	}
+	p := st.Proto()
+	p.Message = translated
+	return resp, status.ErrorProto(p)
+}
+
+// GRPCUserLocaleInterceptor passes the locale of the authenticated caller out to GRPCErrorInterceptor
+func GRPCUserLocaleInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if caller, ok := ctx.Value(localeKey{}).(*callerLocale); ok {
+		if user := util.UserFromContext(ctx); user != nil {
+			caller.locale = user.Locale
+		}
+	}
+	return handler(ctx, req)
+}
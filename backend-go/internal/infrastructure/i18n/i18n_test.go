@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/services"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		err    error
+		want   string
+	}{
+		{"sentinel", "id-ID", Error(codes.NotFound, services.ErrUserNotFound), "Pengguna tidak ditemukan"},
+		{"wrapped sentinel keeps its detail", "id", Error(codes.InvalidArgument, fmt.Errorf("%w: body uses unknown field {{.Code}}", services.ErrInvalidTemplate)), "Template email tidak valid: body uses unknown field {{.Code}}"},
+		{"message without a sentinel", "id", status.Error(codes.InvalidArgument, "email is required"), "Email wajib diisi"},
+		// Looking errors up by sentinel, a plain status error with the same text is not translated
+		{"text of a sentinel", "id", status.Error(codes.NotFound, services.ErrUserNotFound.Error()), "user not found"},
+		{"untranslated locale", "fr", Error(codes.NotFound, services.ErrUserNotFound), "user not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Translate(tt.locale, tt.err); got != tt.want {
+				t.Errorf("Translate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGRPCErrorInterceptorPrefersStoredLocale(t *testing.T) {
+	failing := func(ctx context.Context, req any) (any, error) {
+		return nil, Error(codes.NotFound, services.ErrUserNotFound)
+	}
+	// The auth interceptor stores the caller in context before the locale interceptor runs
+	authenticated := func(user *entities.User) grpc.UnaryHandler {
+		return func(ctx context.Context, req any) (any, error) {
+			if user != nil {
+				ctx = util.WithUser(ctx, user)
+			}
+			return GRPCUserLocaleInterceptor(ctx, req, nil, failing)
+		}
+	}
+	tests := []struct {
+		name   string
+		header string
+		user   *entities.User
+		want   string
+	}{
+		{"accept-language", "id", nil, "Pengguna tidak ditemukan"},
+		{"stored locale over accept-language", "id", &entities.User{Locale: "en"}, "user not found"},
+		{"stored locale without accept-language", "", &entities.User{Locale: "id-ID"}, "Pengguna tidak ditemukan"},
+		{"no stored locale", "id", &entities.User{}, "Pengguna tidak ditemukan"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := util.WithClientInfo(context.Background(), entities.ClientInfo{Locale: tt.header})
+			_, err := GRPCErrorInterceptor(ctx, nil, nil, authenticated(tt.user))
+			st, _ := status.FromError(err)
+			if st.Code() != codes.NotFound || st.Message() != tt.want {
+				t.Errorf("error = %s %q, want NotFound %q", st.Code(), st.Message(), tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -28,6 +29,12 @@ func NewJWTService(signer Signer, accessTokenExp, refreshTokenExp time.Duration,
 	}
 }
 
+// issuedAt is the iat claim in milliseconds, so a token minted in the second sessions were revoked
+// can still be told apart from one minted right after
+func issuedAt(t time.Time) float64 {
+	return float64(t.UnixMilli()) / 1000
+}
+
 // GenerateToken creates a new JWT access token
 func (j *jwtService) GenerateToken(userID uuid.UUID, email string, roles []string) (*entities.TokenResult, error) {
 	now := time.Now()
@@ -38,7 +45,7 @@ func (j *jwtService) GenerateToken(userID uuid.UUID, email string, roles []strin
 		"email":   email,
 		"roles":   roles,
 		"exp":     expiresAt.Unix(),
-		"iat":     now.Unix(),
+		"iat":     issuedAt(now),
 		"iss":     j.issuer,
 		"type":    "access",
 	}
@@ -58,7 +65,7 @@ func (j *jwtService) GenerateRefreshToken(userID uuid.UUID) (*entities.TokenResu
 	claims := jwt.MapClaims{
 		"user_id": userID.String(),
 		"exp":     expiresAt.Unix(),
-		"iat":     now.Unix(),
+		"iat":     issuedAt(now),
 		"iss":     j.issuer,
 		"type":    "refresh",
 	}
@@ -111,7 +118,7 @@ func (j *jwtService) ValidateToken(tokenString string) (*entities.TokenClaims, e
 		Email:     email,
 		Roles:     roles,
 		ExpiresAt: int64(exp),
-		IssuedAt:  int64(iat),
+		IssuedAt:  time.UnixMilli(int64(math.Round(iat * 1000))),
 		Type:      tokenType,
 	}, nil
 }
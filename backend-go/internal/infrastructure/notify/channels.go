@@ -0,0 +1,176 @@
+// Package notify adapts the SMTP, WAHA and SMS clients to repositories.NotificationChannel.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+// wahaStatusTTL bounds how often the WAHA session status is fetched while delivering a batch
+const wahaStatusTTL = 30 * time.Second
+
+type emailChannel struct {
+	sender repositories.Sender
+}
+
+func NewEmailChannel(sender repositories.Sender) repositories.NotificationChannel {
+	return &emailChannel{sender: sender}
+}
+
+func (c *emailChannel) Send(_ context.Context, msg *entities.OutboundMessage) error {
+	m := entities.Message{To: msg.Recipient, Subject: msg.Subject, Body: msg.Body, UnsubscribeURL: msg.UnsubscribeURL}
+	if e := msg.Email; e != nil {
+		m.Cc, m.Bcc, m.ReplyTo = e.Cc, e.Bcc, e.ReplyTo
+		m.TextBody, m.Headers, m.Attachments = e.TextBody, e.Headers, e.Attachments
+	}
+	return c.sender.Send(m)
+}
+
+func (c *emailChannel) Ready(context.Context) bool { return true }
+
+type whatsAppChannel struct {
+	client      repositories.WahaClient
+	sessionRepo repositories.WhatsAppSessionRepository
+	// spacing is the least time between two messages from one session, zero for no limit
+	spacing time.Duration
+
+	mu        sync.Mutex
+	ready     bool
+	checkedAt time.Time
+}
+
+// NewWhatsAppChannel sends at most ratePerMinute messages a minute from each session, or any number when it is zero
+func NewWhatsAppChannel(client repositories.WahaClient, sessionRepo repositories.WhatsAppSessionRepository, ratePerMinute int) repositories.NotificationChannel {
+	c := &whatsAppChannel{client: client, sessionRepo: sessionRepo}
+	if ratePerMinute > 0 {
+		c.spacing = time.Minute / time.Duration(ratePerMinute)
+	}
+	return c
+}
+
+// Send delivers the body together with the message's WhatsApp content. Buttons and lists carry
+// the body, with an image as the buttons' header; other media carries it as a caption, and a
+// location follows as a message of its own. ExternalID is the message that carries the body,
+// which is the one recipients reply to. Once the body is out, a failed location is only logged
+// so a retry does not send the whole message twice. A session that sent too recently returns a
+// RateLimitedError.
+func (c *whatsAppChannel) Send(ctx context.Context, msg *entities.OutboundMessage) error {
+	content := msg.WhatsApp
+	if content == nil {
+		content = &entities.WhatsAppContent{}
+	}
+	to := msg.Recipient
+	client, err := c.sender(ctx, msg)
+	if err != nil {
+		return err
+	}
+	msg.Sender = client.SessionName()
+	if c.spacing > 0 {
+		wait, err := c.sessionRepo.TakeSendSlot(ctx, msg.Sender, c.spacing)
+		if err != nil {
+			return fmt.Errorf("failed to take whatsapp send slot: %w", err)
+		}
+		if wait > 0 {
+			return &repositories.RateLimitedError{RetryAfter: wait}
+		}
+	}
+
+	var id string
+	switch {
+	case len(content.Buttons) > 0:
+		var header *entities.WhatsAppMedia
+		if content.Media != nil && content.Media.Kind == entities.WhatsAppMediaImage {
+			header = content.Media
+		} else if err = sendMedia(ctx, client, to, content.Media, ""); err != nil {
+			return err
+		}
+		id, err = client.SendButtons(ctx, to, msg.Body, content.Footer, content.Buttons, header)
+	case content.List != nil:
+		if err = sendMedia(ctx, client, to, content.Media, ""); err != nil {
+			return err
+		}
+		id, err = client.SendList(ctx, to, msg.Body, content.Footer, *content.List)
+	case content.Media != nil:
+		id, err = sendMediaWithID(ctx, client, to, *content.Media, msg.Body)
+	default:
+		id, err = client.SendText(ctx, to, msg.Body)
+	}
+	if err != nil {
+		return err
+	}
+	msg.ExternalID = id
+
+	if content.Location != nil {
+		if _, err := client.SendLocation(ctx, to, *content.Location); err != nil {
+			log.Println(fmt.Errorf("failed to send WhatsApp location of message %s: %w", msg.ID, err))
+		}
+	}
+	return nil
+}
+
+func sendMedia(ctx context.Context, client repositories.WahaClient, to string, media *entities.WhatsAppMedia, caption string) error {
+	if media == nil {
+		return nil
+	}
+	_, err := sendMediaWithID(ctx, client, to, *media, caption)
+	return err
+}
+
+func sendMediaWithID(ctx context.Context, client repositories.WahaClient, to string, media entities.WhatsAppMedia, caption string) (string, error) {
+	if media.Kind == entities.WhatsAppMediaImage {
+		return client.SendImage(ctx, to, media, caption)
+	}
+	return client.SendFile(ctx, to, media, caption)
+}
+
+// sender picks the session of the salon the message is sent for while it is WORKING, and the
+// platform session otherwise, so a salon whose phone is logged out still reaches its customers
+func (c *whatsAppChannel) sender(ctx context.Context, msg *entities.OutboundMessage) (repositories.WahaClient, error) {
+	if msg.OrganizationID == nil {
+		return c.client, nil
+	}
+	session, err := c.sessionRepo.GetByOrganization(ctx, *msg.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get whatsapp session of organization: %w", err)
+	}
+	if session == nil || session.Status != entities.WhatsAppSessionWorking {
+		return c.client, nil
+	}
+	return c.client.Session(session.Name), nil
+}
+
+// Ready is false while the platform WAHA session is not WORKING, e.g. when the phone was logged out
+func (c *whatsAppChannel) Ready(ctx context.Context) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.checkedAt) < wahaStatusTTL {
+		return c.ready
+	}
+	status, err := c.client.SessionStatus(ctx)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to get WAHA session status: %w", err))
+	}
+	c.ready = err == nil && status == entities.WhatsAppSessionWorking
+	c.checkedAt = time.Now()
+	return c.ready
+}
+
+type smsChannel struct {
+	client repositories.SMSClient
+}
+
+func NewSMSChannel(client repositories.SMSClient) repositories.NotificationChannel {
+	return &smsChannel{client: client}
+}
+
+func (c *smsChannel) Send(ctx context.Context, msg *entities.OutboundMessage) error {
+	return c.client.SendSMS(ctx, msg.Recipient, msg.Body)
+}
+
+func (c *smsChannel) Ready(context.Context) bool { return true }
@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/waha"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/waha/wahatest"
+)
+
+type fakeSender struct {
+	sent []entities.Message
+}
+
+func (s *fakeSender) Send(msg entities.Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestEmailChannelSendsQueuedContent(t *testing.T) {
+	sender := &fakeSender{}
+	content := &entities.EmailContent{
+		Cc:          []string{"owner@example.com"},
+		Bcc:         []string{"audit@example.com"},
+		ReplyTo:     "support@salon.example",
+		TextBody:    "Your receipt is attached",
+		Headers:     map[string]string{"X-Entity-Ref-ID": "payment-1"},
+		Attachments: []entities.Attachment{{Filename: "receipt.pdf", ContentType: "application/pdf", Data: []byte("%PDF")}},
+	}
+	err := NewEmailChannel(sender).Send(context.Background(), &entities.OutboundMessage{
+		Channel:        entities.MessageChannelEmail,
+		Recipient:      "budi@example.com",
+		Subject:        "Payment received",
+		Body:           "<p>Your receipt is attached</p>",
+		Email:          content,
+		UnsubscribeURL: "https://salon.example/v1/unsubscribe?token=t",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := entities.Message{
+		To:             "budi@example.com",
+		Cc:             content.Cc,
+		Bcc:            content.Bcc,
+		ReplyTo:        content.ReplyTo,
+		Subject:        "Payment received",
+		Body:           "<p>Your receipt is attached</p>",
+		TextBody:       content.TextBody,
+		Headers:        content.Headers,
+		Attachments:    content.Attachments,
+		UnsubscribeURL: "https://salon.example/v1/unsubscribe?token=t",
+	}
+	if len(sender.sent) != 1 || !reflect.DeepEqual(sender.sent[0], want) {
+		t.Errorf("sent %+v, want %+v", sender.sent, want)
+	}
+}
+
+// fakeSessions holds the sessions of salons and hands out send slots while wait is zero
+type fakeSessions struct {
+	repositories.WhatsAppSessionRepository
+	byOrg map[uuid.UUID]*entities.WhatsAppSession
+	wait  time.Duration
+	slots []string
+}
+
+func (r *fakeSessions) GetByOrganization(_ context.Context, orgID uuid.UUID) (*entities.WhatsAppSession, error) {
+	return r.byOrg[orgID], nil
+}
+
+func (r *fakeSessions) TakeSendSlot(_ context.Context, session string, _ time.Duration) (time.Duration, error) {
+	r.slots = append(r.slots, session)
+	return r.wait, nil
+}
+
+func TestWhatsAppChannelSendsFromTheSalonSession(t *testing.T) {
+	srv, fake := wahatest.NewServer("key", "default", "salon-working")
+	defer srv.Close()
+
+	working, pairing, none := uuid.New(), uuid.New(), uuid.New()
+	sessions := &fakeSessions{byOrg: map[uuid.UUID]*entities.WhatsAppSession{
+		working: {Name: "salon-working", Status: entities.WhatsAppSessionWorking, OrganizationID: &working},
+		pairing: {Name: "salon-pairing", Status: entities.WhatsAppSessionScanQRCode, OrganizationID: &pairing},
+	}}
+	channel := NewWhatsAppChannel(waha.New(srv.URL, "key", "default", waha.Webhook{}), sessions, 60)
+
+	tests := []struct {
+		name   string
+		orgID  *uuid.UUID
+		sender string
+	}{
+		{"platform message", nil, "default"},
+		{"salon with a working session", &working, "salon-working"},
+		// The salon's phone is not paired, so its customers hear from the platform number
+		{"salon still pairing", &pairing, "default"},
+		{"salon without a session", &none, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(fake.Messages())
+			msg := &entities.OutboundMessage{
+				ID:             uuid.New(),
+				Channel:        entities.MessageChannelWhatsApp,
+				Recipient:      "+6281234567890",
+				Body:           "See you tomorrow",
+				OrganizationID: tt.orgID,
+			}
+			if err := channel.Send(context.Background(), msg); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			sent := fake.Messages()
+			if len(sent) != before+1 {
+				t.Fatalf("%d messages sent, want 1", len(sent)-before)
+			}
+			if got := sent[len(sent)-1]; got.Session != tt.sender || got.ID != msg.ExternalID {
+				t.Errorf("sent %s from %s, want %s from %s", got.ID, got.Session, msg.ExternalID, tt.sender)
+			}
+			if msg.Sender != tt.sender {
+				t.Errorf("Sender = %q, want %q", msg.Sender, tt.sender)
+			}
+			if last := sessions.slots[len(sessions.slots)-1]; last != tt.sender {
+				t.Errorf("send slot taken for %s, want %s", last, tt.sender)
+			}
+		})
+	}
+}
+
+func TestWhatsAppChannelRateLimitsPerSession(t *testing.T) {
+	srv, fake := wahatest.NewServer("key", "default")
+	defer srv.Close()
+	sessions := &fakeSessions{wait: 20 * time.Second}
+	channel := NewWhatsAppChannel(waha.New(srv.URL, "key", "default", waha.Webhook{}), sessions, 3)
+
+	err := channel.Send(context.Background(), &entities.OutboundMessage{Channel: entities.MessageChannelWhatsApp, Recipient: "+6281234567890", Body: "hi"})
+	var limited *repositories.RateLimitedError
+	if !errors.As(err, &limited) || limited.RetryAfter != 20*time.Second {
+		t.Fatalf("Send = %v, want a RateLimitedError after 20s", err)
+	}
+	if n := len(fake.Messages()); n != 0 {
+		t.Errorf("%d messages sent while rate limited", n)
+	}
+}
+
+func TestWhatsAppChannelReadyFollowsPlatformSession(t *testing.T) {
+	srv, fake := wahatest.NewServer("key", "default")
+	defer srv.Close()
+	client := waha.New(srv.URL, "key", "default", waha.Webhook{})
+
+	if !NewWhatsAppChannel(client, &fakeSessions{}, 0).Ready(context.Background()) {
+		t.Error("not ready while the platform session is WORKING")
+	}
+	fake.SetStatus("default", entities.WhatsAppSessionScanQRCode)
+	// Status is cached per channel, so a new channel sees the change right away
+	if NewWhatsAppChannel(client, &fakeSessions{}, 0).Ready(context.Background()) {
+		t.Error("ready while the platform session waits for a QR code scan")
+	}
+}
@@ -0,0 +1,198 @@
+package payment
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+)
+
+// DOKU signs notifications over these headers and the body
+const (
+	dokuClientIDHeader         = "Client-Id"
+	dokuRequestIDHeader        = "Request-Id"
+	dokuRequestTimestampHeader = "Request-Timestamp"
+	dokuSignatureHeader        = "Signature"
+)
+
+type dokuGateway struct {
+	client repositories.DokuClient
+}
+
+// NewDokuGateway takes IDR payments through Jokul Checkout
+func NewDokuGateway(client repositories.DokuClient) repositories.PaymentGateway {
+	return &dokuGateway{client: client}
+}
+
+func (g *dokuGateway) CreateCheckout(ctx context.Context, req entities.CheckoutRequest) (*entities.Checkout, error) {
+	url, txid, amt, currency, err := g.client.CreatePayment(ctx, req.Amount, req.Reference, int(req.ExpiresIn/time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	return &entities.Checkout{
+		URL:           url,
+		TransactionID: txid,
+		Amount:        float64(amt), // IDR has no decimals
+		Currency:      currency,
+		Metadata:      map[string]any{"payment_url": url, "invoice_number": req.Reference},
+	}, nil
+}
+
+func (g *dokuGateway) GetPaymentStatus(ctx context.Context, p *entities.Payment) (*entities.PaymentUpdate, error) {
+	invoiceNumber, _ := p.ExtraMetadata["invoice_number"].(string)
+	if invoiceNumber == "" {
+		invoiceNumber = p.TransactionID
+	}
+	raw, amt, currency, err := g.client.GetPaymentStatus(ctx, invoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+	amount := float64(amt) // IDR has no decimals
+	return &entities.PaymentUpdate{
+		TransactionID:  p.TransactionID,
+		Status:         dokuPaymentStatus(entities.PaymentStatus(strings.ToLower(raw))),
+		Amount:         &amount,
+		Currency:       &currency,
+		AmountRequired: true,
+		Metadata:       map[string]any{"provider": entities.PaymentProviderDoku},
+	}, nil
+}
+
+// VerifyWebhook checks a notification's signature. DOKU gives notifications no id, so retries of one
+// are recognized by their identical body.
+func (g *dokuGateway) VerifyWebhook(_ context.Context, req entities.WebhookRequest) (*entities.WebhookEvent, error) {
+	header := http.Header(req.Header)
+	ok := g.client.VerifyNotification(entities.DokuNotificationHeaders{
+		ClientID:         header.Get(dokuClientIDHeader),
+		RequestID:        header.Get(dokuRequestIDHeader),
+		RequestTimestamp: header.Get(dokuRequestTimestampHeader),
+		Signature:        header.Get(dokuSignatureHeader),
+		RequestTarget:    req.Path,
+	}, req.Payload)
+	if !ok {
+		return nil, errors.New("invalid doku signature")
+	}
+	n, err := parseDokuNotification(req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(req.Payload)
+	return &entities.WebhookEvent{
+		Provider:  entities.PaymentProviderDoku,
+		EventID:   hex.EncodeToString(sum[:]),
+		EventType: string(n.Status),
+		Payload:   req.Payload,
+	}, nil
+}
+
+func (g *dokuGateway) ParseWebhook(_ context.Context, e *entities.WebhookEvent) (*entities.WebhookUpdate, error) {
+	n, err := parseDokuNotification(e.Payload)
+	if err != nil {
+		return nil, err
+	}
+	// DOKU reports the order's amount and currency in every notification; one without them is
+	// not trusted to update a payment
+	u := &entities.PaymentUpdate{
+		TransactionID:  n.TransactionID(),
+		Status:         dokuPaymentStatus(n.Status),
+		AmountRequired: true,
+		Metadata:       map[string]any{"provider": entities.PaymentProviderDoku},
+	}
+	if n.Amount != "" {
+		// DOKU sends amount as string, convert to float
+		var a float64
+		if _, err := fmt.Sscanf(n.Amount, "%f", &a); err == nil {
+			u.Amount = &a
+		}
+	}
+	if n.Currency != "" {
+		u.Currency = &n.Currency
+	}
+	return &entities.WebhookUpdate{Payment: u}, nil
+}
+
+// Refund is not offered by Jokul Checkout; DOKU payments are refunded from the DOKU back office
+func (g *dokuGateway) Refund(context.Context, *entities.Payment) error {
+	return repositories.ErrPaymentOperationUnsupported
+}
+
+// Cancel is not offered by Jokul Checkout; an unpaid payment expires at its due date
+func (g *dokuGateway) Cancel(context.Context, *entities.Payment) error {
+	return repositories.ErrPaymentOperationUnsupported
+}
+
+// dokuNotification is the part of a DOKU payment notification that updates a payment
+type dokuNotification struct {
+	InvoiceNumber string
+	SessionID     string
+	Currency      string
+	Amount        string
+	// Status is DOKU's in lower case, such as success, pending, failed or expired
+	Status entities.PaymentStatus
+}
+
+// TransactionID is the id the payment was recorded under
+func (n *dokuNotification) TransactionID() string {
+	if n.SessionID != "" {
+		return n.SessionID
+	}
+	return n.InvoiceNumber
+}
+
+func parseDokuNotification(payload []byte) (*dokuNotification, error) {
+	var p struct {
+		Response struct {
+			Order struct {
+				InvoiceNumber string `json:"invoice_number"`
+				SessionID     string `json:"session_id"`
+				Currency      string `json:"currency"`
+			} `json:"order"`
+		} `json:"response"`
+		Order struct {
+			InvoiceNumber string      `json:"invoice_number"`
+			Amount        json.Number `json:"amount"`
+			Currency      string      `json:"currency"`
+		} `json:"order"`
+		Transaction struct {
+			Status string `json:"status"`
+		} `json:"transaction"`
+		Status    string      `json:"status"`
+		Currency  string      `json:"currency"`
+		Amount    json.Number `json:"amount"`
+		SessionID string      `json:"session_id"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, err
+	}
+	n := &dokuNotification{
+		InvoiceNumber: cmp.Or(p.Response.Order.InvoiceNumber, p.Order.InvoiceNumber),
+		SessionID:     cmp.Or(p.Response.Order.SessionID, p.SessionID),
+		Currency:      cmp.Or(p.Response.Order.Currency, p.Order.Currency, p.Currency),
+		Amount:        cmp.Or(p.Order.Amount, p.Amount).String(),
+		Status:        entities.PaymentStatus(strings.ToLower(cmp.Or(p.Transaction.Status, p.Status))),
+	}
+	if n.TransactionID() == "" {
+		return nil, errors.New("missing transaction identifier")
+	}
+	return n, nil
+}
+
+// dokuPaymentStatus maps a DOKU transaction status, in lower case, to the payment's
+func dokuPaymentStatus(status entities.PaymentStatus) entities.PaymentStatus {
+	switch status {
+	case entities.PaymentStatusSuccess, entities.PaymentStatusPaid, entities.PaymentStatusCompleted:
+		return entities.PaymentStatusPaid
+	case entities.PaymentStatusFailed, entities.PaymentStatusExpired:
+		return entities.PaymentStatusFailed
+	}
+	return entities.PaymentStatusPending
+}
@@ -0,0 +1,228 @@
+// Package payment adapts the Stripe and DOKU clients to repositories.PaymentGateway.
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v78"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
+	stripeinfra "github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/stripe"
+)
+
+const stripeSignatureHeader = "Stripe-Signature"
+
+type stripeGateway struct {
+	client        *stripeinfra.Client
+	priceID       string
+	webhookSecret string
+}
+
+// NewStripeGateway sells the subscription of priceID and verifies webhooks with webhookSecret
+func NewStripeGateway(client *stripeinfra.Client, priceID, webhookSecret string) repositories.PaymentGateway {
+	return &stripeGateway{client: client, priceID: priceID, webhookSecret: webhookSecret}
+}
+
+func (g *stripeGateway) CreateCheckout(_ context.Context, req entities.CheckoutRequest) (*entities.Checkout, error) {
+	s, err := g.client.CreateCheckoutSession(g.priceID, req.SuccessURL, req.CancelURL, req.Reference, map[string]string{"user_id": req.UserID.String()})
+	if err != nil {
+		return nil, err
+	}
+	// Get price info for amount/currency
+	pr, err := g.client.GetPrice(g.priceID)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.Checkout{
+		URL:           s.URL,
+		TransactionID: s.ID,
+		Amount:        float64(pr.UnitAmount) / 100.0,
+		Currency:      string(pr.Currency),
+		Metadata:      map[string]any{"checkout_url": s.URL},
+	}, nil
+}
+
+func (g *stripeGateway) GetPaymentStatus(_ context.Context, p *entities.Payment) (*entities.PaymentUpdate, error) {
+	sess, err := g.client.GetCheckoutSession(p.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	u := &entities.PaymentUpdate{
+		TransactionID: sess.ID,
+		Status:        entities.PaymentStatusPending,
+		Metadata:      map[string]any{"provider": entities.PaymentProviderStripe},
+	}
+	switch sess.Status {
+	case stripe.CheckoutSessionStatusComplete:
+		u.Status = entities.PaymentStatusPaid
+	case stripe.CheckoutSessionStatusExpired:
+		u.Status = entities.PaymentStatusFailed
+		u.Metadata["reason"] = entities.PaymentStatusExpired
+	}
+	return u, nil
+}
+
+func (g *stripeGateway) VerifyWebhook(_ context.Context, req entities.WebhookRequest) (*entities.WebhookEvent, error) {
+	evt, err := g.client.ConstructEvent(req.Payload, http.Header(req.Header).Get(stripeSignatureHeader), g.webhookSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.WebhookEvent{
+		Provider:  entities.PaymentProviderStripe,
+		EventID:   evt.ID,
+		EventType: string(evt.Type),
+		Payload:   req.Payload,
+	}, nil
+}
+
+func (g *stripeGateway) ParseWebhook(_ context.Context, e *entities.WebhookEvent) (*entities.WebhookUpdate, error) {
+	var evt stripe.Event
+	if err := json.Unmarshal(e.Payload, &evt); err != nil {
+		return nil, err
+	}
+	u := &entities.WebhookUpdate{}
+	switch evt.Type {
+	case stripe.EventTypeCheckoutSessionCompleted:
+		var s stripe.CheckoutSession
+		if err := json.Unmarshal(evt.Data.Raw, &s); err != nil {
+			return nil, err
+		}
+		metadata := map[string]any{}
+		if s.Customer != nil {
+			metadata["customer"] = s.Customer.ID
+		}
+		u.Payment = &entities.PaymentUpdate{TransactionID: s.ID, Status: entities.PaymentStatusPaid, Metadata: metadata}
+		if s.Subscription != nil {
+			sub, err := g.getSubscription(s.Subscription.ID, s.Metadata["user_id"])
+			if err != nil {
+				return nil, err
+			}
+			u.Subscription = sub
+		}
+	case stripe.EventTypeCheckoutSessionExpired:
+		var s stripe.CheckoutSession
+		if err := json.Unmarshal(evt.Data.Raw, &s); err != nil {
+			return nil, err
+		}
+		u.Payment = &entities.PaymentUpdate{
+			TransactionID: s.ID,
+			Status:        entities.PaymentStatusFailed,
+			Metadata:      map[string]any{"reason": entities.PaymentStatusExpired},
+		}
+	case stripe.EventTypeCustomerSubscriptionCreated,
+		stripe.EventTypeCustomerSubscriptionUpdated,
+		stripe.EventTypeCustomerSubscriptionDeleted,
+		stripe.EventTypeCustomerSubscriptionTrialWillEnd:
+		var s stripe.Subscription
+		if err := json.Unmarshal(evt.Data.Raw, &s); err != nil {
+			return nil, err
+		}
+		sub, err := g.getSubscription(s.ID, "")
+		if err != nil {
+			return nil, err
+		}
+		u.Subscription = sub
+		u.TrialEnding = evt.Type == stripe.EventTypeCustomerSubscriptionTrialWillEnd && sub.TrialEnd != nil
+	case stripe.EventTypeInvoicePaid, stripe.EventTypeInvoicePaymentFailed:
+		var inv stripe.Invoice
+		if err := json.Unmarshal(evt.Data.Raw, &inv); err != nil {
+			return nil, err
+		}
+		// One-off invoices have no subscription to update
+		if inv.Subscription == nil {
+			return u, nil
+		}
+		sub, err := g.getSubscription(inv.Subscription.ID, "")
+		if err != nil {
+			return nil, err
+		}
+		u.Subscription = sub
+	}
+	return u, nil
+}
+
+// getSubscription fetches the current state of a subscription rather than taking it from the event,
+// which may be older than one already applied. Its user comes from the metadata set at checkout,
+// then userIDHint.
+func (g *stripeGateway) getSubscription(id, userIDHint string) (*entities.ProviderSubscription, error) {
+	s, err := g.client.GetSubscription(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stripe subscription %s: %w", id, err)
+	}
+	sub := &entities.ProviderSubscription{ID: s.ID, Status: stripeSubscriptionStatus(s.Status)}
+	if s.Customer != nil {
+		sub.CustomerID = s.Customer.ID
+	}
+	rawUserID := s.Metadata["user_id"]
+	if rawUserID == "" {
+		rawUserID = userIDHint
+	}
+	if rawUserID != "" {
+		userID, err := uuid.Parse(rawUserID)
+		if err != nil {
+			return nil, fmt.Errorf("stripe subscription %s has invalid user_id %q", id, rawUserID)
+		}
+		sub.UserID = &userID
+	}
+	if s.CurrentPeriodEnd > 0 {
+		end := time.Unix(s.CurrentPeriodEnd, 0)
+		sub.CurrentPeriodEnd = &end
+	}
+	if s.TrialEnd > 0 {
+		end := time.Unix(s.TrialEnd, 0)
+		sub.TrialEnd = &end
+	}
+	return sub, nil
+}
+
+func stripeSubscriptionStatus(status stripe.SubscriptionStatus) entities.PaymentStatus {
+	switch status {
+	case stripe.SubscriptionStatusActive:
+		return entities.PaymentStatusActive
+	case stripe.SubscriptionStatusTrialing:
+		return entities.PaymentStatusTrialing
+	case stripe.SubscriptionStatusPastDue, stripe.SubscriptionStatusUnpaid:
+		return entities.PaymentStatusPastDue
+	case stripe.SubscriptionStatusCanceled, stripe.SubscriptionStatusIncompleteExpired:
+		return entities.PaymentStatusCanceled
+	default:
+		// incomplete and paused subscriptions wait for a payment
+		return entities.PaymentStatusPending
+	}
+}
+
+// Refund refunds the payment of the session, which for a subscription is the one of its first invoice,
+// and cancels the subscription so it is not charged again. The subscription is canceled first, so a
+// refund that fails can be retried without a second cancellation.
+func (g *stripeGateway) Refund(_ context.Context, p *entities.Payment) error {
+	sess, err := g.client.GetCheckoutSession(p.TransactionID)
+	if err != nil {
+		return err
+	}
+	if s := sess.Subscription; s != nil && s.Status != stripe.SubscriptionStatusCanceled {
+		if _, err := g.client.CancelSubscription(s.ID); err != nil {
+			return fmt.Errorf("failed to cancel stripe subscription %s: %w", s.ID, err)
+		}
+	}
+	pi := sess.PaymentIntent
+	if pi == nil && sess.Invoice != nil {
+		pi = sess.Invoice.PaymentIntent
+	}
+	if pi == nil {
+		return errors.New("stripe checkout session has no payment to refund")
+	}
+	_, err = g.client.RefundPaymentIntent(pi.ID)
+	return err
+}
+
+func (g *stripeGateway) Cancel(_ context.Context, p *entities.Payment) error {
+	_, err := g.client.ExpireCheckoutSession(p.TransactionID)
+	return err
+}
@@ -0,0 +1,61 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Client sends SMS through a generic HTTP gateway.
+// The gateway receives a JSON POST of {"to", "from", "text"} authenticated with a bearer API key,
+// which most providers accept directly or through a small relay.
+type Client struct {
+	url        string
+	apiKey     string
+	sender     string
+	httpClient *http.Client
+}
+
+func New(url, apiKey, sender string) *Client {
+	return &Client{
+		url:        url,
+		apiKey:     apiKey,
+		sender:     sender,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type sendPayload struct {
+	To   string `json:"to"`
+	From string `json:"from,omitempty"`
+	Text string `json:"text"`
+}
+
+func (c *Client) SendSMS(ctx context.Context, phone string, text string) error {
+	b, err := json.Marshal(sendPayload{To: phone, From: c.sender, Text: text})
+	if err != nil {
+		return fmt.Errorf("sms: marshal payload failed: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("sms: request build failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sms: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sms: send failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package smtp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// base64LineLength is the line length limit of RFC 2045 for base64 bodies
+const base64LineLength = 76
+
+var (
+	htmlLinkPattern      = regexp.MustCompile(`(?is)<a\s[^>]*href\s*=\s*"([^"]*)"[^>]*>(.*?)</a>`)
+	htmlParagraphPattern = regexp.MustCompile(`(?i)</(p|div|h[1-6]|li|tr|table)>`)
+	htmlBreakPattern     = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlTagPattern       = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesPattern    = regexp.MustCompile(`\n{3,}`)
+)
+
+// buildMessage renders msg as an RFC 5322 message. The body is multipart/alternative with a
+// plain-text and an HTML part, wrapped in multipart/mixed when there are attachments.
+// Bcc recipients are left out of the headers.
+func buildMessage(from *mail.Address, msg entities.Message, now time.Time) ([]byte, error) {
+	to, err := formatAddressList([]string{msg.To})
+	if err != nil {
+		return nil, fmt.Errorf("invalid to address: %w", err)
+	}
+	cc, err := formatAddressList(msg.Cc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cc address: %w", err)
+	}
+	replyTo, err := formatAddressList(nonEmpty(msg.ReplyTo))
+	if err != nil {
+		return nil, fmt.Errorf("invalid reply-to address: %w", err)
+	}
+
+	textBody := msg.TextBody
+	if textBody == "" {
+		textBody = htmlToText(msg.Body)
+	}
+	var alternative bytes.Buffer
+	altWriter := multipart.NewWriter(&alternative)
+	if err := writeTextPart(altWriter, "text/plain", textBody); err != nil {
+		return nil, err
+	}
+	if err := writeTextPart(altWriter, "text/html", msg.Body); err != nil {
+		return nil, err
+	}
+	if err := altWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	contentType := "multipart/alternative; boundary=" + altWriter.Boundary()
+	body := alternative.Bytes()
+	if len(msg.Attachments) > 0 {
+		var mixed bytes.Buffer
+		mixedWriter := multipart.NewWriter(&mixed)
+		part, err := mixedWriter.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(body); err != nil {
+			return nil, err
+		}
+		for _, a := range msg.Attachments {
+			if err := writeAttachment(mixedWriter, a); err != nil {
+				return nil, err
+			}
+		}
+		if err := mixedWriter.Close(); err != nil {
+			return nil, err
+		}
+		contentType = "multipart/mixed; boundary=" + mixedWriter.Boundary()
+		body = mixed.Bytes()
+	}
+
+	var b bytes.Buffer
+	writeHeader(&b, "From", from.String())
+	writeHeader(&b, "To", to)
+	if cc != "" {
+		writeHeader(&b, "Cc", cc)
+	}
+	if replyTo != "" {
+		writeHeader(&b, "Reply-To", replyTo)
+	}
+	writeHeader(&b, "Subject", mime.QEncoding.Encode("UTF-8", sanitizeHeader(msg.Subject)))
+	writeHeader(&b, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&b, "Message-ID", messageID(from.Address, now))
+	if msg.UnsubscribeURL != "" {
+		writeHeader(&b, "List-Unsubscribe", "<"+sanitizeHeader(msg.UnsubscribeURL)+">")
+		writeHeader(&b, "List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	keys := make([]string, 0, len(msg.Headers))
+	for k := range msg.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeHeader(&b, textproto.CanonicalMIMEHeaderKey(sanitizeHeader(k)), mime.QEncoding.Encode("UTF-8", sanitizeHeader(msg.Headers[k])))
+	}
+	writeHeader(&b, "MIME-Version", "1.0")
+	writeHeader(&b, "Content-Type", contentType)
+	b.WriteString("\r\n")
+	b.Write(body)
+	return b.Bytes(), nil
+}
+
+func writeTextPart(w *multipart.Writer, contentType, text string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func writeAttachment(w *multipart.Writer, a entities.Attachment) error {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(a.Filename))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(a.Data)
+	}
+	filename := sanitizeHeader(a.Filename)
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": filename})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": filename})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	for len(encoded) > base64LineLength {
+		if _, err := io.WriteString(part, encoded[:base64LineLength]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[base64LineLength:]
+	}
+	_, err = io.WriteString(part, encoded+"\r\n")
+	return err
+}
+
+// formatAddressList encodes display names as RFC 2047 words and joins the addresses
+func formatAddressList(addrs []string) (string, error) {
+	formatted := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		addr, err := mail.ParseAddress(a)
+		if err != nil {
+			return "", err
+		}
+		formatted = append(formatted, addr.String())
+	}
+	return strings.Join(formatted, ", "), nil
+}
+
+func writeHeader(b *bytes.Buffer, key, value string) {
+	b.WriteString(key)
+	b.WriteString(": ")
+	b.WriteString(value)
+	b.WriteString("\r\n")
+}
+
+// sanitizeHeader drops line breaks so values cannot inject headers of their own
+func sanitizeHeader(s string) string {
+	return strings.NewReplacer("\r", "", "\n", " ").Replace(s)
+}
+
+func messageID(fromAddress string, now time.Time) string {
+	domain := "localhost"
+	if i := strings.LastIndexByte(fromAddress, '@'); i >= 0 {
+		domain = fromAddress[i+1:]
+	}
+	var random [8]byte
+	_, _ = rand.Read(random[:])
+	return fmt.Sprintf("<%d.%s@%s>", now.UnixNano(), hex.EncodeToString(random[:]), domain)
+}
+
+// htmlToText derives the plain-text alternative of an HTML body, keeping link targets visible
+func htmlToText(s string) string {
+	s = htmlLinkPattern.ReplaceAllStringFunc(s, func(m string) string {
+		parts := htmlLinkPattern.FindStringSubmatch(m)
+		href, text := parts[1], strings.TrimSpace(htmlTagPattern.ReplaceAllString(parts[2], ""))
+		if text == "" || text == href {
+			return href
+		}
+		return text + " (" + href + ")"
+	})
+	s = htmlBreakPattern.ReplaceAllString(s, "\n")
+	s = htmlParagraphPattern.ReplaceAllString(s, "\n\n")
+	s = html.UnescapeString(htmlTagPattern.ReplaceAllString(s, ""))
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	s = blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(s)
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
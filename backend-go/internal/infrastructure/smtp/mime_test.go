@@ -0,0 +1,209 @@
+package smtp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// parsedPart is a leaf of a built message, with its transfer encoding undone
+type parsedPart struct {
+	contentType string
+	params      map[string]string
+	disposition string
+	body        []byte
+}
+
+func parseMessage(t *testing.T, raw []byte) (*mail.Message, []parsedPart) {
+	t.Helper()
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	var parts []parsedPart
+	var walk func(contentType string, body io.Reader)
+	walk = func(contentType string, body io.Reader) {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatalf("content type %q: %v", contentType, err)
+		}
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextRawPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s part: %v", mediaType, err)
+			}
+			ct := p.Header.Get("Content-Type")
+			if strings.HasPrefix(ct, "multipart/") {
+				walk(ct, p)
+				continue
+			}
+			var r io.Reader = p
+			switch p.Header.Get("Content-Transfer-Encoding") {
+			case "quoted-printable":
+				r = quotedprintable.NewReader(p)
+			case "base64":
+				r = base64.NewDecoder(base64.StdEncoding, p)
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("decode part: %v", err)
+			}
+			partType, partParams, _ := mime.ParseMediaType(ct)
+			parts = append(parts, parsedPart{contentType: partType, params: partParams, disposition: p.Header.Get("Content-Disposition"), body: data})
+		}
+	}
+	walk(m.Header.Get("Content-Type"), m.Body)
+	return m, parts
+}
+
+func TestBuildMessage(t *testing.T) {
+	from := &mail.Address{Name: "Salon", Address: "noreply@salon.example"}
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	pdf := []byte("%PDF-1.4 receipt")
+	raw, err := buildMessage(from, entities.Message{
+		To:             "Budi Śantoso <budi@example.com>",
+		Cc:             []string{"owner@example.com"},
+		Bcc:            []string{"audit@example.com"},
+		ReplyTo:        "support@salon.example",
+		Subject:        "Pembayaran diterima ✓",
+		Body:           `<p>Hi Budi,</p><p>See <a href="https://salon.example/r/1">your receipt</a>.</p>`,
+		Headers:        map[string]string{"x-entity-ref-id": "abc\r\nBcc: evil@example.com"},
+		Attachments:    []entities.Attachment{{Filename: "receipt.pdf", Data: pdf}},
+		UnsubscribeURL: "https://salon.example/v1/unsubscribe?token=t",
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, parts := parseMessage(t, raw)
+
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil || subject != "Pembayaran diterima ✓" {
+		t.Errorf("Subject = %q, %v", subject, err)
+	}
+	to, err := m.Header.AddressList("To")
+	if err != nil || len(to) != 1 || to[0].Name != "Budi Śantoso" || to[0].Address != "budi@example.com" {
+		t.Errorf("To = %v, %v", to, err)
+	}
+	if got := m.Header.Get("Cc"); got != "<owner@example.com>" {
+		t.Errorf("Cc = %q", got)
+	}
+	if got := m.Header.Get("Reply-To"); got != "<support@salon.example>" {
+		t.Errorf("Reply-To = %q", got)
+	}
+	if got := m.Header.Get("Bcc"); got != "" {
+		t.Errorf("Bcc header %q reveals blind recipients", got)
+	}
+	if got := m.Header.Get("X-Entity-Ref-Id"); got != "abc Bcc: evil@example.com" {
+		t.Errorf("X-Entity-Ref-Id = %q, a header value must not start a header of its own", got)
+	}
+	if got := m.Header.Get("List-Unsubscribe"); got != "<https://salon.example/v1/unsubscribe?token=t>" {
+		t.Errorf("List-Unsubscribe = %q", got)
+	}
+	if got := m.Header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q", got)
+	}
+	if got := m.Header.Get("Date"); got != now.Format(time.RFC1123Z) {
+		t.Errorf("Date = %q", got)
+	}
+	if id := m.Header.Get("Message-ID"); !strings.HasSuffix(id, "@salon.example>") {
+		t.Errorf("Message-ID = %q", id)
+	}
+	if ct := m.Header.Get("Content-Type"); !strings.HasPrefix(ct, "multipart/mixed;") {
+		t.Errorf("Content-Type = %q, want multipart/mixed with an attachment", ct)
+	}
+
+	if len(parts) != 3 {
+		t.Fatalf("got %d parts, want text, html and the attachment", len(parts))
+	}
+	// Quoted-printable text ends its lines with CRLF
+	if parts[0].contentType != "text/plain" || string(parts[0].body) != "Hi Budi,\r\n\r\nSee your receipt (https://salon.example/r/1)." {
+		t.Errorf("text part = %s %q", parts[0].contentType, parts[0].body)
+	}
+	if parts[1].contentType != "text/html" || !strings.Contains(string(parts[1].body), `href="https://salon.example/r/1"`) {
+		t.Errorf("html part = %s %q", parts[1].contentType, parts[1].body)
+	}
+	if parts[2].contentType != "application/pdf" || parts[2].params["name"] != "receipt.pdf" || !bytes.Equal(parts[2].body, pdf) {
+		t.Errorf("attachment = %s %v %q", parts[2].contentType, parts[2].params, parts[2].body)
+	}
+	if !strings.HasPrefix(parts[2].disposition, "attachment;") {
+		t.Errorf("attachment disposition = %q", parts[2].disposition)
+	}
+}
+
+func TestBuildMessageTextBody(t *testing.T) {
+	from := &mail.Address{Address: "noreply@salon.example"}
+	raw, err := buildMessage(from, entities.Message{
+		To:       "budi@example.com",
+		Subject:  "Hello",
+		Body:     "<p>Hello</p>",
+		TextBody: "Hello, in plain words",
+	}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, parts := parseMessage(t, raw)
+	if ct := m.Header.Get("Content-Type"); !strings.HasPrefix(ct, "multipart/alternative;") {
+		t.Errorf("Content-Type = %q, want multipart/alternative without attachments", ct)
+	}
+	for _, h := range []string{"Cc", "Reply-To", "List-Unsubscribe"} {
+		if m.Header.Get(h) != "" {
+			t.Errorf("unexpected %s header", h)
+		}
+	}
+	if len(parts) != 2 || string(parts[0].body) != "Hello, in plain words" {
+		t.Errorf("text part = %q, want the given TextBody", parts[0].body)
+	}
+}
+
+func TestBuildMessageRejectsInvalidAddresses(t *testing.T) {
+	from := &mail.Address{Address: "noreply@salon.example"}
+	for name, msg := range map[string]entities.Message{
+		"to":       {To: "not an address"},
+		"cc":       {To: "budi@example.com", Cc: []string{"@"}},
+		"reply-to": {To: "budi@example.com", ReplyTo: "nobody"},
+	} {
+		if _, err := buildMessage(from, msg, time.Now()); err == nil {
+			t.Errorf("%s: invalid address was accepted", name)
+		}
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"plain text", "Hello", "Hello"},
+		{"paragraphs", "<p>One</p><p>Two</p>", "One\n\nTwo"},
+		{"line breaks", "One<br>Two<br/>Three<BR />Four", "One\nTwo\nThree\nFour"},
+		{"link with text", `Open <a href="https://x.example/a">the app</a> now`, "Open the app (https://x.example/a) now"},
+		{"link showing its target", `<a href="https://x.example/a">https://x.example/a</a>`, "https://x.example/a"},
+		{"link around markup", `<a class="btn" href="https://x.example/a"><b>Verify</b></a>`, "Verify (https://x.example/a)"},
+		{"link without text", `<a href="https://x.example/a"><img src="logo.png"></a>`, "https://x.example/a"},
+		{"entities", "<p>Tom &amp; Jerry &lt;3</p>", "Tom & Jerry <3"},
+		{"indentation and blank lines", "<div>\n    <h1>Title</h1>\n\n\n\n    <p>  Body  </p>\n</div>", "Title\n\nBody"},
+		{"table rows", "<table><tr><td>A</td></tr><tr><td>B</td></tr></table>", "A\n\nB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := htmlToText(tt.html); got != tt.want {
+				t.Errorf("htmlToText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
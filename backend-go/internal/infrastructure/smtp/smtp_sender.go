@@ -2,94 +2,231 @@ package smtp
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
+	"net/mail"
 	"net/smtp"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
 )
 
+// Security selects how the connection to the SMTP server is protected
+type Security string
+
+const (
+	// SecurityPlain sends in the clear, for local catchers such as MailHog or Mailpit
+	SecurityPlain Security = "plain"
+	// SecuritySTARTTLS upgrades a plain connection, usually on port 587
+	SecuritySTARTTLS Security = "starttls"
+	// SecurityTLS speaks TLS from the first byte, usually on port 465
+	SecurityTLS Security = "tls"
+)
+
+type Options struct {
+	Security Security
+	// Timeout bounds dialing and each message sent over a connection
+	Timeout time.Duration
+	// PoolSize is the number of idle authenticated connections kept for bulk sending; 0 disables reuse
+	PoolSize int
+	// IdleTimeout closes pooled connections unused for longer, before the server drops them
+	IdleTimeout time.Duration
+}
+
 type SMTPSender struct {
 	host     string
 	port     int
 	username string
 	password string
 	from     string
+	opts     Options
+	idle     chan *pooledConn
+}
+
+type pooledConn struct {
+	client   *smtp.Client
+	conn     net.Conn
+	lastUsed time.Time
 }
 
-func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+func NewSMTPSender(host string, port int, username, password, from string, opts Options) *SMTPSender {
 	return &SMTPSender{
 		host:     host,
 		port:     port,
 		username: username,
 		password: password,
 		from:     from,
+		opts:     opts,
+		idle:     make(chan *pooledConn, max(opts.PoolSize, 0)),
 	}
 }
 
 func (s *SMTPSender) Send(msg entities.Message) error {
-	addr := fmt.Sprintf("%s:%d", s.host, s.port)
-
-	auth := smtp.PlainAuth("", s.username, s.password, s.host)
-
-	// Prepare email headers and body
-	var sb strings.Builder
-
-	sb.WriteString(fmt.Sprintf("From: %s\r\n", s.from))
-	sb.WriteString(fmt.Sprintf("To: %s\r\n", msg.To))
-	sb.WriteString(fmt.Sprintf("Subject: %s\r\n", msg.Subject))
-	sb.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z)))
-	sb.WriteString("MIME-Version: 1.0\r\n")
-	sb.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
-	sb.WriteString("\r\n") // empty line before body
-	sb.WriteString(msg.Body)
-
-	// TLS config
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: false,
-		ServerName:         s.host,
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("invalid smtp from address: %w", err)
 	}
-
-	// Connect to SMTP server
-	conn, err := tls.Dial("tcp", addr, tlsConfig)
+	data, err := buildMessage(from, msg, time.Now().UTC())
 	if err != nil {
-		return fmt.Errorf("smtp dial tls failed: %w", err)
+		return fmt.Errorf("smtp build message failed: %w", err)
 	}
-	defer conn.Close()
-
-	client, err := smtp.NewClient(conn, s.host)
+	recipients, err := envelopeRecipients(msg)
 	if err != nil {
-		return fmt.Errorf("smtp new client failed: %w", err)
+		return err
 	}
-	defer client.Quit()
 
-	if err = client.Auth(auth); err != nil {
-		return fmt.Errorf("smtp auth failed: %w", err)
+	c, err := s.acquire()
+	if err != nil {
+		return err
+	}
+	if err := s.deliver(c, from.Address, recipients, data); err != nil {
+		// The session state is unknown after a failure, start over on a new connection next time
+		c.close()
+		return err
 	}
+	s.release(c)
+	return nil
+}
 
-	if err = client.Mail(s.from); err != nil {
+func (s *SMTPSender) deliver(c *pooledConn, from string, recipients []string, data []byte) error {
+	if s.opts.Timeout > 0 {
+		if err := c.conn.SetDeadline(time.Now().Add(s.opts.Timeout)); err != nil {
+			return fmt.Errorf("smtp set deadline failed: %w", err)
+		}
+	}
+	if err := c.client.Mail(from); err != nil {
 		return fmt.Errorf("smtp mail failed: %w", err)
 	}
-
-	if err = client.Rcpt(msg.To); err != nil {
-		return fmt.Errorf("smtp rcpt failed: %w", err)
+	for _, rcpt := range recipients {
+		if err := c.client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp rcpt %s failed: %w", rcpt, err)
+		}
 	}
-
-	w, err := client.Data()
+	w, err := c.client.Data()
 	if err != nil {
 		return fmt.Errorf("smtp data open failed: %w", err)
 	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("smtp write failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp close failed: %w", err)
+	}
+	return nil
+}
 
-	_, err = w.Write([]byte(sb.String()))
+// acquire reuses an idle connection that still answers RSET, or dials a new one
+func (s *SMTPSender) acquire() (*pooledConn, error) {
+	for {
+		select {
+		case c := <-s.idle:
+			if s.opts.IdleTimeout > 0 && time.Since(c.lastUsed) > s.opts.IdleTimeout {
+				c.close()
+				continue
+			}
+			if s.opts.Timeout > 0 {
+				_ = c.conn.SetDeadline(time.Now().Add(s.opts.Timeout))
+			}
+			if err := c.client.Reset(); err != nil {
+				c.close()
+				continue
+			}
+			return c, nil
+		default:
+			return s.dial()
+		}
+	}
+}
+
+func (s *SMTPSender) release(c *pooledConn) {
+	c.lastUsed = time.Now()
+	select {
+	case s.idle <- c:
+	default:
+		c.close()
+	}
+}
+
+func (s *SMTPSender) dial() (*pooledConn, error) {
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	dialer := &net.Dialer{Timeout: s.opts.Timeout}
+	tlsConfig := &tls.Config{ServerName: s.host}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.opts.Security == SecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
 	if err != nil {
-		return fmt.Errorf("smtp write failed: %w", err)
+		return nil, fmt.Errorf("smtp dial %s failed: %w", addr, err)
+	}
+	if s.opts.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(s.opts.Timeout))
 	}
 
-	err = w.Close()
+	client, err := smtp.NewClient(conn, s.host)
 	if err != nil {
-		return fmt.Errorf("smtp close failed: %w", err)
+		conn.Close()
+		return nil, fmt.Errorf("smtp new client failed: %w", err)
+	}
+	c := &pooledConn{client: client, conn: conn}
+
+	if s.opts.Security == SecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			c.close()
+			return nil, errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			c.close()
+			return nil, fmt.Errorf("smtp starttls failed: %w", err)
+		}
 	}
 
-	return nil
+	// Local catchers accept mail without credentials
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			c.close()
+			return nil, fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (c *pooledConn) close() {
+	if err := c.client.Quit(); err != nil {
+		c.client.Close()
+	}
+}
+
+// ParseSecurity maps the SMTP_SSL and SMTP_TLS settings to a Security mode, SSL winning when both are set
+func ParseSecurity(ssl, startTLS bool) Security {
+	switch {
+	case ssl:
+		return SecurityTLS
+	case startTLS:
+		return SecuritySTARTTLS
+	default:
+		return SecurityPlain
+	}
+}
+
+// envelopeRecipients returns the bare addresses of every To, Cc and Bcc recipient
+func envelopeRecipients(msg entities.Message) ([]string, error) {
+	var recipients []string
+	for _, list := range [][]string{{msg.To}, msg.Cc, msg.Bcc} {
+		for _, a := range list {
+			addr, err := mail.ParseAddress(a)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recipient %q: %w", a, err)
+			}
+			recipients = append(recipients, addr.Address)
+		}
+	}
+	return recipients, nil
 }
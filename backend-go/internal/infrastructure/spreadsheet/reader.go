@@ -0,0 +1,106 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Supported upload formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported spreadsheet format")
+
+// Reader returns one record per call and io.EOF after the last one
+type Reader interface {
+	Read() ([]string, error)
+	Close() error
+}
+
+// NewReader opens r as a CSV or the first sheet of an XLSX workbook
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return newCSVReader(r), nil
+	case FormatXLSX:
+		return newXLSXReader(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// FormatFromFilename guesses the format from a file extension, defaulting to CSV
+func FormatFromFilename(name string) string {
+	if strings.HasSuffix(strings.ToLower(name), ".xlsx") {
+		return FormatXLSX
+	}
+	return FormatCSV
+}
+
+type csvReader struct {
+	r *csv.Reader
+}
+
+func newCSVReader(r io.Reader) *csvReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // rows may omit trailing optional columns
+	cr.TrimLeadingSpace = true
+	return &csvReader{r: cr}
+}
+
+func (c *csvReader) Read() ([]string, error) {
+	return c.r.Read()
+}
+
+func (c *csvReader) Close() error {
+	return nil
+}
+
+// xlsxReader streams rows so large sheets are not expanded into memory at once
+type xlsxReader struct {
+	file *excelize.File
+	rows *excelize.Rows
+}
+
+func newXLSXReader(r io.Reader) (*xlsxReader, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workbook: %w", err)
+	}
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		f.Close()
+		return nil, fmt.Errorf("workbook has no sheets")
+	}
+	rows, err := f.Rows(sheets[0])
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read sheet: %w", err)
+	}
+	return &xlsxReader{file: f, rows: rows}, nil
+}
+
+func (x *xlsxReader) Read() ([]string, error) {
+	if !x.rows.Next() {
+		if err := x.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return x.rows.Columns()
+}
+
+func (x *xlsxReader) Close() error {
+	if err := x.rows.Close(); err != nil {
+		x.file.Close()
+		return err
+	}
+	return x.file.Close()
+}
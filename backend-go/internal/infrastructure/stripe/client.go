@@ -2,19 +2,20 @@ package stripeinfra
 
 import (
 	"github.com/stripe/stripe-go/v78"
-	"github.com/stripe/stripe-go/v78/checkout/session"
-	"github.com/stripe/stripe-go/v78/price"
+	"github.com/stripe/stripe-go/v78/client"
 	"github.com/stripe/stripe-go/v78/webhook"
 )
 
-type Client struct{}
+// Client calls Stripe with its own key rather than the package-wide stripe.Key
+type Client struct {
+	api *client.API
+}
 
 func New(secret string) *Client {
-	stripe.Key = secret
-	return &Client{}
+	return &Client{api: client.New(secret, nil)}
 }
 
-func (c *Client) CreateCheckoutSession(priceID, successURL, cancelURL string, metadata map[string]string) (*stripe.CheckoutSession, error) {
+func (c *Client) CreateCheckoutSession(priceID, successURL, cancelURL, reference string, metadata map[string]string) (*stripe.CheckoutSession, error) {
 	params := &stripe.CheckoutSessionParams{
 		Mode:       stripe.String(string(stripe.CheckoutSessionModeSubscription)),
 		SuccessURL: stripe.String(successURL),
@@ -24,10 +25,15 @@ func (c *Client) CreateCheckoutSession(priceID, successURL, cancelURL string, me
 			Quantity: stripe.Int64(1),
 		}},
 	}
+	if reference != "" {
+		params.ClientReferenceID = stripe.String(reference)
+	}
 	if metadata != nil {
 		params.Metadata = metadata
+		// Copied to the subscription so its events can be matched to the user
+		params.SubscriptionData = &stripe.CheckoutSessionSubscriptionDataParams{Metadata: metadata}
 	}
-	s, err := session.New(params)
+	s, err := c.api.CheckoutSessions.New(params)
 	if err != nil {
 		return nil, err
 	}
@@ -39,17 +45,57 @@ func (c *Client) ConstructEvent(payload []byte, sig string, webhookSecret string
 }
 
 func (c *Client) GetPrice(priceID string) (*stripe.Price, error) {
-	p, err := price.Get(priceID, nil)
+	p, err := c.api.Prices.Get(priceID, nil)
 	if err != nil {
 		return nil, err
 	}
 	return p, nil
 }
 
+// GetCheckoutSession returns a session with its invoice, which holds the payment of a subscription,
+// and the subscription itself
 func (c *Client) GetCheckoutSession(id string) (*stripe.CheckoutSession, error) {
-	s, err := session.Get(id, nil)
+	params := &stripe.CheckoutSessionParams{}
+	params.AddExpand("invoice")
+	params.AddExpand("subscription")
+	s, err := c.api.CheckoutSessions.Get(id, params)
 	if err != nil {
 		return nil, err
 	}
 	return s, nil
 }
+
+// ExpireCheckoutSession closes an open session so it can no longer be paid
+func (c *Client) ExpireCheckoutSession(id string) (*stripe.CheckoutSession, error) {
+	s, err := c.api.CheckoutSessions.Expire(id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *Client) GetSubscription(id string) (*stripe.Subscription, error) {
+	s, err := c.api.Subscriptions.Get(id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CancelSubscription ends a subscription right away, without charging for the rest of its period
+func (c *Client) CancelSubscription(id string) (*stripe.Subscription, error) {
+	s, err := c.api.Subscriptions.Cancel(id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RefundPaymentIntent refunds the whole amount of a payment intent
+func (c *Client) RefundPaymentIntent(paymentIntentID string) (*stripe.Refund, error) {
+	r, err := c.api.Refunds.New(&stripe.RefundParams{PaymentIntent: stripe.String(paymentIntentID)})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
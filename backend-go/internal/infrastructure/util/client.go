@@ -0,0 +1,22 @@
+package util
+
+import (
+	"context"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+const (
+	clientInfoContextKey contextKey = "client_info"
+)
+
+// WithClientInfo adds the calling client's details to context
+func WithClientInfo(ctx context.Context, info entities.ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoContextKey, info)
+}
+
+// ClientInfoFromContext retrieves the calling client's details from context
+func ClientInfoFromContext(ctx context.Context) entities.ClientInfo {
+	info, _ := ctx.Value(clientInfoContextKey).(entities.ClientInfo)
+	return info
+}
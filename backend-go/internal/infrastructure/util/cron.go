@@ -0,0 +1,130 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit bounds the search for the next run, so an expression like "0 0 30 2 *" that never matches ends
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// CronSchedule is a parsed five field cron expression: minute, hour, day of month, month and day of week.
+// Fields take *, numbers, ranges like 1-5, steps like */15 or 8-18/2, and comma separated lists of those.
+// Sunday is 0 or 7. As in cron, a time matches either day field when both are restricted.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64 // bit n is set when value n matches
+	domAny, dowAny                bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var cronFields = [5]cronField{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// ParseCron parses a five field cron expression
+func ParseCron(expr string) (*CronSchedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression needs 5 fields, got %d", len(parts))
+	}
+	var bits [5]uint64
+	for i, part := range parts {
+		b, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron field %d %q: %w", i+1, part, err)
+		}
+		bits[i] = b
+	}
+	// Sunday may be written as 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &CronSchedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: strings.HasPrefix(parts[2], "*"),
+		dowAny: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, errors.New("invalid step")
+			}
+			step = n
+		}
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, errors.New("invalid value")
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, errors.New("invalid range")
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+		}
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("out of range %d-%d", f.min, f.max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first time after t that matches the schedule, in t's location, or the zero time when none does
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"slices"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"golang.org/x/text/language"
+)
+
+// anyLanguage is what the Accept-Language wildcard parses to
+var anyLanguage = language.Make("mul")
+
+// NormalizeLocale returns the canonical form of a BCP 47 language tag, such as id-ID for "id_id".
+// It reports false when s is not a valid tag.
+func NormalizeLocale(s string) (string, bool) {
+	tag, err := language.Parse(s)
+	if err != nil || tag == language.Und {
+		return "", false
+	}
+	return tag.String(), true
+}
+
+// LocaleFromAcceptLanguage returns the most preferred tag of an Accept-Language header, or empty
+func LocaleFromAcceptLanguage(header string) string {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return ""
+	}
+	for _, tag := range tags {
+		if tag != language.Und && tag != anyLanguage {
+			return tag.String()
+		}
+	}
+	return ""
+}
+
+// LocaleFallbacks expands each locale into itself and its base language, id-ID into id-ID and id,
+// and ends with the default locale. Empty and invalid locales are skipped.
+func LocaleFallbacks(locales ...string) []string {
+	var chain []string
+	add := func(l string) {
+		if !slices.Contains(chain, l) {
+			chain = append(chain, l)
+		}
+	}
+	for _, l := range locales {
+		tag, err := language.Parse(l)
+		if err != nil || tag == language.Und {
+			continue
+		}
+		add(tag.String())
+		base, _ := tag.Base()
+		add(base.String())
+	}
+	add(entities.DefaultLocale)
+	return chain
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+const (
+	organizationMemberContextKey contextKey = "organization_member"
+	organizationIDContextKey     contextKey = "organization_id"
+	systemScopeContextKey        contextKey = "system_scope"
+)
+
+// WithOrganizationMember adds the caller's membership in the selected organization to context
+func WithOrganizationMember(ctx context.Context, member *entities.OrganizationMember) context.Context {
+	return context.WithValue(ctx, organizationMemberContextKey, member)
+}
+
+// OrganizationMemberFromContext retrieves the caller's membership in the selected organization
+func OrganizationMemberFromContext(ctx context.Context) *entities.OrganizationMember {
+	member, _ := ctx.Value(organizationMemberContextKey).(*entities.OrganizationMember)
+	return member
+}
+
+// WithOrganizationID scopes database access to an organization the caller is not yet a member of,
+// e.g. while accepting an invitation
+func WithOrganizationID(ctx context.Context, orgID uuid.UUID) context.Context {
+	return context.WithValue(ctx, organizationIDContextKey, orgID)
+}
+
+// OrganizationIDFromContext returns the organization database access is scoped to
+func OrganizationIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if orgID, ok := ctx.Value(organizationIDContextKey).(uuid.UUID); ok {
+		return orgID, true
+	}
+	if member := OrganizationMemberFromContext(ctx); member != nil {
+		return member.OrganizationID, true
+	}
+	return uuid.Nil, false
+}
+
+// WithSystemScope lifts row level security for work that belongs to no tenant, such as background
+// workers, provider webhooks and superuser-only admin reads. Never derive it from request input.
+func WithSystemScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemScopeContextKey, true)
+}
+
+// IsSystemScope reports whether database access in ctx bypasses tenant isolation
+func IsSystemScope(ctx context.Context) bool {
+	system, _ := ctx.Value(systemScopeContextKey).(bool)
+	return system
+}
@@ -46,3 +46,22 @@ func HasAllRoles(user *entities.User, requiredRoles ...string) bool {
 
 	return true
 }
+
+// HasOrganizationRole checks required roles in the context of an organization.
+// Salon roles are taken from the membership instead of the user's global roles;
+// without a membership this falls back to HasRole.
+func HasOrganizationRole(user *entities.User, member *entities.OrganizationMember, requiredRoles ...string) bool {
+	if member == nil {
+		return HasRole(user, requiredRoles...)
+	}
+	scoped := *user
+	scoped.Roles = []string{string(member.Role)}
+	for _, role := range user.Roles {
+		switch strings.ToLower(role) {
+		case string(entities.OrganizationRoleOwner), string(entities.OrganizationRoleEmployee):
+			continue
+		}
+		scoped.Roles = append(scoped.Roles, role)
+	}
+	return HasRole(&scoped, requiredRoles...)
+}
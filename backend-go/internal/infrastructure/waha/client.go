@@ -4,63 +4,430 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/repositories"
 )
 
+// webhookEvents are the events the webhook handler takes
+var webhookEvents = []string{"message", "message.ack", "session.status"}
+
+// Webhook is where sessions started through the client report their events; sessions get none without a URL
+type Webhook struct {
+	URL     string
+	HMACKey string
+}
+
 // Client implements WAHA WhatsApp API integration
 type Client struct {
 	url        string
 	apiKey     string
 	session    string
+	webhook    Webhook
 	httpClient *http.Client
 }
 
-func New(url, apiKey, session string) *Client {
+func New(url, apiKey, session string, webhook Webhook) *Client {
 	return &Client{
 		url:        strings.TrimRight(url, "/"),
 		apiKey:     apiKey,
 		session:    session,
+		webhook:    webhook,
 		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
+// Session returns a client for another session of the same WAHA server
+func (c *Client) Session(name string) repositories.WahaClient {
+	clone := *c
+	clone.session = name
+	return &clone
+}
+
+func (c *Client) SessionName() string {
+	return c.session
+}
+
+// StatusError is returned for WAHA responses outside 2xx
+type StatusError struct {
+	Method, Path string
+	Code         int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("waha: %s %s failed with status %d", e.Method, e.Path, e.Code)
+}
+
 type sendTextPayload struct {
 	Session string `json:"session"`
 	ChatID  string `json:"chatId"`
 	Text    string `json:"text"`
 }
 
-func (c *Client) SendText(ctx context.Context, phone string, text string) error {
+type filePayload struct {
+	MimeType string `json:"mimetype,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	URL      string `json:"url"`
+}
+
+type sendFilePayload struct {
+	Session string      `json:"session"`
+	ChatID  string      `json:"chatId"`
+	File    filePayload `json:"file"`
+	Caption string      `json:"caption,omitempty"`
+}
+
+type sendLocationPayload struct {
+	Session   string  `json:"session"`
+	ChatID    string  `json:"chatId"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Title     string  `json:"title,omitempty"`
+}
+
+type buttonPayload struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	URL         string `json:"url,omitempty"`
+	PhoneNumber string `json:"phoneNumber,omitempty"`
+}
+
+type sendButtonsPayload struct {
+	Session     string          `json:"session"`
+	ChatID      string          `json:"chatId"`
+	Body        string          `json:"body"`
+	Footer      string          `json:"footer,omitempty"`
+	Buttons     []buttonPayload `json:"buttons"`
+	HeaderImage *filePayload    `json:"headerImage,omitempty"`
+}
+
+type listMessagePayload struct {
+	Description string                         `json:"description"`
+	Footer      string                         `json:"footer,omitempty"`
+	Button      string                         `json:"button"`
+	Sections    []entities.WhatsAppListSection `json:"sections"`
+}
+
+type sendListPayload struct {
+	Session string             `json:"session"`
+	ChatID  string             `json:"chatId"`
+	Message listMessagePayload `json:"message"`
+}
+
+type presencePayload struct {
+	ChatID   string `json:"chatId"`
+	Presence string `json:"presence"`
+}
+
+// sentMessage is the part of the sent message WAHA returns that identifies it. Depending on the
+// engine the id is a string, an object with a serialized form, or only found in the message key.
+type sentMessage struct {
+	ID  json.RawMessage `json:"id"`
+	Key struct {
+		ID string `json:"id"`
+	} `json:"key"`
+}
+
+// SendText returns the message id in the form MessageKey gives, empty when WAHA did not report one
+func (c *Client) SendText(ctx context.Context, phone string, text string) (string, error) {
+	return c.send(ctx, "sendText", sendTextPayload{Session: c.session, ChatID: formatChatID(phone), Text: text})
+}
+
+func (c *Client) SendImage(ctx context.Context, phone string, media entities.WhatsAppMedia, caption string) (string, error) {
+	return c.send(ctx, "sendImage", sendFilePayload{Session: c.session, ChatID: formatChatID(phone), File: toFilePayload(media), Caption: caption})
+}
+
+func (c *Client) SendFile(ctx context.Context, phone string, media entities.WhatsAppMedia, caption string) (string, error) {
+	return c.send(ctx, "sendFile", sendFilePayload{Session: c.session, ChatID: formatChatID(phone), File: toFilePayload(media), Caption: caption})
+}
+
+func (c *Client) SendLocation(ctx context.Context, phone string, location entities.WhatsAppLocation) (string, error) {
+	lat, err := strconv.ParseFloat(strings.TrimSpace(location.Latitude), 64)
+	if err != nil {
+		return "", fmt.Errorf("waha: invalid latitude %q", location.Latitude)
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(location.Longitude), 64)
+	if err != nil {
+		return "", fmt.Errorf("waha: invalid longitude %q", location.Longitude)
+	}
+	return c.send(ctx, "sendLocation", sendLocationPayload{
+		Session:   c.session,
+		ChatID:    formatChatID(phone),
+		Latitude:  lat,
+		Longitude: lng,
+		Title:     location.Title,
+	})
+}
+
+func (c *Client) SendButtons(ctx context.Context, phone string, text, footer string, buttons []entities.WhatsAppButton, header *entities.WhatsAppMedia) (string, error) {
+	payload := sendButtonsPayload{Session: c.session, ChatID: formatChatID(phone), Body: text, Footer: footer}
+	for _, b := range buttons {
+		payload.Buttons = append(payload.Buttons, buttonPayload{Type: string(b.Type), Text: b.Text, URL: b.URL, PhoneNumber: b.Phone})
+	}
+	if header != nil {
+		file := toFilePayload(*header)
+		payload.HeaderImage = &file
+	}
+	return c.send(ctx, "send/buttons/reply", payload)
+}
+
+func (c *Client) SendList(ctx context.Context, phone string, text, footer string, list entities.WhatsAppList) (string, error) {
+	return c.send(ctx, "sendList", sendListPayload{
+		Session: c.session,
+		ChatID:  formatChatID(phone),
+		Message: listMessagePayload{Description: text, Footer: footer, Button: list.Button, Sections: list.Sections},
+	})
+}
+
+func (c *Client) SetPresence(ctx context.Context, phone string, presence entities.WhatsAppPresence) error {
+	path := "/api/" + url.PathEscape(c.session) + "/presence"
+	resp, err := c.do(ctx, http.MethodPost, path, presencePayload{ChatID: formatChatID(phone), Presence: string(presence)})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// messageStatus is the part of a stored message that tells how far it got
+type messageStatus struct {
+	Ack int `json:"ack"`
+}
+
+// MessageStatus looks a sent message up by the id the Send methods returned. WAHA ack levels are
+// 1 server, 2 device, 3 read and 4 played; -1 means sending failed.
+func (c *Client) MessageStatus(ctx context.Context, phone, messageID string) (entities.MessageAck, error) {
 	chatID := formatChatID(phone)
-	payload := sendTextPayload{Session: c.session, ChatID: chatID, Text: text}
-	b, err := json.Marshal(payload)
+	path := "/api/" + url.PathEscape(c.session) + "/chats/" + url.PathEscape(chatID) +
+		"/messages/" + url.PathEscape("true_"+chatID+"_"+messageID)
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var status messageStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return "", fmt.Errorf("waha: decode message failed: %w", err)
+	}
+	return AckFromLevel(status.Ack), nil
+}
+
+// AckFromLevel maps a WAHA ack level to a receipt, empty for levels that are not one
+func AckFromLevel(level int) entities.MessageAck {
+	switch level {
+	case 2:
+		return entities.MessageAckDelivered
+	case 3, 4:
+		return entities.MessageAckRead
+	case -1:
+		return entities.MessageAckFailed
+	default:
+		return ""
+	}
+}
+
+// send posts to a send endpoint and returns the id of the message sent
+func (c *Client) send(ctx context.Context, endpoint string, payload any) (string, error) {
+	resp, err := c.do(ctx, http.MethodPost, "/api/"+endpoint, payload)
 	if err != nil {
-		return fmt.Errorf("waha: marshal payload failed: %w", err)
+		return "", err
+	}
+	defer resp.Body.Close()
+	// The message went out; an unexpected response only costs its delivery receipts
+	var sent sentMessage
+	if err := json.NewDecoder(resp.Body).Decode(&sent); err != nil {
+		return "", nil
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/api/sendText", bytes.NewReader(b))
+	return sent.messageID(), nil
+}
+
+// do sends a JSON request with the API key and fails on any non-2xx status; callers close the body
+func (c *Client) do(ctx context.Context, method, path string, payload any) (*http.Response, error) {
+	return c.request(ctx, method, path, payload, "application/json")
+}
+
+func (c *Client) request(ctx context.Context, method, path string, payload any, accept string) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("waha: marshal payload failed: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
 	if err != nil {
-		return fmt.Errorf("waha: request build failed: %w", err)
+		return nil, fmt.Errorf("waha: request build failed: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept", accept)
 	if c.apiKey != "" {
 		req.Header.Set("X-Api-Key", c.apiKey)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("waha: request failed: %w", err)
+		return nil, fmt.Errorf("waha: request failed: %w", err)
 	}
-	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
-		return fmt.Errorf("waha: sendText failed with status %d", resp.StatusCode)
+		resp.Body.Close()
+		return nil, &StatusError{Method: method, Path: path, Code: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+func toFilePayload(media entities.WhatsAppMedia) filePayload {
+	return filePayload{MimeType: media.MimeType, Filename: media.Filename, URL: media.URL}
+}
+
+func (m sentMessage) messageID() string {
+	var id string
+	if err := json.Unmarshal(m.ID, &id); err == nil && id != "" {
+		return MessageKey(id)
+	}
+	var serialized struct {
+		Serialized string `json:"_serialized"`
+		ID         string `json:"id"`
+	}
+	if err := json.Unmarshal(m.ID, &serialized); err == nil {
+		if serialized.Serialized != "" {
+			return MessageKey(serialized.Serialized)
+		}
+		if serialized.ID != "" {
+			return serialized.ID
+		}
+	}
+	return m.Key.ID
+}
+
+// MessageKey reduces a WAHA message id to the part every engine agrees on. Serialized ids look
+// like "true_<chat id>_<key>", with the sender appended in groups; acks and replies may use either form.
+func MessageKey(id string) string {
+	parts := strings.SplitN(id, "_", 4)
+	if len(parts) >= 3 && (parts[0] == "true" || parts[0] == "false") {
+		return parts[2]
+	}
+	return id
+}
+
+type sessionInfo struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// SessionStatus reports the state of the configured session, e.g. WORKING, SCAN_QR_CODE, FAILED or STOPPED
+func (c *Client) SessionStatus(ctx context.Context) (string, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/api/sessions/"+url.PathEscape(c.session), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var info sessionInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("waha: decode session failed: %w", err)
+	}
+	return info.Status, nil
+}
+
+type webhookConfig struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	HMAC   *struct {
+		Key string `json:"key"`
+	} `json:"hmac,omitempty"`
+}
+
+type sessionConfig struct {
+	Webhooks []webhookConfig `json:"webhooks,omitempty"`
+}
+
+type createSessionPayload struct {
+	Name   string         `json:"name"`
+	Start  bool           `json:"start"`
+	Config *sessionConfig `json:"config,omitempty"`
+}
+
+// StartSession creates the session and starts it. A session that already exists keeps its
+// configuration and is only started when it is not running.
+func (c *Client) StartSession(ctx context.Context) error {
+	payload := createSessionPayload{Name: c.session, Start: true}
+	if c.webhook.URL != "" {
+		hook := webhookConfig{URL: c.webhook.URL, Events: webhookEvents}
+		if c.webhook.HMACKey != "" {
+			hook.HMAC = &struct {
+				Key string `json:"key"`
+			}{Key: c.webhook.HMACKey}
+		}
+		payload.Config = &sessionConfig{Webhooks: []webhookConfig{hook}}
 	}
+	resp, err := c.do(ctx, http.MethodPost, "/api/sessions", payload)
+	if err == nil {
+		resp.Body.Close()
+		return nil
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusUnprocessableEntity {
+		return err
+	}
+
+	// 422 means the session exists
+	status, err := c.SessionStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if status != entities.WhatsAppSessionStopped && status != entities.WhatsAppSessionFailed {
+		return nil
+	}
+	resp, err = c.do(ctx, http.MethodPost, "/api/sessions/"+url.PathEscape(c.session)+"/start", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
 	return nil
 }
 
+func (c *Client) StopSession(ctx context.Context, logout bool) error {
+	if logout {
+		resp, err := c.do(ctx, http.MethodPost, "/api/sessions/"+url.PathEscape(c.session)+"/logout", nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/api/sessions/"+url.PathEscape(c.session)+"/stop", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// maxQRCodeBytes bounds the image read from WAHA; pairing codes are a few kilobytes
+const maxQRCodeBytes = 1 << 20
+
+func (c *Client) QRCode(ctx context.Context) ([]byte, error) {
+	resp, err := c.request(ctx, http.MethodGet, "/api/"+url.PathEscape(c.session)+"/auth/qr?format=image", nil, "image/png")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	png, err := io.ReadAll(io.LimitReader(resp.Body, maxQRCodeBytes))
+	if err != nil {
+		return nil, fmt.Errorf("waha: read qr code failed: %w", err)
+	}
+	return png, nil
+}
+
 // formatChatID converts an international phone to WAHA chatId: remove '+' and non-digits, append '@c.us'
 func formatChatID(phone string) string {
 	// strip spaces, dashes, parentheses
@@ -73,3 +440,18 @@ func formatChatID(phone string) string {
 	// ensure no leading '+' remains; cleaned already removed non-digits
 	return cleaned + "@c.us"
 }
+
+// PhoneFromChatID returns the E.164 number of a personal chat id such as "6281234567890@c.us",
+// or an empty string for groups, channels and ids that hide the number
+func PhoneFromChatID(chatID string) string {
+	number, server, ok := strings.Cut(chatID, "@")
+	if !ok || (server != "c.us" && server != "s.whatsapp.net") || number == "" {
+		return ""
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return "+" + number
+}
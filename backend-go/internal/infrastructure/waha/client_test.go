@@ -0,0 +1,165 @@
+package waha
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/waha/wahatest"
+)
+
+// statusHook records the session statuses reported to a webhook, failing the test on a bad signature
+type statusHook struct {
+	mu       sync.Mutex
+	statuses []string
+}
+
+func newStatusHook(t *testing.T, key string) (*httptest.Server, *statusHook) {
+	h := &statusHook{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha512.New, []byte(key))
+		mac.Write(body)
+		if r.Header.Get("X-Webhook-Hmac") != hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("webhook signature %q does not match the body", r.Header.Get("X-Webhook-Hmac"))
+		}
+		var event struct {
+			Event   string `json:"event"`
+			Payload struct {
+				Status string `json:"status"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("invalid webhook body: %v", err)
+		}
+		if event.Event == "session.status" {
+			h.mu.Lock()
+			h.statuses = append(h.statuses, event.Payload.Status)
+			h.mu.Unlock()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, h
+}
+
+func (h *statusHook) Statuses() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.statuses)
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	srv, fake := wahatest.NewServer("key")
+	defer srv.Close()
+	hook, events := newStatusHook(t, "secret")
+	ctx := context.Background()
+	c := New(srv.URL, "key", "salon-1", Webhook{URL: hook.URL, HMACKey: "secret"})
+
+	status := func(want string) {
+		t.Helper()
+		got, err := c.SessionStatus(ctx)
+		if err != nil {
+			t.Fatalf("SessionStatus: %v", err)
+		}
+		if got != want {
+			t.Fatalf("status %s, want %s", got, want)
+		}
+	}
+
+	if err := c.StartSession(ctx); err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	status(entities.WhatsAppSessionScanQRCode)
+	qr, err := c.QRCode(ctx)
+	if err != nil {
+		t.Fatalf("QRCode: %v", err)
+	}
+	if _, err := png.Decode(bytes.NewReader(qr)); err != nil {
+		t.Fatalf("QR code is not a PNG: %v", err)
+	}
+
+	fake.Pair("salon-1")
+	status(entities.WhatsAppSessionWorking)
+	if _, err := c.QRCode(ctx); err == nil {
+		t.Error("a paired session returned a QR code")
+	}
+
+	// A stopped session resumes without a new scan, a logged out one needs pairing again
+	if err := c.StopSession(ctx, false); err != nil {
+		t.Fatalf("StopSession: %v", err)
+	}
+	status(entities.WhatsAppSessionStopped)
+	if err := c.StartSession(ctx); err != nil {
+		t.Fatalf("StartSession after stop: %v", err)
+	}
+	status(entities.WhatsAppSessionWorking)
+	if err := c.StopSession(ctx, true); err != nil {
+		t.Fatalf("StopSession with logout: %v", err)
+	}
+	if err := c.StartSession(ctx); err != nil {
+		t.Fatalf("StartSession after logout: %v", err)
+	}
+	status(entities.WhatsAppSessionScanQRCode)
+
+	want := []string{
+		entities.WhatsAppSessionScanQRCode, entities.WhatsAppSessionWorking, entities.WhatsAppSessionStopped,
+		entities.WhatsAppSessionWorking, entities.WhatsAppSessionScanQRCode, entities.WhatsAppSessionStopped,
+		entities.WhatsAppSessionScanQRCode,
+	}
+	if got := events.Statuses(); !slices.Equal(got, want) {
+		t.Errorf("webhook saw statuses %v, want %v", got, want)
+	}
+}
+
+func TestSendAndMessageStatus(t *testing.T) {
+	srv, fake := wahatest.NewServer("key", "default")
+	defer srv.Close()
+	ctx := context.Background()
+	c := New(srv.URL, "key", "default", Webhook{})
+
+	id, err := c.SendText(ctx, "+62 812-3456-7890", "Your appointment is tomorrow")
+	if err != nil {
+		t.Fatalf("SendText: %v", err)
+	}
+	sent := fake.Messages()
+	if len(sent) != 1 || sent[0].ID != id || sent[0].ChatID != "6281234567890@c.us" || sent[0].Payload["text"] != "Your appointment is tomorrow" {
+		t.Fatalf("sent %+v with id %q", sent, id)
+	}
+
+	ack, err := c.MessageStatus(ctx, "+6281234567890", id)
+	if err != nil || ack != "" {
+		t.Fatalf("MessageStatus = %q, %v before a receipt", ack, err)
+	}
+	fake.Ack(id, 3)
+	if ack, err := c.MessageStatus(ctx, "+6281234567890", id); err != nil || ack != entities.MessageAckRead {
+		t.Errorf("MessageStatus = %q, %v, want read", ack, err)
+	}
+
+	// Sessions that are not WORKING cannot send
+	if _, err := c.Session("salon-1").SendText(ctx, "+6281234567890", "hi"); err == nil {
+		t.Error("sent from a session that does not exist")
+	}
+}
+
+func TestAPIKeyRequired(t *testing.T) {
+	srv, _ := wahatest.NewServer("key", "default")
+	defer srv.Close()
+
+	_, err := New(srv.URL, "wrong", "default", Webhook{}).SessionStatus(context.Background())
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusUnauthorized {
+		t.Errorf("SessionStatus with a wrong key = %v, want 401", err)
+	}
+}
@@ -0,0 +1,442 @@
+// Package wahatest is an in-memory WAHA server for tests and local development. It keeps
+// sessions and sent messages in memory and reports session status changes and receipts to
+// the webhooks sessions were created with, signed the way WAHA signs them.
+package wahatest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/domain/entities"
+)
+
+// Message is a message sent through the fake
+type Message struct {
+	// ID is the message key, as the client returns it
+	ID      string
+	Session string
+	ChatID  string
+	// Endpoint is the send endpoint used, such as sendText or sendImage
+	Endpoint string
+	Payload  map[string]any
+	// Ack is the WAHA ack level, 1 (server) until changed with Ack
+	Ack int
+}
+
+type webhook struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	HMAC   *struct {
+		Key string `json:"key"`
+	} `json:"hmac"`
+}
+
+type session struct {
+	name     string
+	status   string
+	webhooks []webhook
+	presence map[string]string
+	// paired survives a stop, so the session resumes without a new scan
+	paired bool
+}
+
+// Fake implements the parts of the WAHA API the client uses
+type Fake struct {
+	apiKey     string
+	mux        *http.ServeMux
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	messages []*Message
+	seq      int
+}
+
+// New returns a fake that requires apiKey when it is not empty. The named sessions exist
+// and are WORKING, like the platform session of a deployment.
+func New(apiKey string, working ...string) *Fake {
+	f := &Fake{
+		apiKey:     apiKey,
+		mux:        http.NewServeMux(),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		sessions:   make(map[string]*session),
+	}
+	for _, name := range working {
+		f.sessions[name] = &session{name: name, status: entities.WhatsAppSessionWorking, presence: map[string]string{}, paired: true}
+	}
+
+	f.mux.HandleFunc("POST /api/sessions", f.createSession)
+	f.mux.HandleFunc("GET /api/sessions/{name}", f.getSession)
+	f.mux.HandleFunc("POST /api/sessions/{name}/start", f.startSession)
+	f.mux.HandleFunc("POST /api/sessions/{name}/stop", f.stopSession)
+	f.mux.HandleFunc("POST /api/sessions/{name}/logout", f.logoutSession)
+	f.mux.HandleFunc("GET /api/{session}/auth/qr", f.qrCode)
+	f.mux.HandleFunc("POST /api/{session}/presence", f.setPresence)
+	f.mux.HandleFunc("GET /api/{session}/chats/{chat}/messages/{id}", f.getMessage)
+	for _, endpoint := range []string{"sendText", "sendImage", "sendFile", "sendLocation", "sendList", "send/buttons/reply"} {
+		f.mux.HandleFunc("POST /api/"+endpoint, f.send(endpoint))
+	}
+	return f
+}
+
+// NewServer starts a fake on a local port; close the server when done
+func NewServer(apiKey string, working ...string) (*httptest.Server, *Fake) {
+	f := New(apiKey, working...)
+	return httptest.NewServer(f), f
+}
+
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.apiKey != "" && r.Header.Get("X-Api-Key") != f.apiKey {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "invalid api key"})
+		return
+	}
+	f.mux.ServeHTTP(w, r)
+}
+
+// Messages returns a copy of every message sent so far, oldest first
+func (f *Fake) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]Message, len(f.messages))
+	for i, m := range f.messages {
+		out[i] = *m
+	}
+	return out
+}
+
+// Status returns the status of a session, empty when it does not exist
+func (f *Fake) Status(name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.sessions[name]; ok {
+		return s.status
+	}
+	return ""
+}
+
+// Presence returns what the session last showed in a chat
+func (f *Fake) Presence(name, chatID string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.sessions[name]; ok {
+		return s.presence[chatID]
+	}
+	return ""
+}
+
+// Pair acts as a phone scanning the QR code of a session waiting for it
+func (f *Fake) Pair(name string) {
+	f.setStatus(name, entities.WhatsAppSessionWorking)
+}
+
+// SetStatus changes the status of a session, as when its phone goes offline
+func (f *Fake) SetStatus(name, status string) {
+	f.setStatus(name, status)
+}
+
+// Ack moves a sent message to the given ack level and reports it to the session's webhooks
+func (f *Fake) Ack(id string, level int) {
+	f.mu.Lock()
+	var msg *Message
+	for _, m := range f.messages {
+		if m.ID == id {
+			msg = m
+		}
+	}
+	if msg == nil {
+		f.mu.Unlock()
+		return
+	}
+	msg.Ack = level
+	hooks := f.sessions[msg.Session].webhooks
+	payload := map[string]any{"id": serializedID(msg.ChatID, msg.ID), "ack": level, "ackName": ackName(level)}
+	f.mu.Unlock()
+
+	f.notify(hooks, "message.ack", msg.Session, payload)
+}
+
+func (f *Fake) setStatus(name, status string) {
+	f.mu.Lock()
+	s, ok := f.sessions[name]
+	if !ok || s.status == status {
+		f.mu.Unlock()
+		return
+	}
+	s.status = status
+	hooks := s.webhooks
+	f.mu.Unlock()
+
+	f.notify(hooks, "session.status", name, map[string]string{"name": name, "status": status})
+}
+
+// notify posts an event to every webhook subscribed to it. Delivery happens before the
+// call returns, so tests see its effects right away; failures are only logged, as in WAHA.
+func (f *Fake) notify(hooks []webhook, event, session string, payload any) {
+	body, err := json.Marshal(map[string]any{"event": event, "session": session, "payload": payload})
+	if err != nil {
+		log.Println(fmt.Errorf("wahatest: marshal event failed: %w", err))
+		return
+	}
+	for _, hook := range hooks {
+		if !subscribed(hook.Events, event) {
+			continue
+		}
+		req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+		if err != nil {
+			log.Println(fmt.Errorf("wahatest: webhook request build failed: %w", err))
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if hook.HMAC != nil {
+			mac := hmac.New(sha512.New, []byte(hook.HMAC.Key))
+			mac.Write(body)
+			req.Header.Set("X-Webhook-Hmac", hex.EncodeToString(mac.Sum(nil)))
+			req.Header.Set("X-Webhook-Hmac-Algorithm", "sha512")
+		}
+		resp, err := f.httpClient.Do(req)
+		if err != nil {
+			log.Println(fmt.Errorf("wahatest: webhook %s failed: %w", hook.URL, err))
+			continue
+		}
+		resp.Body.Close()
+	}
+}
+
+func (f *Fake) createSession(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name   string `json:"name"`
+		Start  *bool  `json:"start"`
+		Config struct {
+			Webhooks []webhook `json:"webhooks"`
+		} `json:"config"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "invalid session"})
+		return
+	}
+	f.mu.Lock()
+	if _, ok := f.sessions[req.Name]; ok {
+		f.mu.Unlock()
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "session already exists"})
+		return
+	}
+	f.sessions[req.Name] = &session{
+		name:     req.Name,
+		status:   entities.WhatsAppSessionStopped,
+		webhooks: req.Config.Webhooks,
+		presence: map[string]string{},
+	}
+	f.mu.Unlock()
+
+	// WAHA starts new sessions unless told otherwise
+	if req.Start == nil || *req.Start {
+		f.setStatus(req.Name, entities.WhatsAppSessionScanQRCode)
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"name": req.Name, "status": f.Status(req.Name)})
+}
+
+func (f *Fake) getSession(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	status := f.Status(name)
+	if status == "" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "session not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"name": name, "status": status})
+}
+
+// startSession resumes a stopped session; it needs pairing again only after a logout
+func (f *Fake) startSession(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	f.mu.Lock()
+	s, ok := f.sessions[name]
+	var next string
+	if ok {
+		next = entities.WhatsAppSessionScanQRCode
+		if s.paired {
+			next = entities.WhatsAppSessionWorking
+		}
+	}
+	f.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "session not found"})
+		return
+	}
+	f.setStatus(name, next)
+	writeJSON(w, http.StatusCreated, map[string]string{"name": name, "status": f.Status(name)})
+}
+
+func (f *Fake) stopSession(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	f.mu.Lock()
+	s, ok := f.sessions[name]
+	if ok && s.status == entities.WhatsAppSessionWorking {
+		s.paired = true
+	}
+	f.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "session not found"})
+		return
+	}
+	f.setStatus(name, entities.WhatsAppSessionStopped)
+	writeJSON(w, http.StatusCreated, map[string]string{"name": name, "status": entities.WhatsAppSessionStopped})
+}
+
+func (f *Fake) logoutSession(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	f.mu.Lock()
+	s, ok := f.sessions[name]
+	if ok {
+		s.paired = false
+	}
+	f.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "session not found"})
+		return
+	}
+	f.setStatus(name, entities.WhatsAppSessionScanQRCode)
+	writeJSON(w, http.StatusCreated, map[string]string{"name": name, "status": entities.WhatsAppSessionScanQRCode})
+}
+
+// qrCode returns a blank PNG; a phone "scans" it by calling Pair
+func (f *Fake) qrCode(w http.ResponseWriter, r *http.Request) {
+	if f.Status(r.PathValue("session")) != entities.WhatsAppSessionScanQRCode {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "session is not waiting for a qr code scan"})
+		return
+	}
+	img := image.NewGray(image.Rect(0, 0, 33, 33))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_ = png.Encode(w, img)
+}
+
+func (f *Fake) setPresence(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ChatID   string `json:"chatId"`
+		Presence string `json:"presence"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChatID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "invalid presence"})
+		return
+	}
+	f.mu.Lock()
+	s, ok := f.sessions[r.PathValue("session")]
+	if ok {
+		s.presence[req.ChatID] = req.Presence
+	}
+	f.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "session not found"})
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{})
+}
+
+func (f *Fake) getMessage(w http.ResponseWriter, r *http.Request) {
+	session, chatID, id := r.PathValue("session"), r.PathValue("chat"), r.PathValue("id")
+	f.mu.Lock()
+	var found *Message
+	for _, m := range f.messages {
+		if m.Session == session && m.ChatID == chatID && serializedID(m.ChatID, m.ID) == id {
+			found = m
+		}
+	}
+	var ack int
+	if found != nil {
+		ack = found.Ack
+	}
+	f.mu.Unlock()
+	if found == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "message not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "ack": ack, "ackName": ackName(ack)})
+}
+
+// send records a message for sessions that are WORKING, and fails like WAHA otherwise
+func (f *Fake) send(endpoint string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": "invalid payload"})
+			return
+		}
+		sessionName, _ := payload["session"].(string)
+		chatID, _ := payload["chatId"].(string)
+		if chatID == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": "chatId is required"})
+			return
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		s, ok := f.sessions[sessionName]
+		if !ok || s.status != entities.WhatsAppSessionWorking {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "session is not WORKING"})
+			return
+		}
+		f.seq++
+		msg := &Message{
+			ID:       fmt.Sprintf("FAKE%08d", f.seq),
+			Session:  sessionName,
+			ChatID:   chatID,
+			Endpoint: endpoint,
+			Payload:  payload,
+			Ack:      1,
+		}
+		f.messages = append(f.messages, msg)
+		writeJSON(w, http.StatusCreated, map[string]any{"id": serializedID(chatID, msg.ID)})
+	}
+}
+
+func serializedID(chatID, key string) string {
+	return "true_" + chatID + "_" + key
+}
+
+func subscribed(events []string, event string) bool {
+	for _, e := range events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func ackName(level int) string {
+	switch level {
+	case -1:
+		return "ERROR"
+	case 0:
+		return "PENDING"
+	case 1:
+		return "SERVER"
+	case 2:
+		return "DEVICE"
+	case 3:
+		return "READ"
+	case 4:
+		return "PLAYED"
+	default:
+		return strings.ToUpper(fmt.Sprint(level))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,35 @@
+// Command fake-waha serves the in-memory WAHA fake for local development. Point WAHA_URL
+// at it; sessions pair right after their QR code is fetched, so the whole flow works
+// without a phone.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/waha/wahatest"
+)
+
+func main() {
+	addr := flag.String("addr", ":3000", "address to listen on")
+	session := flag.String("session", "default", "platform session, started as WORKING")
+	flag.Parse()
+
+	fake := wahatest.New(os.Getenv("WAHA_API_KEY"), *session)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.ServeHTTP(w, r)
+		// Scanning happens on a phone in WAHA; here the first QR fetch stands in for it
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/auth/qr") {
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/"), "/auth/qr")
+			go fake.Pair(name)
+		}
+	})
+
+	log.Printf("fake WAHA listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, handler); err != nil {
+		log.Fatal(err)
+	}
+}
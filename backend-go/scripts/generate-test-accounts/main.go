@@ -9,8 +9,6 @@ import (
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/database/dbgen"
 	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/jwt"
-	"github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/smtp"
-	wahainfra "github.com/williamchand/fullstack-fastapi/backend-go/internal/infrastructure/waha"
 )
 
 var (
@@ -33,10 +31,12 @@ func setup() {
 	oAuthRepo := database.NewOAuthRepository(queries, dbPool)
 	emailTemplateRepo := database.NewEmailTemplateRepository(queries, dbPool)
 	verificationRepo := database.NewVerificationCodeRepository(queries, dbPool)
-	smtpSender := smtp.NewSMTPSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
-	wahaClient := wahainfra.New(cfg.WAHA.URL, cfg.WAHA.APIKey, cfg.WAHA.Session)
+	outboxRepo := database.NewOutboxRepository(queries, dbPool)
+	suppressionRepo := database.NewEmailSuppressionRepository(queries, dbPool)
 	jwtService, _ := jwt.NewService(cfg)
-	userService = services.NewUserService(cfg, userRepo, oAuthRepo, transactionManager, jwtService, emailTemplateRepo, verificationRepo, smtpSender, wahaClient)
+	// Messages are only queued here, the app's outbox worker delivers them
+	notifier, _ := services.NewNotifier(cfg, emailTemplateRepo, suppressionRepo, nil, nil)
+	userService = services.NewUserService(cfg, userRepo, oAuthRepo, transactionManager, jwtService, notifier, verificationRepo, outboxRepo, nil, nil)
 }
 
 func generateTestAccounts() {